@@ -0,0 +1,23 @@
+package accesslog
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+)
+
+type Entry struct {
+	Id        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Balancer  bson.ObjectID `bson:"balancer" json:"balancer"`
+	Node      bson.ObjectID `bson:"node" json:"node"`
+	Timestamp time.Time     `bson:"timestamp" json:"timestamp"`
+	Client    string        `bson:"client" json:"client"`
+	Method    string        `bson:"method" json:"method"`
+	Host      string        `bson:"host" json:"host"`
+	Path      string        `bson:"path" json:"path"`
+	Status    int           `bson:"status" json:"status"`
+	Latency   int64         `bson:"latency" json:"latency"`
+	Backend   string        `bson:"backend" json:"backend"`
+	Bytes     int64         `bson:"bytes" json:"bytes"`
+	Limited   bool          `bson:"limited" json:"limited"`
+}
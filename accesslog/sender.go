@@ -0,0 +1,106 @@
+package accesslog
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/requires"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	buffer     chan *Entry
+	bufferLock sync.Mutex
+	dropped    = 0
+)
+
+func getBuffer() chan *Entry {
+	bufferLock.Lock()
+	defer bufferLock.Unlock()
+
+	if buffer == nil {
+		size := settings.Router.AccessLogBuffer
+		if size < 1 {
+			size = 10000
+		}
+		buffer = make(chan *Entry, size)
+	}
+
+	return buffer
+}
+
+func Log(entry *Entry) {
+	select {
+	case getBuffer() <- entry:
+	default:
+		bufferLock.Lock()
+		dropped += 1
+		bufferLock.Unlock()
+	}
+}
+
+func send(entries []*Entry) {
+	db := database.GetDatabase()
+	defer db.Close()
+
+	docs := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		docs[i] = entry
+	}
+
+	coll := db.AccessLogs()
+	_, err := coll.InsertMany(db, docs)
+	if err != nil {
+		err = database.ParseError(err)
+		logrus.WithFields(logrus.Fields{
+			"count": len(entries),
+			"error": err,
+		}).Error("accesslog: Failed to insert access logs")
+	}
+}
+
+func initSender() {
+	buf := getBuffer()
+	entries := []*Entry{}
+	ticker := time.NewTicker(1 * time.Second)
+
+	for {
+		select {
+		case entry := <-buf:
+			entries = append(entries, entry)
+			if len(entries) < settings.Router.AccessLogBatch {
+				continue
+			}
+		case <-ticker.C:
+			bufferLock.Lock()
+			drop := dropped
+			dropped = 0
+			bufferLock.Unlock()
+
+			if drop > 0 {
+				logrus.WithFields(logrus.Fields{
+					"dropped": drop,
+				}).Warn("accesslog: Access log buffer full")
+			}
+		}
+
+		if len(entries) == 0 {
+			continue
+		}
+
+		send(entries)
+		entries = []*Entry{}
+	}
+}
+
+func init() {
+	module := requires.New("accesslog")
+	module.After("settings")
+
+	module.Handler = func() (err error) {
+		go initSender()
+		return
+	}
+}
@@ -0,0 +1,77 @@
+package accesslog
+
+import (
+	"regexp"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
+	"github.com/pritunl/pritunl-cloud/database"
+)
+
+type Filter struct {
+	Status int
+	Client string
+	Host   string
+	Path   string
+}
+
+func GetAll(db *database.Database, balncId bson.ObjectID,
+	filter *Filter, limit int64) (entries []*Entry, err error) {
+
+	coll := db.AccessLogs()
+	entries = []*Entry{}
+
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	query := bson.M{
+		"balancer": balncId,
+	}
+	if filter != nil {
+		if filter.Status != 0 {
+			query["status"] = filter.Status
+		}
+		if filter.Client != "" {
+			query["client"] = filter.Client
+		}
+		if filter.Host != "" {
+			query["host"] = filter.Host
+		}
+		if filter.Path != "" {
+			query["path"] = &bson.M{
+				"$regex": "^" + regexp.QuoteMeta(filter.Path),
+			}
+		}
+	}
+
+	cursor, err := coll.Find(db, query, options.Find().
+		SetSort(&bson.D{
+			{"$natural", -1},
+		}).
+		SetLimit(limit))
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		entry := &Entry{}
+		err = cursor.Decode(entry)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		entries = append(entries, entry)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
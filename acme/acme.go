@@ -15,6 +15,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/certificate"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/dns"
+	"github.com/pritunl/pritunl-cloud/envelope"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/event"
 	"github.com/pritunl/pritunl-cloud/secret"
@@ -418,7 +419,7 @@ func create(db *database.Database, cert *certificate.Certificate,
 		certPem += strings.TrimSpace(string(pem.EncodeToMemory(certBlock)))
 	}
 
-	cert.Key = strings.TrimSpace(string(keyPem))
+	cert.Key = envelope.String(strings.TrimSpace(string(keyPem)))
 	cert.Certificate = certPem
 	cert.AcmeHash = cert.Hash()
 
@@ -8,6 +8,7 @@ import (
 	"github.com/dropbox/godropbox/container/set"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/debtracker"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/settings"
 	"github.com/pritunl/pritunl-cloud/telemetry"
@@ -237,6 +238,64 @@ func (a *Advisory) MergeVuxml(pkg string, entry *vuxml.VuxmlEntry,
 	}
 }
 
+func severityRank(severity string) int {
+	switch severity {
+	case critical:
+		return 3
+	case important:
+		return 2
+	case moderate:
+		return 1
+	}
+	return 0
+}
+
+func (a *Advisory) MergeSeverity(severity string) {
+	if severityRank(severity) > severityRank(a.Severity) {
+		a.Severity = severity
+	}
+}
+
+func (a *Advisory) MergeTracker(updt *telemetry.Update, urgency string,
+	entries []*debtracker.Entry, vulns []*vulnerability.Vulnerability) {
+
+	a.MergePackages(updt.Packages)
+
+	switch urgency {
+	case "critical":
+		a.MergeSeverity(critical)
+	case "high":
+		a.MergeSeverity(important)
+	case "medium", "low":
+		a.MergeSeverity(moderate)
+	}
+
+	if a.Description == "" {
+		descs := []string{}
+		for _, entry := range entries {
+			if entry != nil && entry.Description != "" {
+				descs = append(descs, entry.Description)
+			}
+		}
+		a.Description = utils.FilterStrExt(
+			strings.Join(descs, "\n"), settings.Telemetry.DescriptionLimit)
+	}
+
+	vulnsSet := set.NewSet()
+	for _, vuln := range a.Vulnerabilities {
+		vulnsSet.Add(vuln.Id)
+	}
+
+	for _, vuln := range vulns {
+		if vuln == nil || vulnsSet.Contains(vuln.Id) {
+			continue
+		}
+		vulnsSet.Add(vuln.Id)
+
+		a.Vulnerabilities = append(a.Vulnerabilities, vuln)
+	}
+}
+
 func (a *Advisory) buildDismissUpdate(dismiss, restore bool,
 	dismissals, restores []bson.ObjectID) (update bson.M) {
 
@@ -327,7 +386,7 @@ func FromUpdate(updt *telemetry.Update, orgId bson.ObjectID, now time.Time,
 	return &Advisory{
 		Organization:       orgId,
 		Reference:          updt.Id,
-		Type:               RedHat,
+		Type:               updt.Type,
 		Updated:            now,
 		Severity:           updt.Severity,
 		Description:        updt.Description,
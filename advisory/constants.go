@@ -8,6 +8,8 @@ import (
 const (
 	RedHat  = "rhel"
 	FreeBsd = "freebsd"
+	Debian  = "debian"
+	Ubuntu  = "ubuntu"
 
 	Low      = 1
 	Medium   = 2
@@ -25,6 +27,8 @@ var (
 	ValidTypes = set.NewSet(
 		RedHat,
 		FreeBsd,
+		Debian,
+		Ubuntu,
 	)
 
 	ValidSeverities = set.NewSet(
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
 	"runtime/debug"
 	"strings"
 	"syscall"
@@ -27,6 +28,7 @@ Usage: pci COMMAND
 Commands:
   get          Get value from IMDS
   image        Sanitize host files and initiate shutdown for imaging
+  metric       Set custom metric value for autoscaling
   set-primary  Notify host to set this deployment as primary
   version      Show version
 `
@@ -223,6 +225,7 @@ func main() {
 
 		if !image {
 			ids.SetInitialized()
+			handleShutdown(ids)
 
 			err = ids.SyncStatus(runStatus)
 			if err != nil {
@@ -377,6 +380,23 @@ func main() {
 			return
 		}
 
+		break
+	case "metric":
+		if flag.NArg() != 3 {
+			fmt.Println("Usage: pci metric NAME VALUE")
+			os.Exit(1)
+			return
+		}
+
+		err := telemetry.SetCustomMetric(flag.Arg(1), flag.Arg(2))
+		if err != nil {
+			logger.WithFields(logger.Fields{
+				"error": err,
+			}).Error("agent: Set custom metric failed")
+			utils.DelayExit(1, 1*time.Second)
+			return
+		}
+
 		break
 	case "status":
 		mem, err := pritunl_utils.GetMemInfo()
@@ -435,6 +455,17 @@ func main() {
 	return
 }
 
+func handleShutdown(ids *imds.Imds) {
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT)
+
+	go func() {
+		<-sigChan
+		ids.RunShutdown()
+		os.Exit(0)
+	}()
+}
+
 func daemonFork() (err error) {
 	fmt.Println("pritunl-cloud-agent: Forking daemon process")
 
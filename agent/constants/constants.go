@@ -1,7 +1,10 @@
 package constants
 
 const (
-	Version      = "1.0.3248.95"
-	ImdsConfPath = "/etc/pritunl-imds.json"
-	ImdsLogPath  = "/var/log/pritunl-imds.log"
+	Version        = "1.0.3248.95"
+	ImdsConfPath   = "/etc/pritunl-imds.json"
+	ImdsLogPath    = "/var/log/pritunl-imds.log"
+	SshHostKeyPath = "/etc/ssh/pritunl_host_ed25519_key"
+	SshdConfigDir  = "/etc/ssh/sshd_config.d"
+	SshdConfigPath = "/etc/ssh/sshd_config.d/90-pritunl-cloud.conf"
 )
@@ -41,6 +41,7 @@ type Imds struct {
 	State       string              `json:"state"`
 	engine      *engine.Engine      `json:"-"`
 	initialized bool                `json:"-"`
+	preStop     bool                `json:"-"`
 	waiter      sync.WaitGroup      `json:"-"`
 	journals    map[string]*Journal `json:"-"`
 	syncLock    sync.Mutex          `json:"-"`
@@ -142,6 +143,8 @@ type SyncResp struct {
 	Spec     string           `json:"spec"`
 	Hash     uint32           `json:"hash"`
 	Journals []*types.Journal `json:"journals"`
+	PreStop  bool             `json:"pre_stop"`
+	SshHost  *types.SshHost   `json:"ssh_host"`
 }
 
 func (m *Imds) SyncReady(timeout time.Duration) (err error) {
@@ -279,6 +282,18 @@ func (m *Imds) Sync() (ready bool, err error) {
 		m.engine.Queue(respData.Spec)
 	}
 
+	if respData.SshHost != nil && m.initialized {
+		m.syncSshHost(respData.SshHost)
+	}
+
+	if respData.PreStop && !m.preStop && m.initialized {
+		m.preStop = true
+		go m.runPreStop()
+	} else if !respData.PreStop && m.preStop {
+		m.preStop = false
+		clearPreStopStatus()
+	}
+
 	activeJournals := set.NewSet()
 	for unit := range m.journals {
 		activeJournals.Add(unit)
@@ -339,6 +354,56 @@ func (m *Imds) Sync() (ready bool, err error) {
 	return
 }
 
+func (m *Imds) runPreStop() {
+	defer func() {
+		panc := recover()
+		if panc != nil {
+			logger.WithFields(logger.Fields{
+				"trace": string(debug.Stack()),
+				"panic": panc,
+			}).Error("agent: Panic in pre-stop")
+		}
+		setPreStopStatus(types.PreStopped)
+	}()
+
+	if m.engine == nil || !m.engine.HasPhase(engine.PreStop) {
+		return
+	}
+
+	setPreStopStatus(types.PreStopping)
+
+	logger.Info("agent: Running pre-stop phase")
+
+	err := m.engine.RunPhase(engine.PreStop)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"error": err,
+		}).Error("agent: Failed to run pre-stop phase")
+	}
+}
+
+func (m *Imds) RunShutdown() {
+	if m.engine == nil || !m.engine.HasPhase(engine.Shutdown) {
+		return
+	}
+
+	logger.Info("agent: Running shutdown phase")
+
+	err := m.engine.RunPhase(engine.Shutdown)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"error": err,
+		}).Error("agent: Failed to run shutdown phase")
+	}
+
+	_, err = m.Sync()
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"error": err,
+		}).Error("agent: Failed to sync")
+	}
+}
+
 func (m *Imds) SetInitialized() {
 	m.initialized = true
 	if m.engine != nil {
@@ -422,6 +487,9 @@ func (m *Imds) Wait() (err error) {
 
 func (m *Imds) Init(eng *engine.Engine) (err error) {
 	m.engine = eng
+	if eng != nil {
+		eng.Query = m.Get
+	}
 
 	confData, err := utils.Read(constants.ImdsConfPath)
 	if err != nil {
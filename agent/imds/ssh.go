@@ -0,0 +1,110 @@
+package imds
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pritunl/pritunl-cloud/agent/constants"
+	"github.com/pritunl/pritunl-cloud/imds/types"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/pritunl/tools/logger"
+)
+
+var (
+	curSshCert string
+)
+
+func writeSshFile(pth, data string, perm os.FileMode) (
+	changed bool, err error) {
+
+	data = strings.TrimSpace(data) + "\n"
+
+	cur, _ := utils.ReadExists(pth)
+	if cur == data {
+		return
+	}
+
+	err = utils.CreateWrite(pth, data, perm)
+	if err != nil {
+		return
+	}
+	changed = true
+
+	return
+}
+
+func (m *Imds) syncSshHost(host *types.SshHost) {
+	if host.Certificate == "" || host.Certificate == curSshCert {
+		return
+	}
+
+	err := m.writeSshHost(host)
+	if err != nil {
+		logger.WithFields(logger.Fields{
+			"error": err,
+		}).Error("agent: Failed to update ssh host certificate")
+		return
+	}
+
+	curSshCert = host.Certificate
+}
+
+func (m *Imds) writeSshHost(host *types.SshHost) (err error) {
+	reload := false
+
+	changed, err := writeSshFile(constants.SshHostKeyPath,
+		host.PrivateKey, 0600)
+	if err != nil {
+		return
+	}
+	reload = reload || changed
+
+	changed, err = writeSshFile(constants.SshHostKeyPath+".pub",
+		host.PublicKey, 0644)
+	if err != nil {
+		return
+	}
+	reload = reload || changed
+
+	changed, err = writeSshFile(constants.SshHostKeyPath+"-cert.pub",
+		host.Certificate, 0644)
+	if err != nil {
+		return
+	}
+	reload = reload || changed
+
+	exists, err := utils.ExistsDir(constants.SshdConfigDir)
+	if err != nil {
+		return
+	}
+
+	if exists {
+		changed, err = writeSshFile(constants.SshdConfigPath, fmt.Sprintf(
+			"HostKey %s\nHostCertificate %s-cert.pub",
+			constants.SshHostKeyPath, constants.SshHostKeyPath,
+		), 0644)
+		if err != nil {
+			return
+		}
+		reload = reload || changed
+	}
+
+	if !reload {
+		return
+	}
+
+	logger.WithFields(logger.Fields{
+		"valid_before": host.ValidBefore,
+	}).Info("agent: Reloading sshd with updated host certificate")
+
+	err = utils.Exec("", "systemctl", "reload", "sshd")
+	if err != nil {
+		err = utils.Exec("", "systemctl", "reload", "ssh")
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -3,6 +3,7 @@ package imds
 import (
 	"sync"
 
+	"github.com/pritunl/pritunl-cloud/engine"
 	"github.com/pritunl/pritunl-cloud/imds/types"
 	"github.com/pritunl/pritunl-cloud/telemetry"
 )
@@ -26,6 +27,8 @@ func (m *Imds) GetState(curHash uint32) (data *StateData, err error) {
 	data.Status = curStatus
 	curStatusLock.Unlock()
 
+	data.PreStopHook = m.engine != nil && m.engine.HasPhase(engine.PreStop)
+
 	data.Metrics = telemetry.Metrics.GetAll()
 
 	updates, ok := telemetry.Updates.Get()
@@ -39,7 +42,23 @@ func (m *Imds) GetState(curHash uint32) (data *StateData, err error) {
 }
 
 func SetStatus(status string) {
+	curStatusLock.Lock()
+	if curStatus != types.PreStopping && curStatus != types.PreStopped {
+		curStatus = status
+	}
+	curStatusLock.Unlock()
+}
+
+func setPreStopStatus(status string) {
 	curStatusLock.Lock()
 	curStatus = status
 	curStatusLock.Unlock()
 }
+
+func clearPreStopStatus() {
+	curStatusLock.Lock()
+	if curStatus == types.PreStopping || curStatus == types.PreStopped {
+		curStatus = types.Running
+	}
+	curStatusLock.Unlock()
+}
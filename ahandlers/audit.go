@@ -1,9 +1,14 @@
 package ahandlers
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/audit"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/demo"
@@ -50,3 +55,120 @@ func auditsGet(c *gin.Context) {
 
 	c.JSON(200, data)
 }
+
+func parseExportTime(val string) (tm time.Time, ok bool) {
+	if val == "" {
+		ok = true
+		return
+	}
+
+	tm, err := time.Parse(time.RFC3339, val)
+	if err != nil {
+		return
+	}
+	ok = true
+
+	return
+}
+
+func auditExportGet(c *gin.Context) {
+	if demo.IsDemo() {
+		utils.AbortWithStatus(c, 404)
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	userId := bson.NilObjectID
+	if c.Query("user_id") != "" {
+		var ok bool
+		userId, ok = utils.ParseObjectId(c.Query("user_id"))
+		if !ok {
+			utils.AbortWithStatus(c, 400)
+			return
+		}
+	}
+
+	start, ok := parseExportTime(c.Query("start"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+	end, ok := parseExportTime(c.Query("end"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	format := c.DefaultQuery("format", "jsonl")
+	filename := fmt.Sprintf("audit-%s.%s",
+		time.Now().UTC().Format("20060102150405"), format)
+
+	var handler func(adt *audit.Audit) error
+
+	switch format {
+	case "jsonl":
+		c.Header("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(c.Writer)
+
+		handler = func(adt *audit.Audit) error {
+			return enc.Encode(adt)
+		}
+		break
+	case "csv":
+		c.Header("Content-Type", "text/csv")
+		writer := csv.NewWriter(c.Writer)
+		defer writer.Flush()
+
+		err := writer.Write([]string{
+			"id",
+			"sequence",
+			"timestamp",
+			"user",
+			"type",
+			"ip",
+			"fields",
+			"prev",
+			"hash",
+		})
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		handler = func(adt *audit.Audit) error {
+			ip := ""
+			if adt.Agent != nil {
+				ip = adt.Agent.Ip
+			}
+
+			fields, _ := json.Marshal(adt.Fields.Strings())
+
+			return writer.Write([]string{
+				adt.Id.Hex(),
+				strconv.FormatInt(adt.Sequence, 10),
+				adt.Timestamp.UTC().Format(time.RFC3339Nano),
+				adt.User.Hex(),
+				adt.Type,
+				ip,
+				string(fields),
+				adt.Prev,
+				adt.Hash,
+			})
+		}
+		break
+	default:
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	c.Header("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Status(200)
+
+	err := audit.Export(db, userId, start, end, handler)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+}
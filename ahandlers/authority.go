@@ -15,6 +15,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/demo"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/sshcert"
 	"github.com/pritunl/pritunl-cloud/utils"
 )
 
@@ -28,6 +29,10 @@ type authorityData struct {
 	Key          string        `json:"key"`
 	Principals   []string      `json:"principals"`
 	Certificate  string        `json:"certificate"`
+
+	HostCertificates bool `json:"host_certificates"`
+	UserTtl          int  `json:"user_ttl"`
+	HostTtl          int  `json:"host_ttl"`
 }
 
 type authoritiesData struct {
@@ -72,6 +77,9 @@ func authorityPut(c *gin.Context) {
 	authr.Key = data.Key
 	authr.Principals = data.Principals
 	authr.Certificate = data.Certificate
+	authr.HostCertificates = data.HostCertificates
+	authr.UserTtl = data.UserTtl
+	authr.HostTtl = data.HostTtl
 
 	fields := set.NewSet(
 		"name",
@@ -82,6 +90,10 @@ func authorityPut(c *gin.Context) {
 		"key",
 		"principals",
 		"certificate",
+		"private_key",
+		"host_certificates",
+		"user_ttl",
+		"host_ttl",
 	)
 
 	errData, err := authr.Validate(db)
@@ -134,6 +146,10 @@ func authorityPost(c *gin.Context) {
 		Key:          data.Key,
 		Principals:   data.Principals,
 		Certificate:  data.Certificate,
+
+		HostCertificates: data.HostCertificates,
+		UserTtl:          data.UserTtl,
+		HostTtl:          data.HostTtl,
 	}
 
 	errData, err := authr.Validate(db)
@@ -332,3 +348,37 @@ func authoritiesGet(c *gin.Context) {
 
 	c.JSON(200, data)
 }
+
+func authorityCertificatesGet(c *gin.Context) {
+	if demo.IsDemo() {
+		c.JSON(200, []*sshcert.Certificate{})
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	authorityId, ok := utils.ParseObjectId(c.Param("authority_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	limit, _ := strconv.ParseInt(c.Query("limit"), 10, 0)
+
+	query := &bson.M{
+		"authority": authorityId,
+	}
+
+	certType := c.Query("type")
+	if certType != "" {
+		(*query)["type"] = certType
+	}
+
+	certs, err := sshcert.GetAll(db, query, limit)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, certs)
+}
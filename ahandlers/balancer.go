@@ -10,6 +10,7 @@ import (
 	"github.com/dropbox/godropbox/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/accesslog"
 	"github.com/pritunl/pritunl-cloud/balancer"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/demo"
@@ -19,18 +20,26 @@ import (
 )
 
 type balancerData struct {
-	Id           bson.ObjectID       `json:"id"`
-	Name         string              `json:"name"`
-	Comment      string              `json:"comment"`
-	State        bool                `json:"state"`
-	Type         string              `json:"type"`
-	Organization bson.ObjectID       `json:"organization"`
-	Datacenter   bson.ObjectID       `json:"datacenter"`
-	Certificates []bson.ObjectID     `json:"certificates"`
-	WebSockets   bool                `json:"websockets"`
-	Domains      []*balancer.Domain  `json:"domains"`
-	Backends     []*balancer.Backend `json:"backends"`
-	CheckPath    string              `json:"check_path"`
+	Id              bson.ObjectID       `json:"id"`
+	Name            string              `json:"name"`
+	Comment         string              `json:"comment"`
+	State           bool                `json:"state"`
+	Type            string              `json:"type"`
+	Organization    bson.ObjectID       `json:"organization"`
+	Datacenter      bson.ObjectID       `json:"datacenter"`
+	Certificates    []bson.ObjectID     `json:"certificates"`
+	WebSockets      bool                `json:"websockets"`
+	Domains         []*balancer.Domain  `json:"domains"`
+	Backends        []*balancer.Backend `json:"backends"`
+	Routes          []*balancer.Route   `json:"routes"`
+	CheckPath       string              `json:"check_path"`
+	RateLimit       int                 `json:"rate_limit"`
+	RateLimitBurst  int                 `json:"rate_limit_burst"`
+	RateLimitKey    string              `json:"rate_limit_key"`
+	RateLimitHeader string              `json:"rate_limit_header"`
+	StickySessions  bool                `json:"sticky_sessions"`
+	StickyCookie    string              `json:"sticky_cookie"`
+	AccessLog       string              `json:"access_log"`
 }
 
 type balancersData struct {
@@ -77,7 +86,15 @@ func balancerPut(c *gin.Context) {
 	balnc.WebSockets = data.WebSockets
 	balnc.Domains = data.Domains
 	balnc.Backends = data.Backends
+	balnc.Routes = data.Routes
 	balnc.CheckPath = data.CheckPath
+	balnc.RateLimit = data.RateLimit
+	balnc.RateLimitBurst = data.RateLimitBurst
+	balnc.RateLimitKey = data.RateLimitKey
+	balnc.RateLimitHeader = data.RateLimitHeader
+	balnc.StickySessions = data.StickySessions
+	balnc.StickyCookie = data.StickyCookie
+	balnc.AccessLog = data.AccessLog
 
 	fields := set.NewSet(
 		"name",
@@ -90,7 +107,15 @@ func balancerPut(c *gin.Context) {
 		"websockets",
 		"domains",
 		"backends",
+		"routes",
 		"check_path",
+		"rate_limit",
+		"rate_limit_burst",
+		"rate_limit_key",
+		"rate_limit_header",
+		"sticky_sessions",
+		"sticky_cookie",
+		"access_log",
 	)
 
 	errData, err := balnc.Validate(db)
@@ -137,17 +162,25 @@ func balancerPost(c *gin.Context) {
 	}
 
 	balnc := &balancer.Balancer{
-		Name:         data.Name,
-		Comment:      data.Comment,
-		State:        data.State,
-		Type:         data.Type,
-		Organization: data.Organization,
-		Datacenter:   data.Datacenter,
-		Certificates: data.Certificates,
-		WebSockets:   data.WebSockets,
-		Domains:      data.Domains,
-		Backends:     data.Backends,
-		CheckPath:    data.CheckPath,
+		Name:            data.Name,
+		Comment:         data.Comment,
+		State:           data.State,
+		Type:            data.Type,
+		Organization:    data.Organization,
+		Datacenter:      data.Datacenter,
+		Certificates:    data.Certificates,
+		WebSockets:      data.WebSockets,
+		Domains:         data.Domains,
+		Backends:        data.Backends,
+		Routes:          data.Routes,
+		CheckPath:       data.CheckPath,
+		RateLimit:       data.RateLimit,
+		RateLimitBurst:  data.RateLimitBurst,
+		RateLimitKey:    data.RateLimitKey,
+		RateLimitHeader: data.RateLimitHeader,
+		StickySessions:  data.StickySessions,
+		StickyCookie:    data.StickyCookie,
+		AccessLog:       data.AccessLog,
 	}
 
 	errData, err := balnc.Validate(db)
@@ -318,3 +351,42 @@ func balancersGet(c *gin.Context) {
 
 	c.JSON(200, data)
 }
+
+func balancerAccessLogGet(c *gin.Context) {
+	if demo.IsDemo() {
+		c.JSON(200, []*accesslog.Entry{})
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	balancerId, ok := utils.ParseObjectId(c.Param("balancer_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	balnc, err := balancer.Get(db, balancerId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	limit, _ := strconv.ParseInt(c.Query("limit"), 10, 0)
+	status, _ := strconv.Atoi(c.Query("status"))
+
+	filter := &accesslog.Filter{
+		Status: status,
+		Client: strings.TrimSpace(c.Query("client")),
+		Host:   strings.TrimSpace(c.Query("host")),
+		Path:   strings.TrimSpace(c.Query("path")),
+	}
+
+	entries, err := accesslog.GetAll(db, balnc.Id, filter, limit)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, entries)
+}
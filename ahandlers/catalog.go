@@ -0,0 +1,34 @@
+package ahandlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/pritunl-cloud/catalog"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type catalogData struct {
+	Catalogs []*catalog.Catalog `json:"catalogs"`
+	Releases []*catalog.Release `json:"releases"`
+}
+
+func imageCatalogGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	cats, err := catalog.GetAll(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	for _, cat := range cats {
+		cat.Releases = nil
+	}
+
+	dta := &catalogData{
+		Catalogs: cats,
+		Releases: catalog.GetReleases(),
+	}
+
+	c.JSON(200, dta)
+}
@@ -44,6 +44,8 @@ type diskData struct {
 	LvSize           int           `json:"lv_size"`
 	NewSize          int           `json:"new_size"`
 	Backup           bool          `json:"backup"`
+	TargetNode       bson.ObjectID `json:"target_node"`
+	TargetPool       bson.ObjectID `json:"target_pool"`
 }
 
 type disksMultiData struct {
@@ -147,6 +149,24 @@ func diskPut(c *gin.Context) {
 
 		fields.Add("action")
 		fields.Add("restore_image")
+	} else if dsk.IsActive() &&
+		(dta.Action == disk.Clone || dta.Action == disk.Move) {
+
+		transferFields, errData, err := dsk.SetTransfer(
+			db, dta.Action, dta.TargetNode, dta.TargetPool)
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		if errData != nil {
+			c.JSON(400, errData)
+			return
+		}
+
+		for field := range transferFields.Iter() {
+			fields.Add(field)
+		}
 	}
 
 	errData, err := dsk.Validate(db)
@@ -33,9 +33,11 @@ func Register(engine *gin.Engine) {
 
 	csrfGroup := authGroup.Group("")
 	csrfGroup.Use(middlewear.CsrfToken)
+	csrfGroup.Use(middlewear.AuditAdmin)
 
 	engine.NoRoute(middlewear.NotFound)
 
+	csrfGroup.GET("/audit/export", auditExportGet)
 	csrfGroup.GET("/audit/:user_id", auditsGet)
 
 	csrfGroup.GET("/alert", alertsGet)
@@ -58,6 +60,8 @@ func Register(engine *gin.Engine) {
 
 	csrfGroup.GET("/authority", authoritiesGet)
 	csrfGroup.GET("/authority/:authority_id", authorityGet)
+	csrfGroup.GET("/authority/:authority_id/certificate",
+		authorityCertificatesGet)
 	csrfGroup.PUT("/authority/:authority_id", authorityPut)
 	csrfGroup.POST("/authority", authorityPost)
 	csrfGroup.DELETE("/authority", authoritiesDelete)
@@ -65,6 +69,7 @@ func Register(engine *gin.Engine) {
 
 	csrfGroup.GET("/balancer", balancersGet)
 	csrfGroup.GET("/balancer/:balancer_id", balancerGet)
+	csrfGroup.GET("/balancer/:balancer_id/access_log", balancerAccessLogGet)
 	csrfGroup.PUT("/balancer/:balancer_id", balancerPut)
 	csrfGroup.POST("/balancer", balancerPost)
 	csrfGroup.DELETE("/balancer", balancersDelete)
@@ -113,6 +118,8 @@ func Register(engine *gin.Engine) {
 	csrfGroup.DELETE("/advisory", advisoriesDelete)
 	csrfGroup.DELETE("/advisory/:advisory_id", advisoryDelete)
 
+	csrfGroup.POST("/vulnerability/import", vulnerabilityImportPost)
+
 	csrfGroup.GET("/disk", disksGet)
 	csrfGroup.GET("/disk/:disk_id", diskGet)
 	csrfGroup.PUT("/disk", disksPut)
@@ -129,6 +136,7 @@ func Register(engine *gin.Engine) {
 	csrfGroup.DELETE("/domain/:domain_id", domainDelete)
 
 	csrfGroup.GET("/event", eventGet)
+	csrfGroup.GET("/event/stream", streamGet)
 
 	csrfGroup.GET("/firewall", firewallsGet)
 	csrfGroup.GET("/firewall/:firewall_id", firewallGet)
@@ -143,14 +151,28 @@ func Register(engine *gin.Engine) {
 	csrfGroup.DELETE("/image", imagesDelete)
 	csrfGroup.DELETE("/image/:image_id", imageDelete)
 
+	csrfGroup.GET("/image_catalog", imageCatalogGet)
+
+	csrfGroup.GET("/image_import", imageImportsGet)
+	csrfGroup.GET("/image_import/:import_id", imageImportGet)
+	csrfGroup.POST("/image_import", imageImportPost)
+	csrfGroup.PUT("/image_import/:import_id/chunk/:chunk",
+		imageImportChunkPut)
+	csrfGroup.POST("/image_import/:import_id/complete",
+		imageImportCompletePost)
+	csrfGroup.DELETE("/image_import/:import_id", imageImportDelete)
+
 	csrfGroup.GET("/instance", instancesGet)
 	csrfGroup.PUT("/instance", instancesPut)
 	csrfGroup.GET("/instance/:instance_id", instanceGet)
 	csrfGroup.GET("/instance/:instance_id/advisory", instanceAdvisoryGet)
 	csrfGroup.GET("/instance/:instance_id/chart", instanceChartGet)
 	csrfGroup.GET("/instance/:instance_id/vnc", instanceVncGet)
+	csrfGroup.GET("/instance/:instance_id/serial", instanceSerialGet)
+	csrfGroup.GET("/instance/:instance_id/serial_log", instanceSerialLogGet)
 	csrfGroup.PUT("/instance/:instance_id", instancePut)
 	csrfGroup.POST("/instance", instancePost)
+	csrfGroup.POST("/instance/:instance_id/clone", instanceClonePost)
 	csrfGroup.DELETE("/instance", instancesDelete)
 	csrfGroup.DELETE("/instance/:instance_id", instanceDelete)
 
@@ -204,20 +226,34 @@ func Register(engine *gin.Engine) {
 	csrfGroup.DELETE("/secret", secretsDelete)
 	csrfGroup.DELETE("/secret/:secr_id", secretDelete)
 
+	csrfGroup.GET("/log_sink", logSinksGet)
+	csrfGroup.GET("/log_sink/:sink_id", logSinkGet)
+	csrfGroup.PUT("/log_sink/:sink_id", logSinkPut)
+	csrfGroup.POST("/log_sink", logSinkPost)
+	csrfGroup.DELETE("/log_sink", logSinksDelete)
+	csrfGroup.DELETE("/log_sink/:sink_id", logSinkDelete)
+
 	csrfGroup.GET("/session/:user_id", sessionsGet)
 	csrfGroup.DELETE("/session/:session_id", sessionDelete)
 
 	csrfGroup.GET("/settings", settingsGet)
 	csrfGroup.PUT("/settings", settingsPut)
 
+	csrfGroup.GET("/task", tasksGet)
+	csrfGroup.PUT("/task/:task_name", taskPut)
+	csrfGroup.GET("/task/:task_name/run", taskRunsGet)
+	csrfGroup.POST("/task/:task_name/run", taskRunPost)
+
 	csrfGroup.GET("/pod", podsGet)
 	csrfGroup.GET("/pod/:pod_id", podGet)
 	csrfGroup.PUT("/pod/:pod_id", podPut)
 	csrfGroup.PUT("/pod/:pod_id/drafts", podDraftsPut)
 	csrfGroup.PUT("/pod/:pod_id/deploy", podDeployPut)
 	csrfGroup.POST("/pod", podPost)
+	csrfGroup.POST("/pod/import", podImportPost)
 	csrfGroup.DELETE("/pod", podsDelete)
 	csrfGroup.DELETE("/pod/:pod_id", podDelete)
+	csrfGroup.GET("/pod/:pod_id/export", podExportGet)
 	csrfGroup.GET("/pod/:pod_id/unit/:unit_id", podUnitGet)
 	csrfGroup.PUT("/pod/:pod_id/unit/:unit_id/deployment",
 		podUnitDeploymentsPut)
@@ -229,7 +265,9 @@ func Register(engine *gin.Engine) {
 		"/pod/:pod_id/unit/:unit_id/deployment/:deployment_id/log",
 		podUnitDeploymentLogGet,
 	)
+	csrfGroup.GET("/pod/:pod_id/unit/:unit_id/log", podUnitLogGet)
 	csrfGroup.GET("/pod/:pod_id/unit/:unit_id/spec", podUnitSpecsGet)
+	csrfGroup.POST("/pod/:pod_id/unit/:unit_id/preview", podUnitPreviewPost)
 	csrfGroup.GET("/pod/:pod_id/unit/:unit_id/spec/:spec_id", podUnitSpecGet)
 
 	csrfGroup.GET("/shape", shapesGet)
@@ -0,0 +1,266 @@
+package ahandlers
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/demo"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/imgimport"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type imageImportData struct {
+	Name         string        `json:"name"`
+	Comment      string        `json:"comment"`
+	Organization bson.ObjectID `json:"organization"`
+	Storage      bson.ObjectID `json:"storage"`
+	Source       string        `json:"source"`
+	Url          string        `json:"url"`
+	Format       string        `json:"format"`
+	Firmware     string        `json:"firmware"`
+	SystemType   string        `json:"system_type"`
+	SystemKind   string        `json:"system_kind"`
+	Size         int64         `json:"size"`
+}
+
+type imageImportsData struct {
+	Imports []*imgimport.Import `json:"imports"`
+	Count   int64               `json:"count"`
+}
+
+func imageImportPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	dta := &imageImportData{}
+
+	err := c.Bind(dta)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	imprt := &imgimport.Import{
+		Name:         dta.Name,
+		Comment:      dta.Comment,
+		Organization: dta.Organization,
+		Storage:      dta.Storage,
+		Source:       dta.Source,
+		Url:          dta.Url,
+		Format:       dta.Format,
+		Firmware:     dta.Firmware,
+		SystemType:   dta.SystemType,
+		SystemKind:   dta.SystemKind,
+		Size:         dta.Size,
+		Created:      time.Now(),
+		Timestamp:    time.Now(),
+	}
+
+	if imprt.Source == imgimport.Upload {
+		imprt.State = imgimport.Uploading
+	} else {
+		imprt.State = imgimport.Pending
+	}
+
+	errData, err := imprt.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = imprt.Insert(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if imprt.Source == imgimport.Upload {
+		err = imprt.StartUpload(db)
+		if err != nil {
+			_ = imgimport.Remove(db, imprt.Id)
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+	}
+
+	event.PublishDispatch(db, "image_import.change")
+
+	c.JSON(200, imprt)
+}
+
+func imageImportChunkPut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	importId, ok := utils.ParseObjectId(c.Param("import_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	number, err := strconv.Atoi(c.Param("chunk"))
+	if err != nil {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	imprt, err := imgimport.Get(db, importId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	errData, err := imprt.PutPart(db, number, c.Request.Body,
+		c.Request.ContentLength)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	c.JSON(200, nil)
+}
+
+func imageImportCompletePost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	importId, ok := utils.ParseObjectId(c.Param("import_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	imprt, err := imgimport.Get(db, importId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	errData, err := imprt.CompleteUpload(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	event.PublishDispatch(db, "image_import.change")
+
+	c.JSON(200, imprt)
+}
+
+func imageImportDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	importId, ok := utils.ParseObjectId(c.Param("import_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	imprt, err := imgimport.Get(db, importId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if imprt.State == imgimport.Processing {
+		errData := &errortypes.ErrorData{
+			Error:   "image_import_processing",
+			Message: "Cannot remove image import while processing",
+		}
+		c.JSON(400, errData)
+		return
+	}
+
+	err = imgimport.Remove(db, importId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	event.PublishDispatch(db, "image_import.change")
+
+	c.JSON(200, nil)
+}
+
+func imageImportGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	importId, ok := utils.ParseObjectId(c.Param("import_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	imprt, err := imgimport.Get(db, importId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, imprt)
+}
+
+func imageImportsGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	page, _ := strconv.ParseInt(c.Query("page"), 10, 0)
+	pageCount, _ := strconv.ParseInt(c.Query("page_count"), 10, 0)
+
+	query := bson.M{}
+
+	state := c.Query("state")
+	if state != "" {
+		query["state"] = state
+	}
+
+	organization, ok := utils.ParseObjectId(c.Query("organization"))
+	if ok {
+		query["organization"] = organization
+	}
+
+	imprts, count, err := imgimport.GetAllPaged(
+		db, &query, page, pageCount)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	dta := &imageImportsData{
+		Imports: imprts,
+		Count:   count,
+	}
+
+	c.JSON(200, dta)
+}
@@ -25,6 +25,8 @@ import (
 	"github.com/pritunl/pritunl-cloud/node"
 	"github.com/pritunl/pritunl-cloud/nodeport"
 	"github.com/pritunl/pritunl-cloud/pci"
+	"github.com/pritunl/pritunl-cloud/schedule"
+	"github.com/pritunl/pritunl-cloud/serial"
 	"github.com/pritunl/pritunl-cloud/storage"
 	"github.com/pritunl/pritunl-cloud/usb"
 	"github.com/pritunl/pritunl-cloud/utils"
@@ -33,48 +35,57 @@ import (
 )
 
 type instanceData struct {
-	Id                  bson.ObjectID       `json:"id"`
-	Organization        bson.ObjectID       `json:"organization"`
-	Zone                bson.ObjectID       `json:"zone"`
-	Vpc                 bson.ObjectID       `json:"vpc"`
-	Subnet              bson.ObjectID       `json:"subnet"`
-	CloudSubnet         string              `json:"cloud_subnet"`
-	Shape               bson.ObjectID       `json:"shape"`
-	Node                bson.ObjectID       `json:"node"`
-	DiskType            string              `json:"disk_type"`
-	DiskPool            bson.ObjectID       `json:"disk_pool"`
-	Image               bson.ObjectID       `json:"image"`
-	ImageBacking        bool                `json:"image_backing"`
-	Name                string              `json:"name"`
-	Comment             string              `json:"comment"`
-	Action              string              `json:"action"`
-	RootEnabled         bool                `json:"root_enabled"`
-	Uefi                bool                `json:"uefi"`
-	SecureBoot          bool                `json:"secure_boot"`
-	Tpm                 bool                `json:"tpm"`
-	DhcpServer          bool                `json:"dhcp_server"`
-	CloudType           string              `json:"cloud_type"`
-	CloudScript         string              `json:"cloud_script"`
-	DeleteProtection    bool                `json:"delete_protection"`
-	SkipSourceDestCheck bool                `json:"skip_source_dest_check"`
-	InitDiskSize        int                 `json:"init_disk_size"`
-	Memory              int                 `json:"memory"`
-	Processors          int                 `json:"processors"`
-	Roles               []string            `json:"roles"`
-	Isos                []*iso.Iso          `json:"isos"`
-	UsbDevices          []*usb.Device       `json:"usb_devices"`
-	PciDevices          []*pci.Device       `json:"pci_devices"`
-	DriveDevices        []*drive.Device     `json:"drive_devices"`
-	IscsiDevices        []*iscsi.Device     `json:"iscsi_devices"`
-	Mounts              []*instance.Mount   `json:"mounts"`
-	Vnc                 bool                `json:"vnc"`
-	Spice               bool                `json:"spice"`
-	Gui                 bool                `json:"gui"`
-	NodePorts           []*nodeport.Mapping `json:"node_ports"`
-	NoPublicAddress     bool                `json:"no_public_address"`
-	NoPublicAddress6    bool                `json:"no_public_address6"`
-	NoHostAddress       bool                `json:"no_host_address"`
-	Count               int                 `json:"count"`
+	Id                  bson.ObjectID        `json:"id"`
+	Organization        bson.ObjectID        `json:"organization"`
+	Zone                bson.ObjectID        `json:"zone"`
+	Vpc                 bson.ObjectID        `json:"vpc"`
+	Subnet              bson.ObjectID        `json:"subnet"`
+	CloudSubnet         string               `json:"cloud_subnet"`
+	Shape               bson.ObjectID        `json:"shape"`
+	Node                bson.ObjectID        `json:"node"`
+	DiskType            string               `json:"disk_type"`
+	DiskPool            bson.ObjectID        `json:"disk_pool"`
+	Image               bson.ObjectID        `json:"image"`
+	ImageBacking        bool                 `json:"image_backing"`
+	Name                string               `json:"name"`
+	Comment             string               `json:"comment"`
+	Action              string               `json:"action"`
+	RootEnabled         bool                 `json:"root_enabled"`
+	Uefi                bool                 `json:"uefi"`
+	SecureBoot          bool                 `json:"secure_boot"`
+	Tpm                 bool                 `json:"tpm"`
+	DhcpServer          bool                 `json:"dhcp_server"`
+	CloudType           string               `json:"cloud_type"`
+	CloudScript         string               `json:"cloud_script"`
+	DeleteProtection    bool                 `json:"delete_protection"`
+	PowerSchedules      []*schedule.Schedule `json:"power_schedules"`
+	Expire              time.Time            `json:"expire"`
+	SkipSourceDestCheck bool                 `json:"skip_source_dest_check"`
+	InitDiskSize        int                  `json:"init_disk_size"`
+	Memory              int                  `json:"memory"`
+	Processors          int                  `json:"processors"`
+	Roles               []string             `json:"roles"`
+	Isos                []*iso.Iso           `json:"isos"`
+	UsbDevices          []*usb.Device        `json:"usb_devices"`
+	PciDevices          []*pci.Device        `json:"pci_devices"`
+	DriveDevices        []*drive.Device      `json:"drive_devices"`
+	IscsiDevices        []*iscsi.Device      `json:"iscsi_devices"`
+	Mounts              []*instance.Mount    `json:"mounts"`
+	Vnc                 bool                 `json:"vnc"`
+	Spice               bool                 `json:"spice"`
+	Gui                 bool                 `json:"gui"`
+	NodePorts           []*nodeport.Mapping  `json:"node_ports"`
+	NoPublicAddress     bool                 `json:"no_public_address"`
+	NoPublicAddress6    bool                 `json:"no_public_address6"`
+	NoHostAddress       bool                 `json:"no_host_address"`
+	Count               int                  `json:"count"`
+}
+
+type instanceCloneData struct {
+	Name      string `json:"name"`
+	DataDisks bool   `json:"data_disks"`
+	Devices   bool   `json:"devices"`
+	Quiesce   bool   `json:"quiesce"`
 }
 
 type instanceMultiData struct {
@@ -135,6 +146,8 @@ func instancePut(c *gin.Context) {
 	inst.CloudType = dta.CloudType
 	inst.CloudScript = dta.CloudScript
 	inst.DeleteProtection = dta.DeleteProtection
+	inst.PowerSchedules = dta.PowerSchedules
+	inst.Expire = dta.Expire
 	inst.SkipSourceDestCheck = dta.SkipSourceDestCheck
 	inst.Memory = dta.Memory
 	inst.Processors = dta.Processors
@@ -175,6 +188,8 @@ func instancePut(c *gin.Context) {
 		"cloud_type",
 		"cloud_script",
 		"delete_protection",
+		"power_schedules",
+		"expire",
 		"skip_source_dest_check",
 		"memory",
 		"processors",
@@ -190,6 +205,7 @@ func instancePut(c *gin.Context) {
 		"vnc",
 		"vnc_display",
 		"vnc_password",
+		"serial_password",
 		"spice",
 		"spice_port",
 		"spice_password",
@@ -276,7 +292,7 @@ func instancePost(c *gin.Context) {
 			return
 		}
 
-		if dta.DiskType == disk.Lvm {
+		if dta.DiskType == disk.Lvm || !dta.DiskPool.IsZero() {
 			poolMatch := false
 			for _, plId := range nde.Pools {
 				if plId == dta.DiskPool {
@@ -295,6 +311,7 @@ func instancePost(c *gin.Context) {
 		}
 	}
 
+	cloudUser := ""
 	if !dta.Image.IsZero() {
 		img, err := image.GetOrgPublic(db, dta.Organization, dta.Image)
 		if err != nil {
@@ -338,6 +355,11 @@ func instancePost(c *gin.Context) {
 
 			return
 		}
+
+		cloudUser = img.GetDefaultUser()
+		if dta.CloudType == "" {
+			dta.CloudType = img.GetCloudInit()
+		}
 	}
 
 	insts := []*instance.Instance{}
@@ -373,7 +395,10 @@ func instancePost(c *gin.Context) {
 			DhcpServer:          dta.DhcpServer,
 			CloudType:           dta.CloudType,
 			CloudScript:         dta.CloudScript,
+			CloudUser:           cloudUser,
 			DeleteProtection:    dta.DeleteProtection,
+			PowerSchedules:      dta.PowerSchedules,
+			Expire:              dta.Expire,
 			SkipSourceDestCheck: dta.SkipSourceDestCheck,
 			Name:                name,
 			Comment:             dta.Comment,
@@ -438,6 +463,80 @@ func instancePost(c *gin.Context) {
 	}
 }
 
+func instanceClonePost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	dta := &instanceCloneData{}
+
+	instanceId, ok := utils.ParseObjectId(c.Param("instance_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(dta)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	inst, err := instance.Get(db, instanceId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	name := dta.Name
+	if name == "" {
+		name = inst.Name + "-clone"
+	}
+
+	clone, errData := inst.Clone(name, dta.DataDisks, dta.Devices,
+		dta.Quiesce)
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	errData, err = clone.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = clone.SyncNodePorts(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = clone.Insert(db)
+	if err != nil {
+		_ = clone.Cleanup(db)
+
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = clone.Cleanup(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	event.PublishDispatch(db, "instance.change")
+
+	c.JSON(200, clone)
+}
+
 func instancesPut(c *gin.Context) {
 	if demo.Blocked(c) {
 		return
@@ -830,6 +929,64 @@ func instanceVncGet(c *gin.Context) {
 	}
 }
 
+func instanceSerialGet(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	instanceId, ok := utils.ParseObjectId(c.Param("instance_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	inst, err := instance.Get(db, instanceId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = inst.SerialConnect(db, c.Writer, c.Request)
+	if err != nil {
+		if _, ok := err.(*instance.SerialDialError); ok {
+			utils.AbortWithStatus(c, 504)
+		} else {
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+}
+
+func instanceSerialLogGet(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	instanceId, ok := utils.ParseObjectId(c.Param("instance_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	inst, err := instance.Get(db, instanceId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	lg, err := serial.GetLog(db, inst.Id)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, lg)
+}
+
 func instanceAdvisoryGet(c *gin.Context) {
 	db := c.MustGet("db").(*database.Database)
 
@@ -0,0 +1,327 @@
+package ahandlers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/demo"
+	"github.com/pritunl/pritunl-cloud/envelope"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/logsink"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type logSinkData struct {
+	Id            bson.ObjectID `json:"id"`
+	Name          string        `json:"name"`
+	Comment       string        `json:"comment"`
+	Organization  bson.ObjectID `json:"organization"`
+	Type          string        `json:"type"`
+	Sources       []string      `json:"sources"`
+	Disabled      bool          `json:"disabled"`
+	Address       string        `json:"address"`
+	Tls           bool          `json:"tls"`
+	Url           string        `json:"url"`
+	Username      string        `json:"username"`
+	Password      string        `json:"password"`
+	Token         string        `json:"token"`
+	Tenant        string        `json:"tenant"`
+	BatchSize     int           `json:"batch_size"`
+	BatchInterval int           `json:"batch_interval"`
+	BufferSize    int           `json:"buffer_size"`
+}
+
+type logSinksData struct {
+	LogSinks []*logsink.Sink `json:"log_sinks"`
+	Count    int64           `json:"count"`
+}
+
+func logSinkPut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &logSinkData{}
+
+	sinkId, ok := utils.ParseObjectId(c.Param("sink_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	sink, err := logsink.Get(db, sinkId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	sink.Name = data.Name
+	sink.Comment = data.Comment
+	sink.Organization = data.Organization
+	sink.Type = data.Type
+	sink.Sources = data.Sources
+	sink.Disabled = data.Disabled
+	sink.Address = data.Address
+	sink.Tls = data.Tls
+	sink.Url = data.Url
+	sink.Username = data.Username
+	sink.Password = envelope.String(data.Password)
+	sink.Token = envelope.String(data.Token)
+	sink.Tenant = data.Tenant
+	sink.BatchSize = data.BatchSize
+	sink.BatchInterval = data.BatchInterval
+	sink.BufferSize = data.BufferSize
+
+	fields := set.NewSet(
+		"name",
+		"comment",
+		"organization",
+		"type",
+		"sources",
+		"disabled",
+		"address",
+		"tls",
+		"url",
+		"username",
+		"password",
+		"token",
+		"tenant",
+		"batch_size",
+		"batch_interval",
+		"buffer_size",
+	)
+
+	errData, err := sink.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = sink.CommitFields(db, fields)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	event.PublishDispatch(db, "log_sink.change")
+
+	c.JSON(200, sink)
+}
+
+func logSinkPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &logSinkData{
+		Name: "new-log-sink",
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	sink := &logsink.Sink{
+		Name:          data.Name,
+		Comment:       data.Comment,
+		Organization:  data.Organization,
+		Type:          data.Type,
+		Sources:       data.Sources,
+		Disabled:      data.Disabled,
+		Address:       data.Address,
+		Tls:           data.Tls,
+		Url:           data.Url,
+		Username:      data.Username,
+		Password:      envelope.String(data.Password),
+		Token:         envelope.String(data.Token),
+		Tenant:        data.Tenant,
+		BatchSize:     data.BatchSize,
+		BatchInterval: data.BatchInterval,
+		BufferSize:    data.BufferSize,
+	}
+
+	errData, err := sink.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = sink.Insert(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	event.PublishDispatch(db, "log_sink.change")
+
+	c.JSON(200, sink)
+}
+
+func logSinkDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	sinkId, ok := utils.ParseObjectId(c.Param("sink_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := logsink.Remove(db, sinkId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	event.PublishDispatch(db, "log_sink.change")
+
+	c.JSON(200, nil)
+}
+
+func logSinksDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := []bson.ObjectID{}
+
+	err := c.Bind(&data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = logsink.RemoveMulti(db, data)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	event.PublishDispatch(db, "log_sink.change")
+
+	c.JSON(200, nil)
+}
+
+func logSinkGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	sinkId, ok := utils.ParseObjectId(c.Param("sink_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	sink, err := logsink.Get(db, sinkId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if demo.IsDemo() {
+		sink.Password = "demo"
+		sink.Token = "demo"
+	}
+
+	c.JSON(200, sink)
+}
+
+func logSinksGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	page, _ := strconv.ParseInt(c.Query("page"), 10, 0)
+	pageCount, _ := strconv.ParseInt(c.Query("page_count"), 10, 0)
+
+	query := bson.M{}
+
+	sinkId, ok := utils.ParseObjectId(c.Query("id"))
+	if ok {
+		query["_id"] = sinkId
+	}
+
+	name := strings.TrimSpace(c.Query("name"))
+	if name != "" {
+		query["name"] = &bson.M{
+			"$regex":   fmt.Sprintf(".*%s.*", regexp.QuoteMeta(name)),
+			"$options": "i",
+		}
+	}
+
+	organization, ok := utils.ParseObjectId(c.Query("organization"))
+	if ok {
+		query["organization"] = organization
+	}
+
+	typ := strings.TrimSpace(c.Query("type"))
+	if typ != "" {
+		query["type"] = typ
+	}
+
+	comment := strings.TrimSpace(c.Query("comment"))
+	if comment != "" {
+		query["comment"] = &bson.M{
+			"$regex":   fmt.Sprintf(".*%s.*", comment),
+			"$options": "i",
+		}
+	}
+
+	sinks, count, err := logsink.GetAllPaged(db, &query, page, pageCount)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if demo.IsDemo() {
+		for _, sink := range sinks {
+			sink.Password = "demo"
+			sink.Token = "demo"
+		}
+	}
+
+	data := &logSinksData{
+		LogSinks: sinks,
+		Count:    count,
+	}
+
+	c.JSON(200, data)
+}
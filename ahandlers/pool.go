@@ -27,6 +27,11 @@ type poolData struct {
 	Zone             bson.ObjectID `json:"zone"`
 	Type             string        `json:"type"`
 	VgName           string        `json:"vg_name"`
+	ThinPool         string        `json:"thin_pool"`
+	Overcommit       float64       `json:"overcommit"`
+	Node             bson.ObjectID `json:"node"`
+	Path             string        `json:"path"`
+	Mount            bool          `json:"mount"`
 }
 
 type poolsData struct {
@@ -64,12 +69,23 @@ func poolPut(c *gin.Context) {
 	pl.Comment = data.Comment
 	pl.DeleteProtection = data.DeleteProtection
 	pl.Type = data.Type
+	pl.ThinPool = data.ThinPool
+	pl.Overcommit = data.Overcommit
+	pl.Node = data.Node
+	pl.Path = data.Path
+	pl.Mount = data.Mount
 
 	fields := set.NewSet(
 		"name",
 		"comment",
 		"delete_protection",
 		"type",
+		"vg_name",
+		"thin_pool",
+		"overcommit",
+		"node",
+		"path",
+		"mount",
 	)
 
 	errData, err := pl.Validate(db)
@@ -123,6 +139,11 @@ func poolPost(c *gin.Context) {
 		Zone:             data.Zone,
 		Type:             data.Type,
 		VgName:           data.VgName,
+		ThinPool:         data.ThinPool,
+		Overcommit:       data.Overcommit,
+		Node:             data.Node,
+		Path:             data.Path,
+		Mount:            data.Mount,
 	}
 
 	errData, err := pl.Validate(db)
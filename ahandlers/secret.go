@@ -12,6 +12,7 @@ import (
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/demo"
+	"github.com/pritunl/pritunl-cloud/envelope"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/event"
 	"github.com/pritunl/pritunl-cloud/relations"
@@ -70,7 +71,7 @@ func secretPut(c *gin.Context) {
 	secr.Organization = data.Organization
 	secr.Type = data.Type
 	secr.Key = data.Key
-	secr.Value = data.Value
+	secr.Value = envelope.String(data.Value)
 	secr.Data = data.Data
 	secr.Region = data.Region
 
@@ -134,7 +135,7 @@ func secretPost(c *gin.Context) {
 		Organization: data.Organization,
 		Type:         data.Type,
 		Key:          data.Key,
-		Value:        data.Value,
+		Value:        envelope.String(data.Value),
 		Data:         data.Data,
 		Region:       data.Region,
 	}
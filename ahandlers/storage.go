@@ -13,6 +13,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/data"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/demo"
+	"github.com/pritunl/pritunl-cloud/envelope"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/event"
 	"github.com/pritunl/pritunl-cloud/storage"
@@ -21,15 +22,18 @@ import (
 )
 
 type storageData struct {
-	Id        bson.ObjectID `json:"id"`
-	Name      string        `json:"name"`
-	Comment   string        `json:"comment"`
-	Type      string        `json:"type"`
-	Endpoint  string        `json:"endpoint"`
-	Bucket    string        `json:"bucket"`
-	AccessKey string        `json:"access_key"`
-	SecretKey string        `json:"secret_key"`
-	Insecure  bool          `json:"insecure"`
+	Id        bson.ObjectID       `json:"id"`
+	Name      string              `json:"name"`
+	Comment   string              `json:"comment"`
+	Type      string              `json:"type"`
+	Endpoint  string              `json:"endpoint"`
+	Bucket    string              `json:"bucket"`
+	AccessKey string              `json:"access_key"`
+	SecretKey string              `json:"secret_key"`
+	Insecure  bool                `json:"insecure"`
+	Path      string              `json:"path"`
+	NodePaths []*storage.NodePath `json:"node_paths"`
+	Mount     bool                `json:"mount"`
 }
 
 type storagesData struct {
@@ -69,8 +73,11 @@ func storagePut(c *gin.Context) {
 	store.Endpoint = dta.Endpoint
 	store.Bucket = dta.Bucket
 	store.AccessKey = dta.AccessKey
-	store.SecretKey = dta.SecretKey
+	store.SecretKey = envelope.String(dta.SecretKey)
 	store.Insecure = dta.Insecure
+	store.Path = dta.Path
+	store.NodePaths = dta.NodePaths
+	store.Mount = dta.Mount
 
 	fields := set.NewSet(
 		"name",
@@ -81,6 +88,9 @@ func storagePut(c *gin.Context) {
 		"access_key",
 		"secret_key",
 		"insecure",
+		"path",
+		"node_paths",
+		"mount",
 	)
 
 	errData, err := store.Validate(db)
@@ -142,8 +152,11 @@ func storagePost(c *gin.Context) {
 		Endpoint:  dta.Endpoint,
 		Bucket:    dta.Bucket,
 		AccessKey: dta.AccessKey,
-		SecretKey: dta.SecretKey,
+		SecretKey: envelope.String(dta.SecretKey),
 		Insecure:  dta.Insecure,
+		Path:      dta.Path,
+		NodePaths: dta.NodePaths,
+		Mount:     dta.Mount,
 	}
 
 	errData, err := store.Validate(db)
@@ -0,0 +1,108 @@
+package ahandlers
+
+import (
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/demo"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/task"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type taskData struct {
+	Disabled        bool `json:"disabled"`
+	DisableDuration int  `json:"disable_duration"`
+}
+
+func tasksGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	tasks, err := task.GetAll(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, tasks)
+}
+
+func taskPut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &taskData{}
+	name := c.Param("task_name")
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	errData, err := task.SetDisabled(db, name, data.Disabled,
+		time.Duration(data.DisableDuration)*time.Minute)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	event.PublishDispatch(db, "task.change")
+
+	c.JSON(200, nil)
+}
+
+func taskRunsGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	name := c.Param("task_name")
+
+	if !task.Exists(name) {
+		utils.AbortWithStatus(c, 404)
+		return
+	}
+
+	runs, err := task.GetRuns(db, name)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, runs)
+}
+
+func taskRunPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	name := c.Param("task_name")
+
+	errData, err := task.Trigger(db, name)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	event.PublishDispatch(db, "task.change")
+
+	c.JSON(200, nil)
+}
@@ -0,0 +1,38 @@
+package ahandlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/demo"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/pritunl/pritunl-cloud/vulnerability"
+)
+
+func vulnerabilityImportPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	name := c.Query("name")
+	if name == "" {
+		name = "import.json"
+	}
+
+	result, err := vulnerability.ImportReader(db, name, c.Request.Body)
+	if err != nil {
+		if _, ok := err.(*errortypes.ParseError); ok {
+			c.JSON(400, &errortypes.ErrorData{
+				Error:   "vulnerability_import_invalid",
+				Message: "Failed to parse vulnerability data",
+			})
+		} else {
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	c.JSON(200, result)
+}
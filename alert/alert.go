@@ -70,6 +70,36 @@ func (a *Alert) Validate(db *database.Database) (
 		a.ValueInt = 0
 		a.ValueStr = ""
 		break
+	case InstanceExpire:
+		a.ValueStr = ""
+
+		if a.ValueInt == 0 {
+			a.ValueInt = 24
+		}
+
+		if a.ValueInt < 1 || a.ValueInt > 720 {
+			errData = &errortypes.ErrorData{
+				Error:   "alert_expire_warning_invalid",
+				Message: "Alert expire warning must be between 1 and 720 hours",
+			}
+			return
+		}
+		break
+	case ThinPoolData, ThinPoolMetadata:
+		a.ValueStr = ""
+
+		if a.ValueInt == 0 {
+			a.ValueInt = 80
+		}
+
+		if a.ValueInt < 1 || a.ValueInt > 100 {
+			errData = &errortypes.ErrorData{
+				Error:   "alert_threshold_invalid",
+				Message: "Alert usage threshold must be between 1 and 100",
+			}
+			return
+		}
+		break
 	default:
 		errData = &errortypes.ErrorData{
 			Error:   "alert_resource_name_invalid",
@@ -7,5 +7,8 @@ const (
 )
 
 const (
-	InstanceOffline = "instance_offline"
+	InstanceOffline  = "instance_offline"
+	InstanceExpire   = "instance_expire"
+	ThinPoolData     = "thin_pool_data"
+	ThinPoolMetadata = "thin_pool_metadata"
 )
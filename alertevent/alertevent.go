@@ -2,26 +2,29 @@ package alertevent
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/device"
+	"github.com/pritunl/pritunl-cloud/event"
 	"github.com/pritunl/pritunl-cloud/user"
 	"github.com/sirupsen/logrus"
 )
 
 type Alert struct {
-	Id         string        `bson:"_id" json:"_id"`
-	Name       string        `bson:"name" json:"name"`
-	Timestamp  time.Time     `bson:"timestamp" json:"timestamp"`
-	Roles      []string      `bson:"roles" json:"roles"`
-	Source     bson.ObjectID `bson:"source" json:"source"`
-	SourceName string        `bson:"source_name" json:"source_name"`
-	Level      int           `bson:"level" json:"level"`
-	Resource   string        `bson:"resource" json:"resource"`
-	Message    string        `bson:"message" json:"message"`
-	Frequency  time.Duration `bson:"frequency" json:"frequency"`
+	Id           string        `bson:"_id" json:"_id"`
+	Name         string        `bson:"name" json:"name"`
+	Timestamp    time.Time     `bson:"timestamp" json:"timestamp"`
+	Organization bson.ObjectID `bson:"organization" json:"organization"`
+	Roles        []string      `bson:"roles" json:"roles"`
+	Source       bson.ObjectID `bson:"source" json:"source"`
+	SourceName   string        `bson:"source_name" json:"source_name"`
+	Level        int           `bson:"level" json:"level"`
+	Resource     string        `bson:"resource" json:"resource"`
+	Message      string        `bson:"message" json:"message"`
+	Frequency    time.Duration `bson:"frequency" json:"frequency"`
 }
 
 func (a *Alert) DocId() string {
@@ -160,10 +163,18 @@ func (a *Alert) Send(db *database.Database, roles []string) (err error) {
 		return
 	}
 
+	event.PublishResource(db, event.AlertFired, a.Organization, a.Source,
+		a.Resource, map[string]string{
+			"name":        a.Name,
+			"source_name": a.SourceName,
+			"level":       strconv.Itoa(a.Level),
+			"message":     a.Message,
+		})
+
 	return
 }
 
-func New(roles []string, source bson.ObjectID,
+func New(orgId bson.ObjectID, roles []string, source bson.ObjectID,
 	name, sourceName, resource, message string, level int,
 	frequency time.Duration) {
 
@@ -171,15 +182,16 @@ func New(roles []string, source bson.ObjectID,
 	defer db.Close()
 
 	alrt := &Alert{
-		Name:       name,
-		Timestamp:  time.Now(),
-		Roles:      roles,
-		Source:     source,
-		SourceName: sourceName,
-		Level:      level,
-		Resource:   resource,
-		Message:    message,
-		Frequency:  frequency,
+		Name:         name,
+		Timestamp:    time.Now(),
+		Organization: orgId,
+		Roles:        roles,
+		Source:       source,
+		SourceName:   sourceName,
+		Level:        level,
+		Resource:     resource,
+		Message:      message,
+		Frequency:    frequency,
 	}
 
 	alrt.Id = alrt.DocId()
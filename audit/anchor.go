@@ -0,0 +1,79 @@
+package audit
+
+import (
+	"encoding/json"
+	"path"
+	"sync"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+var anchorLock sync.Mutex
+
+// anchor is the most recent chain head written by this node, it is kept
+// outside of the database to detect removal of the end of the chain
+type anchor struct {
+	Sequence int64  `json:"sequence"`
+	Hash     string `json:"hash"`
+}
+
+func GetAnchorPath() string {
+	return path.Join(constants.DefaultRoot, "audit.anchor")
+}
+
+func readAnchor() (anc *anchor, err error) {
+	data, err := utils.ReadExists(GetAnchorPath())
+	if err != nil {
+		return
+	}
+
+	if data == "" {
+		return
+	}
+
+	anc = &anchor{}
+	err = json.Unmarshal([]byte(data), anc)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "audit: Failed to parse audit anchor"),
+		}
+		return
+	}
+
+	return
+}
+
+func writeAnchor(sequence int64, hash string) (err error) {
+	anchorLock.Lock()
+	defer anchorLock.Unlock()
+
+	anc, err := readAnchor()
+	if err != nil {
+		return
+	}
+
+	if anc != nil && anc.Sequence >= sequence {
+		return
+	}
+
+	data, err := json.Marshal(&anchor{
+		Sequence: sequence,
+		Hash:     hash,
+	})
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "audit: Failed to marshal audit anchor"),
+		}
+		return
+	}
+
+	err = utils.CreateWrite(GetAnchorPath(), string(data), 0600)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -1,26 +1,37 @@
 package audit
 
 import (
+	"sync"
 	"time"
 
 	"github.com/dropbox/godropbox/errors"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/envelope"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/useragent"
+	"github.com/sirupsen/logrus"
 )
 
+var chainLock sync.Mutex
+
 type Fields map[string]interface{}
 
 type Audit struct {
 	Id        bson.ObjectID    `bson:"_id,omitempty" json:"id"`
+	Sequence  int64            `bson:"s,omitempty" json:"sequence"`
 	User      bson.ObjectID    `bson:"u" json:"user"`
 	Timestamp time.Time        `bson:"t" json:"timestamp"`
 	Type      string           `bson:"y" json:"type"`
 	Fields    Fields           `bson:"f" json:"fields"`
 	Agent     *useragent.Agent `bson:"a" json:"agent"`
+	Prev      string           `bson:"p,omitempty" json:"prev"`
+	Hash      string           `bson:"h,omitempty" json:"hash"`
+	KeyId     string           `bson:"k,omitempty" json:"key_id"`
 }
 
+// Insert appends the entry to the global hash chain, the unique sequence
+// index rejects concurrent inserts from other nodes which are retried
 func (a *Audit) Insert(db *database.Database) (err error) {
 	coll := db.Audits()
 
@@ -31,11 +42,54 @@ func (a *Audit) Insert(db *database.Database) (err error) {
 		return
 	}
 
-	_, err = coll.InsertOne(db, a)
+	a.Timestamp = a.Timestamp.Truncate(time.Millisecond)
+
+	keyId, key, _, err := envelope.DeriveKey("", chainPurpose)
 	if err != nil {
-		err = database.ParseError(err)
 		return
 	}
+	a.KeyId = keyId
+
+	chainLock.Lock()
+	defer chainLock.Unlock()
+
+	for i := 0; i < chainRetry; i++ {
+		head, e := getHead(db)
+		if e != nil {
+			err = e
+			return
+		}
+
+		a.Sequence = head.Sequence + 1
+		a.Prev = head.Hash
+		a.Hash = a.ComputeHash(key)
+
+		resp, e := coll.InsertOne(db, a)
+		if e != nil {
+			err = database.ParseError(e)
+			if _, ok := err.(*database.DuplicateKeyError); ok {
+				time.Sleep(chainRetryWait)
+				continue
+			}
+			return
+		}
 
+		a.Id = resp.InsertedID.(bson.ObjectID)
+		err = nil
+
+		e = writeAnchor(a.Sequence, a.Hash)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"sequence": a.Sequence,
+				"error":    e,
+			}).Warning("audit: Failed to write audit chain anchor")
+		}
+
+		return
+	}
+
+	err = &errortypes.DatabaseError{
+		errors.Wrap(err, "audit: Failed to append to audit chain"),
+	}
 	return
 }
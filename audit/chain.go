@@ -0,0 +1,303 @@
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/envelope"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/useragent"
+)
+
+type chainData struct {
+	Sequence  int64             `json:"s"`
+	Prev      string            `json:"p"`
+	User      string            `json:"u"`
+	Timestamp int64             `json:"t"`
+	Type      string            `json:"y"`
+	Fields    map[string]string `json:"f"`
+	Agent     *useragent.Agent  `json:"a"`
+}
+
+type Break struct {
+	Id       bson.ObjectID `json:"id"`
+	Sequence int64         `json:"sequence"`
+	Reason   string        `json:"reason"`
+}
+
+// fieldString normalizes a field value to the same string before and after
+// a database round trip, times are stored as milliseconds and integers
+// decode as int32 or int64
+func fieldString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.FormatInt(int64(v), 10)
+	case int32:
+		return strconv.FormatInt(int64(v), 10)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case time.Duration:
+		return strconv.FormatInt(int64(v), 10)
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 64)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case bson.ObjectID:
+		return v.Hex()
+	case time.Time:
+		return strconv.FormatInt(v.UnixMilli(), 10)
+	case *time.Time:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatInt(v.UnixMilli(), 10)
+	case bson.DateTime:
+		return strconv.FormatInt(int64(v), 10)
+	case []string:
+		return listString(v)
+	case []bson.ObjectID:
+		vals := make([]string, len(v))
+		for i, item := range v {
+			vals[i] = item.Hex()
+		}
+		return listString(vals)
+	case []interface{}:
+		return interfaceListString(v)
+	case bson.A:
+		return interfaceListString(v)
+	case map[string]interface{}:
+		return mapString(v)
+	case bson.M:
+		return mapString(v)
+	case bson.D:
+		vals := map[string]interface{}{}
+		for _, elem := range v {
+			vals[elem.Key] = elem.Value
+		}
+		return mapString(vals)
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func listString(vals []string) string {
+	data, _ := json.Marshal(vals)
+	return string(data)
+}
+
+func interfaceListString(vals []interface{}) string {
+	strs := make([]string, len(vals))
+	for i, item := range vals {
+		strs[i] = fieldString(item)
+	}
+	return listString(strs)
+}
+
+func mapString(vals map[string]interface{}) string {
+	strs := map[string]string{}
+	for key, item := range vals {
+		strs[key] = fieldString(item)
+	}
+	data, _ := json.Marshal(strs)
+	return string(data)
+}
+
+func (f Fields) Strings() (vals map[string]string) {
+	vals = map[string]string{}
+	for key, val := range f {
+		vals[key] = fieldString(val)
+	}
+	return
+}
+
+// ComputeHash returns the chain hash of the entry keyed with a key derived
+// from the master key, values are normalized to strings so the hash is
+// stable across a database round trip
+func (a *Audit) ComputeHash(key []byte) string {
+	data, _ := json.Marshal(&chainData{
+		Sequence:  a.Sequence,
+		Prev:      a.Prev,
+		User:      a.User.Hex(),
+		Timestamp: a.Timestamp.UnixMilli(),
+		Type:      a.Type,
+		Fields:    a.Fields.Strings(),
+		Agent:     a.Agent,
+	})
+
+	if key == nil {
+		hash := sha256.Sum256(data)
+		return hex.EncodeToString(hash[:])
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func getHead(db *database.Database) (head *Audit, err error) {
+	coll := db.Audits()
+	head = &Audit{}
+
+	err = coll.FindOne(db, &bson.M{
+		"s": &bson.M{
+			"$exists": true,
+		},
+	}, options.FindOne().
+		SetSort(&bson.D{
+			{"s", -1},
+		}).
+		SetProjection(&bson.M{
+			"s": 1,
+			"h": 1,
+		})).Decode(head)
+	if err != nil {
+		err = database.IgnoreNotFoundError(database.ParseError(err))
+		return
+	}
+
+	return
+}
+
+// Verify walks the chain in sequence order and reports entries that were
+// removed, reordered or modified. Entries after the first keyed entry
+// must be keyed and the chain must contain the local anchored head
+func Verify(db *database.Database, handler func(brk *Break)) (
+	count int64, head string, err error) {
+
+	coll := db.Audits()
+
+	cursor, err := coll.Find(db, &bson.M{
+		"s": &bson.M{
+			"$exists": true,
+		},
+	}, options.Find().
+		SetSort(&bson.D{
+			{"s", 1},
+		}))
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	anc, err := readAnchor()
+	if err != nil {
+		return
+	}
+
+	keys := map[string][]byte{}
+	keyed := false
+	anchored := false
+	var prevSeq int64
+	prevHash := ""
+
+	for cursor.Next(db) {
+		adt := &Audit{}
+		err = cursor.Decode(adt)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		count += 1
+
+		if adt.Sequence != prevSeq+1 {
+			handler(&Break{
+				Id:       adt.Id,
+				Sequence: adt.Sequence,
+				Reason: fmt.Sprintf(
+					"Sequence gap, expected %d", prevSeq+1),
+			})
+		}
+
+		if adt.Prev != prevHash {
+			handler(&Break{
+				Id:       adt.Id,
+				Sequence: adt.Sequence,
+				Reason:   "Previous hash does not match chain",
+			})
+		}
+
+		var key []byte
+		if adt.KeyId != "" {
+			keyed = true
+
+			key = keys[adt.KeyId]
+			if key == nil {
+				_, key, _, err = envelope.DeriveKey(adt.KeyId, chainPurpose)
+				if err != nil {
+					return
+				}
+				if key == nil {
+					err = &errortypes.ReadError{
+						errors.New("audit: Master key required to verify"),
+					}
+					return
+				}
+				keys[adt.KeyId] = key
+			}
+		} else if keyed {
+			handler(&Break{
+				Id:       adt.Id,
+				Sequence: adt.Sequence,
+				Reason:   "Entry hash is not keyed",
+			})
+		}
+
+		if adt.Hash != adt.ComputeHash(key) {
+			handler(&Break{
+				Id:       adt.Id,
+				Sequence: adt.Sequence,
+				Reason:   "Entry hash does not match content",
+			})
+		}
+
+		if anc != nil && adt.Sequence == anc.Sequence {
+			anchored = true
+			if adt.Hash != anc.Hash {
+				handler(&Break{
+					Id:       adt.Id,
+					Sequence: adt.Sequence,
+					Reason:   "Entry hash does not match anchor",
+				})
+			}
+		}
+
+		prevSeq = adt.Sequence
+		prevHash = adt.Hash
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	if anc != nil && !anchored {
+		handler(&Break{
+			Sequence: anc.Sequence,
+			Reason:   "Anchored entry missing, chain truncated",
+		})
+	}
+
+	head = prevHash
+
+	return
+}
@@ -1,5 +1,9 @@
 package audit
 
+import (
+	"time"
+)
+
 const (
 	AdminLogin                 = "admin_login"
 	AdminLoginFailed           = "admin_login_failed"
@@ -41,4 +45,15 @@ const (
 	OneLoginDeny         = "one_login_deny"
 	OktaApprove          = "okta_approve"
 	OktaDeny             = "okta_deny"
+
+	SshCertificateIssue = "ssh_certificate_issue"
+
+	AdminMutation = "admin_mutation"
+)
+
+const (
+	chainRetry     = 20
+	chainRetryWait = 10 * time.Millisecond
+	chainPurpose   = "audit_chain"
+	exportBatch    = 1000
 )
@@ -2,11 +2,13 @@ package audit
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
 	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/logsink"
 	"github.com/pritunl/pritunl-cloud/settings"
 	"github.com/pritunl/pritunl-cloud/useragent"
 	"github.com/pritunl/pritunl-cloud/utils"
@@ -92,9 +94,12 @@ func New(db *database.Database, r *http.Request,
 		return
 	}
 
-	agnt, err := useragent.Parse(db, r)
-	if err != nil {
-		return
+	var agnt *useragent.Agent
+	if r != nil {
+		agnt, err = useragent.Parse(db, r)
+		if err != nil {
+			return
+		}
 	}
 
 	adt := &Audit{
@@ -110,5 +115,78 @@ func New(db *database.Database, r *http.Request,
 		return
 	}
 
+	forward(adt)
+
+	return
+}
+
+func forward(adt *Audit) {
+	fields := adt.Fields.Strings()
+	fields["audit"] = adt.Id.Hex()
+	fields["user"] = adt.User.Hex()
+	fields["sequence"] = strconv.FormatInt(adt.Sequence, 10)
+	fields["hash"] = adt.Hash
+	if adt.Agent != nil {
+		fields["ip"] = adt.Agent.Ip
+	}
+
+	logsink.PushAudit(adt.Timestamp, adt.Type, fields)
+}
+
+// Export passes entries within the time range to the handler in chain
+// order, a zero user exports all users
+func Export(db *database.Database, userId bson.ObjectID,
+	start, end time.Time, handler func(adt *Audit) error) (err error) {
+
+	coll := db.Audits()
+
+	query := bson.M{}
+	if !userId.IsZero() {
+		query["u"] = userId
+	}
+
+	timeQuery := bson.M{}
+	if !start.IsZero() {
+		timeQuery["$gte"] = start
+	}
+	if !end.IsZero() {
+		timeQuery["$lt"] = end
+	}
+	if len(timeQuery) > 0 {
+		query["t"] = timeQuery
+	}
+
+	cursor, err := coll.Find(db, query, options.Find().
+		SetSort(&bson.D{
+			{"t", 1},
+			{"s", 1},
+		}).
+		SetBatchSize(exportBatch))
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		adt := &Audit{}
+		err = cursor.Decode(adt)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		err = handler(adt)
+		if err != nil {
+			return
+		}
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
 	return
 }
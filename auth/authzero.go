@@ -50,7 +50,7 @@ func AuthZeroRequest(db *database.Database, location, query string,
 		Secret:    secret,
 		AppDomain: provider.Domain,
 		AppId:     provider.ClientId,
-		AppSecret: provider.ClientSecret,
+		AppSecret: string(provider.ClientSecret),
 	})
 	if err != nil {
 		return
@@ -305,7 +305,7 @@ func authZeroGetToken(provider *settings.Provider) (token string, err error) {
 	reqData := &authZeroTokenReq{
 		GrantType:    "client_credentials",
 		ClientId:     provider.ClientId,
-		ClientSecret: provider.ClientSecret,
+		ClientSecret: string(provider.ClientSecret),
 		Audience:     fmt.Sprintf("https://%s.auth0.com/api/v2/", domain),
 	}
 
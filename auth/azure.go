@@ -70,7 +70,7 @@ func AzureRequest(db *database.Database, location, query string,
 		Region:      provider.Region,
 		DirectoryId: provider.Tenant,
 		AppId:       provider.ClientId,
-		AppSecret:   provider.ClientSecret,
+		AppSecret:   string(provider.ClientSecret),
 	})
 	if err != nil {
 		return
@@ -162,7 +162,7 @@ func azureGetToken(provider *settings.Provider) (token string, err error) {
 	reqForm := url.Values{}
 	reqForm.Add("grant_type", "client_credentials")
 	reqForm.Add("client_id", provider.ClientId)
-	reqForm.Add("client_secret", provider.ClientSecret)
+	reqForm.Add("client_secret", string(provider.ClientSecret))
 	reqForm.Add("resource", graphUrl)
 
 	req, err := http.NewRequest(
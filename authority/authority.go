@@ -2,13 +2,16 @@ package authority
 
 import (
 	"slices"
+	"strings"
 
 	"github.com/dropbox/godropbox/container/set"
 	"github.com/dropbox/godropbox/errors"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/envelope"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/utils"
+	"golang.org/x/crypto/ssh"
 )
 
 type Authority struct {
@@ -21,6 +24,11 @@ type Authority struct {
 	Key          string        `bson:"key" json:"key"`
 	Principals   []string      `bson:"principals" json:"principals"`
 	Certificate  string        `bson:"certificate" json:"certificate"`
+
+	PrivateKey       envelope.String `bson:"private_key" json:"-"`
+	HostCertificates bool            `bson:"host_certificates" json:"host_certificates"`
+	UserTtl          int             `bson:"user_ttl" json:"user_ttl"`
+	HostTtl          int             `bson:"host_ttl" json:"host_ttl"`
 }
 
 func (f *Authority) Validate(db *database.Database) (
@@ -49,6 +57,77 @@ func (f *Authority) Validate(db *database.Database) (
 	case SshCertificate:
 		f.Key = ""
 		break
+	case SshAuthority:
+		f.Key = ""
+		f.Principals = []string{}
+
+		if f.UserTtl == 0 {
+			f.UserTtl = DefaultUserTtl
+		}
+		if f.HostTtl == 0 {
+			f.HostTtl = DefaultHostTtl
+		}
+
+		if f.UserTtl < MinTtl || f.UserTtl > MaxTtl ||
+			f.HostTtl < MinTtl || f.HostTtl > MaxTtl {
+
+			errData = &errortypes.ErrorData{
+				Error:   "authority_ttl_invalid",
+				Message: "Certificate lifetime is invalid",
+			}
+			return
+		}
+
+		coll := db.Authorities()
+		count, e := coll.CountDocuments(db, &bson.M{
+			"_id": &bson.M{
+				"$ne": f.Id,
+			},
+			"type":         SshAuthority,
+			"organization": f.Organization,
+		})
+		if e != nil {
+			err = database.ParseError(e)
+			return
+		}
+
+		if count > 0 {
+			errData = &errortypes.ErrorData{
+				Error:   "authority_ca_exists",
+				Message: "Organization already has a certificate authority",
+			}
+			return
+		}
+
+		if f.PrivateKey == "" {
+			err = f.GenerateCa()
+			if err != nil {
+				return
+			}
+		} else {
+			signer, e := f.signer()
+			if e != nil {
+				err = e
+				return
+			}
+
+			f.Certificate = strings.TrimSpace(string(
+				ssh.MarshalAuthorizedKey(signer.PublicKey())))
+		}
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "authority_type_invalid",
+			Message: "Authority type is invalid",
+		}
+		return
+	}
+
+	if f.Type != SshAuthority {
+		f.PrivateKey = ""
+		f.HostCertificates = false
+		f.UserTtl = 0
+		f.HostTtl = 0
 	}
 
 	return
@@ -5,6 +5,12 @@ import "github.com/pritunl/mongo-go-driver/v2/bson"
 const (
 	SshKey         = "ssh_key"
 	SshCertificate = "ssh_certificate"
+	SshAuthority   = "ssh_authority"
+
+	DefaultUserTtl = 28800
+	DefaultHostTtl = 604800
+	MinTtl         = 300
+	MaxTtl         = 31536000
 )
 
 var (
@@ -0,0 +1,334 @@
+package authority
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/pem"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/audit"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/envelope"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/sshcert"
+	"golang.org/x/crypto/ssh"
+)
+
+func generateKey(comment string) (privKey, pubKey string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "authority: Failed to generate key"),
+		}
+		return
+	}
+
+	block, err := ssh.MarshalPrivateKey(priv, comment)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "authority: Failed to marshal private key"),
+		}
+		return
+	}
+
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "authority: Failed to marshal public key"),
+		}
+		return
+	}
+
+	privKey = strings.TrimSpace(string(pem.EncodeToMemory(block)))
+	pubKey = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshPub)))
+
+	return
+}
+
+func randSerial() (serial uint64, err error) {
+	buf := make([]byte, 8)
+	_, err = rand.Read(buf)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "authority: Failed to generate serial"),
+		}
+		return
+	}
+
+	serial = binary.BigEndian.Uint64(buf) >> 1
+	return
+}
+
+func (f *Authority) GenerateCa() (err error) {
+	privKey, pubKey, err := generateKey("pritunl-cloud-ca")
+	if err != nil {
+		return
+	}
+
+	f.PrivateKey = envelope.String(privKey)
+	f.Certificate = pubKey
+
+	return
+}
+
+func (f *Authority) signer() (signer ssh.Signer, err error) {
+	if f.Type != SshAuthority || f.PrivateKey == "" {
+		err = &errortypes.ReadError{
+			errors.New("authority: Authority is not a certificate authority"),
+		}
+		return
+	}
+
+	signer, err = ssh.ParsePrivateKey([]byte(f.PrivateKey))
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "authority: Failed to parse authority key"),
+		}
+		return
+	}
+
+	return
+}
+
+func (f *Authority) GetUserTtl() time.Duration {
+	if f.UserTtl <= 0 {
+		return DefaultUserTtl * time.Second
+	}
+	return time.Duration(f.UserTtl) * time.Second
+}
+
+func (f *Authority) GetHostTtl() time.Duration {
+	if f.HostTtl <= 0 {
+		return DefaultHostTtl * time.Second
+	}
+	return time.Duration(f.HostTtl) * time.Second
+}
+
+// GetPrincipals returns the roles shared with the authority
+func (f *Authority) GetPrincipals(roles []string) (principals []string) {
+	principals = []string{}
+
+	for _, role := range roles {
+		for _, authrRole := range f.Roles {
+			if role == authrRole {
+				principals = append(principals, role)
+				break
+			}
+		}
+	}
+
+	return
+}
+
+func (f *Authority) sign(db *database.Database, r *http.Request,
+	typ, keyId, pubKey string, principals []string, ttl time.Duration,
+	usrId, instId bson.ObjectID) (
+	cert string, record *sshcert.Certificate, err error) {
+
+	signer, err := f.signer()
+	if err != nil {
+		return
+	}
+
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(pubKey))
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "authority: Failed to parse public key"),
+		}
+		return
+	}
+
+	serial, err := randSerial()
+	if err != nil {
+		return
+	}
+
+	now := time.Now()
+	validAfter := now.Add(-5 * time.Minute)
+	validBefore := now.Add(ttl)
+
+	sshCert := &ssh.Certificate{
+		Key:             pub,
+		Serial:          serial,
+		KeyId:           keyId,
+		ValidPrincipals: principals,
+		ValidAfter:      uint64(validAfter.Unix()),
+		ValidBefore:     uint64(validBefore.Unix()),
+	}
+
+	if typ == sshcert.Host {
+		sshCert.CertType = ssh.HostCert
+	} else {
+		sshCert.CertType = ssh.UserCert
+		sshCert.Permissions = ssh.Permissions{
+			Extensions: map[string]string{
+				"permit-X11-forwarding":   "",
+				"permit-agent-forwarding": "",
+				"permit-port-forwarding":  "",
+				"permit-pty":              "",
+				"permit-user-rc":          "",
+			},
+		}
+	}
+
+	err = sshCert.SignCert(rand.Reader, signer)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "authority: Failed to sign certificate"),
+		}
+		return
+	}
+
+	cert = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(sshCert)))
+
+	record = &sshcert.Certificate{
+		Authority:    f.Id,
+		Organization: f.Organization,
+		Type:         typ,
+		User:         usrId,
+		Instance:     instId,
+		KeyId:        keyId,
+		Serial:       int64(serial),
+		Principals:   principals,
+		Fingerprint:  ssh.FingerprintSHA256(pub),
+		ValidAfter:   validAfter,
+		ValidBefore:  validBefore,
+		Timestamp:    now,
+	}
+
+	err = record.Insert(db)
+	if err != nil {
+		return
+	}
+
+	fields := audit.Fields{
+		"authority":    f.Id,
+		"organization": f.Organization,
+		"type":         typ,
+		"serial":       record.Serial,
+		"fingerprint":  record.Fingerprint,
+		"principals":   record.Principals,
+		"valid_before": record.ValidBefore,
+	}
+	if !instId.IsZero() {
+		fields["instance"] = instId
+	}
+
+	err = audit.New(db, r, usrId, audit.SshCertificateIssue, fields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (f *Authority) IssueUserCertificate(db *database.Database,
+	r *http.Request, usrId bson.ObjectID, username, pubKey string,
+	roles []string) (
+	cert string, record *sshcert.Certificate,
+	errData *errortypes.ErrorData, err error) {
+
+	principals := f.GetPrincipals(roles)
+	if len(principals) == 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "ssh_certificate_unauthorized",
+			Message: "User roles do not match certificate authority",
+		}
+		return
+	}
+
+	_, _, _, _, e := ssh.ParseAuthorizedKey([]byte(pubKey))
+	if e != nil {
+		errData = &errortypes.ErrorData{
+			Error:   "ssh_public_key_invalid",
+			Message: "Invalid SSH public key",
+		}
+		return
+	}
+
+	cert, record, err = f.sign(db, r, sshcert.User, username, pubKey,
+		principals, f.GetUserTtl(), usrId, bson.NilObjectID)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// GetHostKey returns the instance host key, generating the key and renewing
+// the host certificate when required.
+func (f *Authority) GetHostKey(db *database.Database, instId bson.ObjectID,
+	hostnames []string) (hostKey *sshcert.HostKey, err error) {
+
+	hostKey, err = sshcert.GetHostKey(db, instId)
+	if err != nil {
+		return
+	}
+
+	ttl := f.GetHostTtl()
+	if hostKey != nil && hostKey.Authority == f.Id && !hostKey.Expiring(ttl) {
+		return
+	}
+
+	if hostKey == nil {
+		privKey, pubKey, e := generateKey(instId.Hex())
+		if e != nil {
+			err = e
+			return
+		}
+
+		hostKey = &sshcert.HostKey{
+			Id:         instId,
+			PrivateKey: envelope.String(privKey),
+			PublicKey:  pubKey,
+		}
+	}
+
+	cert, record, err := f.sign(db, nil, sshcert.Host, instId.Hex(),
+		hostKey.PublicKey, hostnames, ttl, bson.NilObjectID, instId)
+	if err != nil {
+		return
+	}
+
+	hostKey.Authority = f.Id
+	hostKey.Certificate = cert
+	hostKey.ValidBefore = record.ValidBefore
+
+	err = hostKey.Commit(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// HostPrincipals returns the host certificate principals for an instance
+// name and address lists
+func HostPrincipals(name string, addrs ...[]string) (principals []string) {
+	principals = []string{}
+	principalsSet := set.NewSet()
+
+	name = strings.Replace(name, " ", "_", -1)
+	if name != "" {
+		principalsSet.Add(name)
+		principals = append(principals, name)
+	}
+
+	for _, addrList := range addrs {
+		for _, addr := range addrList {
+			if addr == "" || principalsSet.Contains(addr) {
+				continue
+			}
+			principalsSet.Add(addr)
+			principals = append(principals, addr)
+		}
+	}
+
+	return
+}
@@ -39,6 +39,24 @@ func GetOrg(db *database.Database, orgId, authrId bson.ObjectID) (
 	return
 }
 
+func GetOrgCa(db *database.Database, orgId bson.ObjectID) (
+	authr *Authority, err error) {
+
+	coll := db.Authorities()
+	authr = &Authority{}
+
+	err = coll.FindOne(db, &bson.M{
+		"type":         SshAuthority,
+		"organization": orgId,
+	}).Decode(authr)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
 func GetAll(db *database.Database, query *bson.M) (
 	authrs []*Authority, err error) {
 
@@ -0,0 +1,411 @@
+package autoscale
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/deployment"
+	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/journal"
+	"github.com/pritunl/pritunl-cloud/metric"
+	"github.com/pritunl/pritunl-cloud/scheduler"
+	"github.com/pritunl/pritunl-cloud/spec"
+	"github.com/pritunl/pritunl-cloud/unit"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
+)
+
+type Autoscaler struct {
+	changed bool
+}
+
+func (a *Autoscaler) log(db *database.Database, unt *unit.Unit,
+	level int32, message string, fields map[string]string) {
+
+	jrnl := &journal.Journal{
+		Resource:  unt.Id,
+		Kind:      journal.UnitAutoscale,
+		Level:     level,
+		Timestamp: time.Now(),
+		Message:   message,
+		Fields:    fields,
+	}
+
+	err := jrnl.Insert(db)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"pod":   unt.Pod.Hex(),
+			"unit":  unt.Id.Hex(),
+			"error": err,
+		}).Error("autoscale: Failed to write unit journal")
+	}
+}
+
+func desiredCount(current int, value, target float64) int {
+	if target <= 0 || current < 1 {
+		return current
+	}
+
+	ratio := value / target
+	if math.Abs(ratio-1) <= Tolerance {
+		return current
+	}
+
+	return int(math.Ceil(float64(current) * ratio))
+}
+
+func (a *Autoscaler) disable(db *database.Database, unt *unit.Unit) (
+	err error) {
+
+	coll := db.Units()
+
+	_, err = coll.UpdateOne(db, &bson.M{
+		"_id": unt.Id,
+	}, &bson.M{
+		"$unset": &bson.M{
+			"autoscale": 1,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	a.log(db, unt, journal.Info, fmt.Sprintf(
+		"Autoscale disabled, returning to count %d", unt.Count), nil)
+
+	unt.Autoscale = nil
+	a.changed = true
+
+	return
+}
+
+func (a *Autoscaler) scaleDown(db *database.Database, unt *unit.Unit,
+	deplys []*deployment.Deployment, count int) (
+	removed []bson.ObjectID, err error) {
+
+	active := []*deployment.Deployment{}
+	for _, deply := range deplys {
+		if deply.Action == deployment.Destroy {
+			continue
+		}
+		active = append(active, deply)
+	}
+
+	excess := len(active) - count
+	if excess <= 0 {
+		return
+	}
+
+	sort.SliceStable(active, func(i, j int) bool {
+		x := active[i]
+		y := active[j]
+
+		if (x.Id == unt.Primary) != (y.Id == unt.Primary) {
+			return y.Id == unt.Primary
+		}
+		if x.IsHealthy() != y.IsHealthy() {
+			return !x.IsHealthy()
+		}
+		return x.Timestamp.After(y.Timestamp)
+	})
+
+	for _, deply := range active[:excess] {
+		removed = append(removed, deply.Id)
+	}
+
+	err = deployment.RemoveMulti(db, unt.Id, removed)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (a *Autoscaler) process(db *database.Database, unt *unit.Unit,
+	spc *spec.Spec) (err error) {
+
+	if spc == nil || spc.Instance == nil || spc.Instance.Autoscale == nil {
+		if unt.Autoscale != nil {
+			err = a.disable(db, unt)
+			if err != nil {
+				return
+			}
+		}
+		return
+	}
+	conf := spc.Instance.Autoscale
+
+	if unt.Rollout.IsActive() {
+		return
+	}
+
+	exists, err := scheduler.Exists(db, unt.Id)
+	if err != nil {
+		return
+	}
+	if exists {
+		return
+	}
+
+	now := time.Now()
+	state := unt.Autoscale
+	if state == nil {
+		state = &unit.Autoscale{
+			Count: unt.Count,
+		}
+		unt.Autoscale = state
+	}
+	state.Spec = spc.Id
+
+	deplys, err := deployment.GetAll(db, &bson.M{
+		"unit": unt.Id,
+	})
+	if err != nil {
+		return
+	}
+
+	current := []*deployment.Deployment{}
+	instIds := []bson.ObjectID{}
+	for _, deply := range deplys {
+		if deply.State != deployment.Deployed ||
+			deply.Action == deployment.Destroy {
+
+			continue
+		}
+
+		current = append(current, deply)
+		if !deply.Instance.IsZero() {
+			instIds = append(instIds, deply.Instance)
+		}
+	}
+
+	bounded := utils.Max(conf.MinCount, utils.Min(conf.MaxCount, state.Count))
+	if bounded != state.Count {
+		a.log(db, unt, journal.Info, fmt.Sprintf(
+			"Adjusting count from %d to %d to fit autoscale bounds %d-%d",
+			state.Count, bounded, conf.MinCount, conf.MaxCount,
+		), nil)
+
+		if bounded < state.Count {
+			state.LastScaleDown = now
+		} else {
+			state.LastScaleUp = now
+		}
+		state.Count = bounded
+		state.Desired = bounded
+		state.Timestamp = now
+		state.Message = ""
+
+		if len(current) > bounded {
+			_, err = a.scaleDown(db, unt, current, bounded)
+			if err != nil {
+				return
+			}
+		}
+
+		err = unt.CommitFields(db, set.NewSet("autoscale"))
+		if err != nil {
+			return
+		}
+		a.changed = true
+
+		return
+	}
+
+	if len(current) == 0 || len(current) < state.Count {
+		return
+	}
+
+	start := now.Add(-time.Duration(conf.Window) * time.Second)
+	desired := 0
+	reasons := []string{}
+	fields := map[string]string{}
+
+	if conf.CpuTarget > 0 || conf.MemoryTarget > 0 {
+		cpuUsage, memUsage, samples, e := metric.GetSystemAverage(
+			db, db, instIds, start)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if samples > 0 {
+			state.CpuUsage = utils.ToFixed(cpuUsage, 2)
+			state.MemUsage = utils.ToFixed(memUsage, 2)
+
+			if conf.CpuTarget > 0 {
+				count := desiredCount(len(current), cpuUsage,
+					float64(conf.CpuTarget))
+				desired = utils.Max(desired, count)
+				reasons = append(reasons, fmt.Sprintf(
+					"cpu %.2f%% target %d%%", cpuUsage, conf.CpuTarget))
+				fields["cpu_usage"] = fmt.Sprintf("%.2f", cpuUsage)
+			}
+
+			if conf.MemoryTarget > 0 {
+				count := desiredCount(len(current), memUsage,
+					float64(conf.MemoryTarget))
+				desired = utils.Max(desired, count)
+				reasons = append(reasons, fmt.Sprintf(
+					"memory %.2f%% target %d%%",
+					memUsage, conf.MemoryTarget))
+				fields["mem_usage"] = fmt.Sprintf("%.2f", memUsage)
+			}
+		}
+	}
+
+	if conf.Metric != "" {
+		value, samples, e := metric.GetCustomAverage(
+			db, db, instIds, conf.Metric, start)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if samples > 0 {
+			state.MetricValue = value
+
+			count := desiredCount(len(current), value, conf.MetricTarget)
+			desired = utils.Max(desired, count)
+			reasons = append(reasons, fmt.Sprintf(
+				"%s %.2f target %.2f", conf.Metric, value, conf.MetricTarget))
+			fields["metric"] = conf.Metric
+			fields["metric_value"] = fmt.Sprintf("%.2f", value)
+		}
+	}
+
+	if len(reasons) == 0 {
+		return
+	}
+
+	state.Timestamp = now
+	desired = utils.Max(conf.MinCount, utils.Min(conf.MaxCount, desired))
+	fields["count"] = fmt.Sprintf("%d", state.Count)
+	fields["desired"] = fmt.Sprintf("%d", desired)
+
+	reason := ""
+	for i, rsn := range reasons {
+		if i > 0 {
+			reason += ", "
+		}
+		reason += rsn
+	}
+
+	message := ""
+	if desired > state.Count {
+		cooldown := time.Duration(conf.ScaleUpCooldown) * time.Second
+		if now.Sub(state.LastScaleUp) < cooldown {
+			message = fmt.Sprintf(
+				"Scale up from %d to %d deferred by cooldown (%s)",
+				state.Count, desired, reason)
+		} else {
+			a.log(db, unt, journal.Info, fmt.Sprintf(
+				"Scaling up from %d to %d (%s)",
+				state.Count, desired, reason), fields)
+
+			state.Count = desired
+			state.LastScaleUp = now
+		}
+	} else if desired < state.Count {
+		cooldown := time.Duration(conf.ScaleDownCooldown) * time.Second
+		if now.Sub(state.LastScaleDown) < cooldown ||
+			now.Sub(state.LastScaleUp) < cooldown {
+
+			message = fmt.Sprintf(
+				"Scale down from %d to %d deferred by cooldown (%s)",
+				state.Count, desired, reason)
+		} else {
+			removed, e := a.scaleDown(db, unt, current, desired)
+			if e != nil {
+				err = e
+				return
+			}
+
+			fields["removed"] = fmt.Sprintf("%d", len(removed))
+			a.log(db, unt, journal.Info, fmt.Sprintf(
+				"Scaling down from %d to %d (%s)",
+				state.Count, desired, reason), fields)
+
+			state.Count = desired
+			state.LastScaleDown = now
+		}
+	}
+
+	if message != "" && (message != state.Message ||
+		desired != state.Desired) {
+
+		a.log(db, unt, journal.Debug, message, fields)
+	}
+	state.Message = message
+	state.Desired = desired
+
+	err = unt.CommitFields(db, set.NewSet("autoscale"))
+	if err != nil {
+		return
+	}
+	a.changed = true
+
+	return
+}
+
+func (a *Autoscaler) Apply(db *database.Database) (err error) {
+	units, err := unit.GetAll(db, &bson.M{
+		"kind": deployment.Instance,
+	})
+	if err != nil {
+		return
+	}
+
+	specIds := []bson.ObjectID{}
+	for _, unt := range units {
+		if !unt.DeploySpec.IsZero() {
+			specIds = append(specIds, unt.DeploySpec)
+		}
+	}
+
+	spcs, err := spec.GetAll(db, &bson.M{
+		"_id": &bson.M{
+			"$in": specIds,
+		},
+		"instance.autoscale": &bson.M{
+			"$exists": true,
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	spcsMap := map[bson.ObjectID]*spec.Spec{}
+	for _, spc := range spcs {
+		spcsMap[spc.Id] = spc
+	}
+
+	for _, unt := range units {
+		spc := spcsMap[unt.DeploySpec]
+		if spc == nil && unt.Autoscale == nil {
+			continue
+		}
+
+		e := a.process(db, unt, spc)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"pod":   unt.Pod.Hex(),
+				"unit":  unt.Id.Hex(),
+				"error": e,
+			}).Error("autoscale: Failed to process unit autoscale")
+		}
+	}
+
+	if a.changed {
+		event.PublishDispatch(db, "pod.change")
+		event.PublishDispatch(db, "unit.change")
+	}
+
+	return
+}
@@ -0,0 +1,5 @@
+package autoscale
+
+const (
+	Tolerance = 0.1
+)
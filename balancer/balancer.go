@@ -3,6 +3,7 @@ package balancer
 import (
 	"fmt"
 	"net"
+	"net/http"
 	"time"
 
 	"github.com/dropbox/godropbox/container/set"
@@ -24,6 +25,8 @@ type Backend struct {
 	Protocol string `bson:"protocol" json:"protocol"`
 	Hostname string `bson:"hostname" json:"hostname"`
 	Port     int    `bson:"port" json:"port"`
+	Group    string `bson:"group" json:"group"`
+	Weight   int    `bson:"weight" json:"weight"`
 }
 
 type State struct {
@@ -51,8 +54,16 @@ type Balancer struct {
 	WebSockets      bool              `bson:"websockets" json:"websockets"`
 	Domains         []*Domain         `bson:"domains" json:"domains"`
 	Backends        []*Backend        `bson:"backends" json:"backends"`
+	Routes          []*Route          `bson:"routes" json:"routes"`
 	States          map[string]*State `bson:"states" json:"states"`
 	CheckPath       string            `bson:"check_path" json:"check_path"`
+	RateLimit       int               `bson:"rate_limit" json:"rate_limit"`
+	RateLimitBurst  int               `bson:"rate_limit_burst" json:"rate_limit_burst"`
+	RateLimitKey    string            `bson:"rate_limit_key" json:"rate_limit_key"`
+	RateLimitHeader string            `bson:"rate_limit_header" json:"rate_limit_header"`
+	StickySessions  bool              `bson:"sticky_sessions" json:"sticky_sessions"`
+	StickyCookie    string            `bson:"sticky_cookie" json:"sticky_cookie"`
+	AccessLog       string            `bson:"access_log" json:"access_log"`
 }
 
 func (b *Balancer) Validate(db *database.Database) (
@@ -93,6 +104,11 @@ func (b *Balancer) Validate(db *database.Database) (
 		b.States = map[string]*State{}
 	}
 
+	if b.Routes == nil {
+		b.Routes = []*Route{}
+	}
+
+	groups := set.NewSet()
 	for _, backend := range b.Backends {
 		if backend.Protocol != "http" && backend.Protocol != "https" {
 			errData = &errortypes.ErrorData{
@@ -129,6 +145,21 @@ func (b *Balancer) Validate(db *database.Database) (
 		}
 		backend.Hostname = ip.String()
 
+		backend.Group = utils.FilterName(backend.Group)
+		if backend.Group != "" {
+			groups.Add(backend.Group)
+		}
+
+		if backend.Weight == 0 {
+			backend.Weight = DefaultWeight
+		} else if backend.Weight < 0 || backend.Weight > MaxWeight {
+			errData = &errortypes.ErrorData{
+				Error:   "balancer_weight_invalid",
+				Message: "Invalid balancer backend weight",
+			}
+			return
+		}
+
 		exists, e := instance.ExistsIp(db, backend.Hostname)
 		if e != nil {
 			err = e
@@ -145,6 +176,80 @@ func (b *Balancer) Validate(db *database.Database) (
 		}
 	}
 
+	domainsSet := set.NewSet()
+	for _, domain := range b.Domains {
+		domainsSet.Add(domain.Domain)
+	}
+
+	for _, route := range b.Routes {
+		errData = route.Validate(domainsSet, groups)
+		if errData != nil {
+			return
+		}
+	}
+
+	if b.RateLimit < 0 || b.RateLimitBurst < 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "balancer_rate_limit_invalid",
+			Message: "Invalid balancer rate limit",
+		}
+		return
+	}
+
+	if b.RateLimit == 0 {
+		b.RateLimitBurst = 0
+		b.RateLimitKey = ""
+		b.RateLimitHeader = ""
+	} else {
+		if b.RateLimitBurst < b.RateLimit {
+			b.RateLimitBurst = b.RateLimit
+		}
+
+		switch b.RateLimitKey {
+		case "", RateLimitClientIp:
+			b.RateLimitKey = RateLimitClientIp
+			b.RateLimitHeader = ""
+			break
+		case RateLimitHeader:
+			b.RateLimitHeader = http.CanonicalHeaderKey(
+				utils.FilterName(b.RateLimitHeader))
+			if b.RateLimitHeader == "" {
+				errData = &errortypes.ErrorData{
+					Error:   "balancer_rate_limit_header_invalid",
+					Message: "Missing balancer rate limit header",
+				}
+				return
+			}
+			break
+		default:
+			errData = &errortypes.ErrorData{
+				Error:   "balancer_rate_limit_key_invalid",
+				Message: "Invalid balancer rate limit key",
+			}
+			return
+		}
+	}
+
+	if b.StickySessions {
+		b.StickyCookie = utils.FilterName(b.StickyCookie)
+		if b.StickyCookie == "" {
+			b.StickyCookie = DefaultStickyCookie
+		}
+	} else {
+		b.StickyCookie = ""
+	}
+
+	switch b.AccessLog {
+	case "", AccessLogDatabase, AccessLogNode:
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "balancer_access_log_invalid",
+			Message: "Invalid balancer access log type",
+		}
+		return
+	}
+
 	if b.Organization.IsZero() {
 		errData = &errortypes.ErrorData{
 			Error:   "organization_required",
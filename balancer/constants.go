@@ -2,4 +2,22 @@ package balancer
 
 const (
 	Http = "http"
+
+	DefaultWeight = 100
+	MaxWeight     = 10000
+
+	RateLimitClientIp = "client_ip"
+	RateLimitHeader   = "header"
+
+	AccessLogDatabase = "database"
+	AccessLogNode     = "node"
+
+	DefaultStickyCookie = "pritunl-cloud-affinity"
 )
+
+var redirectCodes = map[int]bool{
+	301: true,
+	302: true,
+	307: true,
+	308: true,
+}
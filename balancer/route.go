@@ -0,0 +1,184 @@
+package balancer
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type Header struct {
+	Name  string `bson:"name" json:"name"`
+	Value string `bson:"value" json:"value"`
+}
+
+type Route struct {
+	Domain                string    `bson:"domain" json:"domain"`
+	Path                  string    `bson:"path" json:"path"`
+	Regex                 string    `bson:"regex" json:"regex"`
+	Group                 string    `bson:"group" json:"group"`
+	Rewrite               string    `bson:"rewrite" json:"rewrite"`
+	RedirectHttps         bool      `bson:"redirect_https" json:"redirect_https"`
+	RedirectHost          string    `bson:"redirect_host" json:"redirect_host"`
+	RedirectCode          int       `bson:"redirect_code" json:"redirect_code"`
+	RequestHeaders        []*Header `bson:"request_headers" json:"request_headers"`
+	RequestHeadersRemove  []string  `bson:"request_headers_remove" json:"request_headers_remove"`
+	ResponseHeaders       []*Header `bson:"response_headers" json:"response_headers"`
+	ResponseHeadersRemove []string  `bson:"response_headers_remove" json:"response_headers_remove"`
+}
+
+func (r *Route) IsRedirect() bool {
+	return r.RedirectHttps || r.RedirectHost != ""
+}
+
+func filterHeaders(headers []*Header) (
+	filtered []*Header, errData *errortypes.ErrorData) {
+
+	filtered = []*Header{}
+	for _, header := range headers {
+		name := http.CanonicalHeaderKey(utils.FilterName(header.Name))
+		if name == "" {
+			errData = &errortypes.ErrorData{
+				Error:   "balancer_route_header_invalid",
+				Message: "Invalid balancer route header name",
+			}
+			return
+		}
+
+		filtered = append(filtered, &Header{
+			Name:  name,
+			Value: utils.FilterStr(header.Value, 1024),
+		})
+	}
+
+	return
+}
+
+func filterHeaderNames(names []string) (
+	filtered []string, errData *errortypes.ErrorData) {
+
+	filtered = []string{}
+	for _, name := range names {
+		name = http.CanonicalHeaderKey(utils.FilterName(name))
+		if name == "" {
+			errData = &errortypes.ErrorData{
+				Error:   "balancer_route_header_invalid",
+				Message: "Invalid balancer route header name",
+			}
+			return
+		}
+
+		filtered = append(filtered, name)
+	}
+
+	return
+}
+
+func (r *Route) Validate(domains, groups set.Set) (
+	errData *errortypes.ErrorData) {
+
+	r.Domain = utils.FilterDomain(r.Domain)
+	r.Path = utils.FilterStr(strings.TrimSpace(r.Path), 512)
+	r.Regex = strings.TrimSpace(r.Regex)
+	r.Group = utils.FilterName(r.Group)
+	r.Rewrite = utils.FilterStr(strings.TrimSpace(r.Rewrite), 512)
+	r.RedirectHost = utils.FilterDomain(r.RedirectHost)
+
+	if r.Domain != "" && !domains.Contains(r.Domain) {
+		errData = &errortypes.ErrorData{
+			Error: "balancer_route_domain_invalid",
+			Message: fmt.Sprintf("Balancer route domain '%s' must "+
+				"match balancer domain", r.Domain),
+		}
+		return
+	}
+
+	if r.Path != "" && !strings.HasPrefix(r.Path, "/") {
+		errData = &errortypes.ErrorData{
+			Error:   "balancer_route_path_invalid",
+			Message: "Balancer route path must start with '/'",
+		}
+		return
+	}
+
+	if r.Regex != "" {
+		if len(r.Regex) > 512 {
+			errData = &errortypes.ErrorData{
+				Error:   "balancer_route_regex_invalid",
+				Message: "Balancer route regex too long",
+			}
+			return
+		}
+
+		_, e := regexp.Compile(r.Regex)
+		if e != nil {
+			errData = &errortypes.ErrorData{
+				Error: "balancer_route_regex_invalid",
+				Message: fmt.Sprintf("Invalid balancer route regex '%s'",
+					r.Regex),
+			}
+			return
+		}
+	}
+
+	if r.Rewrite != "" && r.Regex == "" &&
+		!strings.HasPrefix(r.Rewrite, "/") {
+
+		errData = &errortypes.ErrorData{
+			Error:   "balancer_route_rewrite_invalid",
+			Message: "Balancer route rewrite must start with '/'",
+		}
+		return
+	}
+
+	if r.Group != "" && !groups.Contains(r.Group) {
+		errData = &errortypes.ErrorData{
+			Error: "balancer_route_group_invalid",
+			Message: fmt.Sprintf("Balancer route group '%s' must "+
+				"match backend group", r.Group),
+		}
+		return
+	}
+
+	if r.IsRedirect() {
+		if r.RedirectCode == 0 {
+			r.RedirectCode = 301
+		} else if !redirectCodes[r.RedirectCode] {
+			errData = &errortypes.ErrorData{
+				Error:   "balancer_route_redirect_code_invalid",
+				Message: "Invalid balancer route redirect code",
+			}
+			return
+		}
+	} else {
+		r.RedirectCode = 0
+	}
+
+	r.RequestHeaders, errData = filterHeaders(r.RequestHeaders)
+	if errData != nil {
+		return
+	}
+
+	r.RequestHeadersRemove, errData = filterHeaderNames(
+		r.RequestHeadersRemove)
+	if errData != nil {
+		return
+	}
+
+	r.ResponseHeaders, errData = filterHeaders(r.ResponseHeaders)
+	if errData != nil {
+		return
+	}
+
+	r.ResponseHeadersRemove, errData = filterHeaderNames(
+		r.ResponseHeadersRemove)
+	if errData != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,218 @@
+package bundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"gopkg.in/yaml.v2"
+)
+
+type Bundle struct {
+	Version    int          `yaml:"version" json:"version"`
+	Kind       string       `yaml:"kind" json:"kind"`
+	Exported   time.Time    `yaml:"exported" json:"exported"`
+	Pod        *PodData     `yaml:"pod" json:"pod"`
+	Plans      []*PlanData  `yaml:"plans,omitempty" json:"plans"`
+	References []*Reference `yaml:"references,omitempty" json:"references"`
+}
+
+type PodData struct {
+	Name             string      `yaml:"name" json:"name"`
+	Comment          string      `yaml:"comment,omitempty" json:"comment"`
+	DeleteProtection bool        `yaml:"deleteProtection,omitempty" json:"delete_protection"`
+	Units            []*UnitData `yaml:"units" json:"units"`
+}
+
+type UnitData struct {
+	Name string `yaml:"name" json:"name"`
+	Spec string `yaml:"spec,omitempty" json:"spec"`
+}
+
+type PlanData struct {
+	Name       string   `yaml:"name" json:"name"`
+	Comment    string   `yaml:"comment,omitempty" json:"comment"`
+	Statements []string `yaml:"statements" json:"statements"`
+}
+
+type Reference struct {
+	Kind  string `yaml:"kind" json:"kind"`
+	Name  string `yaml:"name" json:"name"`
+	Unit  string `yaml:"-" json:"unit,omitempty"`
+	Token string `yaml:"-" json:"token,omitempty"`
+}
+
+func (b *Bundle) Yaml() (data []byte, err error) {
+	data, err = yaml.Marshal(b)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "bundle: Failed to marshal yaml"),
+		}
+		return
+	}
+
+	return
+}
+
+func (b *Bundle) Tar() (data []byte, err error) {
+	manifest := *b
+	manifest.Pod = &PodData{
+		Name:             b.Pod.Name,
+		Comment:          b.Pod.Comment,
+		DeleteProtection: b.Pod.DeleteProtection,
+		Units:            []*UnitData{},
+	}
+	for _, unt := range b.Pod.Units {
+		manifest.Pod.Units = append(manifest.Pod.Units, &UnitData{
+			Name: unt.Name,
+		})
+	}
+
+	manifestData, err := manifest.Yaml()
+	if err != nil {
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	writer := tar.NewWriter(buf)
+
+	err = writeTarFile(writer, ManifestName, manifestData, b.Exported)
+	if err != nil {
+		return
+	}
+
+	for _, unt := range b.Pod.Units {
+		err = writeTarFile(writer, path.Join(UnitsDir, unt.Name+".md"),
+			[]byte(unt.Spec), b.Exported)
+		if err != nil {
+			return
+		}
+	}
+
+	err = writer.Close()
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "bundle: Failed to close tar"),
+		}
+		return
+	}
+
+	data = buf.Bytes()
+	return
+}
+
+func writeTarFile(writer *tar.Writer, name string, data []byte,
+	modTime time.Time) (err error) {
+
+	err = writer.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "bundle: Failed to write tar header"),
+		}
+		return
+	}
+
+	_, err = writer.Write(data)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "bundle: Failed to write tar file"),
+		}
+		return
+	}
+
+	return
+}
+
+func ParseYaml(data []byte) (bndl *Bundle, err error) {
+	bndl = &Bundle{}
+
+	err = yaml.Unmarshal(data, bndl)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "bundle: Failed to parse yaml"),
+		}
+		return
+	}
+
+	return
+}
+
+func ParseTar(data []byte) (bndl *Bundle, err error) {
+	reader := tar.NewReader(bytes.NewReader(data))
+	specs := map[string]string{}
+
+	for {
+		header, e := reader.Next()
+		if e != nil {
+			if e == io.EOF {
+				break
+			}
+
+			err = &errortypes.ParseError{
+				errors.Wrap(e, "bundle: Failed to read tar"),
+			}
+			return
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		fileData, e := io.ReadAll(io.LimitReader(reader, MaxSize))
+		if e != nil {
+			err = &errortypes.ReadError{
+				errors.Wrap(e, "bundle: Failed to read tar file"),
+			}
+			return
+		}
+
+		name := path.Clean(strings.TrimPrefix(header.Name, "./"))
+		if name == ManifestName {
+			bndl, err = ParseYaml(fileData)
+			if err != nil {
+				return
+			}
+		} else if path.Dir(name) == UnitsDir &&
+			strings.HasSuffix(name, ".md") {
+
+			specs[strings.TrimSuffix(path.Base(name), ".md")] = string(
+				fileData)
+		}
+	}
+
+	if bndl == nil {
+		err = &errortypes.ParseError{
+			errors.New("bundle: Tar missing bundle manifest"),
+		}
+		return
+	}
+
+	if bndl.Pod != nil {
+		for _, unt := range bndl.Pod.Units {
+			if unt.Spec == "" {
+				unt.Spec = specs[unt.Name]
+			}
+		}
+	}
+
+	return
+}
+
+func Parse(data []byte) (bndl *Bundle, err error) {
+	if len(data) > 262 && string(data[257:262]) == "ustar" {
+		bndl, err = ParseTar(data)
+	} else {
+		bndl, err = ParseYaml(data)
+	}
+	return
+}
@@ -0,0 +1,18 @@
+package bundle
+
+const (
+	Version = 1
+
+	PodKind = "pod"
+
+	Yaml = "yaml"
+	Tar  = "tar"
+
+	ManifestName = "bundle.yaml"
+	UnitsDir     = "units"
+
+	MaxSize = 8 << 20
+
+	Create = "create"
+	Exists = "exists"
+)
@@ -0,0 +1,123 @@
+package bundle
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/finder"
+	"github.com/pritunl/pritunl-cloud/plan"
+	"github.com/pritunl/pritunl-cloud/pod"
+	"github.com/pritunl/pritunl-cloud/spec"
+	"github.com/pritunl/pritunl-cloud/unit"
+)
+
+func parseToken(token string) (kind, name string) {
+	parts := strings.SplitN(
+		strings.TrimPrefix(token, spec.TokenPrefix), "/", 3)
+	if len(parts) < 2 {
+		return
+	}
+
+	kind = parts[0]
+	name = strings.SplitN(parts[1], ":", 2)[0]
+	return
+}
+
+func Export(db *database.Database, pd *pod.Pod) (
+	bndl *Bundle, err error) {
+
+	units, err := unit.GetAll(db, &bson.M{
+		"pod":          pd.Id,
+		"organization": pd.Organization,
+	})
+	if err != nil {
+		return
+	}
+
+	sort.Slice(units, func(i, j int) bool {
+		return units[i].Name < units[j].Name
+	})
+
+	bndl = &Bundle{
+		Version:  Version,
+		Kind:     PodKind,
+		Exported: time.Now().UTC().Truncate(time.Second),
+		Pod: &PodData{
+			Name:             pd.Name,
+			Comment:          pd.Comment,
+			DeleteProtection: pd.DeleteProtection,
+			Units:            []*UnitData{},
+		},
+		Plans:      []*PlanData{},
+		References: []*Reference{},
+	}
+
+	unitNames := set.NewSet()
+	for _, unt := range units {
+		unitNames.Add(unt.Name)
+		bndl.Pod.Units = append(bndl.Pod.Units, &UnitData{
+			Name: unt.Name,
+			Spec: unt.Spec,
+		})
+	}
+
+	refs := set.NewSet()
+	for _, unt := range units {
+		for _, token := range spec.ResourceTokens(unt.Spec) {
+			kind, name := parseToken(token)
+			if kind == "" || name == "" {
+				continue
+			}
+
+			if kind == finder.PodKind && name == pd.Name {
+				continue
+			}
+			if kind == finder.UnitKind && unitNames.Contains(name) {
+				continue
+			}
+
+			key := kind + "/" + name
+			if refs.Contains(key) {
+				continue
+			}
+			refs.Add(key)
+
+			if kind == finder.PlanKind {
+				pln, e := plan.GetOne(db, &bson.M{
+					"name":         name,
+					"organization": pd.Organization,
+				})
+				if e != nil {
+					if _, ok := e.(*database.NotFoundError); !ok {
+						err = e
+						return
+					}
+				} else {
+					plnData := &PlanData{
+						Name:       pln.Name,
+						Comment:    pln.Comment,
+						Statements: []string{},
+					}
+					for _, statement := range pln.Statements {
+						plnData.Statements = append(
+							plnData.Statements, statement.Statement)
+					}
+
+					bndl.Plans = append(bndl.Plans, plnData)
+					continue
+				}
+			}
+
+			bndl.References = append(bndl.References, &Reference{
+				Kind: kind,
+				Name: name,
+			})
+		}
+	}
+
+	return
+}
@@ -0,0 +1,519 @@
+package bundle
+
+import (
+	"regexp"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/finder"
+	"github.com/pritunl/pritunl-cloud/plan"
+	"github.com/pritunl/pritunl-cloud/pod"
+	"github.com/pritunl/pritunl-cloud/spec"
+	"github.com/pritunl/pritunl-cloud/unit"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
+)
+
+type Report struct {
+	DryRun     bool          `json:"dry_run"`
+	Pod        string        `json:"pod"`
+	PodId      bson.ObjectID `json:"pod_id"`
+	Units      []string      `json:"units"`
+	Plans      []*PlanReport `json:"plans"`
+	Unresolved []*Reference  `json:"unresolved"`
+	Errors     []*UnitError  `json:"errors"`
+}
+
+type PlanReport struct {
+	Name   string `json:"name"`
+	Action string `json:"action"`
+}
+
+type UnitError struct {
+	Unit    string `json:"unit"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+func (r *Report) Valid() bool {
+	return len(r.Unresolved) == 0 && len(r.Errors) == 0
+}
+
+type importer struct {
+	db        *database.Database
+	orgId     bson.ObjectID
+	bndl      *Bundle
+	report    *Report
+	podName   string
+	unitNames set.Set
+	planNames set.Set
+	newPlans  []*plan.Plan
+	unitDeps  map[string]set.Set
+}
+
+func (m *importer) validate() (errData *errortypes.ErrorData, err error) {
+	if m.bndl.Version < 1 || m.bndl.Version > Version {
+		errData = &errortypes.ErrorData{
+			Error:   "bundle_version_invalid",
+			Message: "Bundle version is not supported",
+		}
+		return
+	}
+
+	if m.bndl.Kind != PodKind || m.bndl.Pod == nil {
+		errData = &errortypes.ErrorData{
+			Error:   "bundle_kind_invalid",
+			Message: "Bundle does not contain a pod",
+		}
+		return
+	}
+
+	if m.podName == "" {
+		errData = &errortypes.ErrorData{
+			Error:   "pod_name_invalid",
+			Message: "Pod name is invalid",
+		}
+		return
+	}
+
+	if len(m.bndl.Pod.Units) == 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "bundle_units_missing",
+			Message: "Bundle does not contain any units",
+		}
+		return
+	}
+
+	for _, unt := range m.bndl.Pod.Units {
+		if unt.Spec == "" {
+			errData = &errortypes.ErrorData{
+				Error:   "bundle_unit_spec_missing",
+				Message: "Bundle unit missing spec",
+			}
+			return
+		}
+
+		if m.unitNames.Contains(unt.Name) {
+			errData = &errortypes.ErrorData{
+				Error:   "bundle_unit_duplicate",
+				Message: "Bundle contains duplicate unit names",
+			}
+			return
+		}
+		m.unitNames.Add(unt.Name)
+	}
+
+	_, err = pod.GetOne(m.db, &bson.M{
+		"name":         m.podName,
+		"organization": m.orgId,
+	})
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+		} else {
+			return
+		}
+	} else {
+		errData = &errortypes.ErrorData{
+			Error:   "pod_exists",
+			Message: "Pod with the same name already exists",
+		}
+		return
+	}
+
+	return
+}
+
+func (m *importer) rename() {
+	srcName := m.bndl.Pod.Name
+	if srcName == m.podName {
+		return
+	}
+
+	podRe := regexp.MustCompile(`(\+\/` + finder.PodKind + `\/)` +
+		regexp.QuoteMeta(srcName) + `([^a-zA-Z0-9-_.]|$)`)
+
+	for _, unt := range m.bndl.Pod.Units {
+		unt.Spec = podRe.ReplaceAllString(
+			unt.Spec, "${1}"+m.podName+"${2}")
+	}
+}
+
+func (m *importer) plans() (errData *errortypes.ErrorData, err error) {
+	for _, plnData := range m.bndl.Plans {
+		pln := &plan.Plan{
+			Name:         plnData.Name,
+			Comment:      plnData.Comment,
+			Organization: m.orgId,
+			Statements:   []*plan.Statement{},
+		}
+		for _, statement := range plnData.Statements {
+			pln.Statements = append(pln.Statements, &plan.Statement{
+				Statement: statement,
+			})
+		}
+
+		errData, err = pln.Validate(m.db)
+		if err != nil || errData != nil {
+			return
+		}
+
+		if m.planNames.Contains(pln.Name) {
+			continue
+		}
+		m.planNames.Add(pln.Name)
+
+		_, err = plan.GetOne(m.db, &bson.M{
+			"name":         pln.Name,
+			"organization": m.orgId,
+		})
+		if err != nil {
+			if _, ok := err.(*database.NotFoundError); ok {
+				err = nil
+			} else {
+				return
+			}
+		} else {
+			m.report.Plans = append(m.report.Plans, &PlanReport{
+				Name:   pln.Name,
+				Action: Exists,
+			})
+			continue
+		}
+
+		m.newPlans = append(m.newPlans, pln)
+		m.report.Plans = append(m.report.Plans, &PlanReport{
+			Name:   pln.Name,
+			Action: Create,
+		})
+	}
+
+	return
+}
+
+func (m *importer) resolve() (err error) {
+	for _, untData := range m.bndl.Pod.Units {
+		resources := &finder.Resources{
+			Organization: m.orgId,
+		}
+		deps := set.NewSet()
+		tokens := set.NewSet()
+
+		for _, token := range spec.ResourceTokens(untData.Spec) {
+			if tokens.Contains(token) {
+				continue
+			}
+			tokens.Add(token)
+
+			kind, name := parseToken(token)
+			switch {
+			case kind == finder.PodKind && name == m.podName:
+				continue
+			case kind == finder.UnitKind && m.unitNames.Contains(name):
+				deps.Add(name)
+				continue
+			case kind == finder.PlanKind && m.planNames.Contains(name):
+				continue
+			}
+
+			_, e := resources.Find(m.db, token)
+			if e != nil {
+				if _, ok := e.(*errortypes.ParseError); !ok {
+					err = e
+					return
+				}
+			} else if resources.Found(kind) {
+				continue
+			}
+
+			m.report.Unresolved = append(m.report.Unresolved, &Reference{
+				Kind:  kind,
+				Name:  name,
+				Unit:  untData.Name,
+				Token: token,
+			})
+		}
+
+		m.unitDeps[untData.Name] = deps
+	}
+
+	return
+}
+
+func (m *importer) parse() (err error) {
+	podId := bson.NewObjectID()
+	names := set.NewSet()
+
+	for _, untData := range m.bndl.Pod.Units {
+		unt := &unit.Unit{
+			Id:           bson.NewObjectID(),
+			Pod:          podId,
+			Organization: m.orgId,
+			Spec:         untData.Spec,
+			SpecIndex:    1,
+			Deployments:  []bson.ObjectID{},
+		}
+
+		_, _, errData, e := unt.Parse(m.db, true)
+		if e != nil {
+			if _, ok := e.(*errortypes.ParseError); !ok {
+				err = e
+				return
+			}
+
+			m.report.Errors = append(m.report.Errors, &UnitError{
+				Unit:    untData.Name,
+				Error:   "unit_parse_error",
+				Message: e.Error(),
+			})
+			continue
+		}
+		if errData != nil {
+			m.report.Errors = append(m.report.Errors, &UnitError{
+				Unit:    untData.Name,
+				Error:   errData.Error,
+				Message: errData.Message,
+			})
+			continue
+		}
+
+		if unt.Name != untData.Name {
+			m.report.Errors = append(m.report.Errors, &UnitError{
+				Unit:    untData.Name,
+				Error:   "unit_name_mismatch",
+				Message: "Unit spec name does not match bundle unit name",
+			})
+			continue
+		}
+
+		if names.Contains(unt.Name) {
+			m.report.Errors = append(m.report.Errors, &UnitError{
+				Unit:    untData.Name,
+				Error:   "unit_name_duplicate",
+				Message: "Unit name is used by multiple units",
+			})
+			continue
+		}
+		names.Add(unt.Name)
+
+		m.report.Units = append(m.report.Units, unt.Name)
+	}
+
+	return
+}
+
+func (m *importer) order() (units []*UnitData) {
+	added := set.NewSet()
+
+	for len(units) < len(m.bndl.Pod.Units) {
+		progress := false
+
+		for _, untData := range m.bndl.Pod.Units {
+			if added.Contains(untData.Name) {
+				continue
+			}
+
+			ready := true
+			for depInf := range m.unitDeps[untData.Name].Iter() {
+				dep := depInf.(string)
+				if dep != untData.Name && !added.Contains(dep) {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				added.Add(untData.Name)
+				units = append(units, untData)
+				progress = true
+			}
+		}
+
+		if !progress {
+			for _, untData := range m.bndl.Pod.Units {
+				if !added.Contains(untData.Name) {
+					added.Add(untData.Name)
+					units = append(units, untData)
+				}
+			}
+		}
+	}
+
+	return
+}
+
+func (m *importer) rollback(pd *pod.Pod, plnIds []bson.ObjectID) {
+	if pd != nil {
+		err := pod.Remove(m.db, pd.Id)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"pod_id": pd.Id.Hex(),
+				"error":  err,
+			}).Error("bundle: Failed to remove pod on import rollback")
+		}
+	}
+
+	for _, plnId := range plnIds {
+		err := plan.Remove(m.db, plnId)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"plan_id": plnId.Hex(),
+				"error":   err,
+			}).Error("bundle: Failed to remove plan on import rollback")
+		}
+	}
+}
+
+func (m *importer) create() (errData *errortypes.ErrorData, err error) {
+	var pd *pod.Pod
+	plnIds := []bson.ObjectID{}
+
+	defer func() {
+		if err != nil || errData != nil {
+			m.rollback(pd, plnIds)
+		}
+	}()
+
+	for _, pln := range m.newPlans {
+		err = pln.Insert(m.db)
+		if err != nil {
+			return
+		}
+
+		newPln, e := plan.GetOne(m.db, &bson.M{
+			"name":         pln.Name,
+			"organization": m.orgId,
+		})
+		if e != nil {
+			err = e
+			return
+		}
+		plnIds = append(plnIds, newPln.Id)
+	}
+
+	pd = &pod.Pod{
+		Id:           bson.NewObjectID(),
+		Name:         m.podName,
+		Comment:      m.bndl.Pod.Comment,
+		Organization: m.orgId,
+	}
+
+	errData, err = pd.Validate(m.db)
+	if err != nil || errData != nil {
+		return
+	}
+
+	err = pd.Insert(m.db)
+	if err != nil {
+		return
+	}
+
+	for _, untData := range m.order() {
+		unt := &unit.Unit{
+			Id:           bson.NewObjectID(),
+			Pod:          pd.Id,
+			Organization: m.orgId,
+			Spec:         untData.Spec,
+			SpecIndex:    1,
+			Deployments:  []bson.ObjectID{},
+		}
+
+		newSpec, _, ed, e := unt.Parse(m.db, true)
+		if e != nil {
+			err = e
+			return
+		}
+		if ed != nil {
+			errData = ed
+			return
+		}
+
+		err = unt.Insert(m.db)
+		if err != nil {
+			return
+		}
+
+		if newSpec != nil {
+			err = newSpec.Insert(m.db)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	if m.bndl.Pod.DeleteProtection {
+		pd.DeleteProtection = true
+		err = pd.CommitFields(m.db, set.NewSet("delete_protection"))
+		if err != nil {
+			return
+		}
+	}
+
+	m.report.PodId = pd.Id
+
+	return
+}
+
+func Import(db *database.Database, orgId bson.ObjectID, bndl *Bundle,
+	name string, dryRun bool) (report *Report,
+	errData *errortypes.ErrorData, err error) {
+
+	if name == "" && bndl.Pod != nil {
+		name = bndl.Pod.Name
+	}
+
+	m := &importer{
+		db:        db,
+		orgId:     orgId,
+		bndl:      bndl,
+		podName:   utils.FilterName(name),
+		unitNames: set.NewSet(),
+		planNames: set.NewSet(),
+		newPlans:  []*plan.Plan{},
+		unitDeps:  map[string]set.Set{},
+		report: &Report{
+			DryRun:     dryRun,
+			Units:      []string{},
+			Plans:      []*PlanReport{},
+			Unresolved: []*Reference{},
+			Errors:     []*UnitError{},
+		},
+	}
+	report = m.report
+	report.Pod = m.podName
+
+	errData, err = m.validate()
+	if err != nil || errData != nil {
+		return
+	}
+
+	m.rename()
+
+	errData, err = m.plans()
+	if err != nil || errData != nil {
+		return
+	}
+
+	err = m.resolve()
+	if err != nil {
+		return
+	}
+
+	err = m.parse()
+	if err != nil {
+		return
+	}
+
+	if dryRun || !report.Valid() {
+		return
+	}
+
+	errData, err = m.create()
+	if err != nil || errData != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,178 @@
+package catalog
+
+import (
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+)
+
+type Release struct {
+	Name        string `bson:"name" json:"name" yaml:"name"`
+	SystemKind  string `bson:"system_kind" json:"system_kind" yaml:"systemKind"`
+	SystemType  string `bson:"system_type" json:"system_type" yaml:"systemType"`
+	Firmware    string `bson:"firmware" json:"firmware" yaml:"firmware"`
+	CloudInit   string `bson:"cloud_init" json:"cloud_init" yaml:"cloudInit"`
+	DefaultUser string `bson:"default_user" json:"default_user" yaml:"defaultUser"`
+}
+
+type Catalog struct {
+	Id        string        `bson:"_id" json:"id" yaml:"-"`
+	Version   int           `bson:"version" json:"version" yaml:"version"`
+	Storage   bson.ObjectID `bson:"storage" json:"storage" yaml:"-"`
+	Hash      string        `bson:"hash" json:"hash" yaml:"-"`
+	Timestamp time.Time     `bson:"timestamp" json:"timestamp" yaml:"-"`
+	Releases  []*Release    `bson:"releases" json:"releases" yaml:"releases"`
+}
+
+// Normalize fills the defaults derived from the system type
+func (r *Release) Normalize() {
+	if r.SystemType == "" {
+		r.SystemType = Linux
+	}
+
+	if r.Firmware == "" {
+		r.Firmware = Uefi
+	}
+
+	if r.CloudInit == "" {
+		switch r.SystemType {
+		case Bsd:
+			r.CloudInit = Bsd
+			break
+		case LinuxLegacy:
+			r.CloudInit = LinuxLegacy
+			break
+		default:
+			r.CloudInit = Linux
+		}
+	}
+
+	if r.DefaultUser == "" {
+		r.DefaultUser = DefaultUser
+	}
+}
+
+func (r *Release) Validate() (err error) {
+	r.Normalize()
+
+	if !releaseRe.MatchString(r.Name) {
+		err = &errortypes.ParseError{
+			errors.Newf("catalog: Release name '%s' invalid", r.Name),
+		}
+		return
+	}
+
+	if !kindRe.MatchString(r.SystemKind) {
+		err = &errortypes.ParseError{
+			errors.Newf("catalog: Release '%s' system kind invalid",
+				r.Name),
+		}
+		return
+	}
+
+	if !ValidSystemTypes.Contains(r.SystemType) {
+		err = &errortypes.ParseError{
+			errors.Newf("catalog: Release '%s' system type invalid",
+				r.Name),
+		}
+		return
+	}
+
+	if !ValidFirmwares.Contains(r.Firmware) {
+		err = &errortypes.ParseError{
+			errors.Newf("catalog: Release '%s' firmware invalid", r.Name),
+		}
+		return
+	}
+
+	if !ValidCloudInits.Contains(r.CloudInit) {
+		err = &errortypes.ParseError{
+			errors.Newf("catalog: Release '%s' cloud init invalid", r.Name),
+		}
+		return
+	}
+
+	if !ValidDefaultUser(r.DefaultUser) {
+		err = &errortypes.ParseError{
+			errors.Newf("catalog: Release '%s' default user invalid",
+				r.Name),
+		}
+		return
+	}
+
+	return
+}
+
+func (c *Catalog) Validate() (err error) {
+	if c.Version != 1 {
+		err = &errortypes.ParseError{
+			errors.Newf("catalog: Unsupported catalog version %d",
+				c.Version),
+		}
+		return
+	}
+
+	if len(c.Releases) == 0 {
+		err = &errortypes.ParseError{
+			errors.New("catalog: Catalog has no releases"),
+		}
+		return
+	}
+
+	names := set.NewSet()
+	for _, rel := range c.Releases {
+		if rel == nil {
+			err = &errortypes.ParseError{
+				errors.New("catalog: Catalog has empty release"),
+			}
+			return
+		}
+
+		err = rel.Validate()
+		if err != nil {
+			return
+		}
+
+		if names.Contains(rel.Name) {
+			err = &errortypes.ParseError{
+				errors.Newf("catalog: Duplicate release '%s'", rel.Name),
+			}
+			return
+		}
+		names.Add(rel.Name)
+	}
+
+	return
+}
+
+func (c *Catalog) Upsert(db *database.Database) (err error) {
+	coll := db.ImageCatalogs()
+
+	_, err = coll.UpdateOne(
+		db,
+		&bson.M{
+			"_id": c.Id,
+		},
+		&bson.M{
+			"$set": &bson.M{
+				"version":   c.Version,
+				"storage":   c.Storage,
+				"hash":      c.Hash,
+				"timestamp": c.Timestamp,
+				"releases":  c.Releases,
+			},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,51 @@
+package catalog
+
+import (
+	"regexp"
+
+	"github.com/pritunl/tools/set"
+)
+
+const (
+	Local = "local"
+
+	Linux         = "linux"
+	LinuxLegacy   = "linux_legacy"
+	LinuxUnsigned = "linux_unsigned"
+	Bsd           = "bsd"
+
+	Uefi = "uefi"
+	Bios = "bios"
+
+	DefaultUser = "cloud"
+)
+
+var (
+	ValidSystemTypes = set.NewSet(
+		Linux,
+		LinuxLegacy,
+		LinuxUnsigned,
+		Bsd,
+	)
+	ValidFirmwares = set.NewSet(
+		Uefi,
+		Bios,
+	)
+	ValidCloudInits = set.NewSet(
+		Linux,
+		LinuxLegacy,
+		Bsd,
+	)
+	BuiltinSystemKinds = set.NewSet(
+		"alpinelinux",
+		"archlinux",
+		"redhat",
+		"fedora",
+		"ubuntu",
+		"freebsd",
+	)
+
+	releaseRe = regexp.MustCompile(`^[a-z]+[0-9]*$`)
+	kindRe    = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+	userRe    = regexp.MustCompile(`^[a-z_][a-z0-9_-]*$`)
+)
@@ -0,0 +1,59 @@
+package catalog
+
+import (
+	"fmt"
+)
+
+type family struct {
+	Distro     string
+	Versions   []int
+	SystemKind string
+	SystemType string
+}
+
+func versionRange(start, end, step int) (versions []int) {
+	for v := start; v <= end; v += step {
+		versions = append(versions, v)
+	}
+	return
+}
+
+// defaultFamilies is used until a signed catalog has been synced
+var defaultFamilies = []*family{
+	{"almalinux", versionRange(8, 16, 1), "redhat", Linux},
+	{"alpinelinux", nil, "alpinelinux", LinuxUnsigned},
+	{"archlinux", nil, "archlinux", LinuxUnsigned},
+	{"fedora", versionRange(42, 62, 1), "fedora", Linux},
+	{"freebsd", nil, "freebsd", Bsd},
+	{"oraclelinux", []int{7}, "redhat", LinuxLegacy},
+	{"oraclelinux", versionRange(8, 16, 1), "redhat", Linux},
+	{"rockylinux", versionRange(8, 16, 1), "redhat", Linux},
+	{"ubuntu", versionRange(2404, 4404, 200), "ubuntu", Linux},
+}
+
+func defaultReleases() (releases []*Release) {
+	releases = []*Release{}
+
+	for _, fam := range defaultFamilies {
+		names := []string{}
+		if fam.Versions == nil {
+			names = append(names, fam.Distro)
+		} else {
+			for _, ver := range fam.Versions {
+				names = append(names, fmt.Sprintf("%s%d", fam.Distro, ver))
+			}
+		}
+
+		for _, name := range names {
+			rel := &Release{
+				Name:       name,
+				SystemKind: fam.SystemKind,
+				SystemType: fam.SystemType,
+			}
+			rel.Normalize()
+			releases = append(releases, rel)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,218 @@
+package catalog
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	current      = map[string]*Release{}
+	currentKinds = set.NewSet()
+	currentLock  = sync.RWMutex{}
+)
+
+// Parse decodes a catalog manifest, yaml is used when the name has a
+// yaml extension
+func Parse(name string, data []byte) (cat *Catalog, err error) {
+	cat = &Catalog{}
+
+	if strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml") {
+		err = yaml.Unmarshal(data, cat)
+	} else {
+		err = json.Unmarshal(data, cat)
+	}
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "catalog: Failed to parse catalog"),
+		}
+		return
+	}
+
+	err = cat.Validate()
+	if err != nil {
+		return
+	}
+
+	cat.Hash = fmt.Sprintf("%x", sha256.Sum256(data))
+	cat.Timestamp = time.Now()
+
+	return
+}
+
+func GetAll(db *database.Database) (cats []*Catalog, err error) {
+	coll := db.ImageCatalogs()
+	cats = []*Catalog{}
+
+	cursor, err := coll.Find(db, &bson.M{})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		cat := &Catalog{}
+		err = cursor.Decode(cat)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		cats = append(cats, cat)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func Remove(db *database.Database, catId string) (err error) {
+	coll := db.ImageCatalogs()
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"_id": catId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		switch err.(type) {
+		case *database.NotFoundError:
+			err = nil
+		default:
+			return
+		}
+	}
+
+	return
+}
+
+// RemoveStorages removes catalogs published by storages that no longer
+// exist
+func RemoveStorages(db *database.Database, storeIds []bson.ObjectID) (
+	err error) {
+
+	coll := db.ImageCatalogs()
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"_id": &bson.M{
+			"$ne": Local,
+		},
+		"storage": &bson.M{
+			"$nin": storeIds,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Refresh merges the stored catalogs over the default releases, the
+// local catalog takes priority over storage catalogs
+func Refresh(db *database.Database) (err error) {
+	cats, err := GetAll(db)
+	if err != nil {
+		return
+	}
+
+	sort.Slice(cats, func(i, j int) bool {
+		if cats[i].Id == Local {
+			return false
+		}
+		if cats[j].Id == Local {
+			return true
+		}
+		return cats[i].Id < cats[j].Id
+	})
+
+	releases := map[string]*Release{}
+	for _, rel := range defaultReleases() {
+		releases[rel.Name] = rel
+	}
+
+	for _, cat := range cats {
+		for _, rel := range cat.Releases {
+			rel.Normalize()
+			releases[rel.Name] = rel
+		}
+	}
+
+	kinds := set.NewSet()
+	for kind := range BuiltinSystemKinds.Iter() {
+		kinds.Add(kind)
+	}
+	for _, rel := range releases {
+		kinds.Add(rel.SystemKind)
+	}
+
+	currentLock.Lock()
+	current = releases
+	currentKinds = kinds
+	currentLock.Unlock()
+
+	return
+}
+
+func GetRelease(name string) (rel *Release) {
+	currentLock.RLock()
+	rel = current[name]
+	currentLock.RUnlock()
+	return
+}
+
+func IsRelease(name string) bool {
+	return GetRelease(name) != nil
+}
+
+func ValidSystemKind(kind string) (valid bool) {
+	currentLock.RLock()
+	valid = currentKinds.Contains(kind)
+	currentLock.RUnlock()
+	return
+}
+
+func ValidDefaultUser(user string) bool {
+	return user != "root" && userRe.MatchString(user)
+}
+
+func GetReleases() (releases []*Release) {
+	currentLock.RLock()
+	releases = make([]*Release, 0, len(current))
+	for _, rel := range current {
+		releases = append(releases, rel)
+	}
+	currentLock.RUnlock()
+
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].Name < releases[j].Name
+	})
+
+	return
+}
+
+func init() {
+	for _, rel := range defaultReleases() {
+		current[rel.Name] = rel
+		currentKinds.Add(rel.SystemKind)
+	}
+	for kind := range BuiltinSystemKinds.Iter() {
+		currentKinds.Add(kind)
+	}
+}
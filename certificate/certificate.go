@@ -13,6 +13,7 @@ import (
 	"github.com/dropbox/godropbox/errors"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/envelope"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/utils"
 	"github.com/sirupsen/logrus"
@@ -29,20 +30,20 @@ type Info struct {
 }
 
 type Certificate struct {
-	Id           bson.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name         string        `bson:"name" json:"name"`
-	Comment      string        `bson:"comment" json:"comment"`
-	Organization bson.ObjectID `bson:"organization" json:"organization"`
-	Type         string        `bson:"type" json:"type"`
-	Key          string        `bson:"key" json:"key"`
-	Certificate  string        `bson:"certificate" json:"certificate"`
-	Info         *Info         `bson:"info" json:"info"`
-	AcmeHash     string        `bson:"acme_hash" json:"-"`
-	AcmeAccount  string        `bson:"acme_account" json:"-"`
-	AcmeDomains  []string      `bson:"acme_domains" json:"acme_domains"`
-	AcmeType     string        `bson:"acme_type" json:"acme_type"`
-	AcmeAuth     string        `bson:"acme_auth" json:"acme_auth"`
-	AcmeSecret   bson.ObjectID `bson:"acme_secret" json:"acme_secret"`
+	Id           bson.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name         string          `bson:"name" json:"name"`
+	Comment      string          `bson:"comment" json:"comment"`
+	Organization bson.ObjectID   `bson:"organization" json:"organization"`
+	Type         string          `bson:"type" json:"type"`
+	Key          envelope.String `bson:"key" json:"key"`
+	Certificate  string          `bson:"certificate" json:"certificate"`
+	Info         *Info           `bson:"info" json:"info"`
+	AcmeHash     string          `bson:"acme_hash" json:"-"`
+	AcmeAccount  string          `bson:"acme_account" json:"-"`
+	AcmeDomains  []string        `bson:"acme_domains" json:"acme_domains"`
+	AcmeType     string          `bson:"acme_type" json:"acme_type"`
+	AcmeAuth     string          `bson:"acme_auth" json:"acme_auth"`
+	AcmeSecret   bson.ObjectID   `bson:"acme_secret" json:"acme_secret"`
 }
 
 type Completion struct {
@@ -61,7 +62,7 @@ func (c *Certificate) Validate(db *database.Database) (
 		c.Type = Text
 	}
 
-	c.Key = strings.TrimSpace(c.Key)
+	c.Key = envelope.String(strings.TrimSpace(string(c.Key)))
 	c.Certificate = strings.TrimSpace(c.Certificate)
 
 	if c.Type == LetsEncrypt {
@@ -17,6 +17,7 @@ import (
 	"github.com/dropbox/godropbox/errors"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/authority"
+	"github.com/pritunl/pritunl-cloud/catalog"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/datacenter"
 	"github.com/pritunl/pritunl-cloud/deployment"
@@ -25,6 +26,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/paths"
 	"github.com/pritunl/pritunl-cloud/settings"
 	"github.com/pritunl/pritunl-cloud/spec"
+	"github.com/pritunl/pritunl-cloud/sshcert"
 	"github.com/pritunl/pritunl-cloud/unit"
 	"github.com/pritunl/pritunl-cloud/utils"
 	"github.com/pritunl/pritunl-cloud/vm"
@@ -32,6 +34,11 @@ import (
 	"github.com/pritunl/pritunl-cloud/zone"
 )
 
+const (
+	sshHostKeyPath = "/etc/ssh/pritunl_host_ed25519_key"
+	sshdConfigPath = "/etc/ssh/sshd_config.d/90-pritunl-cloud.conf"
+)
+
 const metaDataTmpl = `instance-id: %s
 local-hostname: %s`
 
@@ -114,7 +121,7 @@ users:
     {{if eq .RootPasswd ""}}lock-passwd: true{{else}}lock-passwd: false
     passwd: {{.RootPasswd}}
     hashed_passwd: {{.RootPasswd}}{{end}}
-  - name: cloud
+  - name: {{.User}}
     groups: adm, video, wheel, systemd-journal
     selinux-user: staff_u
     sudo: ALL=(ALL) NOPASSWD:ALL
@@ -151,8 +158,8 @@ users:
     {{if eq .RootPasswd ""}}lock-passwd: true{{else}}lock-passwd: false
     passwd: {{.RootPasswd}}
     hashed_passwd: {{.RootPasswd}}{{end}}
-  - name: cloud
-    groups: cloud, wheel
+  - name: {{.User}}
+    groups: {{.User}}, wheel
     sudo: ALL=(ALL) NOPASSWD:ALL
     lock-passwd: {{.LockPasswd}}
     ssh-authorized-keys:
@@ -228,6 +235,7 @@ type netConfigData struct {
 
 type cloudConfigData struct {
 	Hostname   string
+	User       string
 	RootPasswd string
 	LockPasswd string
 	WriteFiles string
@@ -271,6 +279,7 @@ func getUserData(db *database.Database, inst *instance.Instance,
 	trusted := ""
 	principals := ""
 	authorizedKeys := ""
+	var hostKey *sshcert.HostKey
 	writeFiles := []*fileData{}
 	initGuestPath := utils.FilterPath(settings.Hypervisor.InitGuestPath)
 	agentGuestPath := utils.FilterPath(settings.Hypervisor.AgentGuestPath)
@@ -279,12 +288,17 @@ func getUserData(db *database.Database, inst *instance.Instance,
 	data := cloudConfigData{
 		Keys:      []string{},
 		Hostname:  strings.Replace(inst.Name, " ", "_", -1),
+		User:      inst.CloudUser,
 		Address6:  addr6.String(),
 		Gateway6:  gateway6.String(),
 		DeployRun: initGuestPath,
 		Mounts:    []cloudMount{},
 	}
 
+	if data.User == "" {
+		data.User = catalog.DefaultUser
+	}
+
 	if inst.RootEnabled {
 		data.RootPasswd, err = utils.GenerateShadow(inst.RootPasswd)
 		if err != nil {
@@ -522,9 +536,57 @@ func getUserData(db *database.Database, inst *instance.Instance,
 			trusted += authr.Certificate + "\n"
 			principals += strings.Join(authr.Principals, "\n") + "\n"
 			break
+		case authority.SshAuthority:
+			trusted += authr.Certificate + "\n"
+			principals += strings.Join(
+				authr.GetPrincipals(inst.Roles), "\n") + "\n"
+
+			if !authr.HostCertificates || hostKey != nil {
+				break
+			}
+
+			hostKey, err = authr.GetHostKey(db, inst.Id,
+				authority.HostPrincipals(inst.Name, inst.PublicIps,
+					inst.PublicIps6, inst.PrivateIps, inst.PrivateIps6))
+			if err != nil {
+				return
+			}
+			break
 		}
 	}
 
+	if hostKey != nil {
+		writeFiles = append(writeFiles, &fileData{
+			Content:     string(hostKey.PrivateKey) + "\n",
+			Owner:       owner,
+			Path:        sshHostKeyPath,
+			Permissions: "0600",
+		})
+		writeFiles = append(writeFiles, &fileData{
+			Content:     hostKey.PublicKey + "\n",
+			Owner:       owner,
+			Path:        sshHostKeyPath + ".pub",
+			Permissions: "0644",
+		})
+		writeFiles = append(writeFiles, &fileData{
+			Content:     hostKey.Certificate + "\n",
+			Owner:       owner,
+			Path:        sshHostKeyPath + "-cert.pub",
+			Permissions: "0644",
+		})
+	}
+	if hostKey != nil && virt.CloudType != instance.BSD {
+		writeFiles = append(writeFiles, &fileData{
+			Content: fmt.Sprintf(
+				"HostKey %s\nHostCertificate %s-cert.pub\n",
+				sshHostKeyPath, sshHostKeyPath,
+			),
+			Owner:       owner,
+			Path:        sshdConfigPath,
+			Permissions: "0644",
+		})
+	}
+
 	if trusted == "" {
 		trusted = "\n"
 	}
@@ -546,8 +608,8 @@ func getUserData(db *database.Database, inst *instance.Instance,
 	})
 	writeFiles = append(writeFiles, &fileData{
 		Content:     authorizedKeys,
-		Owner:       "cloud:cloud",
-		Path:        "/home/cloud/.ssh/authorized_keys",
+		Owner:       fmt.Sprintf("%s:%s", data.User, data.User),
+		Path:        fmt.Sprintf("/home/%s/.ssh/authorized_keys", data.User),
 		Permissions: "0600",
 	})
 
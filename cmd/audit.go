@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/audit"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/sirupsen/logrus"
+)
+
+func AuditVerify() (err error) {
+	db := database.GetDatabase()
+	defer db.Close()
+
+	breaks := 0
+	count, head, err := audit.Verify(db, func(brk *audit.Break) {
+		breaks += 1
+		logrus.WithFields(logrus.Fields{
+			"audit_id": brk.Id.Hex(),
+			"sequence": brk.Sequence,
+			"reason":   brk.Reason,
+		}).Error("cmd: Audit chain break")
+	})
+	if err != nil {
+		return
+	}
+
+	if breaks > 0 {
+		err = &errortypes.VerificationError{
+			errors.Newf("cmd: Audit chain verification failed "+
+				"with %d breaks in %d entries", breaks, count),
+		}
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"count": count,
+		"head":  head,
+	}).Info("cmd: Audit chain verified")
+
+	return
+}
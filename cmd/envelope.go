@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/envelope"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/sirupsen/logrus"
+)
+
+func KeyGenerate() (err error) {
+	key, err := envelope.GenerateKey()
+	if err != nil {
+		return
+	}
+
+	fmt.Println(key)
+
+	return
+}
+
+func KeyRotate() (err error) {
+	db := database.GetDatabase()
+	defer db.Close()
+
+	prov := envelope.GetProvider()
+	if prov == nil {
+		err = &errortypes.ReadError{
+			errors.New("cmd: Master key not configured"),
+		}
+		return
+	}
+
+	count, err := envelope.Rotate(db)
+	if err != nil {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"key_id": prov.KeyId(),
+		"count":  count,
+	}).Info("cmd: Database fields encrypted with current master key")
+
+	return
+}
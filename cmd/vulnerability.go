@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"flag"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/vulnerability"
+	"github.com/sirupsen/logrus"
+)
+
+func VulnerabilityImport() (err error) {
+	db := database.GetDatabase()
+	defer db.Close()
+
+	paths := flag.Args()[1:]
+	if len(paths) == 0 {
+		err = &errortypes.ParseError{
+			errors.New("cmd: Missing vulnerability import path"),
+		}
+		return
+	}
+
+	for _, pth := range paths {
+		result, e := vulnerability.ImportFile(db, pth)
+		if e != nil {
+			err = e
+			return
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"path":            pth,
+			"format":          result.Format,
+			"vulnerabilities": result.Vulnerabilities,
+			"vuxmls":          result.Vuxmls,
+		}).Info("cmd: Vulnerability data imported")
+	}
+
+	return
+}
@@ -28,6 +28,8 @@ type ConfigData struct {
 	loaded   bool   `json:"-"`
 	MongoUri string `json:"mongo_uri"`
 	NodeId   string `json:"node_id"`
+
+	MasterKeyPath string `json:"master_key_path,omitempty"`
 }
 
 func (c *ConfigData) Save() (err error) {
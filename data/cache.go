@@ -0,0 +1,170 @@
+package data
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/disk"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/image"
+	"github.com/pritunl/pritunl-cloud/imgcache"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/paths"
+	"github.com/pritunl/pritunl-cloud/storage"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
+)
+
+func imageCacheable(img *image.Image) bool {
+	return img.Type == storage.Public || img.Type == storage.Web ||
+		!img.Deployment.IsZero()
+}
+
+// imageVerifiable returns true if the image has a signature or hash from
+// the origin that a peer download can be verified against
+func imageVerifiable(store *storage.Storage, img *image.Image) bool {
+	return img.Hash != "" || img.Signed ||
+		store.Endpoint == "images.pritunl.com"
+}
+
+func getImagePeerCache(db *database.Database, store *storage.Storage,
+	dsk *disk.Disk, img *image.Image, cache *imgcache.Cache) (
+	tmpPth, hash string, err error) {
+
+	tmpPth = paths.GetImageTempPath()
+	defer func() {
+		if err != nil {
+			utils.Remove(tmpPth)
+			tmpPth = ""
+		}
+	}()
+
+	body, size, err := cache.Open(db)
+	if err != nil {
+		return
+	}
+	defer body.Close()
+
+	out, err := os.Create(tmpPth)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "data: Failed to create temporary file"),
+		}
+		return
+	}
+	defer out.Close()
+
+	prog := &Progress{
+		db:       db,
+		disk:     dsk,
+		img:      img,
+		Total:    size,
+		LastTime: time.Now(),
+	}
+
+	_, err = io.Copy(out, io.TeeReader(body, prog))
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "data: Failed to download peer image"),
+		}
+		return
+	}
+
+	err = out.Close()
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "data: Failed to write peer image"),
+		}
+		return
+	}
+
+	hash, err = utils.FileSha256(tmpPth)
+	if err != nil {
+		return
+	}
+
+	if img.Hash != "" && hash != img.Hash {
+		err = &errortypes.VerificationError{
+			errors.New("data: Peer image hash verification failed"),
+		}
+		return
+	}
+
+	err = checkImageSig(db, store, img, tmpPth)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func getImagePeer(db *database.Database, store *storage.Storage,
+	dsk *disk.Disk, img *image.Image) (tmpPth, hash string, err error) {
+
+	caches, err := imgcache.GetPeers(db, img.Id, img.Etag)
+	if err != nil {
+		return
+	}
+
+	for _, cache := range caches {
+		logrus.WithFields(logrus.Fields{
+			"image_id": img.Id.Hex(),
+			"key":      img.Key,
+			"peer_id":  cache.Node.Hex(),
+		}).Info("data: Downloading image from peer")
+
+		tmpPth, hash, err = getImagePeerCache(db, store, dsk, img, cache)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"image_id": img.Id.Hex(),
+				"key":      img.Key,
+				"peer_id":  cache.Node.Hex(),
+				"error":    err,
+			}).Warning("data: Failed to download image from peer")
+			err = nil
+			continue
+		}
+
+		return
+	}
+
+	return
+}
+
+// PrefetchImage downloads the image into the node image cache ahead of
+// instance creation
+func PrefetchImage(db *database.Database, img *image.Image) (
+	fetched bool, err error) {
+
+	if !imageCacheable(img) {
+		return
+	}
+
+	imagePth := imgcache.GetPath(img.Id, img.Etag)
+
+	exists, err := utils.Exists(imagePth)
+	if err != nil || exists {
+		return
+	}
+
+	err = utils.ExistsMkdir(node.Self.GetCachePath(), 0755)
+	if err != nil {
+		return
+	}
+
+	err = utils.ExistsMkdir(paths.GetTempPath(), 0755)
+	if err != nil {
+		return
+	}
+
+	err = getImage(db, nil, img, imagePth, true)
+	if err != nil {
+		return
+	}
+	fetched = true
+
+	return
+}
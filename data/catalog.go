@@ -0,0 +1,326 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/dropbox/godropbox/errors"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pritunl/pritunl-cloud/catalog"
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/pritunl/pritunl-cloud/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const catalogMaxSize = 4 * 1024 * 1024
+
+var catalogKeys = []string{
+	"catalog.json",
+	"catalog.yaml",
+}
+
+func checkCatalogSig(data, sig []byte) (err error) {
+	keyring, err := openpgp.ReadArmoredKeyRing(
+		strings.NewReader(constants.PritunlKeyring))
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "data: Failed to parse Pritunl keyring"),
+		}
+		return
+	}
+
+	if settings.System.ImageCatalogKeyring != "" {
+		customKeyring, e := openpgp.ReadArmoredKeyRing(
+			strings.NewReader(settings.System.ImageCatalogKeyring))
+		if e != nil {
+			err = &errortypes.ParseError{
+				errors.Wrap(e, "data: Failed to parse catalog keyring"),
+			}
+			return
+		}
+		keyring = append(keyring, customKeyring...)
+	}
+
+	entity, err := openpgp.CheckArmoredDetachedSignature(
+		keyring, bytes.NewReader(data), bytes.NewReader(sig), pgpConfig)
+	if err != nil || entity == nil {
+		err = &errortypes.VerificationError{
+			errors.Wrap(err, "data: Catalog signature verification failed"),
+		}
+		return
+	}
+
+	return
+}
+
+func getCatalogS3(store *storage.Storage) (name string, data,
+	sig []byte, err error) {
+
+	client, err := minio.New(store.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(store.AccessKey, string(store.SecretKey), ""),
+		Secure: !store.Insecure,
+	})
+	if err != nil {
+		err = &errortypes.ConnectionError{
+			errors.Wrap(err, "data: Failed to connect to storage"),
+		}
+		return
+	}
+
+	readObject := func(key string) (buf []byte, exists bool, e error) {
+		obj, e := client.GetObject(context.Background(), store.Bucket,
+			key, minio.GetObjectOptions{})
+		if e != nil {
+			e = &errortypes.ReadError{
+				errors.Wrap(e, "data: Failed to get catalog object"),
+			}
+			return
+		}
+		defer obj.Close()
+
+		buf, e = io.ReadAll(io.LimitReader(obj, catalogMaxSize))
+		if e != nil {
+			if minio.ToErrorResponse(e).Code == "NoSuchKey" {
+				e = nil
+				return
+			}
+			e = &errortypes.ReadError{
+				errors.Wrap(e, "data: Failed to read catalog object"),
+			}
+			return
+		}
+
+		exists = true
+		return
+	}
+
+	for _, key := range catalogKeys {
+		buf, exists, e := readObject(key)
+		if e != nil {
+			err = e
+			return
+		}
+		if !exists {
+			continue
+		}
+
+		sigBuf, sigExists, e := readObject(key + ".sig")
+		if e != nil {
+			err = e
+			return
+		}
+		if !sigExists {
+			err = &errortypes.VerificationError{
+				errors.Newf("data: Catalog '%s' missing signature", key),
+			}
+			return
+		}
+
+		name = key
+		data = buf
+		sig = sigBuf
+		return
+	}
+
+	return
+}
+
+func getCatalogWeb(store *storage.Storage) (name string, data,
+	sig []byte, err error) {
+
+	readFile := func(key string) (buf []byte, exists bool, e error) {
+		u := store.GetWebUrl()
+		u.Path += "/" + key
+
+		req, e := http.NewRequest("GET", u.String(), nil)
+		if e != nil {
+			e = &errortypes.RequestError{
+				errors.Wrap(e, "data: Failed to create catalog request"),
+			}
+			return
+		}
+
+		req.Header.Set("User-Agent", "pritunl-cloud")
+
+		resp, e := client.Do(req)
+		if e != nil {
+			e = &errortypes.RequestError{
+				errors.Wrap(e, "data: Catalog request error"),
+			}
+			return
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound ||
+			resp.StatusCode == http.StatusForbidden {
+
+			return
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			e = &errortypes.RequestError{
+				errors.Newf(
+					"data: Bad status %d from catalog request",
+					resp.StatusCode,
+				),
+			}
+			return
+		}
+
+		buf, e = io.ReadAll(io.LimitReader(resp.Body, catalogMaxSize))
+		if e != nil {
+			e = &errortypes.ReadError{
+				errors.Wrap(e, "data: Failed to read catalog"),
+			}
+			return
+		}
+
+		exists = true
+		return
+	}
+
+	for _, key := range catalogKeys {
+		buf, exists, e := readFile(key)
+		if e != nil {
+			err = e
+			return
+		}
+		if !exists {
+			continue
+		}
+
+		sigBuf, sigExists, e := readFile(key + ".sig")
+		if e != nil {
+			err = e
+			return
+		}
+		if !sigExists {
+			err = &errortypes.VerificationError{
+				errors.Newf("data: Catalog '%s' missing signature", key),
+			}
+			return
+		}
+
+		name = key
+		data = buf
+		sig = sigBuf
+		return
+	}
+
+	return
+}
+
+// SyncCatalog loads the signed release catalog published in a storage,
+// storages without a catalog have their previous catalog removed
+func SyncCatalog(db *database.Database, store *storage.Storage) (
+	err error) {
+
+	var name string
+	var data []byte
+	var sig []byte
+
+	if store.Type == storage.Filesystem {
+		name, data, sig, err = getCatalogFs(store)
+	} else if store.Type == storage.Web ||
+		store.Endpoint == "images.pritunl.com" {
+
+		name, data, sig, err = getCatalogWeb(store)
+	} else {
+		name, data, sig, err = getCatalogS3(store)
+	}
+	if err != nil {
+		return
+	}
+
+	if data == nil {
+		err = catalog.Remove(db, store.Id.Hex())
+		if err != nil {
+			return
+		}
+		return
+	}
+
+	err = checkCatalogSig(data, sig)
+	if err != nil {
+		return
+	}
+
+	cat, err := catalog.Parse(name, data)
+	if err != nil {
+		return
+	}
+
+	cat.Id = store.Id.Hex()
+	cat.Storage = store.Id
+
+	err = cat.Upsert(db)
+	if err != nil {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"storage_id": store.Id.Hex(),
+		"key":        name,
+		"releases":   len(cat.Releases),
+		"hash":       cat.Hash,
+	}).Info("data: Image catalog successfully validated")
+
+	return
+}
+
+// SyncCatalogLocal loads the signed release catalog from the configured
+// local path
+func SyncCatalogLocal(db *database.Database) (err error) {
+	pth := settings.System.ImageCatalogPath
+	if pth == "" {
+		return
+	}
+
+	data, err := os.ReadFile(pth)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+			return
+		}
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "data: Failed to read local catalog"),
+		}
+		return
+	}
+
+	sig, err := os.ReadFile(pth + ".sig")
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "data: Failed to read local catalog signature"),
+		}
+		return
+	}
+
+	err = checkCatalogSig(data, sig)
+	if err != nil {
+		return
+	}
+
+	cat, err := catalog.Parse(pth, data)
+	if err != nil {
+		return
+	}
+
+	cat.Id = catalog.Local
+
+	err = cat.Upsert(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,296 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/disk"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/guest"
+	"github.com/pritunl/pritunl-cloud/paths"
+	"github.com/pritunl/pritunl-cloud/qmp"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/pritunl/pritunl-cloud/vm"
+	"github.com/sirupsen/logrus"
+)
+
+// backupDisks starts drive backups of the disks of a running instance,
+// all backups are started while the guest filesystems are frozen when
+// quiesce is set. Disks not attached to the instance are skipped
+func backupDisks(virt *vm.VirtualMachine, srcs []*disk.Disk,
+	tempPths []string, quiesce bool) (devices []string, err error) {
+
+	devices = make([]string, len(srcs))
+
+	if quiesce {
+		_, e := guest.FsFreeze(virt.Id)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"instance_id": virt.Id.Hex(),
+				"error":       e,
+			}).Warning("data: Failed to freeze guest filesystems, " +
+				"clone will be crash consistent")
+			quiesce = false
+		}
+	}
+
+	for i, src := range srcs {
+		devices[i], err = qmp.StartBackupDisk(virt.Id, src, tempPths[i])
+		if err != nil {
+			if _, ok := err.(*qmp.DiskNotFound); ok {
+				err = nil
+				devices[i] = ""
+				continue
+			}
+			break
+		}
+	}
+
+	if quiesce {
+		_, e := guest.FsThaw(virt.Id)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"instance_id": virt.Id.Hex(),
+				"error":       e,
+			}).Error("data: Failed to thaw guest filesystems")
+		}
+	}
+
+	if err != nil {
+		return
+	}
+
+	for i, device := range devices {
+		if device == "" {
+			continue
+		}
+
+		err = qmp.WaitBackupDisk(virt.Id, device)
+		if err != nil {
+			return
+		}
+
+		err = utils.Chmod(tempPths[i], 0600)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// CloneDisks writes a point in time copy of each source disk to the
+// matching new disk, disks of a running instance are copied from drive
+// backups and otherwise copied directly
+func CloneDisks(db *database.Database, virt *vm.VirtualMachine,
+	srcs, dsts []*disk.Disk, quiesce bool) (err error) {
+
+	devices := make([]string, len(srcs))
+	tempPths := make([]string, len(srcs))
+	for i := range srcs {
+		tempPths[i] = paths.GetDiskTempPath()
+		defer utils.Remove(tempPths[i])
+	}
+
+	if virt != nil && virt.Running() {
+		err = utils.ExistsMkdir(paths.GetTempPath(), 0755)
+		if err != nil {
+			return
+		}
+
+		devices, err = backupDisks(virt, srcs, tempPths, quiesce)
+		if err != nil {
+			return
+		}
+	}
+
+	written := []*disk.Disk{}
+	defer func() {
+		if err == nil {
+			return
+		}
+
+		for _, dst := range written {
+			err2 := removeDiskData(db, dst)
+			if err2 != nil {
+				logrus.WithFields(logrus.Fields{
+					"disk_id": dst.Id.Hex(),
+					"error":   err2,
+				}).Error("data: Failed to remove incomplete disk clone")
+			}
+		}
+	}()
+
+	for i, src := range srcs {
+		dst := dsts[i]
+
+		logrus.WithFields(logrus.Fields{
+			"disk_id":     src.Id.Hex(),
+			"target_disk": dst.Id.Hex(),
+			"live":        devices[i] != "",
+		}).Info("data: Cloning disk")
+
+		if devices[i] != "" {
+			err = writeDiskLocal(db, tempPths[i], "qcow2", dst, nil)
+		} else {
+			err = copyDiskLocal(db, src, dst, nil)
+		}
+		if err != nil {
+			return
+		}
+
+		written = append(written, dst)
+
+		err = regenerateUuid(db, src, dst)
+		if err != nil {
+			return
+		}
+		utils.Remove(tempPths[i])
+	}
+
+	return
+}
+
+// regenerateUuid assigns new identifiers to the filesystem of a cloned
+// disk to allow attaching the clone alongside the source disk
+func regenerateUuid(db *database.Database, src, dst *disk.Disk) (
+	err error) {
+
+	dst.Uuid = ""
+	if dst.FileSystem == "" {
+		return
+	}
+
+	diskFs := ""
+	diskLvm := false
+	switch dst.FileSystem {
+	case disk.Xfs:
+		diskFs = "xfs"
+		diskLvm = false
+	case disk.LvmXfs:
+		diskFs = "xfs"
+		diskLvm = true
+	case disk.Ext4:
+		diskFs = "ext4"
+		diskLvm = false
+	case disk.LvmExt4:
+		diskFs = "ext4"
+		diskLvm = true
+	default:
+		err = &errortypes.WriteError{
+			errors.Newf("data: Invalid disk filesystem %s", dst.FileSystem),
+		}
+		return
+	}
+
+	fsPth, _, err := getDiskDevice(db, dst)
+	if err != nil {
+		return
+	}
+
+	err = ActivateDisk(db, dst)
+	if err != nil {
+		return
+	}
+	defer func() {
+		err2 := DeactivateDisk(db, dst)
+		if err2 != nil {
+			logrus.WithFields(logrus.Fields{
+				"disk_id": dst.Id.Hex(),
+				"error":   err2,
+			}).Error("data: Failed to deactivate disk")
+		}
+	}()
+
+	if dst.Type != disk.Lvm {
+		ndbPath := settings.Hypervisor.NbdPath
+
+		nbdLock.Lock()
+		defer func() {
+			utils.Exec("", "sync")
+			utils.Exec("", "qemu-nbd", "--disconnect", ndbPath)
+			nbdLock.Unlock()
+		}()
+
+		_, err = utils.ExecCombinedOutputLogged(
+			nil, "modprobe", "nbd")
+		if err != nil {
+			return
+		}
+
+		_, err = utils.ExecCombinedOutputLogged(
+			nil, "qemu-nbd", "--disconnect", ndbPath)
+		if err != nil {
+			return
+		}
+
+		time.Sleep(300 * time.Millisecond)
+
+		_, err = utils.ExecCombinedOutputLogged(
+			nil, "qemu-nbd", "--connect", ndbPath, fsPth)
+		if err != nil {
+			return
+		}
+
+		time.Sleep(1 * time.Second)
+
+		fsPth = ndbPath + "p1"
+	}
+
+	if diskLvm {
+		vgName := GetVgName(dst.Id, 0)
+		lvName := GetLvName(dst.Id, 0)
+
+		_, err = utils.ExecCombinedOutputLogged(nil,
+			"vgimportclone", "--basevgname", vgName, fsPth)
+		if err != nil {
+			return
+		}
+
+		_, err = utils.ExecCombinedOutputLogged(nil,
+			"lvrename", vgName, GetLvName(src.Id, 0), lvName)
+		if err != nil {
+			return
+		}
+
+		_, err = utils.ExecCombinedOutputLogged(nil,
+			"vgchange", "-ay", vgName)
+		if err != nil {
+			return
+		}
+		defer utils.Exec("", "vgchange", "-an", vgName)
+
+		fsPth = fmt.Sprintf("/dev/%s/%s", vgName, lvName)
+	}
+
+	if diskFs == "xfs" {
+		_, err = utils.ExecCombinedOutputLogged(nil,
+			"xfs_admin", "-U", "generate", fsPth)
+		if err != nil {
+			return
+		}
+	} else {
+		// Exit status is non zero when errors were corrected
+		_, _ = utils.ExecCombinedOutput("", "e2fsck", "-f", "-y", fsPth)
+
+		_, err = utils.ExecCombinedOutputLogged(nil,
+			"tune2fs", "-U", "random", fsPth)
+		if err != nil {
+			return
+		}
+	}
+
+	output, err := utils.ExecOutput("", "blkid", "-s", "UUID",
+		"-o", "value", fsPth)
+	if err != nil {
+		return
+	}
+
+	dst.Uuid = strings.TrimSpace(output)
+
+	return
+}
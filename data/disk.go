@@ -8,7 +8,6 @@ import (
 	"github.com/pritunl/pritunl-cloud/disk"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/lvm"
-	"github.com/pritunl/pritunl-cloud/paths"
 	"github.com/pritunl/pritunl-cloud/pool"
 	"github.com/pritunl/pritunl-cloud/utils"
 )
@@ -16,7 +15,10 @@ import (
 func createDiskQcow(db *database.Database, dsk *disk.Disk) (
 	newSize int, backingImage string, err error) {
 
-	diskPath := paths.GetDiskPath(dsk.Id)
+	diskPath, err := dsk.GetPath(db)
+	if err != nil {
+		return
+	}
 
 	if !dsk.Image.IsZero() {
 		newSize, backingImage, err = writeImageQcow(db, dsk)
@@ -57,6 +59,11 @@ func createDiskLvm(db *database.Database, dsk *disk.Disk) (
 		return
 	}
 
+	err = checkPoolCapacity(pl, dsk.Size)
+	if err != nil {
+		return
+	}
+
 	if !dsk.Image.IsZero() {
 		newSize, err = writeImageLvm(db, dsk, pl)
 		if err != nil {
@@ -68,7 +75,7 @@ func createDiskLvm(db *database.Database, dsk *disk.Disk) (
 			return
 		}
 	} else {
-		err = lvm.CreateLv(pl.VgName, dsk.Id.Hex(), dsk.Size)
+		err = createLv(pl, dsk.Id.Hex(), dsk.Size)
 		if err != nil {
 			return
 		}
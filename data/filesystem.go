@@ -0,0 +1,396 @@
+package data
+
+import (
+	"crypto/md5"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/disk"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/image"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/paths"
+	"github.com/pritunl/pritunl-cloud/storage"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
+)
+
+type fsObject struct {
+	Key          string
+	Size         int64
+	Etag         string
+	LastModified time.Time
+}
+
+func getFsEtag(info fs.FileInfo) string {
+	hash := md5.New()
+	hash.Write([]byte(fmt.Sprintf("%d-%d",
+		info.Size(), info.ModTime().UnixNano())))
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
+
+func getFsKeyPath(store *storage.Storage, key string) (
+	pth string, err error) {
+
+	err = store.Available(node.Self.Id)
+	if err != nil {
+		return
+	}
+
+	pth, err = store.GetKeyPath(node.Self.Id, key)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func statFsObject(store *storage.Storage, key string) (
+	obj *fsObject, err error) {
+
+	pth, err := getFsKeyPath(store, key)
+	if err != nil {
+		return
+	}
+
+	info, err := os.Stat(pth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "data: Failed to stat filesystem object"),
+		}
+		return
+	}
+
+	obj = &fsObject{
+		Key:          key,
+		Size:         info.Size(),
+		Etag:         getFsEtag(info),
+		LastModified: info.ModTime(),
+	}
+
+	return
+}
+
+func copyFsFile(srcPth, dstPth string, writer io.Writer) (err error) {
+	src, err := os.Open(srcPth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "data: Failed to open source file"),
+		}
+		return
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(dstPth, os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
+		0600)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "data: Failed to create destination file"),
+		}
+		return
+	}
+	defer dst.Close()
+
+	var reader io.Reader = src
+	if writer != nil {
+		reader = io.TeeReader(src, writer)
+	}
+
+	_, err = io.Copy(dst, reader)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "data: Failed to copy file"),
+		}
+		return
+	}
+
+	err = dst.Sync()
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "data: Failed to sync file"),
+		}
+		return
+	}
+
+	return
+}
+
+// putFsObject copies the file into the storage directory, the object is
+// written to a temporary name first so partial copies are never listed
+func putFsObject(store *storage.Storage, key, srcPth string) (
+	obj *fsObject, err error) {
+
+	pth, err := getFsKeyPath(store, key)
+	if err != nil {
+		return
+	}
+
+	err = utils.ExistsMkdir(filepath.Dir(pth), 0755)
+	if err != nil {
+		return
+	}
+
+	tmpPth := pth + ".partial"
+	defer utils.Remove(tmpPth)
+
+	err = copyFsFile(srcPth, tmpPth, nil)
+	if err != nil {
+		return
+	}
+
+	err = os.Rename(tmpPth, pth)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "data: Failed to move filesystem object"),
+		}
+		return
+	}
+
+	obj, err = statFsObject(store, key)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func getFsObject(store *storage.Storage, key, dstPth string,
+	writer io.Writer) (err error) {
+
+	pth, err := getFsKeyPath(store, key)
+	if err != nil {
+		return
+	}
+
+	err = copyFsFile(pth, dstPth, writer)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func removeFsObject(store *storage.Storage, key string) (err error) {
+	pth, err := getFsKeyPath(store, key)
+	if err != nil {
+		return
+	}
+
+	err = os.Remove(pth)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+		} else {
+			err = &errortypes.WriteError{
+				errors.Wrap(err, "data: Failed to remove filesystem object"),
+			}
+			return
+		}
+	}
+
+	_ = os.Remove(pth + ".sig")
+
+	return
+}
+
+func getImagesFs(db *database.Database, store *storage.Storage) (
+	images []*image.Image, err error) {
+
+	err = store.Available(node.Self.Id)
+	if err != nil {
+		return
+	}
+
+	root := store.GetPath(node.Self.Id)
+	images = []*image.Image{}
+	signedKeys := set.NewSet()
+
+	err = filepath.WalkDir(root, func(pth string, entry fs.DirEntry,
+		e error) error {
+
+		if e != nil {
+			return e
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		key, e := filepath.Rel(root, pth)
+		if e != nil {
+			return e
+		}
+		key = filepath.ToSlash(key)
+
+		if strings.HasSuffix(key, ".qcow2.sig") {
+			signedKeys.Add(strings.TrimSuffix(key, ".sig"))
+		} else if strings.HasSuffix(key, ".qcow2") {
+			info, e := entry.Info()
+			if e != nil {
+				return e
+			}
+
+			images = append(images, &image.Image{
+				Storage:      store.Id,
+				Key:          key,
+				Firmware:     image.Uefi,
+				Etag:         getFsEtag(info),
+				Type:         store.Type,
+				LastModified: info.ModTime(),
+			})
+		}
+
+		return nil
+	})
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "data: Failed to list filesystem storage"),
+		}
+		return
+	}
+
+	for _, img := range images {
+		img.Signed = signedKeys.Contains(img.Key)
+	}
+
+	return
+}
+
+func getImageFs(db *database.Database, store *storage.Storage,
+	dsk *disk.Disk, img *image.Image) (tmpPth string, err error) {
+
+	tmpPth = paths.GetImageTempPath()
+
+	logrus.WithFields(logrus.Fields{
+		"image_id":   img.Id.Hex(),
+		"storage_id": store.Id.Hex(),
+		"key":        img.Key,
+		"temp_path":  tmpPth,
+	}).Info("data: Copying filesystem image")
+
+	obj, err := statFsObject(store, img.Key)
+	if err != nil {
+		return
+	}
+
+	prog := &Progress{
+		db:       db,
+		disk:     dsk,
+		img:      img,
+		Total:    obj.Size,
+		LastTime: time.Now(),
+	}
+
+	err = getFsObject(store, img.Key, tmpPth, prog)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func checkImageSigFs(db *database.Database, store *storage.Storage,
+	img *image.Image, tmpPth string) (err error) {
+
+	sigPth, err := getFsKeyPath(store, img.Key+".sig")
+	if err != nil {
+		return
+	}
+
+	signature, err := os.Open(sigPth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "data: Failed to open image signature"),
+		}
+		return
+	}
+	defer signature.Close()
+
+	tmpImg, err := os.Open(tmpPth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "data: Failed to open image"),
+		}
+		return
+	}
+	defer tmpImg.Close()
+
+	keyring, err := openpgp.ReadArmoredKeyRing(
+		strings.NewReader(constants.PritunlKeyring))
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "data: Failed to parse Pritunl keyring"),
+		}
+		return
+	}
+
+	entity, err := openpgp.CheckArmoredDetachedSignature(
+		keyring, tmpImg, io.LimitReader(signature, 65536), pgpConfig)
+	if err != nil || entity == nil {
+		err = &errortypes.VerificationError{
+			errors.Wrap(err, "data: Image signature verification failed"),
+		}
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"id":         img.Id.Hex(),
+		"storage_id": store.Id.Hex(),
+		"key":        img.Key,
+	}).Info("data: Image signature successfully validated")
+
+	return
+}
+
+func getCatalogFs(store *storage.Storage) (name string, data,
+	sig []byte, err error) {
+
+	err = store.Available(node.Self.Id)
+	if err != nil {
+		return
+	}
+
+	for _, key := range catalogKeys {
+		pth, e := store.GetKeyPath(node.Self.Id, key)
+		if e != nil {
+			err = e
+			return
+		}
+
+		buf, e := os.ReadFile(pth)
+		if e != nil {
+			if os.IsNotExist(e) {
+				continue
+			}
+			err = &errortypes.ReadError{
+				errors.Wrap(e, "data: Failed to read catalog"),
+			}
+			return
+		}
+
+		sigBuf, e := os.ReadFile(pth + ".sig")
+		if e != nil {
+			err = &errortypes.VerificationError{
+				errors.Wrapf(e, "data: Catalog '%s' missing signature", key),
+			}
+			return
+		}
+
+		name = key
+		data = buf
+		sig = sigBuf
+		return
+	}
+
+	return
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/event"
 	"github.com/pritunl/pritunl-cloud/image"
+	"github.com/pritunl/pritunl-cloud/imgcache"
 	"github.com/pritunl/pritunl-cloud/instance"
 	"github.com/pritunl/pritunl-cloud/lock"
 	"github.com/pritunl/pritunl-cloud/lvm"
@@ -71,7 +72,7 @@ func getImageS3(db *database.Database, store *storage.Storage,
 	client, err := minio.New(store.Endpoint, &minio.Options{
 		Creds: credentials.NewStaticV4(
 			store.AccessKey,
-			store.SecretKey,
+			string(store.SecretKey),
 			"",
 		),
 		Secure: !store.Insecure,
@@ -364,7 +365,7 @@ func checkImageSigS3(db *database.Database, store *storage.Storage,
 	client, err := minio.New(store.Endpoint, &minio.Options{
 		Creds: credentials.NewStaticV4(
 			store.AccessKey,
-			store.SecretKey,
+			string(store.SecretKey),
 			"",
 		),
 		Secure: !store.Insecure,
@@ -504,8 +505,35 @@ func checkImageSigWeb(db *database.Database, store *storage.Storage,
 	return
 }
 
+func checkImageSig(db *database.Database, store *storage.Storage,
+	img *image.Image, tmpPth string) (err error) {
+
+	if !img.Signed && store.Endpoint != "images.pritunl.com" {
+		return
+	}
+
+	if img.Type == storage.Web {
+		err = checkImageSigWeb(db, store, img, tmpPth)
+		if err != nil {
+			return
+		}
+	} else if img.Type == storage.Filesystem {
+		err = checkImageSigFs(db, store, img, tmpPth)
+		if err != nil {
+			return
+		}
+	} else {
+		err = checkImageSigS3(db, store, img, tmpPth)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
 func getImage(db *database.Database, dsk *disk.Disk, img *image.Image,
-	pth string) (err error) {
+	pth string, cache bool) (err error) {
 
 	if imageLock.Locked(pth) {
 		logrus.WithFields(logrus.Fields{
@@ -539,40 +567,58 @@ func getImage(db *database.Database, dsk *disk.Disk, img *image.Image,
 		return
 	}
 
-	if img.Type == storage.Web {
-		tmpPth, err = getImageWeb(db, store, dsk, img)
-		if err != nil {
-			return
-		}
-	} else {
-		tmpPth, err = getImageS3(db, store, dsk, img)
+	hash := ""
+	peer := false
+	if cache && imageVerifiable(store, img) &&
+		!settings.Hypervisor.NoImageCachePeers {
+
+		tmpPth, hash, err = getImagePeer(db, store, dsk, img)
 		if err != nil {
-			return
+			logrus.WithFields(logrus.Fields{
+				"image_id": img.Id.Hex(),
+				"key":      img.Key,
+				"error":    err,
+			}).Warning("data: Failed to find image cache peers")
+			err = nil
 		}
+		peer = tmpPth != ""
 	}
 
-	if img.Signed || store.Endpoint == "images.pritunl.com" {
+	if !peer {
 		if img.Type == storage.Web {
-			err = checkImageSigWeb(db, store, img, tmpPth)
+			tmpPth, err = getImageWeb(db, store, dsk, img)
+			if err != nil {
+				return
+			}
+		} else if img.Type == storage.Filesystem {
+			tmpPth, err = getImageFs(db, store, dsk, img)
 			if err != nil {
 				return
 			}
 		} else {
-			err = checkImageSigS3(db, store, img, tmpPth)
+			tmpPth, err = getImageS3(db, store, dsk, img)
 			if err != nil {
 				return
 			}
 		}
 	}
 
-	hashed := false
-	if img.Hash != "" {
-		hash, e := utils.FileSha256(tmpPth)
-		if e != nil {
-			err = e
+	if !peer {
+		err = checkImageSig(db, store, img, tmpPth)
+		if err != nil {
 			return
 		}
+	}
 
+	if hash == "" && (img.Hash != "" || cache) {
+		hash, err = utils.FileSha256(tmpPth)
+		if err != nil {
+			return
+		}
+	}
+
+	hashed := false
+	if img.Hash != "" {
 		if hash != img.Hash {
 			err = &errortypes.VerificationError{
 				errors.Wrap(err, "data: Image hash verification failed"),
@@ -590,14 +636,36 @@ func getImage(db *database.Database, dsk *disk.Disk, img *image.Image,
 		"temp_path":  tmpPth,
 		"path":       pth,
 		"hashed":     hashed,
+		"peer":       peer,
 	}).Info("data: Downloaded image")
 
+	stat, err := os.Stat(tmpPth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "data: Failed to stat image"),
+		}
+		return
+	}
+
 	err = utils.Exec("", "mv", tmpPth, pth)
 	if err != nil {
 		return
 	}
 	tmpPth = ""
 
+	if cache {
+		err = imgcache.Register(db, img.Id, img.Etag, hash,
+			stat.Size())
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"image_id": img.Id.Hex(),
+				"key":      img.Key,
+				"error":    err,
+			}).Warning("data: Failed to register image cache")
+			err = nil
+		}
+	}
+
 	return
 }
 
@@ -632,7 +700,10 @@ func writeFsQcow(db *database.Database, dsk *disk.Disk) (err error) {
 		nbdLock.Unlock()
 	}()
 
-	diskPath := paths.GetDiskPath(dsk.Id)
+	diskPath, err := dsk.GetPath(db)
+	if err != nil {
+		return
+	}
 
 	err = utils.Exec("", "qemu-img", "create",
 		"-f", "qcow2", diskPath, fmt.Sprintf("%dG", dsk.Size))
@@ -795,9 +866,15 @@ func writeImageQcow(db *database.Database, dsk *disk.Disk) (
 	newSize int, backingImageName string, err error) {
 
 	size := dsk.Size
-	diskPath := paths.GetDiskPath(dsk.Id)
 	diskTempPath := paths.GetDiskTempPath()
 	disksPath := paths.GetDisksPath()
+	diskPath, err := dsk.GetPath(db)
+	if err != nil {
+		return
+	}
+	if dsk.IsShared() {
+		disksPath = path.Dir(diskPath)
+	}
 	backingPath := paths.GetBackingPath()
 
 	err = utils.ExistsMkdir(disksPath, 0755)
@@ -837,15 +914,9 @@ func writeImageQcow(db *database.Database, dsk *disk.Disk) (
 		}
 	}
 
-	if img.Type == storage.Public || img.Type == storage.Web ||
-		!img.Deployment.IsZero() {
-
+	if imageCacheable(img) {
 		cacheDir := node.Self.GetCachePath()
-
-		imagePth := path.Join(
-			cacheDir,
-			fmt.Sprintf("image-%s-%s", img.Id.Hex(), img.Etag),
-		)
+		imagePth := imgcache.GetPath(img.Id, img.Etag)
 
 		err = utils.ExistsMkdir(cacheDir, 0755)
 		if err != nil {
@@ -853,7 +924,7 @@ func writeImageQcow(db *database.Database, dsk *disk.Disk) (
 		}
 
 		if !backingImageExists {
-			err = getImage(db, dsk, img, imagePth)
+			err = getImage(db, dsk, img, imagePth, true)
 			if err != nil {
 				return
 			}
@@ -956,12 +1027,12 @@ func writeImageQcow(db *database.Database, dsk *disk.Disk) (
 		}
 	} else {
 		if dsk.Backing {
-			err = getImage(db, dsk, img, backingImagePth)
+			err = getImage(db, dsk, img, backingImagePth, false)
 			if err != nil {
 				return
 			}
 		} else {
-			err = getImage(db, dsk, img, diskTempPath)
+			err = getImage(db, dsk, img, diskTempPath, false)
 			if err != nil {
 				return
 			}
@@ -1102,7 +1173,7 @@ func writeFsLvm(db *database.Database, dsk *disk.Disk,
 		return
 	}
 
-	err = lvm.CreateLv(vgName, lvName, size)
+	err = createLv(pl, lvName, size)
 	if err != nil {
 		return
 	}
@@ -1234,26 +1305,22 @@ func writeImageLvm(db *database.Database, dsk *disk.Disk,
 
 	largeBase := strings.Contains(img.Key, "fedora")
 
-	if img.Type == storage.Public || img.Type == storage.Web ||
-		!img.Deployment.IsZero() {
-
+	if imageCacheable(img) {
 		cacheDir := node.Self.GetCachePath()
-
-		imagePth := path.Join(
-			cacheDir,
-			fmt.Sprintf("image-%s-%s", img.Id.Hex(), img.Etag),
-		)
+		imagePth := imgcache.GetPath(img.Id, img.Etag)
 
 		err = utils.ExistsMkdir(cacheDir, 0755)
 		if err != nil {
 			return
 		}
 
-		err = getImage(db, dsk, img, imagePth)
+		err = getImage(db, dsk, img, imagePth, true)
 		if err != nil {
 			return
 		}
 
+		utils.Exec("", "touch", imagePth)
+
 		sourcePth = imagePth
 
 		if largeBase && size < 16 {
@@ -1264,7 +1331,7 @@ func writeImageLvm(db *database.Database, dsk *disk.Disk,
 			newSize = 10
 		}
 	} else {
-		err = getImage(db, dsk, img, diskTempPath)
+		err = getImage(db, dsk, img, diskTempPath, false)
 		if err != nil {
 			return
 		}
@@ -1297,7 +1364,16 @@ func writeImageLvm(db *database.Database, dsk *disk.Disk,
 		}
 	}()
 
-	err = lvm.CreateLv(vgName, lvName, size)
+	if pl.IsThin() {
+		err = writeImageThin(db, pl, img, lvName, sourcePth, size)
+		if err != nil {
+			return
+		}
+
+		return
+	}
+
+	err = createLv(pl, lvName, size)
 	if err != nil {
 		return
 	}
@@ -1322,25 +1398,85 @@ func writeImageLvm(db *database.Database, dsk *disk.Disk,
 	return
 }
 
-func DeleteImage(db *database.Database, imgId bson.ObjectID) (
-	err error) {
+// uploadImage writes the image file to the storage and updates the
+// image object metadata
+func uploadImage(store *storage.Storage, img *image.Image, pth,
+	putClass, imgClass string) (err error) {
 
-	img, err := image.Get(db, imgId)
+	if store.Type == storage.Filesystem {
+		obj, e := putFsObject(store, img.Key, pth)
+		if e != nil {
+			err = e
+			return
+		}
+
+		img.Etag = obj.Etag
+		img.LastModified = obj.LastModified
+		img.StorageClass = ""
+		return
+	}
+
+	client, err := minio.New(store.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(store.AccessKey, string(store.SecretKey), ""),
+		Secure: !store.Insecure,
+	})
 	if err != nil {
+		err = &errortypes.ConnectionError{
+			errors.Wrap(err, "data: Failed to connect to storage"),
+		}
 		return
 	}
 
-	if img.Type == storage.Public || img.Type == storage.Web {
+	putOpts := minio.PutObjectOptions{}
+	storageClass := storage.FormatStorageClass(putClass)
+	if storageClass != "" {
+		putOpts.StorageClass = storageClass
+	}
+
+	_, err = client.FPutObject(context.Background(),
+		store.Bucket, img.Key, pth, putOpts)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "data: Failed to write object"),
+		}
+
 		return
 	}
 
-	store, err := storage.Get(db, img.Storage)
+	time.Sleep(3 * time.Second)
+
+	obj, err := client.StatObject(context.Background(),
+		store.Bucket, img.Key, minio.StatObjectOptions{})
 	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "data: Failed to stat object"),
+		}
+		return
+	}
+
+	img.Etag = image.GetEtag(obj)
+	img.LastModified = obj.LastModified
+
+	if store.IsOracle() {
+		img.StorageClass = storage.ParseStorageClass(obj)
+	} else {
+		img.StorageClass = imgClass
+	}
+
+	return
+}
+
+func removeObject(store *storage.Storage, key string) (err error) {
+	if store.Type == storage.Filesystem {
+		err = removeFsObject(store, key)
+		if err != nil {
+			return
+		}
 		return
 	}
 
 	client, err := minio.New(store.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(store.AccessKey, store.SecretKey, ""),
+		Creds:  credentials.NewStaticV4(store.AccessKey, string(store.SecretKey), ""),
 		Secure: !store.Insecure,
 	})
 	if err != nil {
@@ -1351,7 +1487,32 @@ func DeleteImage(db *database.Database, imgId bson.ObjectID) (
 	}
 
 	err = client.RemoveObject(context.Background(),
-		store.Bucket, img.Key, minio.RemoveObjectOptions{})
+		store.Bucket, key, minio.RemoveObjectOptions{})
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func DeleteImage(db *database.Database, imgId bson.ObjectID) (
+	err error) {
+
+	img, err := image.Get(db, imgId)
+	if err != nil {
+		return
+	}
+
+	if img.Type == storage.Public || img.Type == storage.Web {
+		return
+	}
+
+	store, err := storage.Get(db, img.Storage)
+	if err != nil {
+		return
+	}
+
+	err = removeObject(store, img.Key)
 	if err != nil {
 		return
 	}
@@ -1431,19 +1592,7 @@ func DeleteImageOrg(db *database.Database, orgId, imgId bson.ObjectID) (
 		return
 	}
 
-	client, err := minio.New(store.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(store.AccessKey, store.SecretKey, ""),
-		Secure: !store.Insecure,
-	})
-	if err != nil {
-		err = &errortypes.ConnectionError{
-			errors.Wrap(err, "data: Failed to connect to storage"),
-		}
-		return
-	}
-
-	err = client.RemoveObject(context.Background(),
-		store.Bucket, img.Key, minio.RemoveObjectOptions{})
+	err = removeObject(store, img.Key)
 	if err != nil {
 		return
 	}
@@ -1472,15 +1621,14 @@ func DeleteImagesOrg(db *database.Database, orgId bson.ObjectID,
 func CreateSnapshot(db *database.Database, dsk *disk.Disk,
 	virt *vm.VirtualMachine) (err error) {
 
-	dskPth := paths.GetDiskPath(dsk.Id)
 	cacheDir := node.Self.GetCachePath()
 
-	nde, err := node.Get(db, dsk.Node)
+	dskPth, err := dsk.GetPath(db)
 	if err != nil {
 		return
 	}
 
-	zne, err := zone.Get(db, nde.Zone)
+	zne, err := zone.Get(db, dsk.Zone)
 	if err != nil {
 		return
 	}
@@ -1508,7 +1656,7 @@ func CreateSnapshot(db *database.Database, dsk *disk.Disk,
 		return
 	}
 
-	if store.Type != storage.Private {
+	if store.Type != storage.Private && store.Type != storage.Filesystem {
 		err = &errortypes.ConnectionError{
 			errors.New("data: Cannot upload to non-private storage"),
 		}
@@ -1535,7 +1683,7 @@ func CreateSnapshot(db *database.Database, dsk *disk.Disk,
 			time.Now().Format("20060102-150405")),
 		Organization: dsk.Organization,
 		Deployment:   dsk.Deployment,
-		Type:         storage.Private,
+		Type:         store.Type,
 		SystemType:   dsk.SystemType,
 		SystemKind:   dsk.SystemKind,
 		Firmware:     image.Unknown,
@@ -1584,54 +1732,14 @@ func CreateSnapshot(db *database.Database, dsk *disk.Disk,
 		"hash":       hash,
 	}).Info("data: Uploading disk snapshot")
 
-	client, err := minio.New(store.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(store.AccessKey, store.SecretKey, ""),
-		Secure: !store.Insecure,
-	})
-	if err != nil {
-		err = &errortypes.ConnectionError{
-			errors.Wrap(err, "data: Failed to connect to storage"),
-		}
-		return
-	}
-
-	putOpts := minio.PutObjectOptions{}
-	storageClass := storage.FormatStorageClass(dc.PrivateStorageClass)
-	if storageClass != "" {
-		putOpts.StorageClass = storageClass
-	}
+	img.Hash = hash
 
-	_, err = client.FPutObject(context.Background(),
-		store.Bucket, img.Key, tmpPath, putOpts)
+	err = uploadImage(store, img, tmpPath,
+		dc.PrivateStorageClass, dc.BackupStorageClass)
 	if err != nil {
-		err = &errortypes.WriteError{
-			errors.Wrap(err, "data: Failed to write object"),
-		}
-
 		return
 	}
 
-	time.Sleep(3 * time.Second)
-
-	obj, err := client.StatObject(context.Background(),
-		store.Bucket, img.Key, minio.StatObjectOptions{})
-	if err != nil {
-		err = &errortypes.ReadError{
-			errors.Wrap(err, "data: Failed to stat object"),
-		}
-		return
-	}
-
-	img.Hash = hash
-	img.Etag = image.GetEtag(obj)
-	img.LastModified = obj.LastModified
-
-	if store.IsOracle() {
-		img.StorageClass = storage.ParseStorageClass(obj)
-	} else {
-		img.StorageClass = dc.BackupStorageClass
-	}
-
 	err = img.Upsert(db)
 	if err != nil {
 		return
@@ -1673,15 +1781,14 @@ func CreateSnapshot(db *database.Database, dsk *disk.Disk,
 func CreateBackup(db *database.Database, dsk *disk.Disk,
 	virt *vm.VirtualMachine) (err error) {
 
-	dskPth := paths.GetDiskPath(dsk.Id)
 	cacheDir := node.Self.GetCachePath()
 
-	nde, err := node.Get(db, dsk.Node)
+	dskPth, err := dsk.GetPath(db)
 	if err != nil {
 		return
 	}
 
-	zne, err := zone.Get(db, nde.Zone)
+	zne, err := zone.Get(db, dsk.Zone)
 	if err != nil {
 		return
 	}
@@ -1716,7 +1823,7 @@ func CreateBackup(db *database.Database, dsk *disk.Disk,
 		return
 	}
 
-	if store.Type != storage.Private {
+	if store.Type != storage.Private && store.Type != storage.Filesystem {
 		err = &errortypes.ConnectionError{
 			errors.New("data: Cannot upload to non-private storage"),
 		}
@@ -1743,7 +1850,7 @@ func CreateBackup(db *database.Database, dsk *disk.Disk,
 		Name: fmt.Sprintf("%s-%s", dsk.Name,
 			time.Now().Format("20060102-150405")),
 		Organization: dsk.Organization,
-		Type:         storage.Private,
+		Type:         store.Type,
 		SystemType:   dsk.SystemType,
 		SystemKind:   dsk.SystemKind,
 		Firmware:     image.Unknown,
@@ -1792,54 +1899,14 @@ func CreateBackup(db *database.Database, dsk *disk.Disk,
 		"hash":       hash,
 	}).Info("data: Uploading disk backup")
 
-	client, err := minio.New(store.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(store.AccessKey, store.SecretKey, ""),
-		Secure: !store.Insecure,
-	})
-	if err != nil {
-		err = &errortypes.ConnectionError{
-			errors.Wrap(err, "data: Failed to connect to storage"),
-		}
-		return
-	}
-
-	putOpts := minio.PutObjectOptions{}
-	storageClass := storage.FormatStorageClass(dc.BackupStorageClass)
-	if storageClass != "" {
-		putOpts.StorageClass = storageClass
-	}
-
-	_, err = client.FPutObject(context.Background(),
-		store.Bucket, img.Key, tmpPath, putOpts)
-	if err != nil {
-		err = &errortypes.WriteError{
-			errors.Wrap(err, "data: Failed to write object"),
-		}
-
-		return
-	}
-
-	time.Sleep(3 * time.Second)
+	img.Hash = hash
 
-	obj, err := client.StatObject(context.Background(),
-		store.Bucket, img.Key, minio.StatObjectOptions{})
+	err = uploadImage(store, img, tmpPath,
+		dc.BackupStorageClass, dc.BackupStorageClass)
 	if err != nil {
-		err = &errortypes.ReadError{
-			errors.Wrap(err, "data: Failed to stat object"),
-		}
 		return
 	}
 
-	img.Hash = hash
-	img.Etag = image.GetEtag(obj)
-	img.LastModified = obj.LastModified
-
-	if store.IsOracle() {
-		img.StorageClass = storage.ParseStorageClass(obj)
-	} else {
-		img.StorageClass = dc.BackupStorageClass
-	}
-
 	err = img.Upsert(db)
 	if err != nil {
 		return
@@ -1851,9 +1918,13 @@ func CreateBackup(db *database.Database, dsk *disk.Disk,
 }
 
 func RestoreBackup(db *database.Database, dsk *disk.Disk) (err error) {
-	dskPth := paths.GetDiskPath(dsk.Id)
 	cacheDir := node.Self.GetCachePath()
 
+	dskPth, err := dsk.GetPath(db)
+	if err != nil {
+		return
+	}
+
 	img, err := image.Get(db, dsk.RestoreImage)
 	if err != nil {
 		return
@@ -1871,7 +1942,7 @@ func RestoreBackup(db *database.Database, dsk *disk.Disk) (err error) {
 		return
 	}
 
-	if store.Type != storage.Private {
+	if store.Type != storage.Private && store.Type != storage.Filesystem {
 		err = &errortypes.ConnectionError{
 			errors.New("data: Cannot restore from non-private storage"),
 		}
@@ -1890,29 +1961,37 @@ func RestoreBackup(db *database.Database, dsk *disk.Disk) (err error) {
 		return
 	}
 
-	client, err := minio.New(store.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(store.AccessKey, store.SecretKey, ""),
-		Secure: !store.Insecure,
-	})
-	if err != nil {
-		err = &errortypes.ConnectionError{
-			errors.Wrap(err, "data: Failed to connect to storage"),
-		}
-		return
-	}
-
 	imgId := bson.NewObjectID()
 	tmpPath := path.Join(cacheDir,
 		fmt.Sprintf("restore-%s", imgId.Hex()))
 
 	defer utils.Remove(tmpPath)
-	err = client.FGetObject(context.Background(), store.Bucket,
-		img.Key, tmpPath, minio.GetObjectOptions{})
-	if err != nil {
-		err = &errortypes.ReadError{
-			errors.Wrap(err, "data: Failed to download restore image"),
+
+	if store.Type == storage.Filesystem {
+		err = getFsObject(store, img.Key, tmpPath, nil)
+		if err != nil {
+			return
+		}
+	} else {
+		client, e := minio.New(store.Endpoint, &minio.Options{
+			Creds:  credentials.NewStaticV4(store.AccessKey, string(store.SecretKey), ""),
+			Secure: !store.Insecure,
+		})
+		if e != nil {
+			err = &errortypes.ConnectionError{
+				errors.Wrap(e, "data: Failed to connect to storage"),
+			}
+			return
+		}
+
+		err = client.FGetObject(context.Background(), store.Bucket,
+			img.Key, tmpPath, minio.GetObjectOptions{})
+		if err != nil {
+			err = &errortypes.ReadError{
+				errors.Wrap(err, "data: Failed to download restore image"),
+			}
+			return
 		}
-		return
 	}
 
 	err = utils.Chmod(tmpPath, 0600)
@@ -1958,7 +2037,7 @@ func RestoreBackup(db *database.Database, dsk *disk.Disk) (err error) {
 func ImageAvailable(store *storage.Storage, img *image.Image) (
 	available bool, err error) {
 
-	if img.Type == storage.Web {
+	if img.Type == storage.Web || img.Type == storage.Filesystem {
 		available = true
 		return
 	}
@@ -1966,7 +2045,7 @@ func ImageAvailable(store *storage.Storage, img *image.Image) (
 	if strings.Contains(strings.ToLower(store.Endpoint), "oracle") {
 		client, e := minio.New(store.Endpoint, &minio.Options{
 			Creds: credentials.NewStaticV4(store.AccessKey,
-				store.SecretKey, ""),
+				string(store.SecretKey), ""),
 			Secure: !store.Insecure,
 		})
 		if e != nil {
@@ -2005,7 +2084,7 @@ func ImageAvailable(store *storage.Storage, img *image.Image) (
 	case storage.AwsGlacier:
 		client, e := minio.New(store.Endpoint, &minio.Options{
 			Creds: credentials.NewStaticV4(store.AccessKey,
-				store.SecretKey, ""),
+				string(store.SecretKey), ""),
 			Secure: !store.Insecure,
 		})
 		if e != nil {
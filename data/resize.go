@@ -10,7 +10,6 @@ import (
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/lock"
 	"github.com/pritunl/pritunl-cloud/lvm"
-	"github.com/pritunl/pritunl-cloud/paths"
 	"github.com/pritunl/pritunl-cloud/pool"
 	"github.com/pritunl/pritunl-cloud/utils"
 	"github.com/sirupsen/logrus"
@@ -45,12 +44,22 @@ func getQcowSize(pth string) (size int, err error) {
 	return
 }
 
-func getDiskSizeQcow(dsk *disk.Disk) (size int, err error) {
-	return getQcowSize(paths.GetDiskPath(dsk.Id))
+func getDiskSizeQcow(db *database.Database, dsk *disk.Disk) (
+	size int, err error) {
+
+	dskPth, err := dsk.GetPath(db)
+	if err != nil {
+		return
+	}
+
+	return getQcowSize(dskPth)
 }
 
 func expandDiskQcow(db *database.Database, dsk *disk.Disk) (err error) {
-	dskPth := paths.GetDiskPath(dsk.Id)
+	dskPth, err := dsk.GetPath(db)
+	if err != nil {
+		return
+	}
 
 	logrus.WithFields(logrus.Fields{
 		"disk_id":   dsk.Id.Hex(),
@@ -58,7 +67,7 @@ func expandDiskQcow(db *database.Database, dsk *disk.Disk) (err error) {
 		"new_size":  dsk.NewSize,
 	}).Info("data: Expanding qcow disk")
 
-	curSize, err := getDiskSizeQcow(dsk)
+	curSize, err := getDiskSizeQcow(db, dsk)
 	if err != nil {
 		return
 	}
@@ -82,7 +91,7 @@ func expandDiskQcow(db *database.Database, dsk *disk.Disk) (err error) {
 		return
 	}
 
-	curSize, err = getDiskSizeQcow(dsk)
+	curSize, err = getDiskSizeQcow(db, dsk)
 	if err != nil {
 		return
 	}
@@ -158,6 +167,12 @@ func expandDiskLvm(db *database.Database, dsk *disk.Disk) (err error) {
 	}()
 
 	expandSize := dsk.NewSize - curSize
+
+	err = checkPoolCapacity(pl, expandSize)
+	if err != nil {
+		return
+	}
+
 	err = lvm.ExtendLv(vgName, lvName, expandSize)
 	if err != nil {
 		return
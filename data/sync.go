@@ -12,6 +12,7 @@ import (
 	"github.com/dropbox/godropbox/errors"
 	minio "github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pritunl/pritunl-cloud/catalog"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/image"
@@ -44,7 +45,7 @@ func getImagesS3(db *database.Database, store *storage.Storage) (
 	images []*image.Image, err error) {
 
 	client, err := minio.New(store.Endpoint, &minio.Options{
-		Creds:  credentials.NewStaticV4(store.AccessKey, store.SecretKey, ""),
+		Creds:  credentials.NewStaticV4(store.AccessKey, string(store.SecretKey), ""),
 		Secure: !store.Insecure,
 	})
 	if err != nil {
@@ -188,16 +189,37 @@ func getImagesWeb(db *database.Database, store *storage.Storage) (
 }
 
 func Sync(db *database.Database, store *storage.Storage) (err error) {
-	if store.Endpoint == "" {
+	if store.Endpoint == "" && store.Type != storage.Filesystem {
 		return
 	}
 
 	lockId := syncLock.Lock(store.Id.Hex())
 	defer syncLock.Unlock(store.Id.Hex(), lockId)
 
+	err = SyncCatalog(db, store)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"storage_id": store.Id.Hex(),
+			"error":      err,
+		}).Error("data: Failed to sync image catalog")
+		err = nil
+	}
+
+	err = catalog.Refresh(db)
+	if err != nil {
+		return
+	}
+
 	var images []*image.Image
 
-	if store.Type == storage.Web || store.Endpoint == "images.pritunl.com" {
+	if store.Type == storage.Filesystem {
+		images, err = getImagesFs(db, store)
+		if err != nil {
+			return
+		}
+	} else if store.Type == storage.Web ||
+		store.Endpoint == "images.pritunl.com" {
+
 		images, err = getImagesWeb(db, store)
 		if err != nil {
 			return
@@ -214,11 +236,23 @@ func Sync(db *database.Database, store *storage.Storage) (err error) {
 		remoteKeys.Add(img.Key)
 
 		if img.Signed {
+			img.Parse()
+			rel := catalog.GetRelease(img.Release)
+
 			if strings.Contains(img.Key, "_bios") {
 				img.Firmware = image.Bios
+			} else if rel != nil {
+				img.Firmware = rel.Firmware
 			} else {
 				img.Firmware = image.Uefi
 			}
+
+			if rel != nil {
+				img.SystemType = rel.SystemType
+				img.SystemKind = rel.SystemKind
+				img.CloudInit = rel.CloudInit
+				img.DefaultUser = rel.DefaultUser
+			}
 		}
 
 		err = img.Sync(db)
@@ -0,0 +1,175 @@
+package data
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/image"
+	"github.com/pritunl/pritunl-cloud/lock"
+	"github.com/pritunl/pritunl-cloud/lvm"
+	"github.com/pritunl/pritunl-cloud/pool"
+	"github.com/sirupsen/logrus"
+)
+
+func getThinImagePrefix(img *image.Image) string {
+	return fmt.Sprintf("image_%s_", img.Id.Hex())
+}
+
+func getThinImageName(img *image.Image) string {
+	hash := sha256.Sum256([]byte(img.Etag))
+	return fmt.Sprintf("%s%x", getThinImagePrefix(img), hash[:6])
+}
+
+func createLv(pl *pool.Pool, lvName string, size int) (err error) {
+	if pl.IsThin() {
+		err = lvm.CreateThinLv(pl.VgName, pl.ThinPool, lvName, size)
+	} else {
+		err = lvm.CreateLv(pl.VgName, lvName, size)
+	}
+	return
+}
+
+func checkPoolCapacity(pl *pool.Pool, size int) (err error) {
+	stats, err := lvm.GetStats(pl)
+	if err != nil {
+		return
+	}
+	pl.Stats = stats
+
+	if size > pl.Free() {
+		err = &errortypes.WriteError{
+			errors.Newf("data: Pool %s capacity exceeded, %dG free",
+				pl.Name, pl.Free()),
+		}
+		return
+	}
+
+	return
+}
+
+// createThinImage writes the image to a base volume in the thin pool
+// which disks are then cloned from with thin snapshots
+func createThinImage(db *database.Database, pl *pool.Pool,
+	img *image.Image, baseName, sourcePth string) (err error) {
+
+	vgName := pl.VgName
+
+	acquired, err := lock.LvmLock(db, vgName, baseName)
+	if err != nil {
+		return
+	}
+
+	if !acquired {
+		err = &errortypes.WriteError{
+			errors.New("data: Failed to acquire LVM lock"),
+		}
+		return
+	}
+	defer func() {
+		err2 := lock.LvmUnlock(db, vgName, baseName)
+		if err2 != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err2,
+			}).Error("data: Failed to unlock lvm")
+		}
+	}()
+
+	exists, err := lvm.HasLv(vgName, baseName)
+	if err != nil {
+		return
+	}
+
+	if exists {
+		return
+	}
+
+	imgSize, err := getQcowSize(sourcePth)
+	if err != nil {
+		return
+	}
+	imgSize += 1
+
+	logrus.WithFields(logrus.Fields{
+		"image_id":  img.Id.Hex(),
+		"vg_name":   vgName,
+		"thin_pool": pl.ThinPool,
+		"lv_name":   baseName,
+	}).Info("data: Creating thin image volume")
+
+	err = lvm.CreateThinLv(vgName, pl.ThinPool, baseName, imgSize)
+	if err != nil {
+		return
+	}
+
+	err = lvm.ActivateLv(vgName, baseName)
+	if err == nil {
+		err = lvm.WriteThinLv(vgName, baseName, sourcePth)
+		err2 := lvm.DeactivateLv(vgName, baseName)
+		if err == nil {
+			err = err2
+		}
+	}
+	if err != nil {
+		_ = lvm.RemoveLv(vgName, baseName)
+		return
+	}
+
+	names, err := lvm.GetLvNames(vgName, getThinImagePrefix(img))
+	if err != nil {
+		return
+	}
+
+	for _, name := range names {
+		if name == baseName {
+			continue
+		}
+
+		err = lvm.RemoveLv(vgName, name)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"vg_name": vgName,
+				"lv_name": name,
+				"error":   err,
+			}).Error("data: Failed to remove old thin image volume")
+			err = nil
+		}
+	}
+
+	return
+}
+
+// writeImageThin clones the disk from the thin image volume and extends
+// the clone to the disk size
+func writeImageThin(db *database.Database, pl *pool.Pool,
+	img *image.Image, lvName, sourcePth string, size int) (err error) {
+
+	vgName := pl.VgName
+	baseName := getThinImageName(img)
+
+	err = createThinImage(db, pl, img, baseName, sourcePth)
+	if err != nil {
+		return
+	}
+
+	baseSize, err := lvm.GetSizeLv(vgName, baseName)
+	if err != nil {
+		return
+	}
+
+	err = lvm.SnapshotThinLv(vgName, baseName, lvName)
+	if err != nil {
+		return
+	}
+
+	if size > baseSize {
+		err = lvm.ExtendLv(vgName, lvName, size-baseSize)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
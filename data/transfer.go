@@ -0,0 +1,505 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/disk"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/lock"
+	"github.com/pritunl/pritunl-cloud/lvm"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/paths"
+	"github.com/pritunl/pritunl-cloud/pool"
+	"github.com/pritunl/pritunl-cloud/transfer"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
+)
+
+var convertProgressRe = regexp.MustCompile(`\(([0-9.]+)/100%\)`)
+
+func splitProgress(data []byte, atEOF bool) (
+	advance int, token []byte, err error) {
+
+	if atEOF && len(data) == 0 {
+		return
+	}
+
+	i := bytes.IndexAny(data, "\r\n")
+	if i >= 0 {
+		return i + 1, data[:i], nil
+	}
+
+	if atEOF {
+		return len(data), data, nil
+	}
+
+	return
+}
+
+// convertDisk copies the disk with qemu-img reporting the progress
+// percent parsed from the qemu-img output
+func convertDisk(srcPth, srcFmt, dstPth, dstFmt string, existing bool,
+	progress func(percent int)) (err error) {
+
+	args := []string{"convert", "-p", "-f", srcFmt, "-O", dstFmt}
+	if existing {
+		args = append(args, "-n")
+	}
+	args = append(args, srcPth, dstPth)
+
+	cmd := exec.Command("qemu-img", args...)
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		err = &errortypes.ExecError{
+			errors.Wrap(err, "data: Failed to open qemu-img output"),
+		}
+		return
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		err = &errortypes.ExecError{
+			errors.Wrap(err, "data: Failed to start qemu-img convert"),
+		}
+		return
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Split(splitProgress)
+	for scanner.Scan() {
+		match := convertProgressRe.FindStringSubmatch(scanner.Text())
+		if match == nil || progress == nil {
+			continue
+		}
+
+		percent, e := strconv.ParseFloat(match[1], 64)
+		if e == nil {
+			progress(int(percent))
+		}
+	}
+
+	err = cmd.Wait()
+	if err != nil {
+		err = &errortypes.ExecError{
+			errors.Wrapf(err, "data: Failed to convert disk '%s'",
+				stderr.String()),
+		}
+		return
+	}
+
+	return
+}
+
+func getDiskDevice(db *database.Database, dsk *disk.Disk) (
+	pth, format string, err error) {
+
+	if dsk.Type == disk.Lvm {
+		pl, e := pool.Get(db, dsk.Pool)
+		if e != nil {
+			err = e
+			return
+		}
+
+		pth = filepath.Join("/dev/mapper",
+			fmt.Sprintf("%s-%s", pl.VgName, dsk.Id.Hex()))
+		format = "raw"
+		return
+	}
+
+	pth, err = dsk.GetPath(db)
+	if err != nil {
+		return
+	}
+	format = "qcow2"
+
+	return
+}
+
+func removeDiskData(db *database.Database, dsk *disk.Disk) (err error) {
+	if dsk.Type != disk.Lvm {
+		pth, e := dsk.GetPath(db)
+		if e != nil {
+			err = e
+			return
+		}
+
+		err = utils.RemoveAll(pth)
+		if err != nil {
+			return
+		}
+
+		return
+	}
+
+	pl, err := pool.Get(db, dsk.Pool)
+	if err != nil {
+		return
+	}
+
+	err = lvm.RemoveLv(pl.VgName, dsk.Id.Hex())
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func getTransferTarget(db *database.Database, dsk *disk.Disk) (
+	dst *disk.Disk, err error) {
+
+	dst = &disk.Disk{}
+	*dst = *dsk
+
+	dst.Id = dsk.TargetDisk
+	dst.Backing = false
+	dst.BackingImage = ""
+	dst.Action = ""
+	dst.TargetNode = bson.NilObjectID
+	dst.TargetPool = bson.NilObjectID
+	dst.TargetDisk = bson.NilObjectID
+	dst.TransferToken = ""
+	dst.Progress = 0
+
+	if !dsk.TargetPool.IsZero() {
+		pl, e := pool.Get(db, dsk.TargetPool)
+		if e != nil {
+			err = e
+			return
+		}
+
+		dst.Node = bson.NilObjectID
+		dst.Pool = pl.Id
+		dst.Zone = pl.Zone
+		dst.Datacenter = pl.Datacenter
+		if pl.IsSharedFs() {
+			dst.Type = disk.Qcow2
+		} else {
+			dst.Type = disk.Lvm
+		}
+	} else {
+		nde, e := node.Get(db, dsk.TargetNode)
+		if e != nil {
+			err = e
+			return
+		}
+
+		dst.Node = nde.Id
+		dst.Pool = bson.NilObjectID
+		dst.Zone = nde.Zone
+		dst.Datacenter = nde.Datacenter
+		dst.Type = disk.Qcow2
+	}
+
+	if dsk.Action == disk.Clone {
+		dst.Name = dsk.Name + "-clone"
+		dst.Instance = bson.NilObjectID
+		dst.SourceInstance = bson.NilObjectID
+		dst.Deployment = bson.NilObjectID
+		dst.DeleteProtection = false
+		dst.Index = ""
+		dst.Backup = false
+		dst.LastBackup = time.Time{}
+	}
+
+	return
+}
+
+func copyDiskLocal(db *database.Database, src, dst *disk.Disk,
+	progress func(percent int)) (err error) {
+
+	srcPth, srcFmt, err := getDiskDevice(db, src)
+	if err != nil {
+		return
+	}
+
+	err = ActivateDisk(db, src)
+	if err != nil {
+		return
+	}
+	defer func() {
+		err2 := DeactivateDisk(db, src)
+		if err2 != nil {
+			logrus.WithFields(logrus.Fields{
+				"disk_id": src.Id.Hex(),
+				"error":   err2,
+			}).Error("data: Failed to deactivate disk")
+		}
+	}()
+
+	err = writeDiskLocal(db, srcPth, srcFmt, dst, progress)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// writeDiskLocal creates the storage for the disk and writes the source
+// image to it, the copy is compared to the source before returning
+func writeDiskLocal(db *database.Database, srcPth, srcFmt string,
+	dst *disk.Disk, progress func(percent int)) (err error) {
+
+	dstPth, dstFmt, err := getDiskDevice(db, dst)
+	if err != nil {
+		return
+	}
+
+	if dst.Type == disk.Lvm {
+		pl, e := pool.Get(db, dst.Pool)
+		if e != nil {
+			err = e
+			return
+		}
+
+		acquired, e := lock.LvmLock(db, pl.VgName, dst.Id.Hex())
+		if e != nil {
+			err = e
+			return
+		}
+
+		if !acquired {
+			err = &errortypes.WriteError{
+				errors.New("data: Failed to acquire LVM lock"),
+			}
+			return
+		}
+		defer func() {
+			err2 := lock.LvmUnlock(db, pl.VgName, dst.Id.Hex())
+			if err2 != nil {
+				logrus.WithFields(logrus.Fields{
+					"error": err2,
+				}).Error("data: Failed to unlock lvm")
+			}
+		}()
+
+		err = lvm.InitLock(pl.VgName)
+		if err != nil {
+			return
+		}
+
+		err = checkPoolCapacity(pl, dst.Size)
+		if err != nil {
+			return
+		}
+
+		err = createLv(pl, dst.Id.Hex(), dst.Size)
+		if err != nil {
+			return
+		}
+	} else {
+		err = utils.ExistsMkdir(path.Dir(dstPth), 0755)
+		if err != nil {
+			return
+		}
+	}
+
+	err = ActivateDisk(db, dst)
+	if err == nil {
+		err = convertDisk(srcPth, srcFmt, dstPth, dstFmt,
+			dst.Type == disk.Lvm, progress)
+		if err == nil {
+			err = utils.Exec("", "qemu-img", "compare",
+				"-f", srcFmt, "-F", dstFmt, srcPth, dstPth)
+			if err != nil {
+				err = &errortypes.VerificationError{
+					errors.Wrap(err, "data: Disk copy verification failed"),
+				}
+			}
+		}
+
+		err2 := DeactivateDisk(db, dst)
+		if err == nil {
+			err = err2
+		}
+	}
+
+	if err != nil {
+		err2 := removeDiskData(db, dst)
+		if err2 != nil {
+			logrus.WithFields(logrus.Fields{
+				"disk_id": dst.Id.Hex(),
+				"error":   err2,
+			}).Error("data: Failed to remove incomplete disk copy")
+		}
+		return
+	}
+
+	if dst.Type != disk.Lvm {
+		err = utils.Chmod(dstPth, 0600)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func copyDiskRemote(db *database.Database, src *disk.Disk,
+	progress func(percent int)) (err error) {
+
+	srcPth, srcFmt, err := getDiskDevice(db, src)
+	if err != nil {
+		return
+	}
+
+	if srcFmt != "qcow2" || src.BackingImage != "" {
+		tempPth := paths.GetDiskTempPath()
+		defer utils.Remove(tempPth)
+
+		err = utils.ExistsMkdir(paths.GetTempPath(), 0755)
+		if err != nil {
+			return
+		}
+
+		err = ActivateDisk(db, src)
+		if err != nil {
+			return
+		}
+
+		err = convertDisk(srcPth, srcFmt, tempPth, "qcow2", false, nil)
+		err2 := DeactivateDisk(db, src)
+		if err != nil {
+			return
+		}
+		if err2 != nil {
+			err = err2
+			return
+		}
+
+		srcPth = tempPth
+	}
+
+	err = transfer.Send(db, src, srcPth, progress)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// TransferDisk runs a clone or move of a detached disk, disks are copied
+// locally when the target is accessible from the node and otherwise
+// streamed to the target node
+func TransferDisk(db *database.Database, dsk *disk.Disk) (err error) {
+	dst, err := getTransferTarget(db, dsk)
+	if err != nil {
+		return
+	}
+
+	local := !dsk.TargetPool.IsZero() || dsk.TargetNode == node.Self.Id
+	if local && !dst.Pool.IsZero() &&
+		!slices.Contains(node.Self.Pools, dst.Pool) {
+
+		err = &errortypes.NotFoundError{
+			errors.New("data: Target pool not available on node"),
+		}
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"disk_id":     dsk.Id.Hex(),
+		"action":      dsk.Action,
+		"target_disk": dst.Id.Hex(),
+		"target_node": dsk.TargetNode.Hex(),
+		"target_pool": dsk.TargetPool.Hex(),
+		"local":       local,
+	}).Info("data: Transferring disk")
+
+	lastUpdate := time.Now()
+	progress := func(percent int) {
+		if percent <= dsk.Progress ||
+			time.Since(lastUpdate) < 3*time.Second {
+
+			return
+		}
+		lastUpdate = time.Now()
+
+		dsk.Progress = percent
+		err := dsk.CommitFields(db, set.NewSet("progress"))
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"disk_id": dsk.Id.Hex(),
+				"error":   err,
+			}).Error("data: Failed to update transfer progress")
+			return
+		}
+
+		event.PublishDispatch(db, "disk.change")
+	}
+
+	if local {
+		err = copyDiskLocal(db, dsk, dst, progress)
+	} else {
+		err = copyDiskRemote(db, dsk, progress)
+	}
+	if err != nil {
+		return
+	}
+
+	src := &disk.Disk{}
+	*src = *dsk
+
+	if dsk.Action == disk.Clone {
+		err = dst.Insert(db)
+		if err != nil {
+			return
+		}
+
+		err = dsk.CommitFields(db, dsk.ClearTransfer())
+		if err != nil {
+			return
+		}
+
+		return
+	}
+
+	fields := dsk.ClearTransfer()
+	fields.Add("node")
+	fields.Add("pool")
+	fields.Add("type")
+	fields.Add("zone")
+	fields.Add("datacenter")
+	fields.Add("backing")
+	fields.Add("backing_image")
+
+	dsk.Node = dst.Node
+	dsk.Pool = dst.Pool
+	dsk.Type = dst.Type
+	dsk.Zone = dst.Zone
+	dsk.Datacenter = dst.Datacenter
+	dsk.Backing = false
+	dsk.BackingImage = ""
+
+	err = dsk.CommitFields(db, fields)
+	if err != nil {
+		return
+	}
+
+	err = removeDiskData(db, src)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"disk_id": src.Id.Hex(),
+			"error":   err,
+		}).Error("data: Failed to remove moved disk source")
+		err = nil
+	}
+
+	return
+}
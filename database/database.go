@@ -151,6 +151,16 @@ func (d *Database) Tasks() (coll *Collection) {
 	return
 }
 
+func (d *Database) TaskRuns() (coll *Collection) {
+	coll = d.GetCollection("task_runs")
+	return
+}
+
+func (d *Database) TaskStates() (coll *Collection) {
+	coll = d.GetCollection("task_states")
+	return
+}
+
 func (d *Database) Tokens() (coll *Collection) {
 	coll = d.GetCollection("tokens")
 	return
@@ -221,6 +231,21 @@ func (d *Database) Images() (coll *Collection) {
 	return
 }
 
+func (d *Database) ImageImports() (coll *Collection) {
+	coll = d.GetCollection("image_imports")
+	return
+}
+
+func (d *Database) ImageCatalogs() (coll *Collection) {
+	coll = d.GetCollection("image_catalogs")
+	return
+}
+
+func (d *Database) ImageCaches() (coll *Collection) {
+	coll = d.GetCollection("image_caches")
+	return
+}
+
 func (d *Database) Datacenters() (coll *Collection) {
 	coll = d.GetCollection("datacenters")
 	return
@@ -251,6 +276,11 @@ func (d *Database) Vulnerabilities() (coll *Collection) {
 	return
 }
 
+func (d *Database) Vuxmls() (coll *Collection) {
+	coll = d.GetCollection("vuxmls")
+	return
+}
+
 func (d *Database) Instances() (coll *Collection) {
 	coll = d.GetCollection("instances")
 	return
@@ -316,6 +346,26 @@ func (d *Database) Authorities() (coll *Collection) {
 	return
 }
 
+func (d *Database) SshCertificates() (coll *Collection) {
+	coll = d.GetCollection("ssh_certificates")
+	return
+}
+
+func (d *Database) SshHostKeys() (coll *Collection) {
+	coll = d.GetCollection("ssh_host_keys")
+	return
+}
+
+func (d *Database) LogSinks() (coll *Collection) {
+	coll = d.GetCollection("log_sinks")
+	return
+}
+
+func (d *Database) SerialLogs() (coll *Collection) {
+	coll = d.GetCollection("serial_logs")
+	return
+}
+
 func (d *Database) Certificates() (coll *Collection) {
 	coll = d.GetCollection("certificates")
 	return
@@ -346,6 +396,11 @@ func (d *Database) Logs() (coll *Collection) {
 	return
 }
 
+func (d *Database) AccessLogs() (coll *Collection) {
+	coll = d.getCollectionWeak("access_logs")
+	return
+}
+
 func (d *Database) Audits() (coll *Collection) {
 	coll = d.GetCollection("audits")
 	return
@@ -381,6 +436,11 @@ func (d *Database) MetricsNetwork() (coll *Collection) {
 	return
 }
 
+func (d *Database) MetricsCustom() (coll *Collection) {
+	coll = d.getCollectionWeak("metrics_custom")
+	return
+}
+
 func Connect() (err error) {
 	mongoUrl, err := connstring.ParseAndValidate(config.Config.MongoUri)
 	if err != nil {
@@ -581,6 +641,18 @@ func addIndexes() (err error) {
 		return
 	}
 
+	index = &Index{
+		Collection: db.AccessLogs(),
+		Keys: &bson.D{
+			{"balancer", 1},
+			{"timestamp", -1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
 	index = &Index{
 		Collection: db.Audits(),
 		Keys: &bson.D{
@@ -592,6 +664,81 @@ func addIndexes() (err error) {
 		return
 	}
 
+	index = &Index{
+		Collection: db.Audits(),
+		Keys: &bson.D{
+			{"s", 1},
+		},
+		Partial: &bson.M{
+			"s": &bson.M{
+				"$exists": true,
+			},
+		},
+		Unique: true,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.Audits(),
+		Keys: &bson.D{
+			{"t", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.SshCertificates(),
+		Keys: &bson.D{
+			{"authority", 1},
+			{"timestamp", -1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.SshCertificates(),
+		Keys: &bson.D{
+			{"timestamp", 1},
+		},
+		Expire: 8760 * time.Hour,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.LogSinks(),
+		Keys: &bson.D{
+			{"organization", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.SerialLogs(),
+		Keys: &bson.D{
+			{"timestamp", 1},
+		},
+		Expire: 720 * time.Hour,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
 	index = &Index{
 		Collection: db.Policies(),
 		Keys: &bson.D{
@@ -810,6 +957,68 @@ func addIndexes() (err error) {
 		return
 	}
 
+	index = &Index{
+		Collection: db.ImageImports(),
+		Keys: &bson.D{
+			{"organization", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.ImageImports(),
+		Keys: &bson.D{
+			{"state", 1},
+			{"timestamp", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.TaskRuns(),
+		Keys: &bson.D{
+			{"task", 1},
+			{"start", -1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.ImageCaches(),
+		Keys: &bson.D{
+			{"node", 1},
+			{"image", 1},
+			{"etag", 1},
+		},
+		Unique: true,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.ImageCaches(),
+		Keys: &bson.D{
+			{"image", 1},
+			{"etag", 1},
+			{"zone", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
 	index = &Index{
 		Collection: db.Images(),
 		Keys: &bson.D{
@@ -1572,6 +1781,31 @@ func addIndexes() (err error) {
 		}
 	}
 
+	if !IsTimeSeries("metrics_custom") {
+		index = &Index{
+			Collection: db.MetricsCustom(),
+			Keys: &bson.D{
+				{"r", 1},
+				{"t", 1},
+			},
+		}
+		err = index.Create()
+		if err != nil {
+			return
+		}
+		index = &Index{
+			Collection: db.MetricsCustom(),
+			Keys: &bson.D{
+				{"t", 1},
+			},
+			Expire: 2160 * time.Hour,
+		}
+		err = index.Create()
+		if err != nil {
+			return
+		}
+	}
+
 	return
 }
 
@@ -1589,6 +1823,8 @@ func addCollections() (err error) {
 
 	eventsExists := false
 	isCapped := false
+	accessLogsExists := false
+	taskRunsExists := false
 	collTypes := map[string]string{}
 
 	for cursor.Next(db) {
@@ -1605,6 +1841,14 @@ func addCollections() (err error) {
 
 		collTypes[item.Name] = item.Type
 
+		if item.Name == "access_logs" {
+			accessLogsExists = true
+		}
+
+		if item.Name == "task_runs" {
+			taskRunsExists = true
+		}
+
 		if item.Name == "events" {
 			eventsExists = true
 			if options, ok := item.Options["capped"]; ok {
@@ -1650,6 +1894,37 @@ func addCollections() (err error) {
 		}
 	}
 
+	if !accessLogsExists {
+		err = db.database.RunCommand(
+			db,
+			bson.D{
+				{"create", "access_logs"},
+				{"capped", true},
+				{"size", 104857600},
+			},
+		).Err()
+		if err != nil {
+			err = ParseError(err)
+			return
+		}
+	}
+
+	if !taskRunsExists {
+		err = db.database.RunCommand(
+			db,
+			bson.D{
+				{"create", "task_runs"},
+				{"capped", true},
+				{"max", 20000},
+				{"size", 52428800},
+			},
+		).Err()
+		if err != nil {
+			err = ParseError(err)
+			return
+		}
+	}
+
 	err = addTimeSeriesCollections(db, collTypes)
 	if err != nil {
 		return
@@ -13,6 +13,7 @@ var metricsCollections = []string{
 	"metrics_disk",
 	"metrics_diskio",
 	"metrics_network",
+	"metrics_custom",
 }
 
 var (
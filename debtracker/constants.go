@@ -0,0 +1,7 @@
+package debtracker
+
+const (
+	DebianTracker = "https://security-tracker.debian.org/tracker/data/json"
+
+	Resolved = "resolved"
+)
@@ -0,0 +1,188 @@
+package debtracker
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/settings"
+)
+
+var (
+	trackerClient = &http.Client{
+		Timeout: 120 * time.Second,
+	}
+	cache          Tracker
+	cacheTimestamp time.Time
+	cacheLock      sync.Mutex
+)
+
+type Release struct {
+	Status       string `json:"status"`
+	FixedVersion string `json:"fixed_version"`
+	Urgency      string `json:"urgency"`
+}
+
+type Entry struct {
+	Description string              `json:"description"`
+	Scope       string              `json:"scope"`
+	Releases    map[string]*Release `json:"releases"`
+}
+
+type Tracker map[string]map[string]*Entry
+
+func normalizeVersion(version string) string {
+	return strings.ReplaceAll(version, ":", "")
+}
+
+func urgencyRank(urgency string) int {
+	switch strings.TrimSuffix(urgency, "*") {
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	}
+	return 0
+}
+
+func (t Tracker) Entry(source, cve string) *Entry {
+	if t == nil {
+		return nil
+	}
+
+	entries := t[source]
+	if entries == nil {
+		return nil
+	}
+
+	return entries[cve]
+}
+
+func (t Tracker) Match(source, release, version string, cves []string) (
+	matched []string, urgency string) {
+
+	matchedSet := map[string]bool{}
+	matched = []string{}
+
+	for _, cve := range cves {
+		if !matchedSet[cve] {
+			matchedSet[cve] = true
+			matched = append(matched, cve)
+		}
+	}
+
+	if t != nil && source != "" && release != "" && version != "" {
+		version = normalizeVersion(version)
+
+		for cve, entry := range t[source] {
+			rel := entry.Releases[release]
+			if rel == nil || rel.Status != Resolved {
+				continue
+			}
+
+			if normalizeVersion(rel.FixedVersion) != version {
+				continue
+			}
+
+			if !matchedSet[cve] {
+				matchedSet[cve] = true
+				matched = append(matched, cve)
+			}
+		}
+	}
+
+	for _, cve := range matched {
+		entry := t.Entry(source, cve)
+		if entry == nil {
+			continue
+		}
+
+		rel := entry.Releases[release]
+		if rel == nil {
+			continue
+		}
+
+		if urgencyRank(rel.Urgency) > urgencyRank(urgency) {
+			urgency = strings.TrimSuffix(rel.Urgency, "*")
+		}
+	}
+
+	sort.Strings(matched)
+
+	return
+}
+
+func Load() (trkr Tracker, err error) {
+	req, err := http.NewRequest("GET", DebianTracker, nil)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "debtracker: Failed to create tracker request"),
+		}
+		return
+	}
+
+	resp, err := trackerClient.Do(req)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "debtracker: Failed to request tracker"),
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = &errortypes.RequestError{
+			errors.Newf(
+				"debtracker: Bad tracker status %d", resp.StatusCode),
+		}
+		return
+	}
+
+	trkr = Tracker{}
+	err = json.NewDecoder(io.LimitReader(
+		resp.Body,
+		int64(settings.Telemetry.DebianTrackerSizeLimit),
+	)).Decode(&trkr)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "debtracker: Failed to parse tracker"),
+		}
+		return
+	}
+
+	return
+}
+
+// Get returns the tracker data, the data is cached for the tracker ttl
+// and the last cached data is returned if the reload fails
+func Get() (trkr Tracker, err error) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+
+	ttl := time.Duration(settings.Telemetry.DebianTrackerTtl) * time.Second
+	if cache != nil && time.Since(cacheTimestamp) < ttl {
+		trkr = cache
+		return
+	}
+
+	trkr, err = Load()
+	if err != nil {
+		if cache != nil {
+			trkr = cache
+		}
+		return
+	}
+
+	cache = trkr
+	cacheTimestamp = time.Now()
+
+	return
+}
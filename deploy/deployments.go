@@ -19,6 +19,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/instance"
 	"github.com/pritunl/pritunl-cloud/node"
 	"github.com/pritunl/pritunl-cloud/nodeport"
+	"github.com/pritunl/pritunl-cloud/schedule"
 	"github.com/pritunl/pritunl-cloud/shape"
 	"github.com/pritunl/pritunl-cloud/spec"
 	"github.com/pritunl/pritunl-cloud/state"
@@ -102,6 +103,17 @@ func (d *Deployments) migrate(deply *deployment.Deployment) {
 				"error_data":    errData,
 			}).Error("deploy: Incompatible migrate")
 
+			err = unit.RolloutFailed(db, deply.Unit, deply.Id, newSpec.Id)
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"deployment_id": deply.Id.Hex(),
+					"cur_spec_id":   curSpec.Id.Hex(),
+					"new_spec_id":   newSpec.Id.Hex(),
+					"error":         err,
+				}).Error("deploy: Failed to update unit rollout")
+				err = nil
+			}
+
 			deply.State = deployment.Deployed
 			deply.NewSpec = bson.NilObjectID
 			err = deply.CommitFields(db, set.NewSet("state", "new_spec"))
@@ -158,6 +170,18 @@ func (d *Deployments) migrate(deply *deployment.Deployment) {
 			instFields.Add("skip_source_dest_check")
 			inst.SkipSourceDestCheck = newSpec.Instance.SkipSourceDestCheck
 		}
+		if !schedule.Equal(curSpec.Instance.PowerSchedules,
+			newSpec.Instance.PowerSchedules) {
+
+			instFields.Add("power_schedules")
+			inst.PowerSchedules = newSpec.Instance.PowerSchedules
+		}
+		if curSpec.Instance.Expire != newSpec.Instance.Expire ||
+			curSpec.Instance.Lifetime != newSpec.Instance.Lifetime {
+
+			instFields.Add("expire")
+			inst.Expire = newSpec.Instance.GetExpire(inst.Created)
+		}
 		if curSpec.Instance.Gui != newSpec.Instance.Gui {
 			instFields.Add("gui")
 			inst.Gui = newSpec.Instance.Gui
@@ -352,6 +376,17 @@ func (d *Deployments) migrate(deply *deployment.Deployment) {
 			return
 		}
 
+		err = unit.RolloutMigrated(db, deply.Unit, deply.Id, newSpec.Id)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"deployment_id": deply.Id.Hex(),
+				"cur_spec_id":   curSpec.Id.Hex(),
+				"new_spec_id":   newSpec.Id.Hex(),
+				"error":         err,
+			}).Error("deploy: Failed to update unit rollout")
+			err = nil
+		}
+
 		logrus.WithFields(logrus.Fields{
 			"deployment_id": deply.Id.Hex(),
 			"cur_spec_id":   curSpec.Id.Hex(),
@@ -667,7 +702,9 @@ func (d *Deployments) restore(deply *deployment.Deployment) (err error) {
 							return
 						}
 
-						if dsk.Node != node.Self.Id || !dsk.Instance.IsZero() {
+						if !dsk.IsNode(node.Self.Id, node.Self.Pools) ||
+							!dsk.Instance.IsZero() {
+
 							continue
 						}
 
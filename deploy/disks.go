@@ -1,10 +1,12 @@
 package deploy
 
 import (
+	"slices"
 	"time"
 
 	"github.com/dropbox/godropbox/container/set"
 	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/constants"
 	"github.com/pritunl/pritunl-cloud/data"
 	"github.com/pritunl/pritunl-cloud/database"
@@ -12,6 +14,9 @@ import (
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/event"
 	"github.com/pritunl/pritunl-cloud/instance"
+	"github.com/pritunl/pritunl-cloud/lock"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/pool"
 	"github.com/pritunl/pritunl-cloud/settings"
 	"github.com/pritunl/pritunl-cloud/state"
 	"github.com/pritunl/pritunl-cloud/utils"
@@ -28,6 +33,88 @@ type Disks struct {
 	stat *state.State
 }
 
+// sharedLock acquires the cluster lock for disks in a pool which are
+// visible to every node with access to the pool
+func (d *Disks) sharedLock(db *database.Database, dsk *disk.Disk) bool {
+	if !dsk.IsShared() {
+		return true
+	}
+
+	acquired, err := lock.LvmLock(db, dsk.Pool.Hex(), dsk.Id.Hex())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"disk_id": dsk.Id.Hex(),
+			"error":   err,
+		}).Error("deploy: Failed to acquire shared disk lock")
+		return false
+	}
+
+	return acquired
+}
+
+func (d *Disks) sharedUnlock(db *database.Database, dsk *disk.Disk) {
+	if !dsk.IsShared() {
+		return
+	}
+
+	err := lock.LvmUnlock(db, dsk.Pool.Hex(), dsk.Id.Hex())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"disk_id": dsk.Id.Hex(),
+			"error":   err,
+		}).Error("deploy: Failed to release shared disk lock")
+	}
+}
+
+// lvmLock acquires the volume lock for disks in an lvm pool, the source
+// volume is activated during a transfer and removed after a move
+func (d *Disks) lvmLock(db *database.Database, dsk *disk.Disk) (
+	vgName string, acquired bool) {
+
+	if dsk.Type != disk.Lvm || dsk.Pool.IsZero() {
+		acquired = true
+		return
+	}
+
+	pl, err := pool.Get(db, dsk.Pool)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"disk_id": dsk.Id.Hex(),
+			"error":   err,
+		}).Error("deploy: Failed to get disk pool")
+		return
+	}
+
+	acquired, err = lock.LvmLock(db, pl.VgName, dsk.Id.Hex())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"disk_id": dsk.Id.Hex(),
+			"error":   err,
+		}).Error("deploy: Failed to acquire lvm disk lock")
+		acquired = false
+		return
+	}
+
+	vgName = pl.VgName
+	return
+}
+
+func (d *Disks) lvmUnlock(db *database.Database, dsk *disk.Disk,
+	vgName string) {
+
+	if vgName == "" {
+		return
+	}
+
+	err := lock.LvmUnlock(db, vgName, dsk.Id.Hex())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"disk_id": dsk.Id.Hex(),
+			"error":   err,
+		}).Error("deploy: Failed to release lvm disk lock")
+	}
+}
+
 func (d *Disks) provision(dsk *disk.Disk) {
 	acquired, lockId := disksLock.LockOpen(dsk.Id.Hex())
 	if !acquired {
@@ -53,6 +140,11 @@ func (d *Disks) provision(dsk *disk.Disk) {
 			return
 		}
 
+		if !d.sharedLock(db, dsk) {
+			return
+		}
+		defer d.sharedUnlock(db, dsk)
+
 		newSize, backingImage, err := data.CreateDisk(db, dsk)
 		if err != nil {
 			logrus.WithFields(logrus.Fields{
@@ -172,6 +264,11 @@ func (d *Disks) expand(dsk *disk.Disk) {
 			return
 		}
 
+		if !d.sharedLock(db, dsk) {
+			return
+		}
+		defer d.sharedUnlock(db, dsk)
+
 		inst := d.stat.GetInstace(dsk.Instance)
 		if inst != nil {
 			if inst.Action != instance.Stop {
@@ -332,6 +429,11 @@ func (d *Disks) restore(dsk *disk.Disk) {
 			return
 		}
 
+		if !d.sharedLock(db, dsk) {
+			return
+		}
+		defer d.sharedUnlock(db, dsk)
+
 		inst := d.stat.GetInstace(dsk.Instance)
 		if inst != nil {
 			if inst.Action != instance.Stop {
@@ -391,6 +493,118 @@ func (d *Disks) restore(dsk *disk.Disk) {
 	}()
 }
 
+func (d *Disks) transfer(dsk *disk.Disk) {
+	acquired, lockId := disksLock.LockOpen(dsk.Id.Hex())
+	if !acquired {
+		return
+	}
+
+	go func() {
+		defer disksLock.Unlock(dsk.Id.Hex(), lockId)
+
+		db := database.GetDatabase()
+		defer db.Close()
+
+		if constants.Interrupt {
+			return
+		}
+
+		dsk, err := disk.Get(db, dsk.Id)
+		if err != nil {
+			return
+		}
+
+		if !dsk.IsTransfer() {
+			return
+		}
+
+		if !dsk.TargetPool.IsZero() &&
+			!slices.Contains(node.Self.Pools, dsk.TargetPool) {
+
+			if dsk.IsShared() {
+				avail, e := d.transferAvailable(db, dsk)
+				if e != nil || avail {
+					return
+				}
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"disk_id":     dsk.Id.Hex(),
+				"target_pool": dsk.TargetPool.Hex(),
+			}).Error("deploy: Target pool not available on node")
+
+			err = dsk.CommitFields(db, dsk.ClearTransfer())
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"error": err,
+				}).Error("deploy: Failed update disk state")
+				time.Sleep(5 * time.Second)
+				return
+			}
+
+			event.PublishDispatch(db, "disk.change")
+			return
+		}
+
+		// Move updates the disk pool, locks are released on the source
+		src := &disk.Disk{}
+		*src = *dsk
+
+		if !d.sharedLock(db, src) {
+			return
+		}
+		defer d.sharedUnlock(db, src)
+
+		vgName, acquired := d.lvmLock(db, src)
+		if !acquired {
+			return
+		}
+		defer d.lvmUnlock(db, src, vgName)
+
+		err = data.TransferDisk(db, dsk)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"disk_id": dsk.Id.Hex(),
+				"error":   err,
+			}).Error("deploy: Failed to transfer disk")
+
+			err = dsk.CommitFields(db, dsk.ClearTransfer())
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"error": err,
+				}).Error("deploy: Failed update disk state")
+				time.Sleep(5 * time.Second)
+				return
+			}
+		}
+
+		event.PublishDispatch(db, "disk.change")
+	}()
+}
+
+// transferAvailable checks if another node with access to a shared disk
+// also has access to the transfer target pool
+func (d *Disks) transferAvailable(db *database.Database, dsk *disk.Disk) (
+	avail bool, err error) {
+
+	count, err := db.Nodes().CountDocuments(db, &bson.M{
+		"pools": &bson.M{
+			"$all": []bson.ObjectID{dsk.Pool, dsk.TargetPool},
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		logrus.WithFields(logrus.Fields{
+			"disk_id": dsk.Id.Hex(),
+			"error":   err,
+		}).Error("deploy: Failed to check transfer pool nodes")
+		return
+	}
+
+	avail = count > 0
+	return
+}
+
 func (d *Disks) destroy(db *database.Database, dsk *disk.Disk) {
 	var inst *instance.Instance
 	if !dsk.Instance.IsZero() {
@@ -425,6 +639,11 @@ func (d *Disks) destroy(db *database.Database, dsk *disk.Disk) {
 			return
 		}
 
+		if !d.sharedLock(db, dsk) {
+			return
+		}
+		defer d.sharedUnlock(db, dsk)
+
 		if dsk.DeleteProtection {
 			logrus.WithFields(logrus.Fields{
 				"disk_id": dsk.Id.Hex(),
@@ -560,6 +779,12 @@ func (d *Disks) Deploy(db *database.Database) (err error) {
 	}
 
 	for _, dsk := range disks {
+		if dsk.IsShared() && !dsk.Instance.IsZero() &&
+			d.stat.GetInstace(dsk.Instance) == nil {
+
+			continue
+		}
+
 		if dsk.State == disk.Provision {
 			d.provision(dsk)
 		} else if dsk.IsActive() {
@@ -579,6 +804,9 @@ func (d *Disks) Deploy(db *database.Database) (err error) {
 			case disk.Destroy:
 				d.destroy(db, dsk)
 				break
+			case disk.Clone, disk.Move:
+				d.transfer(dsk)
+				break
 			default:
 				if backupActive && dsk.Backup {
 					d.scheduleBackup(dsk)
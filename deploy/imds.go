@@ -10,6 +10,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/instance"
 	"github.com/pritunl/pritunl-cloud/pod"
 	"github.com/pritunl/pritunl-cloud/secret"
+	"github.com/pritunl/pritunl-cloud/sshcert"
 	"github.com/pritunl/pritunl-cloud/state"
 	"github.com/pritunl/pritunl-cloud/unit"
 	"github.com/pritunl/pritunl-cloud/vm"
@@ -26,8 +27,8 @@ type Imds struct {
 }
 
 func (s *Imds) buildInstance(db *database.Database,
-	inst *instance.Instance, virt *vm.VirtualMachine) (
-	conf *types.Config, err error) {
+	inst *instance.Instance, virt *vm.VirtualMachine,
+	hostKey *sshcert.HostKey) (conf *types.Config, err error) {
 
 	vc := s.stat.Vpc(inst.Vpc)
 	zne := s.stat.GetZone(inst.Zone)
@@ -46,6 +47,7 @@ func (s *Imds) buildInstance(db *database.Database,
 		[]*secret.Secret{},
 		[]*certificate.Certificate{},
 		s.stat.GetDomains(inst.Organization),
+		hostKey,
 	)
 	if err != nil {
 		return
@@ -60,8 +62,8 @@ func (s *Imds) buildInstance(db *database.Database,
 }
 
 func (s *Imds) buildDeployInstance(db *database.Database,
-	inst *instance.Instance, virt *vm.VirtualMachine) (
-	conf *types.Config, err error) {
+	inst *instance.Instance, virt *vm.VirtualMachine,
+	hostKey *sshcert.HostKey) (conf *types.Config, err error) {
 
 	vc := s.stat.Vpc(inst.Vpc)
 	zne := s.stat.GetZone(inst.Zone)
@@ -148,6 +150,7 @@ func (s *Imds) buildDeployInstance(db *database.Database,
 		secrs,
 		certs,
 		s.stat.GetDomains(inst.Organization),
+		hostKey,
 	)
 	if err != nil {
 		return
@@ -164,6 +167,18 @@ func (s *Imds) buildDeployInstance(db *database.Database,
 func (s *Imds) Deploy(db *database.Database) (err error) {
 	instances := s.stat.Instances()
 
+	instIds := []bson.ObjectID{}
+	for _, inst := range instances {
+		if inst.IsActive() {
+			instIds = append(instIds, inst.Id)
+		}
+	}
+
+	hostKeys, err := sshcert.GetHostKeys(db, instIds)
+	if err != nil {
+		return
+	}
+
 	confs := map[bson.ObjectID]*types.Config{}
 	for _, inst := range instances {
 		if !inst.IsActive() {
@@ -181,12 +196,14 @@ func (s *Imds) Deploy(db *database.Database) (err error) {
 
 		var conf *types.Config
 		if inst.Deployment.IsZero() {
-			conf, err = s.buildInstance(db, inst, virt)
+			conf, err = s.buildInstance(db, inst, virt,
+				hostKeys[inst.Id])
 			if err != nil {
 				return
 			}
 		} else {
-			conf, err = s.buildDeployInstance(db, inst, virt)
+			conf, err = s.buildDeployInstance(db, inst, virt,
+				hostKeys[inst.Id])
 			if err != nil {
 				return
 			}
@@ -936,6 +936,18 @@ func (s *Instances) Deploy(db *database.Database) (err error) {
 	for _, inst := range instances {
 		virt := s.stat.GetVirt(inst.Id)
 		if virt != nil {
+			if inst.State != virt.State {
+				evtFields := map[string]string{
+					"node": inst.Node.Hex(),
+				}
+				if !inst.Deployment.IsZero() {
+					evtFields["deployment"] = inst.Deployment.Hex()
+				}
+
+				event.PublishResource(db, event.InstanceState,
+					inst.Organization, inst.Id, virt.State, evtFields)
+			}
+
 			if inst.State == vm.Running &&
 				(virt.State == vm.Stopped || virt.State == vm.Failed) {
 
@@ -273,8 +273,11 @@ func (s *Pods) DeploySpec(db *database.Database,
 		Vnc:                 spc.Instance.Vnc,
 		DhcpServer:          spc.Instance.DhcpServer,
 		CloudScript:         "",
+		CloudUser:           img.GetDefaultUser(),
 		DeleteProtection:    spc.Instance.DeleteProtection,
 		SkipSourceDestCheck: spc.Instance.SkipSourceDestCheck,
+		PowerSchedules:      spc.Instance.PowerSchedules,
+		Expire:              spc.Instance.GetExpire(time.Now()),
 		Gui:                 spc.Instance.Gui,
 		Name:                spc.Name,
 		Comment:             "",
@@ -290,18 +293,19 @@ func (s *Pods) DeploySpec(db *database.Database,
 	}
 
 	switch img.GetSystemType() {
+	case image.Bsd, image.LinuxUnsigned:
+		inst.SecureBoot = false
+	default:
+		inst.SecureBoot = true
+	}
+
+	switch img.GetCloudInit() {
 	case image.Bsd:
 		inst.CloudType = instance.BSD
-		inst.SecureBoot = false
-	case image.LinuxUnsigned:
-		inst.CloudType = instance.Linux
-		inst.SecureBoot = false
 	case image.LinuxLegacy:
 		inst.CloudType = instance.LinuxLegacy
-		inst.SecureBoot = true
 	default:
 		inst.CloudType = instance.Linux
-		inst.SecureBoot = true
 	}
 
 	if spc.Instance.Uefi != nil {
@@ -403,7 +407,9 @@ func (s *Pods) DeploySpec(db *database.Database,
 				return
 			}
 
-			if dsk.Node != node.Self.Id || !dsk.Instance.IsZero() {
+			if !dsk.IsNode(node.Self.Id, node.Self.Pools) ||
+				!dsk.Instance.IsZero() {
+
 				continue
 			}
 
@@ -17,6 +17,8 @@ const (
 	Migrate = "migrate"
 	Restore = "restore"
 
+	Rollback = "rollback"
+
 	Ready    = "ready"
 	Snapshot = "snapshot"
 	Complete = "complete"
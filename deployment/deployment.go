@@ -8,6 +8,7 @@ import (
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/event"
 	"github.com/pritunl/pritunl-cloud/utils"
 )
 
@@ -350,9 +351,29 @@ func (d *Deployment) CommitFields(db *database.Database, fields set.Set) (
 		return
 	}
 
+	if fields.Contains("state") {
+		event.PublishResource(db, event.DeploymentState, d.Organization,
+			d.Id, d.State, d.eventFields())
+	}
+	if fields.Contains("status") {
+		event.PublishResource(db, event.DeploymentStatus, d.Organization,
+			d.Id, d.Status, d.eventFields())
+	}
+
 	return
 }
 
+func (d *Deployment) eventFields() map[string]string {
+	fields := map[string]string{
+		"pod":  d.Pod.Hex(),
+		"unit": d.Unit.Hex(),
+	}
+	if !d.Instance.IsZero() {
+		fields["instance"] = d.Instance.Hex()
+	}
+	return fields
+}
+
 func (d *Deployment) Insert(db *database.Database) (err error) {
 	coll := db.Deployments()
 
@@ -461,3 +461,28 @@ func RestoreMulti(db *database.Database, unitId bson.ObjectID,
 
 	return
 }
+
+func MigrateMulti(db *database.Database, unitId, specId bson.ObjectID,
+	deplyIds []bson.ObjectID) (err error) {
+
+	coll := db.Deployments()
+
+	_, err = coll.UpdateMany(db, &bson.M{
+		"_id": &bson.M{
+			"$in": deplyIds,
+		},
+		"unit":  unitId,
+		"state": Deployed,
+	}, &bson.M{
+		"$set": &bson.M{
+			"action":   Migrate,
+			"new_spec": specId,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
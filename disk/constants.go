@@ -15,6 +15,8 @@ const (
 	Expand   = "expand"
 	Restore  = "restore"
 	Destroy  = "destroy"
+	Clone    = "clone"
+	Move     = "move"
 
 	Qcow2 = "qcow2"
 	Lvm   = "lvm"
@@ -45,12 +47,4 @@ var (
 		LinuxUnsigned,
 		Bsd,
 	)
-	ValidSystemKinds = set.NewSet(
-		AlpineLinux,
-		ArchLinux,
-		RedHat,
-		Fedora,
-		Ubuntu,
-		FreeBSD,
-	)
 )
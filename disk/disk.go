@@ -2,6 +2,7 @@ package disk
 
 import (
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/dropbox/godropbox/container/set"
 	"github.com/dropbox/godropbox/errors"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/catalog"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/event"
@@ -51,6 +53,11 @@ type Disk struct {
 	NewSize          int           `bson:"new_size" json:"new_size"`
 	Backup           bool          `bson:"backup" json:"backup"`
 	LastBackup       time.Time     `bson:"last_backup" json:"last_backup"`
+	TargetNode       bson.ObjectID `bson:"target_node" json:"target_node"`
+	TargetPool       bson.ObjectID `bson:"target_pool" json:"target_pool"`
+	TargetDisk       bson.ObjectID `bson:"target_disk" json:"target_disk"`
+	TransferToken    string        `bson:"transfer_token" json:"-"`
+	Progress         int           `bson:"progress" json:"progress"`
 	curIndex         string        `bson:"-" json:"-"`
 	curInstance      bson.ObjectID `bson:"-" json:"-"`
 }
@@ -66,6 +73,36 @@ func (d *Disk) IsAvailable() bool {
 	return false
 }
 
+// IsShared returns true for QCOW disks stored in a shared filesystem pool
+func (d *Disk) IsShared() bool {
+	return d.Type != Lvm && !d.Pool.IsZero()
+}
+
+// IsNode returns true when the disk is accessible from the node
+func (d *Disk) IsNode(nodeId bson.ObjectID,
+	nodePools []bson.ObjectID) bool {
+
+	if d.IsShared() {
+		return slices.Contains(nodePools, d.Pool)
+	}
+	return d.Node == nodeId
+}
+
+func (d *Disk) GetPath(db *database.Database) (pth string, err error) {
+	if !d.IsShared() {
+		pth = paths.GetDiskPath(d.Id)
+		return
+	}
+
+	pl, err := pool.Get(db, d.Pool)
+	if err != nil {
+		return
+	}
+
+	pth = pl.GetDiskPath(d.Id)
+	return
+}
+
 func (d *Disk) Validate(db *database.Database) (
 	errData *errortypes.ErrorData, err error) {
 
@@ -116,22 +153,45 @@ func (d *Disk) Validate(db *database.Database) (
 		return
 	}
 
-	if d.Node.IsZero() {
-		errData = &errortypes.ErrorData{
-			Error:   "invalid_node",
-			Message: "Missing required node",
-		}
-		return
-	}
-
 	if d.Type == "" {
 		d.Type = Qcow2
 	}
 
 	switch d.Type {
 	case Qcow2:
-		d.Pool = bson.NilObjectID
-		if d.Node.IsZero() {
+		if !d.Pool.IsZero() {
+			pl, e := pool.Get(db, d.Pool)
+			if e != nil {
+				err = e
+				return
+			}
+
+			if !pl.IsSharedFs() {
+				errData = &errortypes.ErrorData{
+					Error:   "pool_type_invalid",
+					Message: "QCOW disk pool must be shared filesystem",
+				}
+				return
+			}
+
+			if pl.Zone != d.Zone {
+				errData = &errortypes.ErrorData{
+					Error:   "pool_zone_invalid",
+					Message: "Pool must be in the same zone as disk",
+				}
+				return
+			}
+
+			if d.Backing || d.BackingImage != "" {
+				errData = &errortypes.ErrorData{
+					Error:   "backing_image_invalid",
+					Message: "Shared pool disk cannot have backing image",
+				}
+				return
+			}
+
+			d.Node = bson.NilObjectID
+		} else if d.Node.IsZero() {
 			errData = &errortypes.ErrorData{
 				Error:   "node_required",
 				Message: "Missing required node",
@@ -176,7 +236,7 @@ func (d *Disk) Validate(db *database.Database) (
 		return
 	}
 
-	if d.SystemKind != "" && !ValidSystemKinds.Contains(d.SystemKind) {
+	if d.SystemKind != "" && !catalog.ValidSystemKind(d.SystemKind) {
 		errData = &errortypes.ErrorData{
 			Error:   "invalid_system_kind",
 			Message: "Disk system kind invalid",
@@ -368,6 +428,19 @@ func (d *Disk) CommitFields(db *database.Database, fields set.Set) (
 		return
 	}
 
+	if fields.Contains("state") {
+		evtFields := map[string]string{}
+		if !d.Instance.IsZero() {
+			evtFields["instance"] = d.Instance.Hex()
+		}
+		if d.Action != "" {
+			evtFields["action"] = d.Action
+		}
+
+		event.PublishResource(db, event.DiskState, d.Organization,
+			d.Id, d.State, evtFields)
+	}
+
 	return
 }
 
@@ -386,7 +459,10 @@ func (d *Disk) Insert(db *database.Database) (err error) {
 }
 
 func (d *Disk) Destroy(db *database.Database) (err error) {
-	dskPath := paths.GetDiskPath(d.Id)
+	dskPath, err := d.GetPath(db)
+	if err != nil {
+		return
+	}
 
 	if d.DeleteProtection {
 		logrus.WithFields(logrus.Fields{
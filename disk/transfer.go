@@ -0,0 +1,156 @@
+package disk
+
+import (
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/pool"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+func (d *Disk) IsTransfer() bool {
+	return d.Action == Clone || d.Action == Move
+}
+
+// SetTransfer starts a clone or move of the detached disk to the target
+// node or pool, returns the fields that must be committed
+func (d *Disk) SetTransfer(db *database.Database, action string,
+	targetNode, targetPool bson.ObjectID) (fields set.Set,
+	errData *errortypes.ErrorData, err error) {
+
+	if !d.IsAvailable() {
+		errData = &errortypes.ErrorData{
+			Error:   "disk_not_available",
+			Message: "Disk must be detached and available",
+		}
+		return
+	}
+
+	if targetNode.IsZero() == targetPool.IsZero() {
+		errData = &errortypes.ErrorData{
+			Error:   "transfer_target_invalid",
+			Message: "Disk transfer requires a target node or pool",
+		}
+		return
+	}
+
+	if !targetNode.IsZero() {
+		nde, e := node.Get(db, targetNode)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if nde.Datacenter != d.Datacenter {
+			errData = &errortypes.ErrorData{
+				Error:   "transfer_target_datacenter",
+				Message: "Disk transfer target must be in the same datacenter",
+			}
+			return
+		}
+
+		if action == Move && d.Node == nde.Id {
+			errData = &errortypes.ErrorData{
+				Error:   "transfer_target_same",
+				Message: "Disk is already on the target node",
+			}
+			return
+		}
+	} else {
+		pl, e := pool.Get(db, targetPool)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if pl.Datacenter != d.Datacenter {
+			errData = &errortypes.ErrorData{
+				Error:   "transfer_target_datacenter",
+				Message: "Disk transfer target must be in the same datacenter",
+			}
+			return
+		}
+
+		if action == Move && d.Pool == pl.Id {
+			errData = &errortypes.ErrorData{
+				Error:   "transfer_target_same",
+				Message: "Disk is already in the target pool",
+			}
+			return
+		}
+
+		query := &bson.M{
+			"_id":   d.Node,
+			"pools": pl.Id,
+		}
+		if !d.Pool.IsZero() {
+			query = &bson.M{
+				"pools": &bson.M{
+					"$all": []bson.ObjectID{d.Pool, pl.Id},
+				},
+			}
+		}
+
+		count, e := db.Nodes().CountDocuments(db, query)
+		if e != nil {
+			err = database.ParseError(e)
+			return
+		}
+
+		if count == 0 {
+			errData = &errortypes.ErrorData{
+				Error:   "transfer_pool_unavailable",
+				Message: "Target pool not available on disk node",
+			}
+			return
+		}
+	}
+
+	token, err := utils.RandStr(32)
+	if err != nil {
+		return
+	}
+
+	d.Action = action
+	d.TargetNode = targetNode
+	d.TargetPool = targetPool
+	d.TransferToken = token
+	d.Progress = 0
+
+	if action == Clone {
+		d.TargetDisk = bson.NewObjectID()
+	} else {
+		d.TargetDisk = d.Id
+	}
+
+	fields = set.NewSet(
+		"action",
+		"target_node",
+		"target_pool",
+		"target_disk",
+		"transfer_token",
+		"progress",
+	)
+
+	return
+}
+
+func (d *Disk) ClearTransfer() set.Set {
+	d.Action = ""
+	d.TargetNode = bson.NilObjectID
+	d.TargetPool = bson.NilObjectID
+	d.TargetDisk = bson.NilObjectID
+	d.TransferToken = ""
+	d.Progress = 0
+
+	return set.NewSet(
+		"action",
+		"target_node",
+		"target_pool",
+		"target_disk",
+		"transfer_token",
+		"progress",
+	)
+}
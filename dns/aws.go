@@ -36,7 +36,7 @@ func (a *Aws) Connect(db *database.Database,
 	a.client = route53.NewFromConfig(aws.Config{
 		Region: secr.Region,
 		Credentials: credentials.NewStaticCredentialsProvider(
-			secr.Key, secr.Value, ""),
+			secr.Key, string(secr.Value), ""),
 	})
 
 	return
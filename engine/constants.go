@@ -1,5 +1,13 @@
 package engine
 
+import (
+	"time"
+)
+
 const (
 	QueueSize = 256
+
+	DefaultHealthInterval = 30 * time.Second
+	DefaultHealthTimeout  = 10 * time.Second
+	DefaultFileMode       = 0644
 )
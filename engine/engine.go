@@ -20,10 +20,13 @@ type Engine struct {
 	bash       *BashEngine
 	python     *PythonEngine
 	lock       sync.Mutex
+	blocksLock sync.Mutex
 	outputLock sync.Mutex
 	fault      atomic.Value
 	queue      chan []*Block
+	health     *HealthChecker
 	OnStatus   func(status string)
+	Query      func(query string) (string, error)
 }
 
 func (e *Engine) UpdateEnv(key, val string) {
@@ -85,6 +88,9 @@ func (e *Engine) Init() (err error) {
 		return
 	}
 
+	e.health = &HealthChecker{}
+	e.health.Init(e)
+
 	e.python = &PythonEngine{}
 	err = e.python.Init(e)
 	if err != nil {
@@ -102,6 +108,7 @@ func (e *Engine) Init() (err error) {
 
 func (e *Engine) StartRunner() {
 	go e.runner()
+	e.health.Start()
 }
 
 func (e *Engine) getBlocks() (blocks []*Block) {
@@ -144,6 +151,8 @@ func (e *Engine) runner() {
 				"error": err,
 			}).Error("agent: Failed to run spec")
 			e.OnStatus(types.Fault)
+		} else if !e.health.Healthy() {
+			e.OnStatus(types.Unhealthy)
 		} else {
 			e.OnStatus(types.Running)
 		}
@@ -151,41 +160,35 @@ func (e *Engine) runner() {
 }
 
 func (e *Engine) Run(phase string, blocks []*Block) (fatal bool, err error) {
+	e.blocksLock.Lock()
+	e.blocks = blocks
+	e.blocksLock.Unlock()
+
+	if phase != PreStop && phase != Shutdown {
+		e.health.Update(blocks)
+	}
+
 	for i, block := range blocks {
-		switch phase {
-		case Initial:
-			break
-		case Reboot:
-			if block.Phase != Reboot && block.Phase != Reload {
-				continue
-			}
-			break
-		case Reload:
-			if block.Phase != Reload {
-				continue
-			}
-			break
+		if !matchPhase(phase, block) {
+			continue
 		}
 
-		err = e.runBlock(block.Type, block.Code)
+		err = e.runBlock(block)
 		if err != nil {
 			for _, block := range blocks[i:] {
+				if !matchPhase(phase, block) {
+					continue
+				}
+
 				switch phase {
 				case Initial:
 					if block.Phase != Reload {
 						fatal = true
 					}
 				case Reboot:
-					if block.Phase != Reboot && block.Phase != Reload {
-						continue
-					}
 					if block.Phase != Reload {
 						fatal = true
 					}
-				case Reload:
-					if block.Phase != Reload {
-						continue
-					}
 				}
 			}
 
@@ -199,15 +202,68 @@ func (e *Engine) Run(phase string, blocks []*Block) (fatal bool, err error) {
 	return
 }
 
-func (e *Engine) runBlock(blockType, block string) (err error) {
-	switch blockType {
-	case "shell":
-		err = e.bash.Run(block)
+func matchPhase(phase string, block *Block) bool {
+	if block.Type == Health {
+		return false
+	}
+
+	switch phase {
+	case Initial:
+		return block.Phase == Initial || block.Phase == Reboot ||
+			block.Phase == Reload
+	case Reboot:
+		return block.Phase == Reboot || block.Phase == Reload
+	default:
+		return block.Phase == phase
+	}
+}
+
+func (e *Engine) RunPhase(phase string) (err error) {
+	e.blocksLock.Lock()
+	blocks := e.blocks
+	e.blocksLock.Unlock()
+
+	for _, block := range blocks {
+		if !matchPhase(phase, block) {
+			continue
+		}
+
+		err = e.runBlock(block)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (e *Engine) HasPhase(phase string) bool {
+	e.blocksLock.Lock()
+	defer e.blocksLock.Unlock()
+
+	for _, block := range e.blocks {
+		if matchPhase(phase, block) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e *Engine) runBlock(block *Block) (err error) {
+	switch block.Type {
+	case Shell:
+		err = e.bash.Run(block.Code)
+		if err != nil {
+			return
+		}
+	case Python:
+		err = e.python.Run(block.Code)
 		if err != nil {
 			return
 		}
-	case "python":
-		err = e.python.Run(block)
+	case File:
+		err = e.writeFile(block)
 		if err != nil {
 			return
 		}
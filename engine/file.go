@@ -0,0 +1,191 @@
+package engine
+
+import (
+	"bytes"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+)
+
+func (e *Engine) templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"imds": func(query string) (val string, err error) {
+			if e.Query == nil {
+				err = &errortypes.RequestError{
+					errors.New("starter: Imds query unavailable"),
+				}
+				return
+			}
+
+			val, err = e.Query(query)
+			if err != nil {
+				return
+			}
+
+			return
+		},
+		"env": func(key string) string {
+			val := e.env[key]
+			if val == "" {
+				val = os.Getenv(key)
+			}
+			return val
+		},
+		"trim":   strings.TrimSpace,
+		"fields": strings.Fields,
+		"join":   strings.Join,
+	}
+}
+
+func (e *Engine) renderTemplate(block *Block) (data []byte, err error) {
+	tmpl, err := template.New(block.Path).Funcs(
+		e.templateFuncs()).Parse(block.Code)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrapf(err, "starter: Failed to parse template for %s",
+				block.Path),
+		}
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	err = tmpl.Execute(buf, nil)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrapf(err, "starter: Failed to render template for %s",
+				block.Path),
+		}
+		return
+	}
+
+	data = buf.Bytes()
+	return
+}
+
+func parseOwner(owner string) (uid, gid int, err error) {
+	uid = -1
+	gid = -1
+
+	if owner == "" {
+		return
+	}
+
+	ownerSpl := strings.SplitN(owner, ":", 2)
+
+	if ownerSpl[0] != "" {
+		usr, e := user.Lookup(ownerSpl[0])
+		if e != nil {
+			err = &errortypes.ReadError{
+				errors.Wrapf(e, "starter: Failed to find user %s",
+					ownerSpl[0]),
+			}
+			return
+		}
+
+		uid, _ = strconv.Atoi(usr.Uid)
+		if len(ownerSpl) == 1 {
+			gid, _ = strconv.Atoi(usr.Gid)
+		}
+	}
+
+	if len(ownerSpl) == 2 && ownerSpl[1] != "" {
+		grp, e := user.LookupGroup(ownerSpl[1])
+		if e != nil {
+			err = &errortypes.ReadError{
+				errors.Wrapf(e, "starter: Failed to find group %s",
+					ownerSpl[1]),
+			}
+			return
+		}
+
+		gid, _ = strconv.Atoi(grp.Gid)
+	}
+
+	return
+}
+
+func (e *Engine) writeFile(block *Block) (err error) {
+	pth := block.Path
+	if !filepath.IsAbs(pth) {
+		pth = filepath.Join(e.cwd, pth)
+	}
+	pth = filepath.Clean(pth)
+
+	mode := os.FileMode(DefaultFileMode)
+	if block.Mode != "" {
+		modeInt, e := strconv.ParseUint(block.Mode, 8, 32)
+		if e != nil || modeInt > 07777 {
+			err = &errortypes.ParseError{
+				errors.Newf("starter: Invalid file mode %s for %s",
+					block.Mode, pth),
+			}
+			return
+		}
+		mode = os.FileMode(modeInt)
+	}
+
+	uid, gid, err := parseOwner(block.Owner)
+	if err != nil {
+		return
+	}
+
+	data, err := e.renderTemplate(block)
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(pth), 0755)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrapf(err, "starter: Failed to create dir for %s", pth),
+		}
+		return
+	}
+
+	tmpPth := pth + ".pci-tmp"
+
+	err = os.WriteFile(tmpPth, data, mode)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrapf(err, "starter: Failed to write file %s", pth),
+		}
+		return
+	}
+	defer os.Remove(tmpPth)
+
+	err = os.Chmod(tmpPth, mode)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrapf(err, "starter: Failed to chmod file %s", pth),
+		}
+		return
+	}
+
+	if uid != -1 || gid != -1 {
+		err = os.Chown(tmpPth, uid, gid)
+		if err != nil {
+			err = &errortypes.WriteError{
+				errors.Wrapf(err, "starter: Failed to chown file %s", pth),
+			}
+			return
+		}
+	}
+
+	err = os.Rename(tmpPth, pth)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrapf(err, "starter: Failed to move file %s", pth),
+		}
+		return
+	}
+
+	e.ProcessOutput("Wrote file " + pth)
+
+	return
+}
@@ -0,0 +1,158 @@
+package engine
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/pritunl/pritunl-cloud/imds/types"
+)
+
+type healthCheck struct {
+	block    *Block
+	interval time.Duration
+	timeout  time.Duration
+	lastRun  time.Time
+	healthy  bool
+}
+
+type HealthChecker struct {
+	engine  *Engine
+	checks  []*healthCheck
+	healthy bool
+	started bool
+	lock    sync.Mutex
+}
+
+func (h *HealthChecker) Init(eng *Engine) {
+	h.engine = eng
+	h.healthy = true
+}
+
+func parseDuration(val string, def time.Duration) time.Duration {
+	if val == "" {
+		return def
+	}
+
+	dur, err := time.ParseDuration(val)
+	if err != nil {
+		secs, e := time.ParseDuration(val + "s")
+		if e != nil {
+			return def
+		}
+		dur = secs
+	}
+
+	if dur < time.Second {
+		return def
+	}
+
+	return dur
+}
+
+func (h *HealthChecker) Update(blocks []*Block) {
+	checks := []*healthCheck{}
+
+	for _, block := range blocks {
+		if block.Type != Health {
+			continue
+		}
+
+		checks = append(checks, &healthCheck{
+			block:    block,
+			interval: parseDuration(block.Interval, DefaultHealthInterval),
+			timeout:  parseDuration(block.Timeout, DefaultHealthTimeout),
+			healthy:  true,
+		})
+	}
+
+	h.lock.Lock()
+	h.checks = checks
+	h.lock.Unlock()
+}
+
+func (h *HealthChecker) Healthy() bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return h.healthy
+}
+
+func (h *HealthChecker) run(check *healthCheck) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), check.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, h.engine.bash.shell, "-c",
+		check.block.Code)
+	cmd.Env = h.engine.GetEnviron()
+	cmd.Dir = h.engine.GetCwd()
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if check.healthy {
+			h.engine.ProcessOutput("Health check failed: " + err.Error())
+			if len(output) > 0 {
+				h.engine.ProcessOutput(string(output))
+			}
+		}
+		return false
+	}
+
+	if !check.healthy {
+		h.engine.ProcessOutput("Health check passed")
+	}
+
+	return true
+}
+
+func (h *HealthChecker) check() {
+	h.lock.Lock()
+	checks := h.checks
+	h.lock.Unlock()
+
+	healthy := true
+	for _, check := range checks {
+		if time.Since(check.lastRun) >= check.interval {
+			check.lastRun = time.Now()
+			check.healthy = h.run(check)
+		}
+
+		if !check.healthy {
+			healthy = false
+		}
+	}
+
+	h.lock.Lock()
+	changed := h.healthy != healthy
+	h.healthy = healthy
+	h.lock.Unlock()
+
+	if !changed || h.engine.OnStatus == nil {
+		return
+	}
+
+	if !healthy {
+		h.engine.OnStatus(types.Unhealthy)
+	} else if h.engine.fault.Load().(bool) {
+		h.engine.OnStatus(types.Fault)
+	} else {
+		h.engine.OnStatus(types.Running)
+	}
+}
+
+func (h *HealthChecker) Start() {
+	h.lock.Lock()
+	if h.started {
+		h.lock.Unlock()
+		return
+	}
+	h.started = true
+	h.lock.Unlock()
+
+	go func() {
+		for {
+			h.check()
+			time.Sleep(1 * time.Second)
+		}
+	}()
+}
@@ -6,17 +6,29 @@ import (
 )
 
 type Block struct {
-	Type    string
-	Phase   string
-	Code    string
-	LineNum int
+	Type     string
+	Phase    string
+	Code     string
+	Path     string
+	Mode     string
+	Owner    string
+	Interval string
+	Timeout  string
+	LineNum  int
 }
 
 const (
-	Initial = "initial"
-	Reboot  = "reboot"
-	Reload  = "reload"
-	Image   = "image"
+	Initial  = "initial"
+	Reboot   = "reboot"
+	Reload   = "reload"
+	PreStop  = "pre-stop"
+	Shutdown = "shutdown"
+	Image    = "image"
+
+	Shell  = "shell"
+	Python = "python"
+	File   = "file"
+	Health = "health"
 )
 
 var (
@@ -42,20 +54,42 @@ func Parse(data string) (blocks []*Block, err error) {
 						phase = Reboot
 					case Reload:
 						phase = Reload
+					case PreStop:
+						phase = PreStop
+					case Shutdown:
+						phase = Shutdown
 					}
 				}
 
 				switch lang {
-				case "shell":
+				case Shell:
 					curBlock = &Block{
-						Type:  "shell",
+						Type:  Shell,
 						Phase: phase,
 					}
-				case "python":
+				case Python:
 					curBlock = &Block{
-						Type:  "python",
+						Type:  Python,
 						Phase: phase,
 					}
+				case File:
+					if attrs["path"] == "" {
+						break
+					}
+
+					curBlock = &Block{
+						Type:  File,
+						Phase: phase,
+						Path:  attrs["path"],
+						Mode:  attrs["mode"],
+						Owner: attrs["owner"],
+					}
+				case Health:
+					curBlock = &Block{
+						Type:     Health,
+						Interval: attrs["interval"],
+						Timeout:  attrs["timeout"],
+					}
 				}
 			}
 		} else {
@@ -0,0 +1,8 @@
+package envelope
+
+const (
+	Prefix    = "$env1$"
+	EnvKey    = "PRITUNL_CLOUD_MASTER_KEY"
+	KeySize   = 32
+	cacheSize = 4096
+)
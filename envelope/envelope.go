@@ -0,0 +1,279 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"strings"
+	"sync"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+var (
+	provider     Provider
+	providerLock sync.RWMutex
+	current      *dataKey
+	cache        = map[string][]byte{}
+	cacheLock    sync.Mutex
+)
+
+type dataKey struct {
+	keyId   string
+	key     []byte
+	wrapped string
+}
+
+func SetProvider(prov Provider) {
+	providerLock.Lock()
+	provider = prov
+	current = nil
+	providerLock.Unlock()
+
+	cacheLock.Lock()
+	cache = map[string][]byte{}
+	cacheLock.Unlock()
+}
+
+func GetProvider() Provider {
+	providerLock.RLock()
+	defer providerLock.RUnlock()
+	return provider
+}
+
+func Enabled() bool {
+	return GetProvider() != nil
+}
+
+func IsSealed(val string) bool {
+	return strings.HasPrefix(val, Prefix)
+}
+
+func KeyId(val string) string {
+	if !IsSealed(val) {
+		return ""
+	}
+
+	parts := strings.SplitN(val[len(Prefix):], "$", 3)
+	if len(parts) != 3 {
+		return ""
+	}
+
+	return parts[0]
+}
+
+// DeriveKey returns a key derived from the master key for the purpose, the
+// current master key is used when the key id is empty. Supported is false
+// when no master key is loaded or the provider cannot derive keys
+func DeriveKey(keyId, purpose string) (outKeyId string, key []byte,
+	supported bool, err error) {
+
+	prov := GetProvider()
+	if prov == nil {
+		return
+	}
+
+	deriver, ok := prov.(Deriver)
+	if !ok {
+		return
+	}
+	supported = true
+
+	if keyId == "" {
+		keyId = prov.KeyId()
+	}
+
+	key, err = deriver.Derive(keyId, purpose)
+	if err != nil {
+		return
+	}
+	outKeyId = keyId
+
+	return
+}
+
+func getDataKey() (dk *dataKey, err error) {
+	providerLock.Lock()
+	defer providerLock.Unlock()
+
+	if provider == nil {
+		err = &errortypes.ReadError{
+			errors.New("envelope: Master key not loaded"),
+		}
+		return
+	}
+
+	if current != nil && current.keyId == provider.KeyId() {
+		dk = current
+		return
+	}
+
+	key, err := utils.RandBytes(KeySize)
+	if err != nil {
+		return
+	}
+
+	keyId, wrapped, err := provider.Wrap(key)
+	if err != nil {
+		return
+	}
+
+	dk = &dataKey{
+		keyId:   keyId,
+		key:     key,
+		wrapped: base64.RawStdEncoding.EncodeToString(wrapped),
+	}
+	current = dk
+
+	return
+}
+
+func unwrapKey(keyId, wrapped string) (key []byte, err error) {
+	cacheKey := keyId + "$" + wrapped
+
+	cacheLock.Lock()
+	key = cache[cacheKey]
+	cacheLock.Unlock()
+	if key != nil {
+		return
+	}
+
+	prov := GetProvider()
+	if prov == nil {
+		err = &errortypes.ReadError{
+			errors.New("envelope: Master key not loaded"),
+		}
+		return
+	}
+
+	wrappedByt, err := base64.RawStdEncoding.DecodeString(wrapped)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "envelope: Failed to decode wrapped key"),
+		}
+		return
+	}
+
+	key, err = prov.Unwrap(keyId, wrappedByt)
+	if err != nil {
+		return
+	}
+
+	cacheLock.Lock()
+	if len(cache) >= cacheSize {
+		cache = map[string][]byte{}
+	}
+	cache[cacheKey] = key
+	cacheLock.Unlock()
+
+	return
+}
+
+func Seal(val string) (sealed string, err error) {
+	if val == "" || IsSealed(val) {
+		sealed = val
+		return
+	}
+
+	dk, err := getDataKey()
+	if err != nil {
+		return
+	}
+
+	gcm, err := newGcm(dk.key)
+	if err != nil {
+		return
+	}
+
+	nonce, err := utils.RandBytes(gcm.NonceSize())
+	if err != nil {
+		return
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, []byte(val), nil)
+
+	sealed = Prefix + dk.keyId + "$" + dk.wrapped + "$" +
+		base64.RawStdEncoding.EncodeToString(ciphertext)
+
+	return
+}
+
+func Open(val string) (plain string, err error) {
+	if !IsSealed(val) {
+		plain = val
+		return
+	}
+
+	parts := strings.SplitN(val[len(Prefix):], "$", 3)
+	if len(parts) != 3 {
+		err = &errortypes.ParseError{
+			errors.New("envelope: Invalid sealed value"),
+		}
+		return
+	}
+
+	key, err := unwrapKey(parts[0], parts[1])
+	if err != nil {
+		return
+	}
+
+	ciphertext, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "envelope: Failed to decode sealed value"),
+		}
+		return
+	}
+
+	gcm, err := newGcm(key)
+	if err != nil {
+		return
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		err = &errortypes.ParseError{
+			errors.New("envelope: Sealed value too short"),
+		}
+		return
+	}
+
+	plainByt, err := gcm.Open(nil, ciphertext[:gcm.NonceSize()],
+		ciphertext[gcm.NonceSize():], nil)
+	if err != nil {
+		err = &errortypes.AuthenticationError{
+			errors.Wrap(err, "envelope: Failed to open sealed value"),
+		}
+		return
+	}
+
+	plain = string(plainByt)
+
+	return
+}
+
+// Reseal returns the value sealed with the current master key, or an
+// empty string if it is already current.
+func Reseal(val string) (sealed string, err error) {
+	prov := GetProvider()
+	if prov == nil || val == "" {
+		return
+	}
+
+	if IsSealed(val) {
+		if KeyId(val) == prov.KeyId() {
+			return
+		}
+
+		val, err = Open(val)
+		if err != nil {
+			return
+		}
+	}
+
+	sealed, err = Seal(val)
+	if err != nil {
+		return
+	}
+
+	return
+}
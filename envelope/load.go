@@ -0,0 +1,175 @@
+package envelope
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/config"
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/requires"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
+)
+
+func GetKeyPath() string {
+	if config.Config.MasterKeyPath != "" {
+		return config.Config.MasterKeyPath
+	}
+	return path.Join(constants.DefaultRoot, "master.key")
+}
+
+// ParseKeys parses a list of id:base64 master keys separated by commas
+// or newlines. The first key is used for new values.
+func ParseKeys(data string) (prov *LocalProvider, err error) {
+	prov = &LocalProvider{
+		keys: map[string][]byte{},
+	}
+
+	data = strings.ReplaceAll(data, "\n", ",")
+	for _, item := range strings.Split(data, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" || strings.HasPrefix(item, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(item, ":", 2)
+		if len(parts) != 2 {
+			err = &errortypes.ParseError{
+				errors.New("envelope: Invalid master key format"),
+			}
+			return
+		}
+
+		keyId := utils.FilterId(parts[0])
+		if keyId == "" || keyId != parts[0] || strings.Contains(keyId, "$") {
+			err = &errortypes.ParseError{
+				errors.New("envelope: Invalid master key id"),
+			}
+			return
+		}
+
+		key, e := base64.StdEncoding.DecodeString(parts[1])
+		if e != nil {
+			err = &errortypes.ParseError{
+				errors.Wrap(e, "envelope: Failed to decode master key"),
+			}
+			return
+		}
+
+		if len(key) != KeySize {
+			err = &errortypes.ParseError{
+				errors.Newf("envelope: Master key '%s' must be %d bytes",
+					keyId, KeySize),
+			}
+			return
+		}
+
+		if prov.keys[keyId] != nil {
+			err = &errortypes.ParseError{
+				errors.Newf("envelope: Duplicate master key '%s'", keyId),
+			}
+			return
+		}
+
+		if prov.current == "" {
+			prov.current = keyId
+		}
+		prov.keys[keyId] = key
+	}
+
+	if prov.current == "" {
+		prov = nil
+	}
+
+	return
+}
+
+func GenerateKey() (key string, err error) {
+	keyId, err := utils.RandStr(8)
+	if err != nil {
+		return
+	}
+
+	keyByt, err := utils.RandBytes(KeySize)
+	if err != nil {
+		return
+	}
+
+	key = fmt.Sprintf("%s:%s", strings.ToLower(keyId),
+		base64.StdEncoding.EncodeToString(keyByt))
+
+	return
+}
+
+func Load() (err error) {
+	data := os.Getenv(EnvKey)
+	source := "environment"
+
+	if data == "" {
+		keyPath := GetKeyPath()
+		source = keyPath
+
+		exists, e := utils.Exists(keyPath)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if !exists {
+			return
+		}
+
+		dataByt, e := ioutil.ReadFile(keyPath)
+		if e != nil {
+			err = &errortypes.ReadError{
+				errors.Wrap(e, "envelope: Failed to read master key file"),
+			}
+			return
+		}
+		data = string(dataByt)
+	}
+
+	prov, err := ParseKeys(data)
+	if err != nil {
+		return
+	}
+
+	if prov == nil {
+		return
+	}
+
+	SetProvider(prov)
+
+	logrus.WithFields(logrus.Fields{
+		"source": source,
+		"key_id": prov.KeyId(),
+		"keys":   len(prov.keys),
+	}).Info("envelope: Loaded master key")
+
+	return
+}
+
+func init() {
+	module := requires.New("envelope")
+	module.After("config")
+	module.Before("settings")
+
+	module.Handler = func() (err error) {
+		if GetProvider() != nil {
+			return
+		}
+
+		err = Load()
+		if err != nil {
+			return
+		}
+
+		return
+	}
+}
@@ -0,0 +1,129 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+// Provider wraps data encryption keys with a master key. External key
+// management services can be used by registering a Provider with
+// SetProvider before the database is loaded.
+type Provider interface {
+	KeyId() string
+	Wrap(dek []byte) (keyId string, wrapped []byte, err error)
+	Unwrap(keyId string, wrapped []byte) (dek []byte, err error)
+}
+
+// Deriver is implemented by providers that can derive keys from the
+// master key for uses other than wrapping data keys
+type Deriver interface {
+	Derive(keyId, purpose string) (key []byte, err error)
+}
+
+type LocalProvider struct {
+	current string
+	keys    map[string][]byte
+}
+
+func (p *LocalProvider) KeyId() string {
+	return p.current
+}
+
+func (p *LocalProvider) Wrap(dek []byte) (
+	keyId string, wrapped []byte, err error) {
+
+	keyId = p.current
+
+	gcm, err := newGcm(p.keys[keyId])
+	if err != nil {
+		return
+	}
+
+	nonce, err := utils.RandBytes(gcm.NonceSize())
+	if err != nil {
+		return
+	}
+
+	wrapped = gcm.Seal(nonce, nonce, dek, []byte(keyId))
+
+	return
+}
+
+func (p *LocalProvider) Unwrap(keyId string, wrapped []byte) (
+	dek []byte, err error) {
+
+	key := p.keys[keyId]
+	if key == nil {
+		err = &errortypes.NotFoundError{
+			errors.Newf("envelope: Unknown master key '%s'", keyId),
+		}
+		return
+	}
+
+	gcm, err := newGcm(key)
+	if err != nil {
+		return
+	}
+
+	if len(wrapped) < gcm.NonceSize() {
+		err = &errortypes.ParseError{
+			errors.New("envelope: Wrapped key too short"),
+		}
+		return
+	}
+
+	dek, err = gcm.Open(nil, wrapped[:gcm.NonceSize()],
+		wrapped[gcm.NonceSize():], []byte(keyId))
+	if err != nil {
+		err = &errortypes.AuthenticationError{
+			errors.Wrap(err, "envelope: Failed to unwrap key"),
+		}
+		return
+	}
+
+	return
+}
+
+func (p *LocalProvider) Derive(keyId, purpose string) (
+	key []byte, err error) {
+
+	master := p.keys[keyId]
+	if master == nil {
+		err = &errortypes.NotFoundError{
+			errors.Newf("envelope: Unknown master key '%s'", keyId),
+		}
+		return
+	}
+
+	mac := hmac.New(sha256.New, master)
+	mac.Write([]byte(purpose))
+	key = mac.Sum(nil)
+
+	return
+}
+
+func newGcm(key []byte) (gcm cipher.AEAD, err error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "envelope: Failed to load cipher"),
+		}
+		return
+	}
+
+	gcm, err = cipher.NewGCM(block)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "envelope: Failed to load gcm"),
+		}
+		return
+	}
+
+	return
+}
@@ -0,0 +1,222 @@
+package envelope
+
+import (
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/sirupsen/logrus"
+)
+
+type field struct {
+	Collection func(db *database.Database) *database.Collection
+	Query      bson.M
+	Key        string
+	Path       []string
+}
+
+var fields = []*field{
+	{
+		Collection: (*database.Database).Secrets,
+		Query:      bson.M{},
+		Key:        "value",
+	},
+	{
+		Collection: (*database.Database).Secrets,
+		Query:      bson.M{},
+		Key:        "private_key",
+	},
+	{
+		Collection: (*database.Database).Certificates,
+		Query:      bson.M{},
+		Key:        "key",
+	},
+	{
+		Collection: (*database.Database).Nodes,
+		Query:      bson.M{},
+		Key:        "oracle_private_key",
+	},
+	{
+		Collection: (*database.Database).Storages,
+		Query:      bson.M{},
+		Key:        "secret_key",
+	},
+	{
+		Collection: (*database.Database).Authorities,
+		Query:      bson.M{},
+		Key:        "private_key",
+	},
+	{
+		Collection: (*database.Database).SshHostKeys,
+		Query:      bson.M{},
+		Key:        "private_key",
+	},
+	{
+		Collection: (*database.Database).LogSinks,
+		Query:      bson.M{},
+		Key:        "password",
+	},
+	{
+		Collection: (*database.Database).LogSinks,
+		Query:      bson.M{},
+		Key:        "token",
+	},
+	{
+		Collection: (*database.Database).Settings,
+		Query: bson.M{
+			"_id": "auth",
+		},
+		Key:  "providers",
+		Path: []string{"client_secret"},
+	},
+}
+
+func resealValue(val interface{}, pth []string) (
+	newVal interface{}, changed bool, err error) {
+
+	newVal = val
+
+	if len(pth) == 0 {
+		str, ok := val.(string)
+		if !ok {
+			return
+		}
+
+		sealed, e := Reseal(str)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if sealed != "" {
+			newVal = sealed
+			changed = true
+		}
+		return
+	}
+
+	switch v := val.(type) {
+	case bson.A:
+		for i, item := range v {
+			itemVal, itemChanged, e := resealValue(item, pth)
+			if e != nil {
+				err = e
+				return
+			}
+			if itemChanged {
+				v[i] = itemVal
+				changed = true
+			}
+		}
+		break
+	case bson.D:
+		for i, elem := range v {
+			if elem.Key != pth[0] {
+				continue
+			}
+
+			elemVal, elemChanged, e := resealValue(elem.Value, pth[1:])
+			if e != nil {
+				err = e
+				return
+			}
+			if elemChanged {
+				v[i].Value = elemVal
+				changed = true
+			}
+		}
+		break
+	case bson.M:
+		elemVal, elemChanged, e := resealValue(v[pth[0]], pth[1:])
+		if e != nil {
+			err = e
+			return
+		}
+		if elemChanged {
+			v[pth[0]] = elemVal
+			changed = true
+		}
+		break
+	}
+
+	return
+}
+
+func (f *field) reseal(db *database.Database) (count int, err error) {
+	coll := f.Collection(db)
+
+	cursor, err := coll.Find(db, f.Query)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		doc := bson.M{}
+		err = cursor.Decode(&doc)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		val, ok := doc[f.Key]
+		if !ok || val == nil {
+			continue
+		}
+
+		newVal, changed, e := resealValue(val, f.Path)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"collection": coll.Name(),
+				"id":         doc["_id"],
+				"field":      f.Key,
+				"error":      e,
+			}).Error("envelope: Failed to reseal field")
+			continue
+		}
+
+		if !changed {
+			continue
+		}
+
+		_, err = coll.UpdateOne(db, &bson.M{
+			"_id": doc["_id"],
+		}, &bson.M{
+			"$set": &bson.M{
+				f.Key: newVal,
+			},
+		})
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		count += 1
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Rotate encrypts all protected fields with the current master key,
+// including values stored before encryption was enabled.
+func Rotate(db *database.Database) (count int, err error) {
+	if !Enabled() {
+		return
+	}
+
+	for _, f := range fields {
+		n, e := f.reseal(db)
+		if e != nil {
+			err = e
+			return
+		}
+		count += n
+	}
+
+	return
+}
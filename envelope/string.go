@@ -0,0 +1,60 @@
+package envelope
+
+import (
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+)
+
+// String is stored encrypted in the database when a master key is loaded
+// and transparently decrypted when read.
+type String string
+
+func (s String) MarshalBSONValue() (typ byte, data []byte, err error) {
+	val := string(s)
+
+	if Enabled() {
+		val, err = Seal(val)
+		if err != nil {
+			return
+		}
+	}
+
+	t, data, err := bson.MarshalValue(val)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "envelope: Failed to marshal value"),
+		}
+		return
+	}
+	typ = byte(t)
+
+	return
+}
+
+func (s *String) UnmarshalBSONValue(typ byte, data []byte) (err error) {
+	if bson.Type(typ) == bson.TypeNull ||
+		bson.Type(typ) == bson.TypeUndefined {
+
+		*s = ""
+		return
+	}
+
+	val := ""
+	err = bson.UnmarshalValue(bson.Type(typ), data, &val)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "envelope: Failed to unmarshal value"),
+		}
+		return
+	}
+
+	val, err = Open(val)
+	if err != nil {
+		return
+	}
+
+	*s = String(val)
+
+	return
+}
@@ -185,12 +185,14 @@ func (l *Listener) sub(cursorId bson.ObjectID) {
 	}
 }
 
-func (l *Listener) init() (err error) {
-	coll := l.db.Events()
-	cursorId, err := getCursorId(l.db, coll, l.channels)
-	if err != nil {
-		err = database.ParseError(err)
-		return
+func (l *Listener) init(cursorId bson.ObjectID) (err error) {
+	if cursorId.IsZero() {
+		coll := l.db.Events()
+		cursorId, err = getCursorId(l.db, coll, l.channels)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
 	}
 
 	l.state = true
@@ -216,7 +218,25 @@ func SubscribeListener(db *database.Database, channels []string) (
 		stream:   make(chan *Event, 10),
 	}
 
-	err = lst.init()
+	err = lst.init(bson.NilObjectID)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// SubscribeListenerCursor resumes a listener after the cursor event
+func SubscribeListenerCursor(db *database.Database, channels []string,
+	cursorId bson.ObjectID) (lst *Listener, err error) {
+
+	lst = &Listener{
+		db:       db,
+		channels: channels,
+		stream:   make(chan *Event, 10),
+	}
+
+	err = lst.init(cursorId)
 	if err != nil {
 		return
 	}
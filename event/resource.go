@@ -0,0 +1,72 @@
+package event
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+)
+
+const (
+	InstanceState    = "instance.state"
+	DeploymentState  = "deployment.state"
+	DeploymentStatus = "deployment.status"
+	DiskState        = "disk.state"
+	AlertFired       = "alert.fired"
+	StreamReset      = "stream.reset"
+
+	resourceChannel = "resource"
+)
+
+type Resource struct {
+	Type         string            `bson:"type" json:"type"`
+	Organization bson.ObjectID     `bson:"organization" json:"organization"`
+	Resource     bson.ObjectID     `bson:"resource" json:"resource"`
+	State        string            `bson:"state" json:"state"`
+	Fields       map[string]string `bson:"fields,omitempty" json:"fields,omitempty"`
+}
+
+type ResourceEvent struct {
+	Id        bson.ObjectID `json:"id"`
+	Timestamp time.Time     `json:"timestamp"`
+	*Resource
+}
+
+func PublishResource(db *database.Database, typ string,
+	orgId, resourceId bson.ObjectID, state string,
+	fields map[string]string) (err error) {
+
+	err = Publish(db, resourceChannel, &Resource{
+		Type:         typ,
+		Organization: orgId,
+		Resource:     resourceId,
+		State:        state,
+		Fields:       fields,
+	})
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func parseResource(evt *Event) (resEvt *ResourceEvent, err error) {
+	data, err := bson.Marshal(evt.Data)
+	if err != nil {
+		return
+	}
+
+	res := &Resource{}
+	err = bson.Unmarshal(data, res)
+	if err != nil {
+		return
+	}
+
+	resEvt = &ResourceEvent{
+		Id:        evt.Id,
+		Timestamp: evt.Timestamp,
+		Resource:  res,
+	}
+
+	return
+}
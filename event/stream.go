@@ -0,0 +1,336 @@
+package event
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gorilla/websocket"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	streamWriteTimeout = 10 * time.Second
+	streamPingInterval = 30 * time.Second
+	streamPingWait     = 40 * time.Second
+)
+
+// Stream serves resource events to external clients, a zero organization
+// streams events for all organizations
+type Stream struct {
+	Organization bson.ObjectID
+	Types        set.Set
+	Resources    set.Set
+	Cursor       bson.ObjectID
+}
+
+func (s *Stream) Match(evt *ResourceEvent) bool {
+	if !s.Organization.IsZero() && evt.Organization != s.Organization {
+		return false
+	}
+
+	if s.Types != nil && s.Types.Len() > 0 && !s.Types.Contains(evt.Type) {
+		return false
+	}
+
+	if s.Resources != nil && s.Resources.Len() > 0 &&
+		!s.Resources.Contains(evt.Resource.Resource) {
+
+		return false
+	}
+
+	return true
+}
+
+func (s *Stream) listen(db *database.Database) (
+	lst *Listener, reset bool, err error) {
+
+	cursor := s.Cursor
+
+	if !cursor.IsZero() {
+		coll := db.Events()
+
+		err = coll.FindOneId(cursor, &Event{})
+		if err != nil {
+			if _, ok := err.(*database.NotFoundError); ok {
+				reset = true
+				cursor = bson.NilObjectID
+				err = nil
+			} else {
+				return
+			}
+		}
+	}
+
+	lst, err = SubscribeListenerCursor(
+		db, []string{resourceChannel}, cursor)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (s *Stream) resetEvent() *ResourceEvent {
+	return &ResourceEvent{
+		Timestamp: time.Now(),
+		Resource: &Resource{
+			Type: StreamReset,
+		},
+	}
+}
+
+func (s *Stream) Serve(db *database.Database, w http.ResponseWriter,
+	r *http.Request) (err error) {
+
+	lst, reset, err := s.listen(db)
+	if err != nil {
+		return
+	}
+
+	if strings.ToLower(r.Header.Get("Upgrade")) == "websocket" {
+		err = s.serveWebSocket(w, r, lst, reset)
+	} else {
+		err = s.serveSse(w, r, lst, reset)
+	}
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (s *Stream) serveSse(w http.ResponseWriter, r *http.Request,
+	lst *Listener, reset bool) (err error) {
+
+	defer lst.Close()
+
+	ctrl := http.NewResponseController(w)
+
+	header := w.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(200)
+
+	write := func(data []byte) (err error) {
+		_ = ctrl.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+
+		_, err = w.Write(data)
+		if err != nil {
+			err = &errortypes.RequestError{
+				errors.Wrap(err, "event: Failed to write stream"),
+			}
+			return
+		}
+
+		err = ctrl.Flush()
+		if err != nil {
+			err = &errortypes.RequestError{
+				errors.Wrap(err, "event: Failed to flush stream"),
+			}
+			return
+		}
+
+		return
+	}
+
+	writeEvent := func(evt *ResourceEvent) (err error) {
+		data, err := json.Marshal(evt)
+		if err != nil {
+			err = &errortypes.ParseError{
+				errors.Wrap(err, "event: Failed to marshal event"),
+			}
+			return
+		}
+
+		buf := &bytes.Buffer{}
+		if !evt.Id.IsZero() {
+			fmt.Fprintf(buf, "id: %s\n", evt.Id.Hex())
+		}
+		fmt.Fprintf(buf, "event: %s\ndata: %s\n\n", evt.Type, data)
+
+		err = write(buf.Bytes())
+		if err != nil {
+			return
+		}
+
+		return
+	}
+
+	if reset {
+		err = writeEvent(s.resetEvent())
+		if err != nil {
+			return
+		}
+	} else {
+		err = write([]byte(":\n\n"))
+		if err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(streamPingInterval)
+	defer ticker.Stop()
+	sub := lst.Listen()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-sub:
+			if !ok {
+				return
+			}
+
+			evt, e := parseResource(msg)
+			if e != nil || !s.Match(evt) {
+				continue
+			}
+
+			err = writeEvent(evt)
+			if err != nil {
+				return
+			}
+		case <-ticker.C:
+			err = write([]byte(":\n\n"))
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Stream) serveWebSocket(w http.ResponseWriter, r *http.Request,
+	lst *Listener, reset bool) (err error) {
+
+	socket := &WebSocket{
+		Listener: lst,
+	}
+
+	defer func() {
+		socket.Close()
+		WebSocketsLock.Lock()
+		WebSockets.Remove(socket)
+		WebSocketsLock.Unlock()
+	}()
+
+	WebSocketsLock.Lock()
+	WebSockets.Add(socket)
+	WebSocketsLock.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	socket.Cancel = cancel
+
+	conn, err := Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "event: Failed to upgrade request"),
+		}
+		return
+	}
+	socket.Conn = conn
+
+	err = conn.SetReadDeadline(time.Now().Add(streamPingWait))
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "event: Failed to set read deadline"),
+		}
+		return
+	}
+
+	conn.SetPongHandler(func(x string) error {
+		return conn.SetReadDeadline(time.Now().Add(streamPingWait))
+	})
+
+	ticker := time.NewTicker(streamPingInterval)
+	socket.Ticker = ticker
+	sub := lst.Listen()
+
+	go func() {
+		defer func() {
+			r := recover()
+			if r != nil && !socket.Closed {
+				logrus.WithFields(logrus.Fields{
+					"error": errors.New(fmt.Sprintf("%s", r)),
+				}).Error("event: Stream panic")
+			}
+		}()
+		for {
+			_, _, err := conn.NextReader()
+			if err != nil {
+				cancel()
+				conn.Close()
+				break
+			}
+		}
+	}()
+
+	writeEvent := func(evt *ResourceEvent) (err error) {
+		err = conn.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if err != nil {
+			err = &errortypes.RequestError{
+				errors.Wrap(err, "event: Failed to set write deadline"),
+			}
+			return
+		}
+
+		err = conn.WriteJSON(evt)
+		if err != nil {
+			err = &errortypes.RequestError{
+				errors.Wrap(err, "event: Failed to write json"),
+			}
+			return
+		}
+
+		return
+	}
+
+	if reset {
+		err = writeEvent(s.resetEvent())
+		if err != nil {
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-sub:
+			if !ok {
+				_ = conn.WriteControl(websocket.CloseMessage, []byte{},
+					time.Now().Add(streamWriteTimeout))
+				return
+			}
+
+			evt, e := parseResource(msg)
+			if e != nil || !s.Match(evt) {
+				continue
+			}
+
+			err = writeEvent(evt)
+			if err != nil {
+				return
+			}
+		case <-ticker.C:
+			err = conn.WriteControl(websocket.PingMessage, []byte{},
+				time.Now().Add(streamWriteTimeout))
+			if err != nil {
+				err = &errortypes.RequestError{
+					errors.Wrap(err, "event: Failed to write ping"),
+				}
+				return
+			}
+		}
+	}
+}
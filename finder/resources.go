@@ -6,6 +6,7 @@ import (
 
 	"github.com/dropbox/godropbox/errors"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/catalog"
 	"github.com/pritunl/pritunl-cloud/certificate"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/datacenter"
@@ -49,6 +50,51 @@ type Resources struct {
 var tokenRe = regexp.MustCompile(
 	`\+\/([a-zA-Z0-9-]*)\/([a-zA-Z0-9-_.]*)(?:(?:\/|\:)([a-zA-Z0-9-_.]*)(?:\/([a-zA-Z0-9-_.]*))?)?`)
 
+func FindTokens(data string) []string {
+	return tokenRe.FindAllString(data, -1)
+}
+
+func (r *Resources) Found(kind string) bool {
+	switch kind {
+	case DomainKind:
+		return r.Domain != nil
+	case VpcKind:
+		return r.Vpc != nil
+	case SubnetKind:
+		return r.Subnet != nil
+	case DatacenterKind:
+		return r.Datacenter != nil
+	case NodeKind:
+		return r.Node != nil
+	case PoolKind:
+		return r.Pool != nil
+	case ZoneKind:
+		return r.Zone != nil
+	case ShapeKind:
+		return r.Shape != nil
+	case DiskKind:
+		return len(r.Disks) > 0
+	case ImageKind:
+		return r.Image != nil
+	case BuildKind:
+		return r.Deployment != nil
+	case InstanceKind:
+		return r.Instance != nil
+	case PlanKind:
+		return r.Plan != nil
+	case CertificateKind:
+		return r.Certificate != nil
+	case SecretKind:
+		return r.Secret != nil
+	case PodKind:
+		return r.Pod != nil
+	case UnitKind:
+		return r.Unit != nil
+	default:
+		return false
+	}
+}
+
 func (r *Resources) Find(db *database.Database, token string) (
 	kind string, err error) {
 
@@ -185,7 +231,7 @@ func (r *Resources) Find(db *database.Database, token string) (
 		}
 		break
 	case ImageKind:
-		if image.Releases.Contains(resource) {
+		if catalog.IsRelease(resource) {
 			imgs, e := image.GetAll(db, &bson.M{
 				"release": resource,
 				"organization": &bson.M{
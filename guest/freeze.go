@@ -0,0 +1,131 @@
+package guest
+
+import (
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/paths"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type freezeResponse struct {
+	Return int                    `json:"return"`
+	Error  map[string]interface{} `json:"error,omitempty"`
+}
+
+func freezeCommand(vmId bson.ObjectID, execute string) (
+	count int, err error) {
+
+	lockId := socketsLock.Lock(vmId.Hex())
+	defer socketsLock.Unlock(vmId.Hex(), lockId)
+
+	sockPath := paths.GetGuestPath(vmId)
+
+	exists, err := utils.Exists(sockPath)
+	if err != nil {
+		return
+	}
+
+	if !exists {
+		err = &errortypes.ReadError{
+			errors.New("guest: Guest agent socket missing"),
+		}
+		return
+	}
+
+	conn, err := net.DialTimeout(
+		"unix",
+		sockPath,
+		3*time.Second,
+	)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "guest: Failed to open socket"),
+		}
+		return
+	}
+	defer conn.Close()
+
+	err = conn.SetDeadline(time.Now().Add(30 * time.Second))
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "guest: Failed set deadline"),
+		}
+		return
+	}
+
+	cmd := Command{
+		Execute: execute,
+	}
+
+	cmdData, err := json.Marshal(cmd)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "guest: Failed to marshal socket data"),
+		}
+		return
+	}
+
+	cmdData = append(cmdData, '\n')
+
+	_, err = conn.Write(cmdData)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "guest: Failed to write socket"),
+		}
+		return
+	}
+
+	buffer := make([]byte, 8192)
+	n, err := conn.Read(buffer)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "guest: Failed to read socket"),
+		}
+		return
+	}
+
+	response := &freezeResponse{}
+	err = json.Unmarshal(buffer[:n], response)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "guest: Failed to parse socket data"),
+		}
+		return
+	}
+
+	if response.Error != nil {
+		err = &errortypes.ReadError{
+			errors.Newf("guest: Guest returned error %v", response.Error),
+		}
+		return
+	}
+
+	count = response.Return
+
+	return
+}
+
+// FsFreeze flushes and freezes the guest filesystems, the guest must be
+// thawed with FsThaw
+func FsFreeze(vmId bson.ObjectID) (count int, err error) {
+	count, err = freezeCommand(vmId, "guest-fsfreeze-freeze")
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func FsThaw(vmId bson.ObjectID) (count int, err error) {
+	count, err = freezeCommand(vmId, "guest-fsfreeze-thaw")
+	if err != nil {
+		return
+	}
+
+	return
+}
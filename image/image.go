@@ -8,6 +8,7 @@ import (
 	"github.com/dropbox/godropbox/errors"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
+	"github.com/pritunl/pritunl-cloud/catalog"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/deployment"
 	"github.com/pritunl/pritunl-cloud/errortypes"
@@ -28,6 +29,8 @@ type Image struct {
 	SystemType   string        `bson:"system_type" json:"system_type"`
 	SystemKind   string        `bson:"system_kind" json:"system_kind"`
 	Firmware     string        `bson:"firmware" json:"firmware"`
+	CloudInit    string        `bson:"cloud_init" json:"cloud_init"`
+	DefaultUser  string        `bson:"default_user" json:"default_user"`
 	Storage      bson.ObjectID `bson:"storage" json:"storage"`
 	Key          string        `bson:"key" json:"key"`
 	LastModified time.Time     `bson:"last_modified" json:"last_modified"`
@@ -71,7 +74,7 @@ func (i *Image) Validate(db *database.Database) (
 		return
 	}
 
-	if i.SystemKind != "" && !ValidSystemKinds.Contains(i.SystemKind) {
+	if i.SystemKind != "" && !catalog.ValidSystemKind(i.SystemKind) {
 		errData = &errortypes.ErrorData{
 			Error:   "invalid_system_kind",
 			Message: "Image system kind invalid",
@@ -79,6 +82,22 @@ func (i *Image) Validate(db *database.Database) (
 		return
 	}
 
+	if i.CloudInit != "" && !catalog.ValidCloudInits.Contains(i.CloudInit) {
+		errData = &errortypes.ErrorData{
+			Error:   "invalid_cloud_init",
+			Message: "Image cloud init type invalid",
+		}
+		return
+	}
+
+	if i.DefaultUser != "" && !catalog.ValidDefaultUser(i.DefaultUser) {
+		errData = &errortypes.ErrorData{
+			Error:   "invalid_default_user",
+			Message: "Image default user invalid",
+		}
+		return
+	}
+
 	return
 }
 
@@ -121,6 +140,30 @@ func (i *Image) GetSystemType() string {
 	return Linux
 }
 
+// GetCloudInit returns the cloud init flavor from the catalog release or
+// the flavor matching the system type
+func (i *Image) GetCloudInit() string {
+	if i.CloudInit != "" {
+		return i.CloudInit
+	}
+
+	switch i.GetSystemType() {
+	case Bsd:
+		return Bsd
+	case LinuxLegacy:
+		return LinuxLegacy
+	default:
+		return Linux
+	}
+}
+
+func (i *Image) GetDefaultUser() string {
+	if i.DefaultUser != "" {
+		return i.DefaultUser
+	}
+	return catalog.DefaultUser
+}
+
 func (i *Image) GetSystemKind() string {
 	if i.SystemKind != "" {
 		return i.SystemKind
@@ -211,6 +254,8 @@ func (i *Image) Upsert(db *database.Database) (err error) {
 		"system_type":   i.SystemType,
 		"system_kind":   i.SystemKind,
 		"firmware":      i.Firmware,
+		"cloud_init":    i.CloudInit,
+		"default_user":  i.DefaultUser,
 		"storage":       i.Storage,
 		"key":           i.Key,
 		"last_modified": i.LastModified,
@@ -270,6 +315,8 @@ func (i *Image) Sync(db *database.Database) (err error) {
 					"system_type":   i.SystemType,
 					"system_kind":   i.SystemKind,
 					"firmware":      i.Firmware,
+					"cloud_init":    i.CloudInit,
+					"default_user":  i.DefaultUser,
 					"etag":          i.Etag,
 					"last_modified": i.LastModified,
 					"storage_class": i.StorageClass,
@@ -294,6 +341,30 @@ func (i *Image) Sync(db *database.Database) (err error) {
 		if resp.UpsertedID != nil {
 			i.Id = resp.UpsertedID.(bson.ObjectID)
 		}
+	} else if strings.HasPrefix(i.Key, "import/") {
+		_, e := coll.UpdateOne(
+			db,
+			&bson.M{
+				"storage": i.Storage,
+				"key":     i.Key,
+			},
+			&bson.M{
+				"$set": &bson.M{
+					"etag":          i.Etag,
+					"last_modified": i.LastModified,
+					"storage_class": i.StorageClass,
+				},
+			},
+		)
+		if e != nil {
+			err = database.ParseError(e)
+			if _, ok := err.(*database.NotFoundError); ok {
+				err = &LostImageError{
+					errors.Wrap(err, "image: Lost image"),
+				}
+			}
+			return
+		}
 	} else {
 		resp, e := coll.UpdateOne(
 			db,
@@ -314,6 +385,8 @@ func (i *Image) Sync(db *database.Database) (err error) {
 					"system_type":   i.SystemType,
 					"system_kind":   i.SystemKind,
 					"firmware":      i.Firmware,
+					"cloud_init":    i.CloudInit,
+					"default_user":  i.DefaultUser,
 					"etag":          i.Etag,
 					"last_modified": i.LastModified,
 				},
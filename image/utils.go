@@ -12,6 +12,7 @@ import (
 	minio "github.com/minio/minio-go/v7"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
+	"github.com/pritunl/pritunl-cloud/catalog"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/utils"
 )
@@ -71,7 +72,7 @@ func ParseImageName(key string) (name, release, build string) {
 		name = fmt.Sprintf("%s%s-%s%s", distro, version, yearStr, monthStr)
 	}
 
-	if Releases.Contains(distro + version) {
+	if catalog.IsRelease(distro + version) {
 		release = distro + version
 	}
 
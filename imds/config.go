@@ -12,6 +12,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/pod"
 	"github.com/pritunl/pritunl-cloud/secret"
 	"github.com/pritunl/pritunl-cloud/spec"
+	"github.com/pritunl/pritunl-cloud/sshcert"
 	"github.com/pritunl/pritunl-cloud/unit"
 	"github.com/pritunl/pritunl-cloud/vm"
 	"github.com/pritunl/pritunl-cloud/vpc"
@@ -29,7 +30,7 @@ func BuildConfig(inst *instance.Instance, virt *vm.VirtualMachine,
 	podUnitsMap map[bson.ObjectID][]*unit.Unit,
 	deployments map[bson.ObjectID]*deployment.Deployment,
 	secrs []*secret.Secret, certs []*certificate.Certificate,
-	domains []*types.Domain) (conf *types.Config, err error) {
+	domains []*types.Domain, hostKey *sshcert.HostKey) (conf *types.Config, err error) {
 
 	var dnsServers []string
 	var dnsServers6 []string
@@ -53,6 +54,7 @@ func BuildConfig(inst *instance.Instance, virt *vm.VirtualMachine,
 		Domains:        domains,
 		DnsServers:     dnsServers,
 		DnsServers6:    dnsServers6,
+		SshHost:        types.NewSshHost(hostKey),
 	}
 
 	if spc != nil {
@@ -23,6 +23,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/imds/types"
 	"github.com/pritunl/pritunl-cloud/iproute"
 	"github.com/pritunl/pritunl-cloud/journal"
+	"github.com/pritunl/pritunl-cloud/logsink"
 	"github.com/pritunl/pritunl-cloud/manifest"
 	"github.com/pritunl/pritunl-cloud/metric"
 	"github.com/pritunl/pritunl-cloud/paths"
@@ -256,6 +257,8 @@ func Sync(db *database.Database, namespace string,
 			if err != nil {
 				return
 			}
+
+			shipJournal(orgId, instId, deplyId, "agent", jrnl)
 		}
 
 		if ste.Journals != nil {
@@ -291,6 +294,8 @@ func Sync(db *database.Database, namespace string,
 					if err != nil {
 						return
 					}
+
+					shipJournal(orgId, instId, deplyId, key, jrnl)
 				}
 			}
 		}
@@ -683,6 +688,8 @@ func Pull(db *database.Database, instId, orgId, deplyId bson.ObjectID,
 			if err != nil {
 				return
 			}
+
+			shipJournal(orgId, instId, deplyId, "agent", jrnl)
 		}
 
 		if ste.Journals != nil {
@@ -718,6 +725,8 @@ func Pull(db *database.Database, instId, orgId, deplyId bson.ObjectID,
 					if err != nil {
 						return
 					}
+
+					shipJournal(orgId, instId, deplyId, key, jrnl)
 				}
 			}
 		}
@@ -807,3 +816,152 @@ func State(db *database.Database, instId bson.ObjectID,
 
 	return
 }
+
+func preStop(instId bson.ObjectID, imdsHostSecret, method string) (
+	ste *types.State, err error) {
+
+	sockPath := paths.GetImdsSockPath(instId)
+
+	exists, err := utils.Exists(sockPath)
+	if err != nil {
+		return
+	}
+
+	if !exists {
+		return
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context,
+				_, _ string) (net.Conn, error) {
+
+				return net.Dial("unix", sockPath)
+			},
+		},
+		Timeout: 6 * time.Second,
+	}
+
+	req, err := http.NewRequest(method, "http://unix/pre_stop", nil)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "agent: Failed to create imds request"),
+		}
+		return
+	}
+
+	req.Header.Set("User-Agent", "pritunl-imds")
+	req.Header.Set("Auth-Token", imdsHostSecret)
+
+	resp, e := client.Do(req)
+	if e != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(e, "agent: Imds request failed"),
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body := ""
+		data, _ := ioutil.ReadAll(resp.Body)
+		if data != nil {
+			body = string(data)
+		}
+
+		errData := &errortypes.ErrorData{}
+		err = json.Unmarshal(data, errData)
+		if err != nil || errData.Error == "" {
+			errData = nil
+		}
+
+		if errData != nil && errData.Message != "" {
+			body = errData.Message
+		}
+
+		err = &errortypes.RequestError{
+			errors.Newf(
+				"agent: Imds host pre stop error %d - %s",
+				resp.StatusCode, body),
+		}
+		return
+	}
+
+	ste = &types.State{}
+	err = json.NewDecoder(resp.Body).Decode(ste)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "agent: Failed to decode imds host pre stop resp"),
+		}
+		return
+	}
+
+	return
+}
+
+func PreStop(db *database.Database, instId bson.ObjectID,
+	imdsHostSecret string, timeout time.Duration) (err error) {
+
+	ste, err := preStop(instId, imdsHostSecret, "PUT")
+	if err != nil {
+		return
+	}
+
+	// Older agents and instances without a pre-stop phase never report
+	// the pre-stop status
+	if ste == nil || !ste.PreStopHook {
+		_, err = preStop(instId, imdsHostSecret, "DELETE")
+		return
+	}
+
+	switch ste.Status {
+	case types.Running, types.ReloadingClean, types.ReloadingFault,
+		types.Fault, types.Unhealthy, types.PreStopping:
+
+		break
+	default:
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"instance": instId.Hex(),
+	}).Info("imds: Waiting for instance pre-stop")
+
+	start := time.Now()
+	for {
+		time.Sleep(1 * time.Second)
+
+		ste, err = State(db, instId, imdsHostSecret)
+		if err != nil {
+			return
+		}
+
+		if ste == nil || ste.Status == types.PreStopped {
+			return
+		}
+
+		if time.Since(start) > timeout {
+			logrus.WithFields(logrus.Fields{
+				"instance": instId.Hex(),
+				"status":   ste.Status,
+			}).Warn("imds: Instance pre-stop timed out")
+
+			_, err = preStop(instId, imdsHostSecret, "DELETE")
+			return
+		}
+	}
+}
+
+func shipJournal(orgId, instId, deplyId bson.ObjectID, key string,
+	jrnl *journal.Journal) {
+
+	fields := map[string]string{
+		"instance": instId.Hex(),
+		"kind":     key,
+	}
+	if !deplyId.IsZero() {
+		fields["deployment"] = deplyId.Hex()
+	}
+
+	logsink.PushJournal(orgId, jrnl, fields)
+}
@@ -131,6 +131,7 @@ func RegisterVirt(engine *gin.Engine) {
 	virtGroup.GET("/subnet", subnetGet)
 	virtGroup.GET("/certificate", certificatesGet)
 	virtGroup.GET("/secret", secretsGet)
+	virtGroup.GET("/ssh", sshGet)
 	virtGroup.PUT("/sync", syncPut)
 	virtGroup.PUT("/primary", primaryPut)
 
@@ -145,4 +146,6 @@ func RegisterHost(engine *gin.Engine) {
 	engine.PUT("/sync", hostSyncPut)
 	engine.GET("/sync", hostSyncGet)
 	engine.GET("/state", hostStateGet)
+	engine.PUT("/pre_stop", hostPreStopPut)
+	engine.DELETE("/pre_stop", hostPreStopDelete)
 }
@@ -0,0 +1,10 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/pritunl-cloud/imds/server/config"
+)
+
+func sshGet(c *gin.Context) {
+	c.JSON(200, config.Config.SshHost)
+}
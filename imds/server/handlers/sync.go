@@ -18,6 +18,8 @@ type syncRespData struct {
 	Spec     string           `json:"spec"`
 	Hash     uint32           `json:"hash"`
 	Journals []*types.Journal `json:"journals"`
+	PreStop  bool             `json:"pre_stop"`
+	SshHost  *types.SshHost   `json:"ssh_host"`
 }
 
 func syncPut(c *gin.Context) {
@@ -35,8 +37,13 @@ func syncPut(c *gin.Context) {
 	if !state.Global.State.Final() {
 		state.Global.State.Status = data.Status
 	}
+	state.Global.State.PreStopHook = data.PreStopHook
 	state.Global.State.Timestamp = time.Now()
 
+	if data.Status == types.Initializing {
+		state.Global.ClearPreStop()
+	}
+
 	if data.Metrics != nil {
 		telemetry.Metrics.Append(data.Metrics...)
 	}
@@ -63,11 +70,15 @@ func syncPut(c *gin.Context) {
 			Spec:     config.Config.SpecData,
 			Hash:     config.Config.Hash,
 			Journals: config.Config.Journals,
+			PreStop:  state.Global.GetPreStop(),
+			SshHost:  config.Config.SshHost,
 		})
 	} else {
 		c.JSON(200, &syncRespData{
 			Hash:     config.Config.Hash,
 			Journals: config.Config.Journals,
+			PreStop:  state.Global.GetPreStop(),
+			SshHost:  config.Config.SshHost,
 		})
 	}
 }
@@ -122,6 +133,22 @@ func hostSyncGet(c *gin.Context) {
 	c.JSON(200, ste)
 }
 
+func hostPreStopPut(c *gin.Context) {
+	state.Global.SetPreStop()
+
+	ste := state.Global.State.Copy()
+
+	c.JSON(200, ste)
+}
+
+func hostPreStopDelete(c *gin.Context) {
+	state.Global.ClearPreStop()
+
+	ste := state.Global.State.Copy()
+
+	c.JSON(200, ste)
+}
+
 func hostStateGet(c *gin.Context) {
 	ste := state.Global.State.Copy()
 
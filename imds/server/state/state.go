@@ -19,6 +19,7 @@ type Store struct {
 	journals    map[string]chan *types.Entry
 	setPrimary  bool
 	primaryTime time.Time
+	preStop     bool
 	lock        sync.RWMutex
 }
 
@@ -38,6 +39,26 @@ func (s *Store) GetSetPrimary() (val bool, timestamp time.Time) {
 	return
 }
 
+func (s *Store) SetPreStop() {
+	s.lock.Lock()
+	s.preStop = true
+	s.lock.Unlock()
+}
+
+func (s *Store) ClearPreStop() {
+	s.lock.Lock()
+	s.preStop = false
+	s.lock.Unlock()
+}
+
+func (s *Store) GetPreStop() (val bool) {
+	s.lock.RLock()
+	val = s.preStop
+	s.lock.RUnlock()
+
+	return
+}
+
 func (s *Store) AppendOutput(entry *types.Entry) {
 	if len(s.output) > 9000 {
 		return
@@ -25,7 +25,7 @@ func NewCertificates(certs []*certificate.Certificate) []*Certificate {
 			Id:          cert.Id,
 			Name:        cert.Name,
 			Type:        cert.Type,
-			Key:         cert.Key,
+			Key:         string(cert.Key),
 			Certificate: cert.Certificate,
 		}
 
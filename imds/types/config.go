@@ -24,6 +24,7 @@ type Config struct {
 	Domains        []*Domain      `json:"domains"`
 	DnsServers     []string       `json:"dns_servers"`
 	DnsServers6    []string       `json:"dns_servers6"`
+	SshHost        *SshHost       `json:"ssh_host"`
 	Hash           uint32         `json:"hash"`
 }
 
@@ -8,4 +8,7 @@ const (
 	Fault          = "fault"
 	Offline        = "offline"
 	Imaged         = "imaged"
+	Unhealthy      = "unhealthy"
+	PreStopping    = "pre_stopping"
+	PreStopped     = "pre_stopped"
 )
@@ -30,11 +30,11 @@ func NewSecrets(secrs []*secret.Secret) []*Secret {
 			Name:       secr.Name,
 			Type:       secr.Type,
 			Key:        secr.Key,
-			Value:      secr.Value,
+			Value:      string(secr.Value),
 			Data:       secr.Data,
 			Region:     secr.Region,
 			PublicKey:  secr.PublicKey,
-			PrivateKey: secr.PrivateKey,
+			PrivateKey: string(secr.PrivateKey),
 		}
 
 		datas = append(datas, data)
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/sshcert"
+)
+
+type SshHost struct {
+	Authority   bson.ObjectID `json:"authority"`
+	PrivateKey  string        `json:"private_key"`
+	PublicKey   string        `json:"public_key"`
+	Certificate string        `json:"certificate"`
+	ValidBefore time.Time     `json:"valid_before"`
+}
+
+func NewSshHost(hostKey *sshcert.HostKey) *SshHost {
+	if hostKey == nil {
+		return nil
+	}
+
+	return &SshHost{
+		Authority:   hostKey.Authority,
+		PrivateKey:  string(hostKey.PrivateKey),
+		PublicKey:   hostKey.PublicKey,
+		Certificate: hostKey.Certificate,
+		ValidBefore: hostKey.ValidBefore,
+	}
+}
@@ -23,6 +23,7 @@ type State struct {
 	Journals         map[string][]*Entry `json:"journals,omitempty"`
 	Primary          bool                `json:"primary,omitempty"`
 	PrimaryTimestamp time.Time           `json:"primary_timestamp,omitempty"`
+	PreStopHook      bool                `json:"pre_stop_hook,omitempty"`
 }
 
 func (s *State) Final() bool {
@@ -44,6 +45,7 @@ func (s *State) Copy() *State {
 		Updates:     s.Updates,
 		Metrics:     s.Metrics,
 		Timestamp:   s.Timestamp,
+		PreStopHook: s.PreStopHook,
 	}
 }
 
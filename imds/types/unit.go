@@ -29,7 +29,7 @@ func NewUnit(unt *unit.Unit) *Unit {
 		Organization:     unt.Organization,
 		Name:             unt.Name,
 		Kind:             unt.Kind,
-		Count:            unt.Count,
+		Count:            unt.GetCount(),
 		Primary:          unt.Primary,
 		PrimaryTimestamp: unt.PrimaryTimestamp,
 	}
@@ -0,0 +1,217 @@
+package imgcache
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/image"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
+)
+
+type cacheFile struct {
+	Key     string
+	Image   bson.ObjectID
+	Etag    string
+	Path    string
+	Size    int64
+	ModTime time.Time
+}
+
+// Clean removes cached images that no longer exist, evicts the least
+// recently used images over the size limit and reconciles the peer
+// registry with the cache directory
+func Clean(db *database.Database) (err error) {
+	cacheDir := node.Self.GetCachePath()
+
+	imageKeys, err := image.GetAllKeys(db)
+	if err != nil {
+		return
+	}
+
+	exists, err := utils.ExistsDir(cacheDir)
+	if err != nil {
+		return
+	}
+	if !exists {
+		return
+	}
+
+	items, err := os.ReadDir(cacheDir)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "imgcache: Failed to read cache directory"),
+		}
+		return
+	}
+
+	files := []*cacheFile{}
+	total := int64(0)
+	for _, item := range items {
+		name := item.Name()
+		pth := filepath.Join(cacheDir, name)
+
+		if !strings.HasPrefix(name, "image-") || item.IsDir() {
+			continue
+		}
+
+		info, e := item.Info()
+		if e != nil {
+			continue
+		}
+
+		keys := strings.SplitN(name, "-", 3)
+		if len(keys) != 3 {
+			logrus.WithFields(logrus.Fields{
+				"path": pth,
+			}).Warning("imgcache: Removing unknown image cache")
+			os.Remove(pth)
+			continue
+		}
+
+		imgId, e := bson.ObjectIDFromHex(keys[1])
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"path": pth,
+			}).Warning("imgcache: Removing unknown image cache")
+			os.Remove(pth)
+			continue
+		}
+		key := keys[1] + "-" + keys[2]
+
+		if !imageKeys.Contains(key) {
+			if time.Since(info.ModTime()) > unknownAge {
+				logrus.WithFields(logrus.Fields{
+					"key":  key,
+					"path": pth,
+				}).Info("imgcache: Removing old image cache")
+				os.Remove(pth)
+			}
+			continue
+		}
+
+		files = append(files, &cacheFile{
+			Key:     key,
+			Image:   imgId,
+			Etag:    keys[2],
+			Path:    pth,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		total += info.Size()
+	}
+
+	limit := int64(settings.Hypervisor.ImageCacheMax) * 1024 * 1024 * 1024
+	if limit > 0 && total > limit {
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].ModTime.Before(files[j].ModTime)
+		})
+
+		remaining := []*cacheFile{}
+		for _, file := range files {
+			if total <= limit || time.Since(file.ModTime) < evictAge {
+				remaining = append(remaining, file)
+				continue
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"key":        file.Key,
+				"path":       file.Path,
+				"size":       file.Size,
+				"last_used":  file.ModTime,
+				"cache_size": total,
+				"cache_max":  limit,
+			}).Info("imgcache: Evicting least recently used image cache")
+
+			err = os.Remove(file.Path)
+			if err != nil {
+				err = &errortypes.WriteError{
+					errors.Wrap(err, "imgcache: Failed to remove image cache"),
+				}
+				return
+			}
+			total -= file.Size
+
+			err = Remove(db, node.Self.Id, file.Image, file.Etag)
+			if err != nil {
+				return
+			}
+		}
+		files = remaining
+	}
+
+	err = reconcile(db, files)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func reconcile(db *database.Database, files []*cacheFile) (err error) {
+	caches, err := GetAllNode(db, node.Self.Id)
+	if err != nil {
+		return
+	}
+
+	fileKeys := map[string]*cacheFile{}
+	for _, file := range files {
+		fileKeys[file.Key] = file
+	}
+
+	registered := set.NewSet()
+	staleIds := []bson.ObjectID{}
+	for _, cache := range caches {
+		key := cache.Image.Hex() + "-" + cache.Etag
+		file := fileKeys[key]
+
+		if file == nil || file.Size != cache.Size {
+			staleIds = append(staleIds, cache.Id)
+			continue
+		}
+
+		if cache.Zone != node.Self.Zone {
+			err = Register(db, cache.Image, cache.Etag,
+				cache.Hash, cache.Size)
+			if err != nil {
+				return
+			}
+		}
+
+		registered.Add(key)
+	}
+
+	err = RemoveNode(db, node.Self.Id, staleIds)
+	if err != nil {
+		return
+	}
+
+	for _, file := range files {
+		if registered.Contains(file.Key) {
+			continue
+		}
+
+		hash, e := utils.FileSha256(file.Path)
+		if e != nil {
+			err = e
+			return
+		}
+
+		err = Register(db, file.Image, file.Etag, hash, file.Size)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,14 @@
+package imgcache
+
+import (
+	"time"
+)
+
+const (
+	tokenHeader    = "Pritunl-Cache-Token"
+	dialTimeout    = 10 * time.Second
+	headerTimeout  = 30 * time.Second
+	evictAge       = 10 * time.Minute
+	unknownAge     = 5 * time.Minute
+	maxPeerAttempt = 3
+)
@@ -0,0 +1,132 @@
+package imgcache
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/settings"
+)
+
+var client = &http.Client{
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: dialTimeout,
+		}).DialContext,
+		ResponseHeaderTimeout: headerTimeout,
+	},
+}
+
+type Cache struct {
+	Id        bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Node      bson.ObjectID `bson:"node" json:"node"`
+	Zone      bson.ObjectID `bson:"zone" json:"zone"`
+	Image     bson.ObjectID `bson:"image" json:"image"`
+	Etag      string        `bson:"etag" json:"etag"`
+	Hash      string        `bson:"hash" json:"hash"`
+	Size      int64         `bson:"size" json:"size"`
+	Token     string        `bson:"token" json:"-"`
+	Timestamp time.Time     `bson:"timestamp" json:"timestamp"`
+}
+
+func (c *Cache) getHost(db *database.Database) (host string, err error) {
+	nde, err := node.Get(db, c.Node)
+	if err != nil {
+		return
+	}
+
+	if !nde.IsOnline() {
+		err = &errortypes.NotFoundError{
+			errors.New("imgcache: Peer node is offline"),
+		}
+		return
+	}
+
+	if len(nde.PrivateIps) > 0 {
+		host = nde.PrivateIps[nde.DefaultInterface]
+		if host == "" {
+			for _, privIp := range nde.PrivateIps {
+				if privIp != "" {
+					host = privIp
+					break
+				}
+			}
+		}
+	}
+
+	if host == "" {
+		err = &errortypes.NotFoundError{
+			errors.New("imgcache: Peer node missing private IP"),
+		}
+		return
+	}
+
+	return
+}
+
+// Open requests the cached image from the peer node over the
+// internal network
+func (c *Cache) Open(db *database.Database) (
+	body io.ReadCloser, size int64, err error) {
+
+	host, err := c.getHost(db)
+	if err != nil {
+		return
+	}
+
+	u := fmt.Sprintf("http://%s/image/%s", net.JoinHostPort(host,
+		fmt.Sprintf("%d", settings.Hypervisor.ImageCachePort)), c.Id.Hex())
+
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "imgcache: Failed to create peer request"),
+		}
+		return
+	}
+
+	req.Header.Set("User-Agent", "pritunl-cloud")
+	req.Header.Set(tokenHeader, c.Token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "imgcache: Peer request error"),
+		}
+		return
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		err = &errortypes.RequestError{
+			errors.Newf(
+				"imgcache: Bad status %d from peer request",
+				resp.StatusCode,
+			),
+		}
+		return
+	}
+
+	if resp.ContentLength != c.Size {
+		resp.Body.Close()
+		err = &errortypes.RequestError{
+			errors.Newf(
+				"imgcache: Peer size mismatch %d != %d",
+				resp.ContentLength, c.Size,
+			),
+		}
+		return
+	}
+
+	body = resp.Body
+	size = resp.ContentLength
+
+	return
+}
@@ -0,0 +1,120 @@
+package imgcache
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/sirupsen/logrus"
+)
+
+func authorize(cacheId bson.ObjectID, token string) (
+	cache *Cache, err error) {
+
+	db := database.GetDatabase()
+	defer db.Close()
+
+	cache = &Cache{}
+	err = db.ImageCaches().FindOneId(cacheId, cache)
+	if err != nil {
+		cache = nil
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+		}
+		return
+	}
+
+	if cache.Node != node.Self.Id || cache.Token == "" ||
+		subtle.ConstantTimeCompare(
+			[]byte(cache.Token), []byte(token)) != 1 {
+
+		cache = nil
+		return
+	}
+
+	return
+}
+
+func handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	cacheId, err := bson.ObjectIDFromHex(
+		strings.TrimPrefix(r.URL.Path, "/image/"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	cache, err := authorize(cacheId, r.Header.Get(tokenHeader))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"remote": r.RemoteAddr,
+			"error":  err,
+		}).Error("imgcache: Failed to authorize peer request")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if cache == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	file, err := os.Open(GetPath(cache.Image, cache.Etag))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil || stat.Size() != cache.Size {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"remote":   r.RemoteAddr,
+		"image_id": cache.Image.Hex(),
+		"etag":     cache.Etag,
+	}).Info("imgcache: Serving cached image to peer")
+
+	http.ServeContent(w, r, "", stat.ModTime(), file)
+}
+
+// Serve runs the peer server for distributing locally cached images to
+// other nodes in the zone
+func Serve() (err error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/image/", handle)
+
+	server := &http.Server{
+		Addr: fmt.Sprintf(
+			":%d", settings.Hypervisor.ImageCachePort),
+		Handler:           mux,
+		ReadHeaderTimeout: headerTimeout,
+		IdleTimeout:       1 * time.Minute,
+	}
+
+	err = server.ListenAndServe()
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "imgcache: Peer server error"),
+		}
+		return
+	}
+
+	return
+}
@@ -0,0 +1,201 @@
+package imgcache
+
+import (
+	"fmt"
+	"math/rand"
+	"path"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+func GetPath(imgId bson.ObjectID, etag string) string {
+	return path.Join(
+		node.Self.GetCachePath(),
+		fmt.Sprintf("image-%s-%s", imgId.Hex(), etag),
+	)
+}
+
+// GetPeers returns online candidates in the local zone that hold a
+// cached copy of the image in random order
+func GetPeers(db *database.Database, imgId bson.ObjectID, etag string) (
+	caches []*Cache, err error) {
+
+	coll := db.ImageCaches()
+	caches = []*Cache{}
+
+	if node.Self.Zone.IsZero() {
+		return
+	}
+
+	cursor, err := coll.Find(db, &bson.M{
+		"image": imgId,
+		"etag":  etag,
+		"zone":  node.Self.Zone,
+		"node": &bson.M{
+			"$ne": node.Self.Id,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		cache := &Cache{}
+		err = cursor.Decode(cache)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		caches = append(caches, cache)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	rand.Shuffle(len(caches), func(i, j int) {
+		caches[i], caches[j] = caches[j], caches[i]
+	})
+
+	if len(caches) > maxPeerAttempt {
+		caches = caches[:maxPeerAttempt]
+	}
+
+	return
+}
+
+func GetAllNode(db *database.Database, ndeId bson.ObjectID) (
+	caches []*Cache, err error) {
+
+	coll := db.ImageCaches()
+	caches = []*Cache{}
+
+	cursor, err := coll.Find(
+		db,
+		&bson.M{
+			"node": ndeId,
+		},
+		options.Find().
+			SetSort(bson.D{{"timestamp", 1}}),
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		cache := &Cache{}
+		err = cursor.Decode(cache)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		caches = append(caches, cache)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Register records the local cached image so that peer nodes in the
+// same zone can fetch it
+func Register(db *database.Database, imgId bson.ObjectID, etag,
+	hash string, size int64) (err error) {
+
+	coll := db.ImageCaches()
+
+	token, err := utils.RandStr(32)
+	if err != nil {
+		return
+	}
+
+	_, err = coll.UpdateOne(
+		db,
+		&bson.M{
+			"node":  node.Self.Id,
+			"image": imgId,
+			"etag":  etag,
+		},
+		&bson.M{
+			"$set": &bson.M{
+				"zone":      node.Self.Zone,
+				"hash":      hash,
+				"size":      size,
+				"timestamp": time.Now(),
+			},
+			"$setOnInsert": &bson.M{
+				"token": token,
+			},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func Remove(db *database.Database, ndeId, imgId bson.ObjectID,
+	etag string) (err error) {
+
+	coll := db.ImageCaches()
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"node":  ndeId,
+		"image": imgId,
+		"etag":  etag,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		switch err.(type) {
+		case *database.NotFoundError:
+			err = nil
+		default:
+			return
+		}
+	}
+
+	return
+}
+
+func RemoveNode(db *database.Database, ndeId bson.ObjectID,
+	cacheIds []bson.ObjectID) (err error) {
+
+	coll := db.ImageCaches()
+
+	if len(cacheIds) == 0 {
+		return
+	}
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"_id": &bson.M{
+			"$in": cacheIds,
+		},
+		"node": ndeId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,224 @@
+package imgimport
+
+import (
+	"context"
+	"io"
+	"strconv"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/storage"
+)
+
+func getClient(store *storage.Storage) (client *minio.Core, err error) {
+	client, err = minio.NewCore(store.Endpoint, &minio.Options{
+		Creds: credentials.NewStaticV4(
+			store.AccessKey,
+			string(store.SecretKey),
+			"",
+		),
+		Secure: !store.Insecure,
+	})
+	if err != nil {
+		err = &errortypes.ConnectionError{
+			errors.Wrap(err, "imgimport: Failed to connect to storage"),
+		}
+		return
+	}
+
+	return
+}
+
+func (i *Import) StartUpload(db *database.Database) (err error) {
+	store, err := storage.Get(db, i.Storage)
+	if err != nil {
+		return
+	}
+
+	client, err := getClient(store)
+	if err != nil {
+		return
+	}
+
+	uploadId, err := client.NewMultipartUpload(context.Background(),
+		store.Bucket, i.UploadKey(), minio.PutObjectOptions{})
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "imgimport: Failed to start upload"),
+		}
+		return
+	}
+
+	i.UploadId = uploadId
+	err = i.CommitFields(db, set.NewSet("upload_id"))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (i *Import) PutPart(db *database.Database, number int,
+	reader io.Reader, size int64) (errData *errortypes.ErrorData,
+	err error) {
+
+	if i.State != Uploading || i.UploadId == "" {
+		errData = &errortypes.ErrorData{
+			Error:   "image_import_state_invalid",
+			Message: "Image import is not accepting uploads",
+		}
+		return
+	}
+
+	if number < 1 || number > i.PartCount() {
+		errData = &errortypes.ErrorData{
+			Error:   "image_import_chunk_invalid",
+			Message: "Image import chunk number invalid",
+		}
+		return
+	}
+
+	if size != i.PartSize(number) {
+		errData = &errortypes.ErrorData{
+			Error:   "image_import_chunk_size_invalid",
+			Message: "Image import chunk size does not match",
+		}
+		return
+	}
+
+	store, err := storage.Get(db, i.Storage)
+	if err != nil {
+		return
+	}
+
+	client, err := getClient(store)
+	if err != nil {
+		return
+	}
+
+	objPart, err := client.PutObjectPart(context.Background(),
+		store.Bucket, i.UploadKey(), i.UploadId, number,
+		reader, size, minio.PutObjectPartOptions{})
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "imgimport: Failed to upload part"),
+		}
+		return
+	}
+
+	err = i.SetPart(db, &Part{
+		Number: number,
+		Etag:   objPart.ETag,
+		Size:   objPart.Size,
+	})
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (i *Import) CompleteUpload(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	if i.State != Uploading || i.UploadId == "" {
+		errData = &errortypes.ErrorData{
+			Error:   "image_import_state_invalid",
+			Message: "Image import is not accepting uploads",
+		}
+		return
+	}
+
+	parts := []minio.CompletePart{}
+	for number := 1; number <= i.PartCount(); number++ {
+		part := i.Parts[strconv.Itoa(number)]
+		if part == nil {
+			errData = &errortypes.ErrorData{
+				Error:   "image_import_incomplete",
+				Message: "Image import is missing chunks",
+			}
+			return
+		}
+
+		parts = append(parts, minio.CompletePart{
+			PartNumber: part.Number,
+			ETag:       part.Etag,
+		})
+	}
+
+	store, err := storage.Get(db, i.Storage)
+	if err != nil {
+		return
+	}
+
+	client, err := getClient(store)
+	if err != nil {
+		return
+	}
+
+	_, err = client.CompleteMultipartUpload(context.Background(),
+		store.Bucket, i.UploadKey(), i.UploadId, parts,
+		minio.PutObjectOptions{})
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "imgimport: Failed to complete upload"),
+		}
+		return
+	}
+
+	i.UploadId = ""
+	i.State = Pending
+	err = i.CommitFields(db, set.NewSet("upload_id", "state"))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Cleanup aborts incomplete uploads and removes the staging object
+func (i *Import) Cleanup(db *database.Database) (err error) {
+	store, err := storage.Get(db, i.Storage)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+		}
+		return
+	}
+
+	client, err := getClient(store)
+	if err != nil {
+		return
+	}
+
+	if i.UploadId != "" {
+		err = client.AbortMultipartUpload(context.Background(),
+			store.Bucket, i.UploadKey(), i.UploadId)
+		if err != nil {
+			err = &errortypes.WriteError{
+				errors.Wrap(err, "imgimport: Failed to abort upload"),
+			}
+			return
+		}
+	}
+
+	err = client.RemoveObject(context.Background(), store.Bucket,
+		i.UploadKey(), minio.RemoveObjectOptions{})
+	if err != nil {
+		resp := minio.ToErrorResponse(err)
+		if resp.Code == "NoSuchKey" {
+			err = nil
+		} else {
+			err = &errortypes.WriteError{
+				errors.Wrap(err, "imgimport: Failed to remove upload"),
+			}
+			return
+		}
+	}
+
+	return
+}
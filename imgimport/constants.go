@@ -0,0 +1,55 @@
+package imgimport
+
+import (
+	"time"
+
+	"github.com/pritunl/tools/set"
+)
+
+const (
+	Upload = "upload"
+	Url    = "url"
+
+	Uploading  = "uploading"
+	Pending    = "pending"
+	Processing = "processing"
+	Complete   = "complete"
+	Failed     = "failed"
+
+	Downloading = "downloading"
+	Extracting  = "extracting"
+	Converting  = "converting"
+	Storing     = "storing"
+
+	Auto  = "auto"
+	Raw   = "raw"
+	Qcow2 = "qcow2"
+	Vmdk  = "vmdk"
+	Vhdx  = "vhdx"
+	Vpc   = "vpc"
+	Vdi   = "vdi"
+	Ova   = "ova"
+
+	ChunkSize    = 64 * 1024 * 1024
+	MaxChunkSize = ChunkSize + 1024*1024
+	MaxParts     = 10000
+
+	keyPrefix      = "import/"
+	processTtl     = 3 * time.Minute
+	uploadTtl      = 48 * time.Hour
+	heartbeatRate  = 30 * time.Second
+	requestTimeout = 30 * time.Second
+)
+
+var (
+	ValidFormats = set.NewSet(
+		Auto,
+		Raw,
+		Qcow2,
+		Vmdk,
+		Vhdx,
+		Vpc,
+		Vdi,
+		Ova,
+	)
+)
@@ -0,0 +1,420 @@
+package imgimport
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/image"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+const vmdkDescriptor = "# Disk DescriptorFile"
+
+type imageInfo struct {
+	BackingFilename     string              `json:"backing-filename"`
+	FullBackingFilename string              `json:"full-backing-filename"`
+	FormatSpecific      *imageFormatDetails `json:"format-specific"`
+}
+
+type imageFormatDetails struct {
+	Type string           `json:"type"`
+	Data *imageFormatData `json:"data"`
+}
+
+type imageFormatData struct {
+	DataFile string         `json:"data-file"`
+	Extents  []*imageExtent `json:"extents"`
+}
+
+type imageExtent struct {
+	Filename string `json:"filename"`
+}
+
+var (
+	guidEsp         = guidBytes("c12a7328-f81f-11d2-ba4b-00a0c93ec93b")
+	guidFreeBsdBoot = guidBytes("83bd6b9d-7f41-11dc-be0b-001560b84f0f")
+	guidFreeBsdUfs  = guidBytes("516e7cb6-6ecf-11d6-8ff8-00022d09712b")
+	guidFreeBsdZfs  = guidBytes("516e7cba-6ecf-11d6-8ff8-00022d09712b")
+	diskExts        = []string{
+		".vmdk",
+		".qcow2",
+		".vhdx",
+		".vhd",
+		".vdi",
+		".img",
+		".raw",
+	}
+)
+
+// guidBytes returns the on disk mixed endian encoding of a GPT GUID
+func guidBytes(guid string) []byte {
+	data, _ := hex.DecodeString(strings.ReplaceAll(guid, "-", ""))
+	if len(data) != 16 {
+		return nil
+	}
+
+	return []byte{
+		data[3], data[2], data[1], data[0],
+		data[5], data[4],
+		data[7], data[6],
+		data[8], data[9], data[10], data[11],
+		data[12], data[13], data[14], data[15],
+	}
+}
+
+func detectFormat(pth string) (format string, err error) {
+	file, err := os.Open(pth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "imgimport: Failed to open image"),
+		}
+		return
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := file.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "imgimport: Failed to read image"),
+		}
+		return
+	}
+	err = nil
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, []byte("QFI\xfb")):
+		format = Qcow2
+		break
+	case bytes.HasPrefix(head, []byte("KDMV")),
+		bytes.HasPrefix(head, []byte("COWD")),
+		bytes.HasPrefix(head, []byte(vmdkDescriptor)):
+
+		format = Vmdk
+		break
+	case bytes.HasPrefix(head, []byte("vhdxfile")):
+		format = Vhdx
+		break
+	case bytes.HasPrefix(head, []byte("conectix")):
+		format = Vpc
+		break
+	case len(head) >= 0x44 &&
+		binary.LittleEndian.Uint32(head[0x40:0x44]) == 0xbeda107f:
+
+		format = Vdi
+		break
+	case len(head) >= 262 && string(head[257:262]) == "ustar":
+		format = Ova
+		break
+	default:
+		format = Raw
+
+		stat, e := file.Stat()
+		if e != nil {
+			err = &errortypes.ReadError{
+				errors.Wrap(e, "imgimport: Failed to stat image"),
+			}
+			return
+		}
+
+		if stat.Size() >= 512 {
+			footer := make([]byte, 8)
+			_, e = file.ReadAt(footer, stat.Size()-512)
+			if e == nil && string(footer) == "conectix" {
+				format = Vpc
+			}
+		}
+	}
+
+	return
+}
+
+// checkImage rejects images that reference other files, a backing file,
+// external data file or vmdk extent would be read from the host during
+// conversion
+func checkImage(pth, format string) (err error) {
+	file, err := os.Open(pth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "imgimport: Failed to open image"),
+		}
+		return
+	}
+
+	head := make([]byte, len(vmdkDescriptor))
+	n, e := file.ReadAt(head, 0)
+	file.Close()
+	if e != nil && e != io.EOF {
+		err = &errortypes.ReadError{
+			errors.Wrap(e, "imgimport: Failed to read image"),
+		}
+		return
+	}
+
+	if bytes.Equal(head[:n], []byte(vmdkDescriptor)) {
+		err = &errortypes.ParseError{
+			errors.New("imgimport: Descriptor vmdk images not supported"),
+		}
+		return
+	}
+
+	output, err := utils.ExecOutput("", "qemu-img", "info",
+		"--output=json", "-f", format, pth)
+	if err != nil {
+		return
+	}
+
+	info := &imageInfo{}
+	err = json.Unmarshal([]byte(output), info)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "imgimport: Failed to parse image info"),
+		}
+		return
+	}
+
+	if info.BackingFilename != "" || info.FullBackingFilename != "" {
+		err = &errortypes.ParseError{
+			errors.New("imgimport: Images with backing file not supported"),
+		}
+		return
+	}
+
+	if info.FormatSpecific == nil || info.FormatSpecific.Data == nil {
+		return
+	}
+	data := info.FormatSpecific.Data
+
+	if data.DataFile != "" {
+		err = &errortypes.ParseError{
+			errors.New("imgimport: Images with data file not supported"),
+		}
+		return
+	}
+
+	absPth, err := filepath.Abs(pth)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "imgimport: Failed to get image path"),
+		}
+		return
+	}
+
+	for _, extent := range data.Extents {
+		extentPth, e := filepath.Abs(extent.Filename)
+		if e != nil || extentPth != absPth {
+			err = &errortypes.ParseError{
+				errors.New("imgimport: Images with external " +
+					"extents not supported"),
+			}
+			return
+		}
+	}
+
+	return
+}
+
+// extractOva extracts the first disk and the descriptor from an OVA
+func extractOva(pth, dir string) (diskPth, ovf string, err error) {
+	file, err := os.Open(pth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "imgimport: Failed to open ova"),
+		}
+		return
+	}
+	defer file.Close()
+
+	reader := tar.NewReader(file)
+	for {
+		header, e := reader.Next()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			err = &errortypes.ReadError{
+				errors.Wrap(e, "imgimport: Failed to read ova"),
+			}
+			return
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := filepath.Base(header.Name)
+		ext := strings.ToLower(filepath.Ext(name))
+
+		if ext == ".ovf" && ovf == "" {
+			data, e := io.ReadAll(io.LimitReader(reader, 4*1024*1024))
+			if e != nil {
+				err = &errortypes.ReadError{
+					errors.Wrap(e, "imgimport: Failed to read ovf"),
+				}
+				return
+			}
+			ovf = string(data)
+			continue
+		}
+
+		if diskPth != "" {
+			continue
+		}
+
+		isDisk := false
+		for _, diskExt := range diskExts {
+			if ext == diskExt {
+				isDisk = true
+				break
+			}
+		}
+		if !isDisk {
+			continue
+		}
+
+		diskPth = path.Join(dir, "disk"+ext)
+
+		out, e := os.OpenFile(diskPth,
+			os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if e != nil {
+			err = &errortypes.WriteError{
+				errors.Wrap(e, "imgimport: Failed to create ova disk"),
+			}
+			return
+		}
+
+		_, e = io.Copy(out, reader)
+		out.Close()
+		if e != nil {
+			err = &errortypes.WriteError{
+				errors.Wrap(e, "imgimport: Failed to extract ova disk"),
+			}
+			return
+		}
+	}
+
+	if diskPth == "" {
+		err = &errortypes.ParseError{
+			errors.New("imgimport: No disk found in ova"),
+		}
+		return
+	}
+
+	return
+}
+
+func ovfFirmware(ovf string) string {
+	ovf = strings.ToLower(ovf)
+
+	if !strings.Contains(ovf, `key="firmware"`) {
+		return ""
+	}
+
+	if strings.Contains(ovf, `value="efi"`) {
+		return image.Uefi
+	} else if strings.Contains(ovf, `value="bios"`) {
+		return image.Bios
+	}
+
+	return ""
+}
+
+// detectBoot reads the partition table of a qcow2 image to determine the
+// firmware and whether the image contains a bsd system
+func detectBoot(pth, tmpDir string) (firmware string, bsd bool,
+	err error) {
+
+	headPth := path.Join(tmpDir, "head.raw")
+	defer utils.Remove(headPth)
+
+	_, err = utils.ExecCombinedOutputLogged(nil, "qemu-img", "dd",
+		"-f", "qcow2", "-O", "raw", "bs=1M", "count=1",
+		"if="+pth, "of="+headPth)
+	if err != nil {
+		return
+	}
+
+	head, err := os.ReadFile(headPth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "imgimport: Failed to read image head"),
+		}
+		return
+	}
+
+	if len(head) < 512 || head[510] != 0x55 || head[511] != 0xaa {
+		return
+	}
+
+	firmware = image.Bios
+	gpt := false
+
+	for n := 0; n < 4; n++ {
+		switch head[446+n*16+4] {
+		case 0xee:
+			gpt = true
+			break
+		case 0xef:
+			firmware = image.Uefi
+			break
+		case 0xa5:
+			bsd = true
+			break
+		}
+	}
+
+	if !gpt {
+		return
+	}
+
+	for _, sectorSize := range []int{512, 4096} {
+		if len(head) < sectorSize*2 ||
+			string(head[sectorSize:sectorSize+8]) != "EFI PART" {
+
+			continue
+		}
+
+		hdr := head[sectorSize:]
+		entryLba := int(binary.LittleEndian.Uint64(hdr[72:80]))
+		entryCount := int(binary.LittleEndian.Uint32(hdr[80:84]))
+		entrySize := int(binary.LittleEndian.Uint32(hdr[84:88]))
+		if entrySize < 128 {
+			break
+		}
+
+		for n := 0; n < entryCount; n++ {
+			start := entryLba*sectorSize + n*entrySize
+			if start+16 > len(head) {
+				break
+			}
+			typ := head[start : start+16]
+
+			switch {
+			case bytes.Equal(typ, guidEsp):
+				firmware = image.Uefi
+				break
+			case bytes.Equal(typ, guidFreeBsdBoot),
+				bytes.Equal(typ, guidFreeBsdUfs),
+				bytes.Equal(typ, guidFreeBsdZfs):
+
+				bsd = true
+				break
+			}
+		}
+
+		break
+	}
+
+	return
+}
@@ -0,0 +1,139 @@
+package imgimport
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/storage"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+// newHttpClient returns a client for url imports, organization imports
+// are restricted to public addresses at connect time
+func newHttpClient(restricted bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   requestTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+
+	if restricted {
+		dialer.Control = utils.PublicDialControl
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext:           dialer.DialContext,
+			TLSHandshakeTimeout:   10 * time.Second,
+			ResponseHeaderTimeout: requestTimeout,
+			TLSClientConfig: &tls.Config{
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+	}
+}
+
+func (i *Import) fetchUrl(db *database.Database, pth string) (err error) {
+	client := newHttpClient(!i.IsGlobal())
+
+	req, err := http.NewRequest("GET", i.Url, nil)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "imgimport: Failed to create request"),
+		}
+		return
+	}
+
+	req.Header.Set("User-Agent", "pritunl-cloud")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "imgimport: Image request failed"),
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = &errortypes.RequestError{
+			errors.Newf("imgimport: Bad status %d from image request",
+				resp.StatusCode),
+		}
+		return
+	}
+
+	err = i.writeSource(db, pth, resp.Body, resp.ContentLength)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (i *Import) fetchUpload(db *database.Database,
+	store *storage.Storage, pth string) (err error) {
+
+	client, err := getClient(store)
+	if err != nil {
+		return
+	}
+
+	obj, err := client.Client.GetObject(context.Background(), store.Bucket,
+		i.UploadKey(), minio.GetObjectOptions{})
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "imgimport: Failed to get upload"),
+		}
+		return
+	}
+	defer obj.Close()
+
+	stat, err := obj.Stat()
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "imgimport: Failed to stat upload"),
+		}
+		return
+	}
+
+	err = i.writeSource(db, pth, obj, stat.Size)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (i *Import) writeSource(db *database.Database, pth string,
+	reader io.Reader, size int64) (err error) {
+
+	out, err := os.OpenFile(pth, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "imgimport: Failed to create source file"),
+		}
+		return
+	}
+	defer out.Close()
+
+	prog := newProgress(db, i, Downloading, size)
+
+	_, err = io.Copy(out, io.TeeReader(reader, prog))
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "imgimport: Failed to download image"),
+		}
+		return
+	}
+
+	return
+}
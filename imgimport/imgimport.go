@@ -0,0 +1,296 @@
+package imgimport
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/catalog"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/image"
+	"github.com/pritunl/pritunl-cloud/storage"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type Part struct {
+	Number int    `bson:"number" json:"number"`
+	Etag   string `bson:"etag" json:"etag"`
+	Size   int64  `bson:"size" json:"size"`
+}
+
+type Import struct {
+	Id           bson.ObjectID    `bson:"_id,omitempty" json:"id"`
+	Name         string           `bson:"name" json:"name"`
+	Comment      string           `bson:"comment" json:"comment"`
+	Organization bson.ObjectID    `bson:"organization" json:"organization"`
+	Storage      bson.ObjectID    `bson:"storage" json:"storage"`
+	Source       string           `bson:"source" json:"source"`
+	Url          string           `bson:"url" json:"url"`
+	Format       string           `bson:"format" json:"format"`
+	Detected     string           `bson:"detected" json:"detected"`
+	Firmware     string           `bson:"firmware" json:"firmware"`
+	SystemType   string           `bson:"system_type" json:"system_type"`
+	SystemKind   string           `bson:"system_kind" json:"system_kind"`
+	State        string           `bson:"state" json:"state"`
+	Stage        string           `bson:"stage" json:"stage"`
+	Error        string           `bson:"error" json:"error"`
+	Size         int64            `bson:"size" json:"size"`
+	ChunkSize    int64            `bson:"chunk_size" json:"chunk_size"`
+	UploadId     string           `bson:"upload_id" json:"-"`
+	Parts        map[string]*Part `bson:"parts" json:"parts"`
+	Progress     int              `bson:"progress" json:"progress"`
+	Speed        float64          `bson:"speed" json:"speed"`
+	Hash         string           `bson:"hash" json:"hash"`
+	Image        bson.ObjectID    `bson:"image" json:"image"`
+	Node         bson.ObjectID    `bson:"node" json:"node"`
+	Created      time.Time        `bson:"created" json:"created"`
+	Timestamp    time.Time        `bson:"timestamp" json:"timestamp"`
+}
+
+func (i *Import) Validate(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	i.Name = utils.FilterName(i.Name)
+
+	if i.Format == "" {
+		i.Format = Auto
+	}
+	if !ValidFormats.Contains(i.Format) {
+		errData = &errortypes.ErrorData{
+			Error:   "image_import_format_invalid",
+			Message: "Image import format invalid",
+		}
+		return
+	}
+
+	if i.Firmware != "" && i.Firmware != image.Uefi &&
+		i.Firmware != image.Bios {
+
+		errData = &errortypes.ErrorData{
+			Error:   "image_import_firmware_invalid",
+			Message: "Image import firmware invalid",
+		}
+		return
+	}
+
+	if i.SystemType != "" && !image.ValidSystemTypes.Contains(i.SystemType) {
+		errData = &errortypes.ErrorData{
+			Error:   "image_import_system_type_invalid",
+			Message: "Image import system type invalid",
+		}
+		return
+	}
+
+	if i.SystemKind != "" && !catalog.ValidSystemKind(i.SystemKind) {
+		errData = &errortypes.ErrorData{
+			Error:   "image_import_system_kind_invalid",
+			Message: "Image import system kind invalid",
+		}
+		return
+	}
+
+	switch i.Source {
+	case Upload:
+		i.Url = ""
+
+		if i.Size <= 0 {
+			errData = &errortypes.ErrorData{
+				Error:   "image_import_size_invalid",
+				Message: "Image import size required for uploads",
+			}
+			return
+		}
+
+		i.ChunkSize = ChunkSize
+		if i.PartCount() > MaxParts {
+			errData = &errortypes.ErrorData{
+				Error:   "image_import_size_invalid",
+				Message: "Image import size too large",
+			}
+			return
+		}
+
+		break
+	case Url:
+		i.Size = 0
+		i.ChunkSize = 0
+
+		u, e := url.Parse(i.Url)
+		if e != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+			u.Host == "" {
+
+			errData = &errortypes.ErrorData{
+				Error:   "image_import_url_invalid",
+				Message: "Image import URL must be a valid HTTP or HTTPS URL",
+			}
+			return
+		}
+
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "image_import_source_invalid",
+			Message: "Image import source invalid",
+		}
+		return
+	}
+
+	store, err := storage.Get(db, i.Storage)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+			errData = &errortypes.ErrorData{
+				Error:   "image_import_storage_invalid",
+				Message: "Image import storage not found",
+			}
+		}
+		return
+	}
+
+	if store.Type == storage.Web {
+		errData = &errortypes.ErrorData{
+			Error:   "image_import_storage_invalid",
+			Message: "Image import storage must be writable",
+		}
+		return
+	}
+
+	if store.Type == storage.Filesystem {
+		errData = &errortypes.ErrorData{
+			Error:   "image_import_storage_invalid",
+			Message: "Image import storage must be object storage",
+		}
+		return
+	}
+
+	if i.Parts == nil {
+		i.Parts = map[string]*Part{}
+	}
+
+	return
+}
+
+func (i *Import) PartCount() int {
+	if i.ChunkSize <= 0 {
+		return 0
+	}
+	return int((i.Size + i.ChunkSize - 1) / i.ChunkSize)
+}
+
+// PartSize returns the expected size of the one indexed part
+func (i *Import) PartSize(number int) int64 {
+	if number < i.PartCount() {
+		return i.ChunkSize
+	}
+	return i.Size - int64(number-1)*i.ChunkSize
+}
+
+func (i *Import) IsGlobal() bool {
+	return i.Organization.IsZero()
+}
+
+func (i *Import) UploadKey() string {
+	return fmt.Sprintf("%s%s.upload", keyPrefix, i.Id.Hex())
+}
+
+func (i *Import) ImageKey() string {
+	return fmt.Sprintf("%s%s.qcow2", keyPrefix, i.Id.Hex())
+}
+
+func (i *Import) SetPart(db *database.Database, part *Part) (err error) {
+	coll := db.ImageImports()
+
+	_, err = coll.UpdateOne(db, &bson.M{
+		"_id":   i.Id,
+		"state": Uploading,
+	}, &bson.M{
+		"$set": &bson.M{
+			fmt.Sprintf("parts.%d", part.Number): part,
+			"timestamp":                          time.Now(),
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func (i *Import) SetProgress(db *database.Database, stage string,
+	progress int, speedMb float64) (err error) {
+
+	coll := db.ImageImports()
+
+	i.Stage = stage
+	i.Progress = progress
+	i.Speed = speedMb
+	i.Timestamp = time.Now()
+
+	_, err = coll.UpdateOne(db, &bson.M{
+		"_id":  i.Id,
+		"node": i.Node,
+	}, &bson.M{
+		"$set": &bson.M{
+			"stage":     i.Stage,
+			"progress":  i.Progress,
+			"speed":     i.Speed,
+			"timestamp": i.Timestamp,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func (i *Import) Commit(db *database.Database) (err error) {
+	coll := db.ImageImports()
+
+	err = coll.Commit(i.Id, i)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (i *Import) CommitFields(db *database.Database, fields set.Set) (
+	err error) {
+
+	coll := db.ImageImports()
+
+	err = coll.CommitFields(i.Id, i, fields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (i *Import) Insert(db *database.Database) (err error) {
+	coll := db.ImageImports()
+
+	if !i.Id.IsZero() {
+		err = &errortypes.DatabaseError{
+			errors.New("imgimport: Image import already exists"),
+		}
+		return
+	}
+
+	i.Id = bson.NewObjectID()
+
+	_, err = coll.InsertOne(db, i)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,434 @@
+package imgimport
+
+import (
+	"context"
+	"path"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	minio "github.com/minio/minio-go/v7"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/image"
+	"github.com/pritunl/pritunl-cloud/paths"
+	"github.com/pritunl/pritunl-cloud/storage"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// Claim assigns the oldest pending import or an import abandoned by
+// another node to this node
+func Claim(db *database.Database, nodeId bson.ObjectID) (
+	imprt *Import, err error) {
+
+	coll := db.ImageImports()
+	now := time.Now()
+
+	imprt = &Import{}
+	err = coll.FindOneAndUpdate(
+		db,
+		&bson.M{
+			"$or": []*bson.M{
+				&bson.M{
+					"state": Pending,
+				},
+				&bson.M{
+					"state": Processing,
+					"timestamp": &bson.M{
+						"$lt": now.Add(-processTtl),
+					},
+				},
+			},
+		},
+		&bson.M{
+			"$set": &bson.M{
+				"state":     Processing,
+				"stage":     "",
+				"progress":  0,
+				"speed":     0,
+				"node":      nodeId,
+				"timestamp": now,
+			},
+		},
+		options.FindOneAndUpdate().SetSort(&bson.D{
+			{"created", 1},
+		}).SetReturnDocument(options.After),
+	).Decode(imprt)
+	if err != nil {
+		err = database.ParseError(err)
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+		}
+		imprt = nil
+		return
+	}
+
+	return
+}
+
+func (i *Import) heartbeat(db *database.Database) (stop func()) {
+	done := make(chan struct{})
+	coll := db.ImageImports()
+
+	go func() {
+		ticker := time.NewTicker(heartbeatRate)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			_, err := coll.UpdateOne(db, &bson.M{
+				"_id":   i.Id,
+				"node":  i.Node,
+				"state": Processing,
+			}, &bson.M{
+				"$set": &bson.M{
+					"timestamp": time.Now(),
+				},
+			})
+			if err != nil {
+				err = database.ParseError(err)
+				logrus.WithFields(logrus.Fields{
+					"import_id": i.Id.Hex(),
+					"error":     err,
+				}).Error("imgimport: Failed to update import heartbeat")
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+	}
+}
+
+func (i *Import) finish(db *database.Database, fields set.Set) (
+	err error) {
+
+	coll := db.ImageImports()
+
+	i.Timestamp = time.Now()
+	fields.Add("timestamp")
+
+	doc := bson.M{}
+	for fieldInf := range fields.Iter() {
+		field := fieldInf.(string)
+
+		switch field {
+		case "state":
+			doc[field] = i.State
+			break
+		case "stage":
+			doc[field] = i.Stage
+			break
+		case "error":
+			doc[field] = i.Error
+			break
+		case "progress":
+			doc[field] = i.Progress
+			break
+		case "speed":
+			doc[field] = i.Speed
+			break
+		case "detected":
+			doc[field] = i.Detected
+			break
+		case "hash":
+			doc[field] = i.Hash
+			break
+		case "image":
+			doc[field] = i.Image
+			break
+		case "timestamp":
+			doc[field] = i.Timestamp
+			break
+		}
+	}
+
+	_, err = coll.UpdateOne(db, &bson.M{
+		"_id":  i.Id,
+		"node": i.Node,
+	}, &bson.M{
+		"$set": doc,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Fail marks the import as failed with the error message
+func (i *Import) Fail(db *database.Database, cause error) (err error) {
+	i.State = Failed
+	i.Error = errors.GetMessage(cause)
+	i.Speed = 0
+
+	err = i.finish(db, set.NewSet("state", "error", "speed"))
+	if err != nil {
+		return
+	}
+
+	_ = i.Cleanup(db)
+
+	event.PublishDispatch(db, "image_import.change")
+
+	return
+}
+
+// Process downloads, converts and stores a claimed import
+func (i *Import) Process(db *database.Database) (err error) {
+	stop := i.heartbeat(db)
+	defer stop()
+
+	err = i.process(db)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_id": i.Id.Hex(),
+			"source":    i.Source,
+			"error":     err,
+		}).Error("imgimport: Image import failed")
+
+		err = i.Fail(db, err)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (i *Import) process(db *database.Database) (err error) {
+	store, err := storage.Get(db, i.Storage)
+	if err != nil {
+		return
+	}
+
+	tmpDir := paths.GetTempDir()
+	defer utils.RemoveAll(tmpDir)
+
+	err = utils.ExistsMkdir(tmpDir, 0700)
+	if err != nil {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"import_id":  i.Id.Hex(),
+		"storage_id": store.Id.Hex(),
+		"source":     i.Source,
+		"url":        i.Url,
+	}).Info("imgimport: Processing image import")
+
+	srcPth := path.Join(tmpDir, "source")
+	if i.Source == Url {
+		err = i.fetchUrl(db, srcPth)
+	} else {
+		err = i.fetchUpload(db, store, srcPth)
+	}
+	if err != nil {
+		return
+	}
+
+	format := i.Format
+	if format == Auto {
+		format, err = detectFormat(srcPth)
+		if err != nil {
+			return
+		}
+	}
+
+	ovfFirm := ""
+	if format == Ova {
+		err = i.SetProgress(db, Extracting, 0, 0)
+		if err != nil {
+			return
+		}
+
+		diskPth, ovf, e := extractOva(srcPth, tmpDir)
+		if e != nil {
+			err = e
+			return
+		}
+		ovfFirm = ovfFirmware(ovf)
+
+		utils.Remove(srcPth)
+		srcPth = diskPth
+
+		format, err = detectFormat(srcPth)
+		if err != nil {
+			return
+		}
+
+		if format == Ova {
+			err = &errortypes.ParseError{
+				errors.New("imgimport: Nested ova archive not supported"),
+			}
+			return
+		}
+	}
+
+	i.Detected = format
+	err = i.finish(db, set.NewSet("detected"))
+	if err != nil {
+		return
+	}
+
+	err = i.SetProgress(db, Converting, 0, 0)
+	if err != nil {
+		return
+	}
+
+	err = checkImage(srcPth, format)
+	if err != nil {
+		return
+	}
+
+	imgPth := path.Join(tmpDir, "image.qcow2")
+	_, err = utils.ExecCombinedOutputLogged(nil, "qemu-img", "convert",
+		"-f", format, "-O", "qcow2", srcPth, imgPth)
+	if err != nil {
+		return
+	}
+	utils.Remove(srcPth)
+
+	firmware, bsd, err := detectBoot(imgPth, tmpDir)
+	if err != nil {
+		return
+	}
+
+	if i.Firmware != "" {
+		firmware = i.Firmware
+	} else if ovfFirm != "" {
+		firmware = ovfFirm
+	} else if firmware == "" {
+		firmware = image.Unknown
+	}
+
+	err = utils.Chmod(imgPth, 0600)
+	if err != nil {
+		return
+	}
+
+	hash, err := utils.FileSha256(imgPth)
+	if err != nil {
+		return
+	}
+
+	err = i.SetProgress(db, Storing, 0, 0)
+	if err != nil {
+		return
+	}
+
+	client, err := getClient(store)
+	if err != nil {
+		return
+	}
+
+	key := i.ImageKey()
+
+	_, err = client.FPutObject(context.Background(),
+		store.Bucket, key, imgPth, minio.PutObjectOptions{})
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "imgimport: Failed to write object"),
+		}
+		return
+	}
+
+	obj, err := client.StatObject(context.Background(),
+		store.Bucket, key, minio.StatObjectOptions{})
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "imgimport: Failed to stat object"),
+		}
+		return
+	}
+
+	img := &image.Image{
+		Name:         i.Name,
+		Comment:      i.Comment,
+		Organization: i.Organization,
+		Type:         store.Type,
+		SystemType:   i.SystemType,
+		SystemKind:   i.SystemKind,
+		Firmware:     firmware,
+		Storage:      store.Id,
+		Key:          key,
+		LastModified: obj.LastModified,
+		StorageClass: storage.ParseStorageClass(obj),
+		Hash:         hash,
+		Etag:         image.GetEtag(obj),
+	}
+
+	if bsd && img.SystemType == "" {
+		img.SystemType = image.Bsd
+		if img.SystemKind == "" {
+			img.SystemKind = image.FreeBSD
+		}
+	}
+	img.SystemType = img.GetSystemType()
+	img.SystemKind = img.GetSystemKind()
+
+	err = img.Upsert(db)
+	if err != nil {
+		return
+	}
+
+	if img.Id.IsZero() {
+		existing, e := image.GetKey(db, store.Id, key)
+		if e != nil {
+			err = e
+			return
+		}
+		img.Id = existing.Id
+	}
+
+	if img.Comment != "" {
+		err = img.CommitFields(db, set.NewSet("comment"))
+		if err != nil {
+			return
+		}
+	}
+
+	i.State = Complete
+	i.Stage = ""
+	i.Progress = 100
+	i.Speed = 0
+	i.Hash = hash
+	i.Image = img.Id
+	err = i.finish(db, set.NewSet(
+		"state", "stage", "progress", "speed", "hash", "image"))
+	if err != nil {
+		return
+	}
+
+	err = i.Cleanup(db)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"import_id": i.Id.Hex(),
+			"error":     err,
+		}).Error("imgimport: Failed to remove import upload")
+		err = nil
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"import_id": i.Id.Hex(),
+		"image_id":  img.Id.Hex(),
+		"format":    format,
+		"firmware":  firmware,
+		"hash":      hash,
+	}).Info("imgimport: Image import complete")
+
+	event.PublishDispatch(db, "image_import.change")
+	event.PublishDispatch(db, "image.change")
+
+	return
+}
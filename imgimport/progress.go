@@ -0,0 +1,66 @@
+package imgimport
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-cloud/database"
+)
+
+// progress reports transfer progress in ten percent steps in the same
+// way as disk image downloads
+type progress struct {
+	db         *database.Database
+	imprt      *Import
+	stage      string
+	Total      int64
+	Wrote      int64
+	LastWrote  int64
+	LastReport int
+	LastTime   time.Time
+}
+
+func newProgress(db *database.Database, imprt *Import, stage string,
+	size int64) (prog *progress) {
+
+	prog = &progress{
+		db:       db,
+		imprt:    imprt,
+		stage:    stage,
+		Total:    size,
+		LastTime: time.Now(),
+	}
+
+	_ = imprt.SetProgress(db, stage, 0, 0)
+
+	return
+}
+
+func (p *progress) Write(data []byte) (n int, err error) {
+	n = len(data)
+	p.Wrote += int64(n)
+
+	if p.Total <= 0 {
+		return
+	}
+
+	percent := int(float64(p.Wrote) / float64(p.Total) * 100)
+	if percent > 100 {
+		percent = 100
+	}
+
+	if percent >= p.LastReport+10 {
+		now := time.Now()
+		elapsed := now.Sub(p.LastTime).Seconds()
+
+		speed := float64(p.Wrote-p.LastWrote) / elapsed
+
+		p.LastTime = now
+		p.LastWrote = p.Wrote
+		p.LastReport = percent - (percent % 10)
+
+		_ = p.imprt.SetProgress(
+			p.db, p.stage, p.LastReport, speed/1_000_000.0)
+	}
+
+	return
+}
@@ -0,0 +1,219 @@
+package imgimport
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
+)
+
+func Get(db *database.Database, importId bson.ObjectID) (
+	imprt *Import, err error) {
+
+	coll := db.ImageImports()
+	imprt = &Import{}
+
+	err = coll.FindOneId(importId, imprt)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetOrg(db *database.Database, orgId, importId bson.ObjectID) (
+	imprt *Import, err error) {
+
+	coll := db.ImageImports()
+	imprt = &Import{}
+
+	err = coll.FindOne(db, &bson.M{
+		"_id":          importId,
+		"organization": orgId,
+	}).Decode(imprt)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetAll(db *database.Database, query *bson.M) (
+	imprts []*Import, err error) {
+
+	coll := db.ImageImports()
+	imprts = []*Import{}
+
+	cursor, err := coll.Find(db, query)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		imprt := &Import{}
+		err = cursor.Decode(imprt)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		imprts = append(imprts, imprt)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetAllPaged(db *database.Database, query *bson.M,
+	page, pageCount int64) (imprts []*Import, count int64, err error) {
+
+	coll := db.ImageImports()
+	imprts = []*Import{}
+
+	count, err = coll.CountDocuments(db, query)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	if pageCount == 0 {
+		pageCount = 20
+	}
+	maxPage := count / pageCount
+	if count == pageCount {
+		maxPage = 0
+	}
+	page = utils.Min64(page, maxPage)
+	skip := utils.Min64(page*pageCount, count)
+
+	cursor, err := coll.Find(
+		db,
+		query,
+		options.Find().
+			SetSort(bson.D{{"created", -1}}).
+			SetSkip(skip).
+			SetLimit(pageCount),
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		imprt := &Import{}
+		err = cursor.Decode(imprt)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		imprts = append(imprts, imprt)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func remove(db *database.Database, imprt *Import) (err error) {
+	coll := db.ImageImports()
+
+	err = imprt.Cleanup(db)
+	if err != nil {
+		return
+	}
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"_id": imprt.Id,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		switch err.(type) {
+		case *database.NotFoundError:
+			err = nil
+		default:
+			return
+		}
+	}
+
+	return
+}
+
+func Remove(db *database.Database, importId bson.ObjectID) (err error) {
+	imprt, err := Get(db, importId)
+	if err != nil {
+		return
+	}
+
+	err = remove(db, imprt)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func RemoveOrg(db *database.Database, orgId, importId bson.ObjectID) (
+	err error) {
+
+	imprt, err := GetOrg(db, orgId, importId)
+	if err != nil {
+		return
+	}
+
+	err = remove(db, imprt)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Clean removes abandoned uploads and old failed imports
+func Clean(db *database.Database) (err error) {
+	imprts, err := GetAll(db, &bson.M{
+		"state": &bson.M{
+			"$in": []string{Uploading, Failed},
+		},
+		"timestamp": &bson.M{
+			"$lt": time.Now().Add(-uploadTtl),
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	for _, imprt := range imprts {
+		e := remove(db, imprt)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"import_id": imprt.Id.Hex(),
+				"error":     e,
+			}).Error("imgimport: Failed to remove stale import")
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"import_id": imprt.Id.Hex(),
+			"state":     imprt.State,
+		}).Info("imgimport: Removed stale image import")
+	}
+
+	return
+}
@@ -0,0 +1,76 @@
+package instance
+
+import (
+	"slices"
+
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/nodeport"
+)
+
+// Clone returns a new instance with the configuration of the instance on
+// the same node, identifiers, addresses, secrets and disks are not copied.
+// Host devices are exclusive to the source and are never copied
+func (i *Instance) Clone(name string, dataDisks, devices, quiesce bool) (
+	inst *Instance, errData *errortypes.ErrorData) {
+
+	if devices {
+		errData = &errortypes.ErrorData{
+			Error:   "clone_devices_invalid",
+			Message: "Host devices cannot be cloned to another instance",
+		}
+		return
+	}
+
+	nodePorts := []*nodeport.Mapping{}
+	for _, mapping := range i.NodePorts {
+		nodePorts = append(nodePorts, &nodeport.Mapping{
+			Protocol:     mapping.Protocol,
+			InternalPort: mapping.InternalPort,
+		})
+	}
+
+	inst = &Instance{
+		Action:              Start,
+		Organization:        i.Organization,
+		Zone:                i.Zone,
+		Vpc:                 i.Vpc,
+		Subnet:              i.Subnet,
+		CloudSubnet:         i.CloudSubnet,
+		Shape:               i.Shape,
+		Node:                i.Node,
+		DiskType:            i.DiskType,
+		DiskPool:            i.DiskPool,
+		Image:               i.Image,
+		CloneSource:         i.Id,
+		CloneDisks:          dataDisks,
+		CloneQuiesce:        quiesce,
+		Uefi:                i.Uefi,
+		SecureBoot:          i.SecureBoot,
+		Tpm:                 i.Tpm,
+		DhcpServer:          i.DhcpServer,
+		CloudType:           i.CloudType,
+		CloudScript:         i.CloudScript,
+		CloudUser:           i.CloudUser,
+		SystemKind:          i.SystemKind,
+		SkipSourceDestCheck: i.SkipSourceDestCheck,
+		PowerSchedules:      slices.Clone(i.PowerSchedules),
+		Name:                name,
+		Comment:             i.Comment,
+		InitDiskSize:        i.InitDiskSize,
+		Memory:              i.Memory,
+		Processors:          i.Processors,
+		Roles:               slices.Clone(i.Roles),
+		Isos:                slices.Clone(i.Isos),
+		Mounts:              slices.Clone(i.Mounts),
+		RootEnabled:         i.RootEnabled,
+		Vnc:                 i.Vnc,
+		Spice:               i.Spice,
+		Gui:                 i.Gui,
+		NodePorts:           nodePorts,
+		NoPublicAddress:     i.NoPublicAddress,
+		NoPublicAddress6:    i.NoPublicAddress6,
+		NoHostAddress:       i.NoHostAddress,
+	}
+
+	return
+}
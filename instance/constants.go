@@ -58,12 +58,4 @@ var (
 		LinuxLegacy,
 		BSD,
 	)
-	ValidSystemKinds = set.NewSet(
-		AlpineLinux,
-		ArchLinux,
-		RedHat,
-		Fedora,
-		Ubuntu,
-		FreeBSD,
-	)
 )
@@ -7,3 +7,7 @@ import (
 type VncDialError struct {
 	errors.DropboxError
 }
+
+type SerialDialError struct {
+	errors.DropboxError
+}
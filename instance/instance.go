@@ -16,6 +16,7 @@ import (
 	"github.com/gorilla/websocket"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/block"
+	"github.com/pritunl/pritunl-cloud/catalog"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/disk"
 	"github.com/pritunl/pritunl-cloud/drive"
@@ -29,6 +30,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/paths"
 	"github.com/pritunl/pritunl-cloud/pci"
 	"github.com/pritunl/pritunl-cloud/pool"
+	"github.com/pritunl/pritunl-cloud/schedule"
 	"github.com/pritunl/pritunl-cloud/settings"
 	"github.com/pritunl/pritunl-cloud/shape"
 	"github.com/pritunl/pritunl-cloud/systemd"
@@ -43,95 +45,103 @@ import (
 var scriptReg = regexp.MustCompile("^#!")
 
 type Instance struct {
-	Id                  bson.ObjectID       `bson:"_id,omitempty" json:"id"`
-	Organization        bson.ObjectID       `bson:"organization" json:"organization"`
-	UnixId              int                 `bson:"unix_id" json:"unix_id"`
-	Datacenter          bson.ObjectID       `bson:"datacenter" json:"datacenter"`
-	Zone                bson.ObjectID       `bson:"zone" json:"zone"`
-	Vpc                 bson.ObjectID       `bson:"vpc" json:"vpc"`
-	Subnet              bson.ObjectID       `bson:"subnet" json:"subnet"`
-	Created             time.Time           `bson:"created" json:"created"`
-	Guest               *GuestData          `bson:"guest,omitempty" json:"guest"`
-	CloudSubnet         string              `bson:"cloud_subnet" json:"cloud_subnet"`
-	CloudVnic           string              `bson:"cloud_vnic" json:"cloud_vnic"`
-	CloudVnicAttach     string              `bson:"cloud_vnic_attach" json:"cloud_vnic_attach"`
-	Image               bson.ObjectID       `bson:"image" json:"image"`
-	ImageBacking        bool                `bson:"image_backing" json:"image_backing"`
-	DiskType            string              `bson:"disk_type" json:"disk_type"`
-	DiskPool            bson.ObjectID       `bson:"disk_pool" json:"disk_pool"`
-	Status              string              `bson:"-" json:"status"`
-	StatusInfo          *StatusInfo         `bson:"status_info,omitempty" json:"status_info"`
-	Uptime              string              `bson:"-" json:"uptime"`
-	State               string              `bson:"state" json:"state"`
-	Action              string              `bson:"action" json:"action"`
-	PublicMac           string              `bson:"-" json:"public_mac"`
-	Timestamp           time.Time           `bson:"timestamp" json:"timestamp"`
-	Restart             bool                `bson:"restart" json:"restart"`
-	RestartReason       string              `bson:"restart_reason" json:"restart_reason"`
-	RestartBlockIp      bool                `bson:"restart_block_ip" json:"restart_block_ip"`
-	ResetFirmware       bool                `bson:"reset_firmware" json:"reset_firmware"`
-	Uefi                bool                `bson:"uefi" json:"uefi"`
-	SecureBoot          bool                `bson:"secure_boot" json:"secure_boot"`
-	Tpm                 bool                `bson:"tpm" json:"tpm"`
-	TpmSecret           string              `bson:"tpm_secret" json:"-"`
-	DhcpServer          bool                `bson:"dhcp_server" json:"dhcp_server"`
-	CloudType           string              `bson:"cloud_type" json:"cloud_type"`
-	CloudScript         string              `bson:"cloud_script" json:"cloud_script"`
-	SystemKind          string              `bson:"system_kind" json:"system_kind"`
-	DeleteProtection    bool                `bson:"delete_protection" json:"delete_protection"`
-	SkipSourceDestCheck bool                `bson:"skip_source_dest_check" json:"skip_source_dest_check"`
-	QemuVersion         string              `bson:"qemu_version" json:"qemu_version"`
-	PublicIps           []string            `bson:"public_ips" json:"public_ips"`
-	PublicIps6          []string            `bson:"public_ips6" json:"public_ips6"`
-	PrivateIps          []string            `bson:"private_ips" json:"private_ips"`
-	PrivateIps6         []string            `bson:"private_ips6" json:"private_ips6"`
-	GatewayIps          []string            `bson:"gateway_ips" json:"gateway_ips"`
-	GatewayIps6         []string            `bson:"gateway_ips6" json:"gateway_ips6"`
-	CloudPrivateIps     []string            `bson:"cloud_private_ips" json:"cloud_private_ips"`
-	CloudPublicIps      []string            `bson:"cloud_public_ips" json:"cloud_public_ips"`
-	CloudPublicIps6     []string            `bson:"cloud_public_ips6" json:"cloud_public_ips6"`
-	HostIps             []string            `bson:"host_ips" json:"host_ips"`
-	NodePortIps         []string            `bson:"node_port_ips" json:"node_port_ips"`
-	AdvisoryCount       int                 `bson:"advisory_count" json:"advisory_count"`
-	AdvisoryMax         int                 `bson:"advisory_max" json:"advisory_max"`
-	NodePorts           []*nodeport.Mapping `bson:"node_ports,omitempty" json:"node_ports"`
-	DhcpIp              string              `bson:"dhcp_ip" json:"dhcp_ip"`
-	DhcpIp6             string              `bson:"dhcp_ip6" json:"dhcp_ip6"`
-	NetworkNamespace    string              `bson:"network_namespace" json:"network_namespace"`
-	NoPublicAddress     bool                `bson:"no_public_address" json:"no_public_address"`
-	NoPublicAddress6    bool                `bson:"no_public_address6" json:"no_public_address6"`
-	NoHostAddress       bool                `bson:"no_host_address" json:"no_host_address"`
-	Node                bson.ObjectID       `bson:"node" json:"node"`
-	Shape               bson.ObjectID       `bson:"shape" json:"shape"`
-	Name                string              `bson:"name" json:"name"`
-	Comment             string              `bson:"comment" json:"comment"`
-	RootEnabled         bool                `bson:"root_enabled" json:"root_enabled"`
-	RootPasswd          string              `bson:"root_passwd" json:"root_passwd"`
-	InitDiskSize        int                 `bson:"init_disk_size" json:"init_disk_size"`
-	Memory              int                 `bson:"memory" json:"memory"`
-	Processors          int                 `bson:"processors" json:"processors"`
-	Roles               []string            `bson:"roles" json:"roles"`
-	Isos                []*iso.Iso          `bson:"isos,omitempty" json:"isos"`
-	UsbDevices          []*usb.Device       `bson:"usb_devices,omitempty" json:"usb_devices"`
-	PciDevices          []*pci.Device       `bson:"pci_devices,omitempty" json:"pci_devices"`
-	DriveDevices        []*drive.Device     `bson:"drive_devices,omitempty" json:"drive_devices"`
-	IscsiDevices        []*iscsi.Device     `bson:"iscsi_devices,omitempty" json:"iscsi_devices"`
-	Mounts              []*Mount            `bson:"mounts,omitempty" json:"mounts"`
-	Vnc                 bool                `bson:"vnc" json:"vnc"`
-	VncPassword         string              `bson:"vnc_password" json:"vnc_password"`
-	VncDisplay          int                 `bson:"vnc_display" json:"vnc_display"`
-	Spice               bool                `bson:"spice" json:"spice"`
-	SpicePassword       string              `bson:"spice_password" json:"spice_password"`
-	SpicePort           int                 `bson:"spice_port" json:"spice_port"`
-	Gui                 bool                `bson:"gui" json:"gui"`
-	Deployment          bson.ObjectID       `bson:"deployment" json:"deployment"`
-	Info                *Info               `bson:"info,omitempty" json:"info"`
-	Virt                *vm.VirtualMachine  `bson:"-" json:"-"`
+	Id                  bson.ObjectID        `bson:"_id,omitempty" json:"id"`
+	Organization        bson.ObjectID        `bson:"organization" json:"organization"`
+	UnixId              int                  `bson:"unix_id" json:"unix_id"`
+	Datacenter          bson.ObjectID        `bson:"datacenter" json:"datacenter"`
+	Zone                bson.ObjectID        `bson:"zone" json:"zone"`
+	Vpc                 bson.ObjectID        `bson:"vpc" json:"vpc"`
+	Subnet              bson.ObjectID        `bson:"subnet" json:"subnet"`
+	Created             time.Time            `bson:"created" json:"created"`
+	Guest               *GuestData           `bson:"guest,omitempty" json:"guest"`
+	CloudSubnet         string               `bson:"cloud_subnet" json:"cloud_subnet"`
+	CloudVnic           string               `bson:"cloud_vnic" json:"cloud_vnic"`
+	CloudVnicAttach     string               `bson:"cloud_vnic_attach" json:"cloud_vnic_attach"`
+	Image               bson.ObjectID        `bson:"image" json:"image"`
+	ImageBacking        bool                 `bson:"image_backing" json:"image_backing"`
+	DiskType            string               `bson:"disk_type" json:"disk_type"`
+	DiskPool            bson.ObjectID        `bson:"disk_pool" json:"disk_pool"`
+	CloneSource         bson.ObjectID        `bson:"clone_source" json:"clone_source"`
+	CloneDisks          bool                 `bson:"clone_disks" json:"clone_disks"`
+	CloneQuiesce        bool                 `bson:"clone_quiesce" json:"clone_quiesce"`
+	Status              string               `bson:"-" json:"status"`
+	StatusInfo          *StatusInfo          `bson:"status_info,omitempty" json:"status_info"`
+	Uptime              string               `bson:"-" json:"uptime"`
+	State               string               `bson:"state" json:"state"`
+	Action              string               `bson:"action" json:"action"`
+	PublicMac           string               `bson:"-" json:"public_mac"`
+	Timestamp           time.Time            `bson:"timestamp" json:"timestamp"`
+	Restart             bool                 `bson:"restart" json:"restart"`
+	RestartReason       string               `bson:"restart_reason" json:"restart_reason"`
+	RestartBlockIp      bool                 `bson:"restart_block_ip" json:"restart_block_ip"`
+	ResetFirmware       bool                 `bson:"reset_firmware" json:"reset_firmware"`
+	Uefi                bool                 `bson:"uefi" json:"uefi"`
+	SecureBoot          bool                 `bson:"secure_boot" json:"secure_boot"`
+	Tpm                 bool                 `bson:"tpm" json:"tpm"`
+	TpmSecret           string               `bson:"tpm_secret" json:"-"`
+	DhcpServer          bool                 `bson:"dhcp_server" json:"dhcp_server"`
+	CloudType           string               `bson:"cloud_type" json:"cloud_type"`
+	CloudScript         string               `bson:"cloud_script" json:"cloud_script"`
+	CloudUser           string               `bson:"cloud_user" json:"cloud_user"`
+	SystemKind          string               `bson:"system_kind" json:"system_kind"`
+	DeleteProtection    bool                 `bson:"delete_protection" json:"delete_protection"`
+	PowerSchedules      []*schedule.Schedule `bson:"power_schedules,omitempty" json:"power_schedules"`
+	Expire              time.Time            `bson:"expire" json:"expire"`
+	SkipSourceDestCheck bool                 `bson:"skip_source_dest_check" json:"skip_source_dest_check"`
+	QemuVersion         string               `bson:"qemu_version" json:"qemu_version"`
+	PublicIps           []string             `bson:"public_ips" json:"public_ips"`
+	PublicIps6          []string             `bson:"public_ips6" json:"public_ips6"`
+	PrivateIps          []string             `bson:"private_ips" json:"private_ips"`
+	PrivateIps6         []string             `bson:"private_ips6" json:"private_ips6"`
+	GatewayIps          []string             `bson:"gateway_ips" json:"gateway_ips"`
+	GatewayIps6         []string             `bson:"gateway_ips6" json:"gateway_ips6"`
+	CloudPrivateIps     []string             `bson:"cloud_private_ips" json:"cloud_private_ips"`
+	CloudPublicIps      []string             `bson:"cloud_public_ips" json:"cloud_public_ips"`
+	CloudPublicIps6     []string             `bson:"cloud_public_ips6" json:"cloud_public_ips6"`
+	HostIps             []string             `bson:"host_ips" json:"host_ips"`
+	NodePortIps         []string             `bson:"node_port_ips" json:"node_port_ips"`
+	AdvisoryCount       int                  `bson:"advisory_count" json:"advisory_count"`
+	AdvisoryMax         int                  `bson:"advisory_max" json:"advisory_max"`
+	NodePorts           []*nodeport.Mapping  `bson:"node_ports,omitempty" json:"node_ports"`
+	DhcpIp              string               `bson:"dhcp_ip" json:"dhcp_ip"`
+	DhcpIp6             string               `bson:"dhcp_ip6" json:"dhcp_ip6"`
+	NetworkNamespace    string               `bson:"network_namespace" json:"network_namespace"`
+	NoPublicAddress     bool                 `bson:"no_public_address" json:"no_public_address"`
+	NoPublicAddress6    bool                 `bson:"no_public_address6" json:"no_public_address6"`
+	NoHostAddress       bool                 `bson:"no_host_address" json:"no_host_address"`
+	Node                bson.ObjectID        `bson:"node" json:"node"`
+	Shape               bson.ObjectID        `bson:"shape" json:"shape"`
+	Name                string               `bson:"name" json:"name"`
+	Comment             string               `bson:"comment" json:"comment"`
+	RootEnabled         bool                 `bson:"root_enabled" json:"root_enabled"`
+	RootPasswd          string               `bson:"root_passwd" json:"root_passwd"`
+	InitDiskSize        int                  `bson:"init_disk_size" json:"init_disk_size"`
+	Memory              int                  `bson:"memory" json:"memory"`
+	Processors          int                  `bson:"processors" json:"processors"`
+	Roles               []string             `bson:"roles" json:"roles"`
+	Isos                []*iso.Iso           `bson:"isos,omitempty" json:"isos"`
+	UsbDevices          []*usb.Device        `bson:"usb_devices,omitempty" json:"usb_devices"`
+	PciDevices          []*pci.Device        `bson:"pci_devices,omitempty" json:"pci_devices"`
+	DriveDevices        []*drive.Device      `bson:"drive_devices,omitempty" json:"drive_devices"`
+	IscsiDevices        []*iscsi.Device      `bson:"iscsi_devices,omitempty" json:"iscsi_devices"`
+	Mounts              []*Mount             `bson:"mounts,omitempty" json:"mounts"`
+	Vnc                 bool                 `bson:"vnc" json:"vnc"`
+	VncPassword         string               `bson:"vnc_password" json:"vnc_password"`
+	VncDisplay          int                  `bson:"vnc_display" json:"vnc_display"`
+	SerialPassword      string               `bson:"serial_password" json:"-"`
+	Spice               bool                 `bson:"spice" json:"spice"`
+	SpicePassword       string               `bson:"spice_password" json:"spice_password"`
+	SpicePort           int                  `bson:"spice_port" json:"spice_port"`
+	Gui                 bool                 `bson:"gui" json:"gui"`
+	Deployment          bson.ObjectID        `bson:"deployment" json:"deployment"`
+	Info                *Info                `bson:"info,omitempty" json:"info"`
+	Virt                *vm.VirtualMachine   `bson:"-" json:"-"`
 
 	curVpc              bson.ObjectID                       `bson:"-" json:"-"`
 	curSubnet           bson.ObjectID                       `bson:"-" json:"-"`
 	curDeleteProtection bool                                `bson:"-" json:"-"`
 	curAction           string                              `bson:"-" json:"-"`
+	curExpire           time.Time                           `bson:"-" json:"-"`
 	curNoPublicAddress  bool                                `bson:"-" json:"-"`
 	curNoHostAddress    bool                                `bson:"-" json:"-"`
 	curNodePorts        map[bson.ObjectID]*nodeport.Mapping `bson:"-" json:"-"`
@@ -308,6 +318,9 @@ func (i *Instance) Validate(db *database.Database) (
 		break
 	case disk.Qcow2, "":
 		i.DiskType = disk.Qcow2
+		if !i.DiskPool.IsZero() {
+			i.ImageBacking = false
+		}
 	}
 
 	vc, err := vpc.Get(db, i.Vpc)
@@ -380,7 +393,7 @@ func (i *Instance) Validate(db *database.Database) (
 		return
 	}
 
-	if i.SystemKind != "" && !ValidSystemKinds.Contains(i.SystemKind) {
+	if i.SystemKind != "" && !catalog.ValidSystemKind(i.SystemKind) {
 		errData = &errortypes.ErrorData{
 			Error:   "invalid_system_kind",
 			Message: "Instance system kind invalid",
@@ -388,6 +401,17 @@ func (i *Instance) Validate(db *database.Database) (
 		return
 	}
 
+	if i.CloudUser == "" {
+		i.CloudUser = catalog.DefaultUser
+	}
+	if !catalog.ValidDefaultUser(i.CloudUser) {
+		errData = &errortypes.ErrorData{
+			Error:   "invalid_cloud_user",
+			Message: "Instance cloud user invalid",
+		}
+		return
+	}
+
 	if i.CloudScript != "" && !scriptReg.MatchString(i.CloudScript) {
 		errData = &errortypes.ErrorData{
 			Error:   "invalid_cloud_script",
@@ -610,6 +634,13 @@ func (i *Instance) Validate(db *database.Database) (
 		i.VncPassword = ""
 	}
 
+	if i.SerialPassword == "" {
+		i.SerialPassword, err = utils.RandPasswd(32)
+		if err != nil {
+			return
+		}
+	}
+
 	if i.Spice {
 		if i.SpicePassword == "" {
 			i.SpicePassword, err = utils.RandPasswd(32)
@@ -621,6 +652,31 @@ func (i *Instance) Validate(db *database.Database) (
 		i.SpicePassword = ""
 	}
 
+	if len(i.PowerSchedules) > 10 {
+		errData = &errortypes.ErrorData{
+			Error:   "power_schedules_invalid",
+			Message: "Too many power schedules",
+		}
+		return
+	}
+
+	for _, schd := range i.PowerSchedules {
+		errData, err = schd.Validate()
+		if err != nil || errData != nil {
+			return
+		}
+	}
+
+	if !i.Expire.IsZero() && i.Expire != i.curExpire &&
+		i.Expire.Before(time.Now()) {
+
+		errData = &errortypes.ErrorData{
+			Error:   "expire_invalid",
+			Message: "Instance expire time must be in the future",
+		}
+		return
+	}
+
 	externalNodePorts := set.NewSet()
 	for _, mapping := range i.NodePorts {
 		extPortKey := fmt.Sprintf("%s:%d",
@@ -868,6 +924,7 @@ func (i *Instance) PreCommit() {
 	i.curSubnet = i.Subnet
 	i.curDeleteProtection = i.DeleteProtection
 	i.curAction = i.Action
+	i.curExpire = i.Expire
 	i.curNoPublicAddress = i.NoPublicAddress
 	i.curNoHostAddress = i.NoHostAddress
 
@@ -1259,10 +1316,24 @@ func (i *Instance) LoadVirt(poolsMap map[bson.ObjectID]*pool.Pool,
 					continue
 				}
 
+				dskPth := paths.GetDiskPath(dsk.Id)
+				if dsk.IsShared() {
+					if poolsMap == nil {
+						continue
+					}
+
+					pl := poolsMap[dsk.Pool]
+					if pl == nil {
+						continue
+					}
+
+					dskPth = pl.GetDiskPath(dsk.Id)
+				}
+
 				i.Virt.Disks = append(i.Virt.Disks, &vm.Disk{
 					Id:    dsk.Id,
 					Index: index,
-					Path:  paths.GetDiskPath(dsk.Id),
+					Path:  dskPth,
 				})
 				break
 			}
@@ -1624,32 +1695,39 @@ func (i *Instance) GetChart(c context.Context, db *database.Database,
 	return
 }
 
-func (i *Instance) VncConnect(db *database.Database,
-	rw http.ResponseWriter, r *http.Request) (err error) {
-
-	nde, err := node.Get(db, i.Node)
-	if err != nil {
-		return
-	}
-
-	vncHost := ""
+func getNodeHost(nde *node.Node) string {
 	if nde.Id == node.Self.Id {
-		vncHost = "127.0.0.1"
+		return "127.0.0.1"
 	}
-	if vncHost == "" && len(nde.PrivateIps) > 0 {
-		vncHost = nde.PrivateIps[nde.DefaultInterface]
-		if vncHost == "" {
+
+	host := ""
+	if len(nde.PrivateIps) > 0 {
+		host = nde.PrivateIps[nde.DefaultInterface]
+		if host == "" {
 			for _, privIp := range nde.PrivateIps {
 				if privIp != "" {
-					vncHost = privIp
+					host = privIp
 					break
 				}
 			}
 		}
 	}
-	if vncHost == "" && len(nde.PublicIps) > 0 {
-		vncHost = nde.PublicIps[0]
+	if host == "" && len(nde.PublicIps) > 0 {
+		host = nde.PublicIps[0]
 	}
+
+	return host
+}
+
+func (i *Instance) VncConnect(db *database.Database,
+	rw http.ResponseWriter, r *http.Request) (err error) {
+
+	nde, err := node.Get(db, i.Node)
+	if err != nil {
+		return
+	}
+
+	vncHost := getNodeHost(nde)
 	if vncHost == "" {
 		err = &errortypes.NotFoundError{
 			errors.New("instance: Node missing IP for VNC"),
@@ -0,0 +1,38 @@
+package instance
+
+import (
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ScheduledAction returns the action of the most recent power schedule due
+// after the last run and up to the time or an empty string if no schedule
+// is due, schedules later in the list take priority in the same minute
+func (i *Instance) ScheduledAction(lastRun, now time.Time) (action string) {
+	latest := time.Time{}
+
+	for _, schd := range i.PowerSchedules {
+		last, err := schd.Last(lastRun, now)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"instance_id": i.Id.Hex(),
+				"cron":        schd.Cron,
+				"error":       err,
+			}).Error("instance: Failed to parse power schedule")
+			continue
+		}
+
+		if !last.IsZero() && !last.Before(latest) {
+			latest = last
+			action = schd.Action
+		}
+	}
+
+	return
+}
+
+// Expired returns true if the instance has an expire time that has passed
+func (i *Instance) Expired(now time.Time) bool {
+	return !i.Expire.IsZero() && !now.Before(i.Expire)
+}
@@ -0,0 +1,158 @@
+package instance
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gorilla/websocket"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/serial"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
+)
+
+func (i *Instance) initSerialPassword(db *database.Database) (err error) {
+	if i.SerialPassword != "" {
+		return
+	}
+
+	i.SerialPassword, err = utils.RandPasswd(32)
+	if err != nil {
+		return
+	}
+
+	err = i.CommitFields(db, set.NewSet("serial_password"))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (i *Instance) SerialConnect(db *database.Database,
+	rw http.ResponseWriter, r *http.Request) (err error) {
+
+	err = i.initSerialPassword(db)
+	if err != nil {
+		return
+	}
+
+	nde, err := node.Get(db, i.Node)
+	if err != nil {
+		return
+	}
+
+	serialHost := serial.GetHost(nde)
+	if serialHost == "" {
+		err = &errortypes.NotFoundError{
+			errors.New("instance: Node missing private IP for " +
+				"serial console"),
+		}
+		return
+	}
+
+	backConn, err := serial.Dial(serialHost, i.Id, i.SerialPassword)
+	if err != nil {
+		err = &SerialDialError{
+			errors.Wrap(err, "instance: Serial relay dial error"),
+		}
+		return
+	}
+	defer backConn.Close()
+
+	wsUpgrader := &websocket.Upgrader{
+		HandshakeTimeout: time.Duration(
+			settings.Router.HandshakeTimeout) * time.Second,
+		ReadBufferSize:  2048,
+		WriteBufferSize: 2048,
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+
+	frontConn, err := wsUpgrader.Upgrade(rw, r, nil)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "instance: WebSocket upgrade error"),
+		}
+		return
+	}
+	defer frontConn.Close()
+
+	wait := make(chan bool, 4)
+	go func() {
+		defer func() {
+			rec := recover()
+			if rec != nil {
+				logrus.WithFields(logrus.Fields{
+					"panic": rec,
+				}).Error("instance: WebSocket serial back panic")
+				wait <- true
+			}
+		}()
+
+		for {
+			_, msg, err := frontConn.ReadMessage()
+			if err != nil {
+				break
+			}
+
+			_, err = backConn.Write(msg)
+			if err != nil {
+				err = &errortypes.WriteError{
+					errors.Wrap(err, "instance: Serial relay write error"),
+				}
+				logrus.WithFields(logrus.Fields{
+					"error": err,
+				}).Error("instance: WebSocket serial back write error")
+				break
+			}
+		}
+
+		wait <- true
+	}()
+	go func() {
+		defer func() {
+			rec := recover()
+			if rec != nil {
+				logrus.WithFields(logrus.Fields{
+					"panic": rec,
+				}).Error("instance: WebSocket serial front panic")
+				wait <- true
+			}
+		}()
+
+		buf := make([]byte, 4096)
+		for {
+			n, err := backConn.Read(buf)
+			if err != nil {
+				closeMsg := websocket.FormatCloseMessage(
+					websocket.CloseNormalClosure, fmt.Sprintf("%v", err))
+				_ = frontConn.WriteMessage(websocket.CloseMessage, closeMsg)
+				break
+			}
+
+			err = frontConn.WriteMessage(websocket.BinaryMessage, buf[:n])
+			if err != nil {
+				err = &errortypes.WriteError{
+					errors.Wrap(err, "instance: WebSocket serial write error"),
+				}
+				logrus.WithFields(logrus.Fields{
+					"error": err,
+				}).Error("instance: WebSocket serial front write error")
+				break
+			}
+		}
+
+		wait <- true
+	}()
+	<-wait
+
+	return
+}
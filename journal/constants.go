@@ -3,6 +3,7 @@ package journal
 const (
 	InstanceAgent   = 1
 	DeploymentAgent = 2
+	UnitAutoscale   = 3
 )
 
 const (
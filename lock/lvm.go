@@ -45,10 +45,12 @@ func LvmRelock(db *database.Database, vgName, lvName string) (err error) {
 	coll := db.LvmLock()
 
 	_, err = coll.UpdateOne(db, &bson.M{
-		"id":   fmt.Sprintf("%s/%s", vgName, lvName),
+		"_id":  fmt.Sprintf("%s/%s", vgName, lvName),
 		"node": node.Self.Id,
 	}, &bson.M{
-		"timestamp": time.Now(),
+		"$set": &bson.M{
+			"timestamp": time.Now(),
+		},
 	})
 	if err != nil {
 		err = database.ParseError(err)
@@ -62,7 +64,7 @@ func LvmUnlock(db *database.Database, vgName, lvName string) (err error) {
 	coll := db.LvmLock()
 
 	_, err = coll.DeleteOne(db, &bson.M{
-		"id":   fmt.Sprintf("%s/%s", vgName, lvName),
+		"_id":  fmt.Sprintf("%s/%s", vgName, lvName),
 		"node": node.Self.Id,
 	})
 	if err != nil {
@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pritunl/pritunl-cloud/journal"
+	"github.com/pritunl/pritunl-cloud/logsink"
+	"github.com/sirupsen/logrus"
+)
+
+type remoteSender struct{}
+
+func (s *remoteSender) Init() {}
+
+func (s *remoteSender) Parse(entry *logrus.Entry) {
+	if strings.HasPrefix(entry.Message, "logsink:") {
+		return
+	}
+
+	var level int32
+	switch entry.Level {
+	case logrus.DebugLevel:
+		level = journal.Debug
+		break
+	case logrus.WarnLevel:
+		level = journal.Warning
+		break
+	case logrus.ErrorLevel:
+		level = journal.Error
+		break
+	case logrus.FatalLevel:
+		level = journal.Critical
+		break
+	case logrus.PanicLevel:
+		level = journal.Panic
+		break
+	default:
+		level = journal.Info
+	}
+
+	fields := map[string]string{}
+	for key, val := range entry.Data {
+		fields[key] = fmt.Sprintf("%v", val)
+	}
+
+	logsink.PushNode(level, entry.Time, entry.Message, fields)
+}
+
+func init() {
+	senders = append(senders, &remoteSender{})
+}
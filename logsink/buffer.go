@@ -0,0 +1,170 @@
+package logsink
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type buffer struct {
+	path    string
+	maxSize int64
+}
+
+func (b *buffer) files() (names []string, size int64, err error) {
+	items, err := os.ReadDir(b.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+			return
+		}
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "logsink: Failed to read buffer directory"),
+		}
+		return
+	}
+
+	for _, item := range items {
+		if item.IsDir() || path.Ext(item.Name()) != ".json" {
+			continue
+		}
+
+		info, e := item.Info()
+		if e != nil {
+			continue
+		}
+
+		names = append(names, item.Name())
+		size += info.Size()
+	}
+
+	sort.Strings(names)
+
+	return
+}
+
+func (b *buffer) Empty() bool {
+	names, _, _ := b.files()
+	return len(names) == 0
+}
+
+// Write spools a batch to disk removing the oldest batches when the buffer
+// exceeds the maximum size
+func (b *buffer) Write(recs []*Record) (dropped int, err error) {
+	data, err := json.Marshal(recs)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "logsink: Failed to marshal buffer batch"),
+		}
+		return
+	}
+
+	if int64(len(data)) > b.maxSize {
+		dropped = len(recs)
+		return
+	}
+
+	err = utils.ExistsMkdir(b.path, 0700)
+	if err != nil {
+		return
+	}
+
+	names, size, err := b.files()
+	if err != nil {
+		return
+	}
+
+	for len(names) > 0 && size+int64(len(data)) > b.maxSize {
+		pth := path.Join(b.path, names[0])
+		names = names[1:]
+
+		info, e := os.Stat(pth)
+		if e == nil {
+			size -= info.Size()
+		}
+
+		recs, e := b.read(pth)
+		if e == nil {
+			dropped += len(recs)
+		}
+
+		_ = os.Remove(pth)
+	}
+
+	name := fmt.Sprintf("%020d-%s.json", time.Now().UnixNano(),
+		bson.NewObjectID().Hex())
+
+	err = utils.CreateWrite(path.Join(b.path, name), string(data), 0600)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (b *buffer) read(pth string) (recs []*Record, err error) {
+	data, err := os.ReadFile(pth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "logsink: Failed to read buffer batch"),
+		}
+		return
+	}
+
+	err = json.Unmarshal(data, &recs)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "logsink: Failed to parse buffer batch"),
+		}
+		return
+	}
+
+	return
+}
+
+// Replay sends spooled batches oldest first until a send fails
+func (b *buffer) Replay(send func([]*Record) error) (err error) {
+	names, _, err := b.files()
+	if err != nil {
+		return
+	}
+
+	for _, name := range names {
+		pth := path.Join(b.path, name)
+
+		recs, e := b.read(pth)
+		if e != nil {
+			_ = os.Remove(pth)
+			continue
+		}
+
+		err = send(recs)
+		if err != nil {
+			return
+		}
+
+		_ = os.Remove(pth)
+	}
+
+	return
+}
+
+func (b *buffer) Clear() {
+	_ = os.RemoveAll(b.path)
+}
+
+func newBuffer(sinkId bson.ObjectID, maxSize int) *buffer {
+	return &buffer{
+		path:    path.Join(constants.DefaultRoot, "logsink", sinkId.Hex()),
+		maxSize: int64(maxSize) * 1024 * 1024,
+	}
+}
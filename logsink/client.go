@@ -0,0 +1,138 @@
+package logsink
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type client interface {
+	Send(recs []*Record) (err error)
+	Close()
+}
+
+var (
+	httpClient           = newHttpClient(false)
+	restrictedHttpClient = newHttpClient(true)
+)
+
+func newDialer(restricted bool) *net.Dialer {
+	dialer := &net.Dialer{
+		Timeout: sendTimeout,
+	}
+
+	if restricted {
+		dialer.Control = utils.PublicDialControl
+	}
+
+	return dialer
+}
+
+// newHttpClient returns a client for sink requests, organization sinks
+// are restricted to public addresses at connect time
+func newHttpClient(restricted bool) *http.Client {
+	return &http.Client{
+		Timeout: sendTimeout,
+		Transport: &http.Transport{
+			DialContext:         newDialer(restricted).DialContext,
+			TLSHandshakeTimeout: sendTimeout,
+			TLSClientConfig: &tls.Config{
+				MinVersion: tls.VersionTLS12,
+			},
+		},
+	}
+}
+
+func newClient(sink *Sink) client {
+	switch sink.Type {
+	case Loki:
+		return &lokiClient{
+			sink: sink,
+		}
+	case Otlp:
+		return &otlpClient{
+			sink: sink,
+		}
+	case Webhook:
+		return &webhookClient{
+			sink: sink,
+		}
+	default:
+		return &syslogClient{
+			sink: sink,
+		}
+	}
+}
+
+func getUrl(rawUrl, defaultPath string) string {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return rawUrl
+	}
+
+	if u.Path == "" || u.Path == "/" {
+		u.Path = defaultPath
+	}
+
+	return u.String()
+}
+
+func postJson(sink *Sink, reqUrl string, data interface{}) (err error) {
+	body, err := json.Marshal(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "logsink: Failed to marshal request"),
+		}
+		return
+	}
+
+	req, err := http.NewRequest("POST", reqUrl, bytes.NewReader(body))
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "logsink: Failed to create request"),
+		}
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "pritunl-cloud")
+
+	if sink.Tenant != "" {
+		req.Header.Set("X-Scope-OrgID", sink.Tenant)
+	}
+	if sink.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+string(sink.Token))
+	} else if sink.Username != "" {
+		req.SetBasicAuth(sink.Username, string(sink.Password))
+	}
+
+	client := httpClient
+	if !sink.IsGlobal() {
+		client = restrictedHttpClient
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "logsink: Request failed"),
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		err = &errortypes.RequestError{
+			errors.Newf("logsink: Bad status %d", resp.StatusCode),
+		}
+		return
+	}
+
+	return
+}
@@ -0,0 +1,27 @@
+package logsink
+
+import (
+	"time"
+)
+
+const (
+	Syslog  = "syslog"
+	Loki    = "loki"
+	Otlp    = "otlp"
+	Webhook = "webhook"
+
+	Node    = "node"
+	Journal = "journal"
+	Audit   = "audit"
+
+	DefaultBatchSize     = 500
+	MaxBatchSize         = 10000
+	DefaultBatchInterval = 5
+	MaxBatchInterval     = 300
+	DefaultBufferSize    = 64
+	MaxBufferSize        = 4096
+
+	sendTimeout = 15 * time.Second
+	retryMin    = 5 * time.Second
+	retryMax    = 5 * time.Minute
+)
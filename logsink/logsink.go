@@ -0,0 +1,174 @@
+package logsink
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/journal"
+	"github.com/pritunl/pritunl-cloud/node"
+)
+
+var (
+	shippers     = map[bson.ObjectID]*shipper{}
+	orgShippers  = map[bson.ObjectID][]*shipper{}
+	shippersLock sync.RWMutex
+)
+
+func getHost() string {
+	if node.Self == nil {
+		return ""
+	}
+	return node.Self.Name
+}
+
+// Enabled returns true when sinks exist for the organization, node log
+// sinks use a nil organization
+func Enabled(orgId bson.ObjectID) bool {
+	shippersLock.RLock()
+	n := len(orgShippers[orgId])
+	shippersLock.RUnlock()
+	return n > 0
+}
+
+func Push(orgId bson.ObjectID, rec *Record) {
+	shippersLock.RLock()
+	for _, shpr := range orgShippers[orgId] {
+		if shpr.sink.HasSource(rec.Source) {
+			shpr.push(rec)
+		}
+	}
+	shippersLock.RUnlock()
+}
+
+func PushNode(level int32, timestamp time.Time, message string,
+	fields map[string]string) {
+
+	if !Enabled(bson.NilObjectID) {
+		return
+	}
+
+	if node.Self != nil {
+		fields["node"] = node.Self.Id.Hex()
+	}
+
+	Push(bson.NilObjectID, &Record{
+		Timestamp: timestamp,
+		Level:     level,
+		Source:    Node,
+		Host:      getHost(),
+		Message:   message,
+		Fields:    fields,
+	})
+}
+
+func PushAudit(timestamp time.Time, message string,
+	fields map[string]string) {
+
+	if !Enabled(bson.NilObjectID) {
+		return
+	}
+
+	Push(bson.NilObjectID, &Record{
+		Timestamp: timestamp,
+		Level:     journal.Info,
+		Source:    Audit,
+		Host:      getHost(),
+		Message:   message,
+		Fields:    fields,
+	})
+}
+
+func PushJournal(orgId bson.ObjectID, jrnl *journal.Journal,
+	fields map[string]string) {
+
+	if !Enabled(orgId) {
+		return
+	}
+
+	fields["organization"] = orgId.Hex()
+	fields["resource"] = jrnl.Resource.Hex()
+
+	Push(orgId, &Record{
+		Timestamp: jrnl.Timestamp,
+		Level:     jrnl.Level,
+		Source:    Journal,
+		Host:      getHost(),
+		Message:   jrnl.Message,
+		Fields:    fields,
+	})
+}
+
+// Update reconciles the running shippers with the sinks
+func Update(sinks []*Sink) {
+	newShippers := map[bson.ObjectID]*shipper{}
+	newOrgShippers := map[bson.ObjectID][]*shipper{}
+	stopShippers := []*shipper{}
+
+	shippersLock.RLock()
+	curShippers := shippers
+	shippersLock.RUnlock()
+
+	for _, sink := range sinks {
+		shpr := curShippers[sink.Id]
+		if shpr == nil || shpr.hash != sinkHash(sink) {
+			if shpr != nil {
+				stopShippers = append(stopShippers, shpr)
+			}
+			shpr = newShipper(sink)
+			shpr.Start()
+		}
+
+		newShippers[sink.Id] = shpr
+		newOrgShippers[sink.Organization] = append(
+			newOrgShippers[sink.Organization], shpr)
+	}
+
+	removed := []bson.ObjectID{}
+	for sinkId, shpr := range curShippers {
+		if newShippers[sinkId] == nil {
+			stopShippers = append(stopShippers, shpr)
+			removed = append(removed, sinkId)
+		}
+	}
+
+	shippersLock.Lock()
+	shippers = newShippers
+	orgShippers = newOrgShippers
+	shippersLock.Unlock()
+
+	for _, shpr := range stopShippers {
+		shpr.Stop()
+	}
+	for _, sinkId := range removed {
+		removeBuffer(sinkId)
+	}
+}
+
+func Sync(db *database.Database) (err error) {
+	sinks, err := GetAll(db, &bson.M{
+		"disabled": &bson.M{
+			"$ne": true,
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	for _, sink := range sinks {
+		if sink.BatchSize <= 0 {
+			sink.BatchSize = DefaultBatchSize
+		}
+		if sink.BatchInterval <= 0 {
+			sink.BatchInterval = DefaultBatchInterval
+		}
+		if sink.BufferSize <= 0 {
+			sink.BufferSize = DefaultBufferSize
+		}
+	}
+
+	Update(sinks)
+
+	return
+}
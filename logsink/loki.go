@@ -0,0 +1,94 @@
+package logsink
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var lokiLabels = map[string]bool{
+	"organization": true,
+	"kind":         true,
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][]interface{}   `json:"values"`
+}
+
+type lokiPush struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+type lokiClient struct {
+	sink *Sink
+}
+
+func (c *lokiClient) Send(recs []*Record) (err error) {
+	streams := map[string]*lokiStream{}
+	keys := []string{}
+
+	for _, rec := range recs {
+		labels := map[string]string{
+			"source": rec.Source,
+			"host":   rec.Host,
+			"level":  rec.LevelName(),
+		}
+		metadata := map[string]string{}
+
+		for key, val := range rec.Fields {
+			if lokiLabels[key] {
+				labels[key] = val
+			} else {
+				metadata[key] = val
+			}
+		}
+
+		labelKeys := make([]string, 0, len(labels))
+		for key := range labels {
+			labelKeys = append(labelKeys, key)
+		}
+		sort.Strings(labelKeys)
+
+		streamKey := ""
+		for _, key := range labelKeys {
+			streamKey += key + "=" + labels[key] + ","
+		}
+
+		stream := streams[streamKey]
+		if stream == nil {
+			stream = &lokiStream{
+				Stream: labels,
+				Values: [][]interface{}{},
+			}
+			streams[streamKey] = stream
+			keys = append(keys, streamKey)
+		}
+
+		value := []interface{}{
+			strconv.FormatInt(rec.Timestamp.UnixNano(), 10),
+			strings.TrimRight(rec.Message, "\n"),
+		}
+		if len(metadata) > 0 {
+			value = append(value, metadata)
+		}
+
+		stream.Values = append(stream.Values, value)
+	}
+
+	push := &lokiPush{
+		Streams: []*lokiStream{},
+	}
+	for _, key := range keys {
+		push.Streams = append(push.Streams, streams[key])
+	}
+
+	err = postJson(c.sink, getUrl(c.sink.Url, "/loki/api/v1/push"), push)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (c *lokiClient) Close() {}
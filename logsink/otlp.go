@@ -0,0 +1,126 @@
+package logsink
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string     `json:"key"`
+	Value *otlpValue `json:"value"`
+}
+
+type otlpLogRecord struct {
+	TimeUnixNano   string           `json:"timeUnixNano"`
+	SeverityNumber int              `json:"severityNumber"`
+	SeverityText   string           `json:"severityText"`
+	Body           *otlpValue       `json:"body"`
+	Attributes     []*otlpAttribute `json:"attributes"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpScopeLogs struct {
+	Scope      *otlpScope       `json:"scope"`
+	LogRecords []*otlpLogRecord `json:"logRecords"`
+}
+
+type otlpResource struct {
+	Attributes []*otlpAttribute `json:"attributes"`
+}
+
+type otlpResourceLogs struct {
+	Resource  *otlpResource    `json:"resource"`
+	ScopeLogs []*otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpRequest struct {
+	ResourceLogs []*otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpClient struct {
+	sink *Sink
+}
+
+func otlpAttributes(vals map[string]string) (attrs []*otlpAttribute) {
+	keys := make([]string, 0, len(vals))
+	for key := range vals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	attrs = []*otlpAttribute{}
+	for _, key := range keys {
+		attrs = append(attrs, &otlpAttribute{
+			Key: key,
+			Value: &otlpValue{
+				StringValue: vals[key],
+			},
+		})
+	}
+
+	return
+}
+
+func (c *otlpClient) Send(recs []*Record) (err error) {
+	resources := map[string]*otlpScopeLogs{}
+	req := &otlpRequest{
+		ResourceLogs: []*otlpResourceLogs{},
+	}
+
+	for _, rec := range recs {
+		scopeLogs := resources[rec.Host]
+		if scopeLogs == nil {
+			scopeLogs = &otlpScopeLogs{
+				Scope: &otlpScope{
+					Name: "pritunl-cloud",
+				},
+				LogRecords: []*otlpLogRecord{},
+			}
+			resources[rec.Host] = scopeLogs
+
+			req.ResourceLogs = append(req.ResourceLogs, &otlpResourceLogs{
+				Resource: &otlpResource{
+					Attributes: otlpAttributes(map[string]string{
+						"service.name": "pritunl-cloud",
+						"host.name":    rec.Host,
+					}),
+				},
+				ScopeLogs: []*otlpScopeLogs{scopeLogs},
+			})
+		}
+
+		attrs := map[string]string{
+			"log.source": rec.Source,
+		}
+		for key, val := range rec.Fields {
+			attrs[key] = val
+		}
+
+		scopeLogs.LogRecords = append(scopeLogs.LogRecords, &otlpLogRecord{
+			TimeUnixNano:   strconv.FormatInt(rec.Timestamp.UnixNano(), 10),
+			SeverityNumber: rec.OtlpSeverity(),
+			SeverityText:   strings.ToUpper(rec.LevelName()),
+			Body: &otlpValue{
+				StringValue: strings.TrimRight(rec.Message, "\n"),
+			},
+			Attributes: otlpAttributes(attrs),
+		})
+	}
+
+	err = postJson(c.sink, getUrl(c.sink.Url, "/v1/logs"), req)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (c *otlpClient) Close() {}
@@ -0,0 +1,73 @@
+package logsink
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-cloud/journal"
+)
+
+type Record struct {
+	Timestamp time.Time         `json:"t"`
+	Level     int32             `json:"l"`
+	Source    string            `json:"s"`
+	Host      string            `json:"h"`
+	Message   string            `json:"m"`
+	Fields    map[string]string `json:"f,omitempty"`
+}
+
+func (r *Record) LevelName() string {
+	switch r.Level {
+	case journal.Panic:
+		return "panic"
+	case journal.Critical:
+		return "critical"
+	case journal.Error:
+		return "error"
+	case journal.Warning:
+		return "warning"
+	case journal.Debug:
+		return "debug"
+	case journal.Trace:
+		return "trace"
+	default:
+		return "info"
+	}
+}
+
+// SyslogSeverity returns the RFC5424 severity
+func (r *Record) SyslogSeverity() int {
+	switch r.Level {
+	case journal.Panic:
+		return 0
+	case journal.Critical:
+		return 2
+	case journal.Error:
+		return 3
+	case journal.Warning:
+		return 4
+	case journal.Debug, journal.Trace:
+		return 7
+	default:
+		return 6
+	}
+}
+
+// OtlpSeverity returns the OpenTelemetry severity number
+func (r *Record) OtlpSeverity() int {
+	switch r.Level {
+	case journal.Panic:
+		return 21
+	case journal.Critical:
+		return 21
+	case journal.Error:
+		return 17
+	case journal.Warning:
+		return 13
+	case journal.Debug:
+		return 5
+	case journal.Trace:
+		return 1
+	default:
+		return 9
+	}
+}
@@ -0,0 +1,189 @@
+package logsink
+
+import (
+	"encoding/json"
+	"sync/atomic"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/sirupsen/logrus"
+)
+
+type shipper struct {
+	sink       *Sink
+	hash       string
+	queue      chan *Record
+	stop       chan bool
+	done       chan bool
+	client     client
+	buffer     *buffer
+	dropped    atomic.Int64
+	retryAt    time.Time
+	retryDelay time.Duration
+}
+
+func sinkHash(sink *Sink) string {
+	data, _ := json.Marshal(sink)
+	return string(data)
+}
+
+func (s *shipper) push(rec *Record) {
+	select {
+	case s.queue <- rec:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+func (s *shipper) fail(err error) {
+	if s.retryDelay == 0 {
+		s.retryDelay = retryMin
+	} else {
+		s.retryDelay *= 2
+		if s.retryDelay > retryMax {
+			s.retryDelay = retryMax
+		}
+	}
+	s.retryAt = time.Now().Add(s.retryDelay)
+
+	logrus.WithFields(logrus.Fields{
+		"log_sink": s.sink.Id.Hex(),
+		"type":     s.sink.Type,
+		"retry":    s.retryDelay.String(),
+		"error":    err,
+	}).Warn("logsink: Failed to send logs, buffering to disk")
+}
+
+func (s *shipper) spool(recs []*Record) {
+	dropped, err := s.buffer.Write(recs)
+	if err != nil {
+		s.dropped.Add(int64(len(recs)))
+		logrus.WithFields(logrus.Fields{
+			"log_sink": s.sink.Id.Hex(),
+			"error":    err,
+		}).Error("logsink: Failed to write disk buffer")
+		return
+	}
+	s.dropped.Add(int64(dropped))
+}
+
+func (s *shipper) replay() bool {
+	if time.Now().Before(s.retryAt) {
+		return false
+	}
+
+	err := s.buffer.Replay(s.client.Send)
+	if err != nil {
+		s.fail(err)
+		return false
+	}
+
+	return true
+}
+
+func (s *shipper) flush(recs []*Record) {
+	if len(recs) == 0 {
+		return
+	}
+
+	if !s.replay() {
+		s.spool(recs)
+		return
+	}
+
+	err := s.client.Send(recs)
+	if err != nil {
+		s.fail(err)
+		s.spool(recs)
+		return
+	}
+
+	s.retryDelay = 0
+}
+
+func (s *shipper) report() {
+	dropped := s.dropped.Swap(0)
+	if dropped == 0 {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"log_sink": s.sink.Id.Hex(),
+		"dropped":  dropped,
+	}).Warn("logsink: Dropped log records")
+}
+
+func (s *shipper) run() {
+	defer close(s.done)
+	defer s.client.Close()
+
+	ticker := time.NewTicker(
+		time.Duration(s.sink.BatchInterval) * time.Second)
+	defer ticker.Stop()
+
+	lastReport := time.Now()
+	batch := make([]*Record, 0, s.sink.BatchSize)
+
+	for {
+		select {
+		case <-s.stop:
+			for {
+				select {
+				case rec := <-s.queue:
+					batch = append(batch, rec)
+					continue
+				default:
+				}
+				break
+			}
+			if len(batch) > 0 {
+				s.spool(batch)
+			}
+			s.report()
+			return
+		case rec := <-s.queue:
+			batch = append(batch, rec)
+			if len(batch) >= s.sink.BatchSize {
+				s.flush(batch)
+				batch = make([]*Record, 0, s.sink.BatchSize)
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				s.flush(batch)
+				batch = make([]*Record, 0, s.sink.BatchSize)
+			} else if !s.buffer.Empty() && s.replay() {
+				s.retryDelay = 0
+			}
+
+			if time.Since(lastReport) > time.Minute {
+				s.report()
+				lastReport = time.Now()
+			}
+		}
+	}
+}
+
+func (s *shipper) Start() {
+	go s.run()
+}
+
+func (s *shipper) Stop() {
+	close(s.stop)
+	<-s.done
+}
+
+func newShipper(sink *Sink) *shipper {
+	return &shipper{
+		sink:   sink,
+		hash:   sinkHash(sink),
+		queue:  make(chan *Record, sink.BatchSize*4),
+		stop:   make(chan bool),
+		done:   make(chan bool),
+		client: newClient(sink),
+		buffer: newBuffer(sink.Id, sink.BufferSize),
+	}
+}
+
+func removeBuffer(sinkId bson.ObjectID) {
+	newBuffer(sinkId, 0).Clear()
+}
@@ -0,0 +1,218 @@
+package logsink
+
+import (
+	"net"
+	"net/url"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/envelope"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type Sink struct {
+	Id            bson.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name          string          `bson:"name" json:"name"`
+	Comment       string          `bson:"comment" json:"comment"`
+	Organization  bson.ObjectID   `bson:"organization" json:"organization"`
+	Type          string          `bson:"type" json:"type"`
+	Sources       []string        `bson:"sources" json:"sources"`
+	Disabled      bool            `bson:"disabled" json:"disabled"`
+	Address       string          `bson:"address" json:"address"`
+	Tls           bool            `bson:"tls" json:"tls"`
+	Url           string          `bson:"url" json:"url"`
+	Username      string          `bson:"username" json:"username"`
+	Password      envelope.String `bson:"password" json:"password"`
+	Token         envelope.String `bson:"token" json:"token"`
+	Tenant        string          `bson:"tenant" json:"tenant"`
+	BatchSize     int             `bson:"batch_size" json:"batch_size"`
+	BatchInterval int             `bson:"batch_interval" json:"batch_interval"`
+	BufferSize    int             `bson:"buffer_size" json:"buffer_size"`
+}
+
+func (s *Sink) Validate(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	s.Name = utils.FilterName(s.Name)
+
+	switch s.Type {
+	case Syslog, "":
+		s.Type = Syslog
+		s.Url = ""
+		s.Username = ""
+		s.Password = ""
+		s.Token = ""
+		s.Tenant = ""
+
+		_, port, e := net.SplitHostPort(s.Address)
+		if e != nil || port == "" {
+			errData = &errortypes.ErrorData{
+				Error:   "log_sink_address_invalid",
+				Message: "Log sink syslog address must be host:port",
+			}
+			return
+		}
+
+		break
+	case Loki, Otlp, Webhook:
+		s.Address = ""
+		s.Tls = false
+		if s.Type != Loki {
+			s.Tenant = ""
+		}
+
+		u, e := url.Parse(s.Url)
+		if e != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+			u.Host == "" {
+
+			errData = &errortypes.ErrorData{
+				Error:   "log_sink_url_invalid",
+				Message: "Log sink URL must be a valid HTTP or HTTPS URL",
+			}
+			return
+		}
+
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "log_sink_type_invalid",
+			Message: "Log sink type invalid",
+		}
+		return
+	}
+
+	sources := []string{}
+	sourcesSet := set.NewSet()
+	for _, source := range s.Sources {
+		if sourcesSet.Contains(source) {
+			continue
+		}
+		sourcesSet.Add(source)
+
+		switch source {
+		case Node, Audit:
+			if !s.IsGlobal() {
+				errData = &errortypes.ErrorData{
+					Error:   "log_sink_source_invalid",
+					Message: "Log sink source only available to global sinks",
+				}
+				return
+			}
+			break
+		case Journal:
+			if s.IsGlobal() {
+				errData = &errortypes.ErrorData{
+					Error:   "log_sink_source_invalid",
+					Message: "Log sink journal source requires organization",
+				}
+				return
+			}
+			break
+		default:
+			errData = &errortypes.ErrorData{
+				Error:   "log_sink_source_invalid",
+				Message: "Log sink source invalid",
+			}
+			return
+		}
+
+		sources = append(sources, source)
+	}
+	if len(sources) == 0 {
+		sources = s.DefaultSources()
+	}
+	s.Sources = sources
+
+	if s.BatchSize <= 0 {
+		s.BatchSize = DefaultBatchSize
+	} else if s.BatchSize > MaxBatchSize {
+		s.BatchSize = MaxBatchSize
+	}
+
+	if s.BatchInterval <= 0 {
+		s.BatchInterval = DefaultBatchInterval
+	} else if s.BatchInterval > MaxBatchInterval {
+		s.BatchInterval = MaxBatchInterval
+	}
+
+	if s.BufferSize <= 0 {
+		s.BufferSize = DefaultBufferSize
+	} else if s.BufferSize > MaxBufferSize {
+		s.BufferSize = MaxBufferSize
+	}
+
+	return
+}
+
+func (s *Sink) IsGlobal() bool {
+	return s.Organization.IsZero()
+}
+
+func (s *Sink) DefaultSources() []string {
+	if s.IsGlobal() {
+		return []string{Node}
+	}
+	return []string{Journal}
+}
+
+func (s *Sink) HasSource(source string) bool {
+	sources := s.Sources
+	if len(sources) == 0 {
+		sources = s.DefaultSources()
+	}
+
+	for _, src := range sources {
+		if src == source {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Sink) Commit(db *database.Database) (err error) {
+	coll := db.LogSinks()
+
+	err = coll.Commit(s.Id, s)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (s *Sink) CommitFields(db *database.Database, fields set.Set) (
+	err error) {
+
+	coll := db.LogSinks()
+
+	err = coll.CommitFields(s.Id, s, fields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (s *Sink) Insert(db *database.Database) (err error) {
+	coll := db.LogSinks()
+
+	if !s.Id.IsZero() {
+		err = &errortypes.DatabaseError{
+			errors.New("logsink: Log sink already exists"),
+		}
+		return
+	}
+
+	resp, err := coll.InsertOne(db, s)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	s.Id = resp.InsertedID.(bson.ObjectID)
+
+	return
+}
@@ -0,0 +1,164 @@
+package logsink
+
+import (
+	"bytes"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+)
+
+const (
+	syslogApp             = "pritunl-cloud"
+	syslogEnterprise      = "pritunl@32473"
+	syslogTimeFormat      = "2006-01-02T15:04:05.000000Z07:00"
+	syslogFacilityNode    = 3
+	syslogFacilityJournal = 1
+	syslogFacilityAudit   = 13
+)
+
+var syslogEscape = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+type syslogClient struct {
+	sink *Sink
+	conn net.Conn
+}
+
+func syslogHeaderValue(val string, maxLen int) string {
+	val = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 {
+			return -1
+		}
+		return r
+	}, val)
+
+	if val == "" {
+		return "-"
+	}
+	if len(val) > maxLen {
+		val = val[:maxLen]
+	}
+	return val
+}
+
+func syslogParamName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 33 || r > 126 || r == '=' || r == ']' || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+
+	if len(name) > 32 {
+		name = name[:32]
+	}
+	return name
+}
+
+// formatSyslog formats a record as an RFC5424 message
+func formatSyslog(rec *Record) string {
+	facility := syslogFacilityNode
+	switch rec.Source {
+	case Journal:
+		facility = syslogFacilityJournal
+		break
+	case Audit:
+		facility = syslogFacilityAudit
+		break
+	}
+
+	data := "-"
+	if len(rec.Fields) > 0 {
+		keys := make([]string, 0, len(rec.Fields))
+		for key := range rec.Fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		params := []string{syslogEnterprise}
+		for _, key := range keys {
+			name := syslogParamName(key)
+			if name == "" {
+				continue
+			}
+			params = append(params, fmt.Sprintf(`%s="%s"`,
+				name, syslogEscape.Replace(rec.Fields[key])))
+		}
+
+		data = "[" + strings.Join(params, " ") + "]"
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s - %s %s %s",
+		facility*8+rec.SyslogSeverity(),
+		rec.Timestamp.UTC().Format(syslogTimeFormat),
+		syslogHeaderValue(rec.Host, 255),
+		syslogApp,
+		syslogHeaderValue(rec.Source, 32),
+		data,
+		strings.TrimRight(rec.Message, "\n"),
+	)
+}
+
+func (c *syslogClient) connect() (err error) {
+	dialer := newDialer(!c.sink.IsGlobal())
+
+	if c.sink.Tls {
+		host, _, _ := net.SplitHostPort(c.sink.Address)
+		c.conn, err = tls.DialWithDialer(dialer, "tcp", c.sink.Address,
+			&tls.Config{
+				ServerName: host,
+				MinVersion: tls.VersionTLS12,
+			})
+	} else {
+		c.conn, err = dialer.Dial("tcp", c.sink.Address)
+	}
+	if err != nil {
+		c.conn = nil
+		err = &errortypes.ConnectionError{
+			errors.Wrap(err, "logsink: Failed to connect to syslog server"),
+		}
+		return
+	}
+
+	return
+}
+
+func (c *syslogClient) Send(recs []*Record) (err error) {
+	if c.conn == nil {
+		err = c.connect()
+		if err != nil {
+			return
+		}
+	}
+
+	buf := &bytes.Buffer{}
+	for _, rec := range recs {
+		msg := formatSyslog(rec)
+		fmt.Fprintf(buf, "%d %s", len(msg), msg)
+	}
+
+	_ = c.conn.SetWriteDeadline(time.Now().Add(sendTimeout))
+
+	_, err = c.conn.Write(buf.Bytes())
+	if err != nil {
+		c.Close()
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "logsink: Failed to write to syslog server"),
+		}
+		return
+	}
+
+	return
+}
+
+func (c *syslogClient) Close() {
+	if c.conn != nil {
+		_ = c.conn.Close()
+		c.conn = nil
+	}
+}
@@ -0,0 +1,217 @@
+package logsink
+
+import (
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+func Get(db *database.Database, sinkId bson.ObjectID) (
+	sink *Sink, err error) {
+
+	coll := db.LogSinks()
+	sink = &Sink{}
+
+	err = coll.FindOneId(sinkId, sink)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetOrg(db *database.Database, orgId, sinkId bson.ObjectID) (
+	sink *Sink, err error) {
+
+	coll := db.LogSinks()
+	sink = &Sink{}
+
+	err = coll.FindOne(db, &bson.M{
+		"_id":          sinkId,
+		"organization": orgId,
+	}).Decode(sink)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetAll(db *database.Database, query *bson.M) (
+	sinks []*Sink, err error) {
+
+	coll := db.LogSinks()
+	sinks = []*Sink{}
+
+	cursor, err := coll.Find(db, query)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		sink := &Sink{}
+		err = cursor.Decode(sink)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetAllPaged(db *database.Database, query *bson.M,
+	page, pageCount int64) (sinks []*Sink, count int64, err error) {
+
+	coll := db.LogSinks()
+	sinks = []*Sink{}
+
+	if len(*query) == 0 {
+		count, err = coll.EstimatedDocumentCount(db)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+	} else {
+		count, err = coll.CountDocuments(db, query)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+	}
+
+	if pageCount == 0 {
+		pageCount = 20
+	}
+	maxPage := count / pageCount
+	if count == pageCount {
+		maxPage = 0
+	}
+	page = utils.Min64(page, maxPage)
+	skip := utils.Min64(page*pageCount, count)
+
+	cursor, err := coll.Find(
+		db,
+		query,
+		options.Find().
+			SetSort(&bson.D{
+				{"name", 1},
+			}).
+			SetSkip(skip).
+			SetLimit(pageCount),
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		sink := &Sink{}
+		err = cursor.Decode(sink)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		sinks = append(sinks, sink)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func Remove(db *database.Database, sinkId bson.ObjectID) (err error) {
+	coll := db.LogSinks()
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"_id": sinkId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		switch err.(type) {
+		case *database.NotFoundError:
+			err = nil
+		default:
+			return
+		}
+	}
+
+	return
+}
+
+func RemoveOrg(db *database.Database, orgId, sinkId bson.ObjectID) (
+	err error) {
+
+	coll := db.LogSinks()
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"_id":          sinkId,
+		"organization": orgId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		switch err.(type) {
+		case *database.NotFoundError:
+			err = nil
+		default:
+			return
+		}
+	}
+
+	return
+}
+
+func RemoveMulti(db *database.Database, sinkIds []bson.ObjectID) (
+	err error) {
+
+	coll := db.LogSinks()
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"_id": &bson.M{
+			"$in": sinkIds,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func RemoveMultiOrg(db *database.Database, orgId bson.ObjectID,
+	sinkIds []bson.ObjectID) (err error) {
+
+	coll := db.LogSinks()
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"_id": &bson.M{
+			"$in": sinkIds,
+		},
+		"organization": orgId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,48 @@
+package logsink
+
+import (
+	"time"
+)
+
+type webhookRecord struct {
+	Timestamp string            `json:"timestamp"`
+	Level     string            `json:"level"`
+	Source    string            `json:"source"`
+	Host      string            `json:"host"`
+	Message   string            `json:"message"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+type webhookRequest struct {
+	Records []*webhookRecord `json:"records"`
+}
+
+type webhookClient struct {
+	sink *Sink
+}
+
+func (c *webhookClient) Send(recs []*Record) (err error) {
+	req := &webhookRequest{
+		Records: make([]*webhookRecord, 0, len(recs)),
+	}
+
+	for _, rec := range recs {
+		req.Records = append(req.Records, &webhookRecord{
+			Timestamp: rec.Timestamp.UTC().Format(time.RFC3339Nano),
+			Level:     rec.LevelName(),
+			Source:    rec.Source,
+			Host:      rec.Host,
+			Message:   rec.Message,
+			Fields:    rec.Fields,
+		})
+	}
+
+	err = postJson(c.sink, c.sink.Url, req)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (c *webhookClient) Close() {}
@@ -0,0 +1,293 @@
+package lvm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/pool"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type lvsReport struct {
+	Report []*lvReport `json:"report"`
+}
+
+type lvReport struct {
+	Lv []*lvDetails `json:"lv"`
+}
+
+type lvDetails struct {
+	LvName          string `json:"lv_name"`
+	PoolLv          string `json:"pool_lv"`
+	LvSize          string `json:"lv_size"`
+	DataPercent     string `json:"data_percent"`
+	MetadataPercent string `json:"metadata_percent"`
+}
+
+type vgsSizeReport struct {
+	Report []*vgSizeReport `json:"report"`
+}
+
+type vgSizeReport struct {
+	Vg []*vgSizeDetails `json:"vg"`
+}
+
+type vgSizeDetails struct {
+	VgName string `json:"vg_name"`
+	VgSize string `json:"vg_size"`
+	VgFree string `json:"vg_free"`
+}
+
+func parseFloat(val string) float64 {
+	num, _ := strconv.ParseFloat(strings.TrimSpace(val), 64)
+	return num
+}
+
+func getLvs(vgName string) (lvs []*lvDetails, err error) {
+	output, err := utils.ExecCombinedOutput("",
+		"lvs", "--reportformat", "json", "--units", "g", "--nosuffix",
+		"-o", "lv_name,pool_lv,lv_size,data_percent,metadata_percent",
+		vgName)
+	if err != nil {
+		return
+	}
+
+	reprt := &lvsReport{}
+	err = json.Unmarshal([]byte(output), reprt)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "lvm: Failed to unmarshal lvs report"),
+		}
+		return
+	}
+
+	lvs = []*lvDetails{}
+	for _, reportGroup := range reprt.Report {
+		if reportGroup.Lv != nil {
+			lvs = append(lvs, reportGroup.Lv...)
+		}
+	}
+
+	return
+}
+
+func getVgStats(vgName string) (stats *pool.Stats, err error) {
+	output, err := utils.ExecCombinedOutput("",
+		"vgs", "--reportformat", "json", "--units", "g", "--nosuffix",
+		"-o", "vg_name,vg_size,vg_free", vgName)
+	if err != nil {
+		return
+	}
+
+	reprt := &vgsSizeReport{}
+	err = json.Unmarshal([]byte(output), reprt)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "lvm: Failed to unmarshal vgs report"),
+		}
+		return
+	}
+
+	for _, reportGroup := range reprt.Report {
+		for _, reportVg := range reportGroup.Vg {
+			if reportVg.VgName != vgName {
+				continue
+			}
+
+			size := parseFloat(reportVg.VgSize)
+			free := parseFloat(reportVg.VgFree)
+
+			stats = &pool.Stats{
+				Size:      int(math.Floor(size)),
+				Allocated: int(math.Ceil(size - free)),
+				Timestamp: time.Now(),
+			}
+			return
+		}
+	}
+
+	err = &errortypes.NotFoundError{
+		errors.Newf("lvm: Volume group %s not found", vgName),
+	}
+	return
+}
+
+func getThinLv(vgName, thinPool string) (thinLv *lvDetails,
+	allocated float64, err error) {
+
+	lvs, err := getLvs(vgName)
+	if err != nil {
+		return
+	}
+
+	for _, lv := range lvs {
+		if lv.LvName == thinPool {
+			thinLv = lv
+		} else if lv.PoolLv == thinPool {
+			allocated += parseFloat(lv.LvSize)
+		}
+	}
+
+	if thinLv == nil {
+		err = &errortypes.NotFoundError{
+			errors.Newf("lvm: Thin pool %s/%s not found",
+				vgName, thinPool),
+		}
+		return
+	}
+
+	return
+}
+
+func getThinStats(vgName, thinPool string) (
+	stats *pool.Stats, err error) {
+
+	thinLv, allocated, err := getThinLv(vgName, thinPool)
+	if err != nil {
+		return
+	}
+
+	// Usage is only reported while the thin pool is active
+	if strings.TrimSpace(thinLv.DataPercent) == "" {
+		err = checkThinVg(vgName)
+		if err != nil {
+			return
+		}
+
+		err = ActivateLv(vgName, thinPool)
+		if err != nil {
+			return
+		}
+
+		thinLv, allocated, err = getThinLv(vgName, thinPool)
+		if err != nil {
+			return
+		}
+	}
+
+	stats = &pool.Stats{
+		Size:        int(math.Floor(parseFloat(thinLv.LvSize))),
+		Allocated:   int(math.Ceil(allocated)),
+		DataPercent: parseFloat(thinLv.DataPercent),
+		MetaPercent: parseFloat(thinLv.MetadataPercent),
+		Timestamp:   time.Now(),
+	}
+
+	return
+}
+
+// GetStats returns the size and allocation of the pool volume group or
+// thin pool, thin pool allocation is the sum of the virtual volume sizes
+func GetStats(pl *pool.Pool) (stats *pool.Stats, err error) {
+	if pl.IsThin() {
+		stats, err = getThinStats(pl.VgName, pl.ThinPool)
+	} else {
+		stats, err = getVgStats(pl.VgName)
+	}
+	return
+}
+
+func checkThinVg(vgName string) (err error) {
+	hasLock, err := HasLocking(vgName)
+	if err != nil {
+		return
+	}
+
+	if hasLock {
+		err = &errortypes.WriteError{
+			errors.Newf("lvm: Thin pool volume group %s is shared", vgName),
+		}
+		return
+	}
+
+	return
+}
+
+func CreateThinLv(vgName, thinPool, lvName string, size int) (err error) {
+	err = checkThinVg(vgName)
+	if err != nil {
+		return
+	}
+
+	_, err = utils.ExecCombinedOutputLogged(nil,
+		"lvcreate", "-an", "-V", fmt.Sprintf("%d.1G", size),
+		"--thin", "-n", lvName, fmt.Sprintf("%s/%s", vgName, thinPool))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// SnapshotThinLv creates a thin snapshot of the source volume, the
+// snapshot shares unmodified blocks with the source
+func SnapshotThinLv(vgName, sourceLvName, lvName string) (err error) {
+	err = checkThinVg(vgName)
+	if err != nil {
+		return
+	}
+
+	_, err = utils.ExecCombinedOutputLogged(nil,
+		"lvcreate", "-an", "-kn", "-s", "-n", lvName,
+		fmt.Sprintf("%s/%s", vgName, sourceLvName))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// WriteThinLv writes the image to a new thin volume skipping zero blocks
+// to avoid allocating unused space in the thin pool
+func WriteThinLv(vgName, lvName, sourcePth string) (err error) {
+	dstPth := filepath.Join("/dev/mapper",
+		fmt.Sprintf("%s-%s", vgName, lvName))
+
+	_, err = utils.ExecCombinedOutputLogged(nil,
+		"qemu-img", "convert", "-f", "qcow2", "-O", "raw",
+		"-n", "--target-is-zero", sourcePth, dstPth)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func HasLv(vgName, lvName string) (exists bool, err error) {
+	lvs, err := getLvs(vgName)
+	if err != nil {
+		return
+	}
+
+	for _, lv := range lvs {
+		if lv.LvName == lvName {
+			exists = true
+			return
+		}
+	}
+
+	return
+}
+
+func GetLvNames(vgName, prefix string) (names []string, err error) {
+	lvs, err := getLvs(vgName)
+	if err != nil {
+		return
+	}
+
+	names = []string{}
+	for _, lv := range lvs {
+		if strings.HasPrefix(lv.LvName, prefix) {
+			names = append(names, lv.LvName)
+		}
+	}
+
+	return
+}
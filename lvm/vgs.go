@@ -11,6 +11,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/pool"
 	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
 )
 
 var (
@@ -36,8 +37,8 @@ type vgDetails struct {
 	VgFree    string `json:"vg_free"`
 }
 
-func GetAvailablePools(db *database.Database, zoneId bson.ObjectID) (
-	availablePools []*pool.Pool, err error) {
+func GetAvailablePools(db *database.Database,
+	nodeId, zoneId bson.ObjectID) (availablePools []*pool.Pool, err error) {
 
 	if time.Since(cachedNodePoolsTimestamp) < 30*time.Second {
 		availablePools = cachedNodePools
@@ -72,19 +73,70 @@ func GetAvailablePools(db *database.Database, zoneId bson.ObjectID) (
 		}
 	}
 
-	if vgNames.Len() > 0 {
-		pools, e := pool.GetAll(db, &bson.M{
-			"zone": zoneId,
-		})
-		if e != nil {
-			err = e
-			return
-		}
+	pools, err := pool.GetAll(db, &bson.M{
+		"zone": zoneId,
+	})
+	if err != nil {
+		return
+	}
+
+	for _, pl := range pools {
+		if pl.IsSharedFs() {
+			e := pl.CheckPath(nodeId)
+			if e != nil {
+				logrus.WithFields(logrus.Fields{
+					"pool_id": pl.Id.Hex(),
+					"path":    pl.Path,
+					"error":   e,
+				}).Warn("lvm: Shared filesystem pool unavailable")
+				continue
+			}
+			availablePools = append(availablePools, pl)
+		} else if vgNames.Contains(pl.VgName) {
+			if pl.IsThin() {
+				if pl.Node != nodeId {
+					continue
+				}
 
-		for _, pl := range pools {
-			if vgNames.Contains(pl.VgName) {
-				availablePools = append(availablePools, pl)
+				hasLock, e := HasLocking(pl.VgName)
+				if e != nil {
+					logrus.WithFields(logrus.Fields{
+						"pool_id": pl.Id.Hex(),
+						"vg_name": pl.VgName,
+						"error":   e,
+					}).Error("lvm: Failed to check volume group locking")
+					continue
+				}
+
+				if hasLock {
+					logrus.WithFields(logrus.Fields{
+						"pool_id":   pl.Id.Hex(),
+						"vg_name":   pl.VgName,
+						"thin_pool": pl.ThinPool,
+					}).Warn("lvm: Thin pool on shared volume group unavailable")
+					continue
+				}
 			}
+
+			stats, e := GetStats(pl)
+			if e != nil {
+				logrus.WithFields(logrus.Fields{
+					"pool_id": pl.Id.Hex(),
+					"vg_name": pl.VgName,
+					"error":   e,
+				}).Error("lvm: Failed to get pool stats")
+			} else {
+				pl.Stats = stats
+				e = pl.CommitFields(db, set.NewSet("stats"))
+				if e != nil {
+					logrus.WithFields(logrus.Fields{
+						"pool_id": pl.Id.Hex(),
+						"error":   e,
+					}).Error("lvm: Failed to commit pool stats")
+				}
+			}
+
+			availablePools = append(availablePools, pl)
 		}
 	}
 
@@ -34,6 +34,10 @@ Commands:
   shutdown          Shutdown all instances running on this node
   mtu-check         Check and show instance MTUs
   backup            Backup local data
+  vuln-import       Import offline vulnerability data
+  key-generate      Generate database master key
+  key-rotate        Encrypt database with current master key
+  audit-verify      Verify audit log hash chain
 `
 
 func Init() {
@@ -180,6 +184,36 @@ func main() {
 			panic(err)
 		}
 		return
+	case "vuln-import":
+		flag.Parse()
+		InitLimited()
+		err := cmd.VulnerabilityImport()
+		if err != nil {
+			panic(err)
+		}
+		return
+	case "key-generate":
+		err := cmd.KeyGenerate()
+		if err != nil {
+			panic(err)
+		}
+		return
+	case "key-rotate":
+		flag.Parse()
+		InitLimited()
+		err := cmd.KeyRotate()
+		if err != nil {
+			panic(err)
+		}
+		return
+	case "audit-verify":
+		flag.Parse()
+		InitLimited()
+		err := cmd.AuditVerify()
+		if err != nil {
+			panic(err)
+		}
+		return
 	case "imds-server":
 		err := cmd.ImdsServer()
 		if err != nil {
@@ -0,0 +1,6 @@
+package metric
+
+const (
+	CustomLimit     = 16
+	CustomNameLimit = 32
+)
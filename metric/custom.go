@@ -0,0 +1,117 @@
+package metric
+
+import (
+	"context"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+)
+
+type Custom struct {
+	Id        bson.ObjectID `bson:"_id" json:"id"`
+	Resource  bson.ObjectID `bson:"r" json:"r"`
+	Timestamp time.Time     `bson:"t" json:"t"`
+
+	Values map[string]float64 `bson:"v" json:"v"`
+}
+
+type CustomAgg struct {
+	Id    interface{} `bson:"_id"`
+	Value float64     `bson:"v"`
+	Count int         `bson:"c"`
+}
+
+func (d *Custom) GetCollection(db *database.Database) *database.Collection {
+	return db.MetricsCustom()
+}
+
+func (d *Custom) Format(id bson.ObjectID) time.Time {
+	d.Resource = id
+	d.Timestamp = d.Timestamp.UTC().Truncate(1 * time.Minute)
+	d.Id = GenerateId(id, d.Timestamp)
+
+	values := map[string]float64{}
+	for name, val := range d.Values {
+		name = FilterCustomName(name)
+		if name == "" {
+			continue
+		}
+		if len(values) >= CustomLimit {
+			break
+		}
+		values[name] = val
+	}
+	d.Values = values
+
+	return d.Timestamp
+}
+
+func (d *Custom) StaticData() bson.M {
+	return bson.M{}
+}
+
+func GetCustomAverage(c context.Context, db *database.Database,
+	resources []bson.ObjectID, name string, start time.Time) (
+	value float64, count int, err error) {
+
+	name = FilterCustomName(name)
+	if name == "" || len(resources) == 0 {
+		return
+	}
+
+	coll := db.MetricsCustom()
+	key := "v." + name
+
+	cursor, err := coll.Aggregate(c, []*bson.M{
+		&bson.M{
+			"$match": &bson.M{
+				"r": &bson.M{
+					"$in": resources,
+				},
+				"t": &bson.M{
+					"$gte": start,
+				},
+				key: &bson.M{
+					"$exists": true,
+				},
+			},
+		},
+		&bson.M{
+			"$group": &bson.M{
+				"_id": nil,
+				"v": &bson.M{
+					"$avg": "$" + key,
+				},
+				"c": &bson.M{
+					"$sum": 1,
+				},
+			},
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		doc := &CustomAgg{}
+		err = cursor.Decode(doc)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		value = doc.Value
+		count = doc.Count
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/binary"
 	"hash/fnv"
+	"strings"
 	"time"
 
 	"github.com/dropbox/godropbox/errors"
@@ -40,6 +41,23 @@ func GenerateId(resourceId bson.ObjectID,
 	return b
 }
 
+func FilterCustomName(name string) string {
+	if len(name) > CustomNameLimit {
+		name = name[:CustomNameLimit]
+	}
+
+	var ns strings.Builder
+	for _, c := range strings.ToLower(name) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+			c == '_' || c == '-' {
+
+			ns.WriteRune(c)
+		}
+	}
+
+	return ns.String()
+}
+
 func GetObj(typ string) Doc {
 	switch typ {
 	case "system":
@@ -52,6 +70,8 @@ func GetObj(typ string) Doc {
 		return &DiskIo{}
 	case "network":
 		return &Network{}
+	case "custom":
+		return &Custom{}
 	default:
 		return nil
 	}
@@ -15,6 +15,7 @@ type Sample struct {
 	Disk      *Disk     `json:"dk,omitempty"`
 	DiskIo    *DiskIo   `json:"di,omitempty"`
 	Network   *Network  `json:"nw,omitempty"`
+	Custom    *Custom   `json:"cm,omitempty"`
 }
 
 func (s *Sample) StaticData() bson.M {
@@ -60,6 +61,10 @@ func (s *Sample) docs() (docs []Doc) {
 		s.Network.Timestamp = s.Timestamp
 		docs = append(docs, s.Network)
 	}
+	if s.Custom != nil && len(s.Custom.Values) > 0 {
+		s.Custom.Timestamp = s.Timestamp
+		docs = append(docs, s.Custom)
+	}
 	return
 }
 
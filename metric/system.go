@@ -204,3 +204,71 @@ func GetSystemChart(c context.Context, db *database.Database,
 
 	return
 }
+
+func GetSystemAverage(c context.Context, db *database.Database,
+	resources []bson.ObjectID, start time.Time) (
+	cpuUsage, memUsage float64, count int, err error) {
+
+	if len(resources) == 0 {
+		return
+	}
+
+	coll := db.MetricsSystem()
+
+	cursor, err := coll.Aggregate(c, []*bson.M{
+		&bson.M{
+			"$match": &bson.M{
+				"r": &bson.M{
+					"$in": resources,
+				},
+				"t": &bson.M{
+					"$gte": start,
+				},
+			},
+		},
+		&bson.M{
+			"$group": &bson.M{
+				"_id": nil,
+				"cu": &bson.M{
+					"$avg": "$cu",
+				},
+				"mu": &bson.M{
+					"$avg": "$mu",
+				},
+				"c": &bson.M{
+					"$sum": 1,
+				},
+			},
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		doc := &struct {
+			CpuUsage float64 `bson:"cu"`
+			MemUsage float64 `bson:"mu"`
+			Count    int     `bson:"c"`
+		}{}
+		err = cursor.Decode(doc)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		cpuUsage = doc.CpuUsage
+		memUsage = doc.MemUsage
+		count = doc.Count
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
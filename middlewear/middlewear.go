@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/dropbox/godropbox/container/set"
 	"github.com/dropbox/godropbox/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/pritunl/pritunl-cloud/audit"
@@ -13,6 +14,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/csrf"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/imgimport"
 	"github.com/pritunl/pritunl-cloud/node"
 	"github.com/pritunl/pritunl-cloud/organization"
 	"github.com/pritunl/pritunl-cloud/session"
@@ -25,7 +27,17 @@ const robots = `User-agent: *
 Disallow: /
 `
 
+var largeBodyRoutes = set.NewSet(
+	"/image_import/:import_id/chunk/:chunk",
+)
+
 func Limiter(c *gin.Context) {
+	if largeBodyRoutes.Contains(c.FullPath()) {
+		c.Request.Body = http.MaxBytesReader(
+			c.Writer, c.Request.Body, imgimport.MaxChunkSize)
+		return
+	}
+
 	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 1000000)
 }
 
@@ -348,6 +360,58 @@ func CsrfToken(c *gin.Context) {
 	}
 }
 
+func AuditAdmin(c *gin.Context) {
+	switch c.Request.Method {
+	case "GET", "HEAD", "OPTIONS":
+		return
+	}
+
+	c.Next()
+
+	status := c.Writer.Status()
+	if c.IsAborted() || status >= 400 {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	usr, err := authr.GetUser(db)
+	if err != nil || usr == nil {
+		logrus.WithFields(logrus.Fields{
+			"path":  c.Request.URL.Path,
+			"error": err,
+		}).Error("middlewear: Failed to get admin user for audit")
+		return
+	}
+
+	fields := audit.Fields{
+		"method": c.Request.Method,
+		"path":   c.Request.URL.Path,
+		"route":  c.FullPath(),
+		"status": status,
+		"api":    authr.IsApi(),
+	}
+	for _, param := range c.Params {
+		fields[param.Key] = param.Value
+	}
+
+	err = audit.New(
+		db,
+		c.Request,
+		usr.Id,
+		audit.AdminMutation,
+		fields,
+	)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"path":  c.Request.URL.Path,
+			"error": err,
+		}).Error("middlewear: Failed to audit admin request")
+		return
+	}
+}
+
 func Recovery(c *gin.Context) {
 	defer func() {
 		if r := recover(); r != nil {
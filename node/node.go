@@ -28,6 +28,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/constants"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/drive"
+	"github.com/pritunl/pritunl-cloud/envelope"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/event"
 	"github.com/pritunl/pritunl-cloud/ip"
@@ -128,7 +129,7 @@ type Node struct {
 	TempPath                string             `bson:"temp_path" json:"temp_path"`
 	OracleUser              string             `bson:"oracle_user" json:"oracle_user"`
 	OracleTenancy           string             `bson:"oracle_tenancy" json:"oracle_tenancy"`
-	OraclePrivateKey        string             `bson:"oracle_private_key" json:"-"`
+	OraclePrivateKey        envelope.String    `bson:"oracle_private_key" json:"-"`
 	OraclePublicKey         string             `bson:"oracle_public_key" json:"oracle_public_key"`
 	Operation               string             `bson:"operation" json:"operation"`
 	Metric                  *MetricData        `bson:"metric,omitempty" json:"metric"`
@@ -1478,7 +1479,7 @@ func (n *Node) sync() (nde *Node) {
 
 	n.SyncNetwork(false)
 
-	pools, err := lvm.GetAvailablePools(db, n.Zone)
+	pools, err := lvm.GetAvailablePools(db, n.Id, n.Zone)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"error": err,
@@ -1673,7 +1674,8 @@ func (n *Node) Init() (err error) {
 		}
 
 		bsonSet["oracle_public_key"] = strings.TrimSpace(string(pubKey))
-		bsonSet["oracle_private_key"] = strings.TrimSpace(string(privKey))
+		bsonSet["oracle_private_key"] = envelope.String(
+			strings.TrimSpace(string(privKey)))
 	}
 
 	_, err = coll.UpdateOne(
@@ -13,5 +13,5 @@ func (n *NodeOracleAuthProvider) OracleTenancy() string {
 }
 
 func (n *NodeOracleAuthProvider) OraclePrivateKey() string {
-	return n.nde.OraclePrivateKey
+	return string(n.nde.OraclePrivateKey)
 }
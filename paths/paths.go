@@ -256,6 +256,15 @@ func GetGuestPath(virtId bson.ObjectID) string {
 		fmt.Sprintf("%s.guest", virtId.Hex()))
 }
 
+func GetSerialSockPath(virtId bson.ObjectID) string {
+	return path.Join(settings.Hypervisor.RunPath,
+		fmt.Sprintf("%s.serial", virtId.Hex()))
+}
+
+func GetSerialLogPath(virtId bson.ObjectID) string {
+	return path.Join(GetVmPath(virtId), "serial.log")
+}
+
 // TODO Backward compatibility
 func GetPidPathOld(virtId bson.ObjectID) string {
 	return path.Join(settings.Hypervisor.LibPath,
@@ -30,7 +30,7 @@ func buildEvalData(unt *unit.Unit,
 	dataStrct := plan.Data{
 		Unit: plan.Unit{
 			Name:  unt.Name,
-			Count: unt.Count,
+			Count: unt.GetCount(),
 		},
 		Instance: plan.Instance{
 			Name:          inst.Name,
@@ -1,7 +1,8 @@
 package pool
 
 const (
-	Lvm = "lvm"
+	Lvm      = "lvm"
+	SharedFs = "shared_fs"
 
 	Active = "active"
 )
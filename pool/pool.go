@@ -1,6 +1,10 @@
 package pool
 
 import (
+	"path"
+	"strings"
+	"time"
+
 	"github.com/dropbox/godropbox/container/set"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/database"
@@ -17,6 +21,20 @@ type Pool struct {
 	Zone             bson.ObjectID `bson:"zone" json:"zone"`
 	Type             string        `bson:"type" json:"type"`
 	VgName           string        `bson:"vg_name" json:"vg_name"`
+	ThinPool         string        `bson:"thin_pool" json:"thin_pool"`
+	Overcommit       float64       `bson:"overcommit" json:"overcommit"`
+	Node             bson.ObjectID `bson:"node" json:"node"`
+	Stats            *Stats        `bson:"stats" json:"stats"`
+	Path             string        `bson:"path" json:"path"`
+	Mount            bool          `bson:"mount" json:"mount"`
+}
+
+type Stats struct {
+	Size        int       `bson:"size" json:"size"`
+	Allocated   int       `bson:"allocated" json:"allocated"`
+	DataPercent float64   `bson:"data_percent" json:"data_percent"`
+	MetaPercent float64   `bson:"meta_percent" json:"meta_percent"`
+	Timestamp   time.Time `bson:"timestamp" json:"timestamp"`
 }
 
 type Completion struct {
@@ -28,6 +46,31 @@ type Completion struct {
 func (p *Pool) Json(nodeNames map[bson.ObjectID]string) {
 }
 
+func (p *Pool) IsThin() bool {
+	return p.Type == Lvm && p.ThinPool != ""
+}
+
+// Capacity returns the size in gigabytes that can be allocated from the
+// pool including the thin pool overcommit
+func (p *Pool) Capacity() int {
+	if p.Stats == nil {
+		return 0
+	}
+
+	if p.IsThin() {
+		return int(float64(p.Stats.Size) * p.Overcommit)
+	}
+	return p.Stats.Size
+}
+
+func (p *Pool) Free() int {
+	if p.Stats == nil {
+		return 0
+	}
+
+	return max(p.Capacity()-p.Stats.Allocated, 0)
+}
+
 func (p *Pool) Validate(db *database.Database) (
 	errData *errortypes.ErrorData, err error) {
 
@@ -49,6 +92,73 @@ func (p *Pool) Validate(db *database.Database) (
 		return
 	}
 
+	if p.Type == "" {
+		p.Type = Lvm
+	}
+
+	switch p.Type {
+	case Lvm:
+		p.Path = ""
+		p.Mount = false
+
+		if p.VgName == "" {
+			errData = &errortypes.ErrorData{
+				Error:   "invalid_vg_name",
+				Message: "Missing required volume group name",
+			}
+			return
+		}
+		p.ThinPool = strings.TrimSpace(p.ThinPool)
+		if p.ThinPool == "" {
+			p.Overcommit = 1
+			p.Node = bson.NilObjectID
+		} else {
+			// lvmlockd only allows a thin pool to be active on one host,
+			// thin pools must be in a local volume group on the node
+			if p.Node.IsZero() {
+				errData = &errortypes.ErrorData{
+					Error:   "node_required",
+					Message: "Thin pool requires node with local volume group",
+				}
+				return
+			}
+
+			if p.Overcommit == 0 {
+				p.Overcommit = 1
+			}
+
+			if p.Overcommit < 1 || p.Overcommit > 20 {
+				errData = &errortypes.ErrorData{
+					Error:   "invalid_overcommit",
+					Message: "Pool overcommit ratio must be between 1 and 20",
+				}
+				return
+			}
+		}
+		break
+	case SharedFs:
+		p.VgName = ""
+		p.ThinPool = ""
+		p.Overcommit = 0
+		p.Node = bson.NilObjectID
+
+		p.Path = path.Clean(strings.TrimSpace(p.Path))
+		if !path.IsAbs(p.Path) || p.Path == "/" {
+			errData = &errortypes.ErrorData{
+				Error:   "invalid_path",
+				Message: "Pool path must be an absolute directory",
+			}
+			return
+		}
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "unknown_type",
+			Message: "Unknown pool type",
+		}
+		return
+	}
+
 	return
 }
 
@@ -0,0 +1,53 @@
+package pool
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+func (p *Pool) IsSharedFs() bool {
+	return p.Type == SharedFs
+}
+
+func (p *Pool) GetDisksPath() string {
+	return path.Join(p.Path, "disks")
+}
+
+func (p *Pool) GetDiskPath(diskId bson.ObjectID) string {
+	return path.Join(p.GetDisksPath(),
+		fmt.Sprintf("%s.qcow2", diskId.Hex()))
+}
+
+// CheckPath verifies the shared directory exists on the node, is writable
+// and when required is a mount point
+func (p *Pool) CheckPath(nodeId bson.ObjectID) (err error) {
+	status, message := utils.CheckDir(p.Path, p.Mount,
+		fmt.Sprintf(".pritunl-cloud-%s", nodeId.Hex()))
+	if status == utils.DirReadOnly {
+		err = &errortypes.WriteError{
+			errors.New("pool: " + message),
+		}
+		return
+	} else if status != "" {
+		err = &errortypes.NotFoundError{
+			errors.New("pool: " + message),
+		}
+		return
+	}
+
+	err = os.MkdirAll(p.GetDisksPath(), 0755)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrapf(err, "pool: Failed to create disks directory"),
+		}
+		return
+	}
+
+	return
+}
@@ -0,0 +1,106 @@
+package proxy
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/accesslog"
+	"github.com/pritunl/pritunl-cloud/balancer"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/sirupsen/logrus"
+)
+
+type logWriter struct {
+	http.ResponseWriter
+	status  int
+	bytes   int64
+	backend string
+	limited bool
+}
+
+func (w *logWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *logWriter) Write(data []byte) (n int, err error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err = w.ResponseWriter.Write(data)
+	w.bytes += int64(n)
+	return
+}
+
+func (w *logWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *logWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, &errortypes.RequestError{
+			errors.New("proxy: Response writer does not support hijack"),
+		}
+	}
+
+	w.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+func (w *logWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func setBackend(rw http.ResponseWriter, hand *Handler) {
+	if lw, ok := rw.(*logWriter); ok {
+		lw.backend = hand.Key
+	}
+}
+
+func (d *Domain) accessLog(lw *logWriter, r *http.Request,
+	start time.Time) {
+
+	entry := &accesslog.Entry{
+		Balancer:  d.Balancer.Id,
+		Node:      node.Self.Id,
+		Timestamp: start,
+		Client:    node.Self.GetRemoteAddr(r),
+		Method:    r.Method,
+		Host:      r.Host,
+		Path:      r.URL.Path,
+		Status:    lw.status,
+		Latency:   time.Since(start).Milliseconds(),
+		Backend:   lw.backend,
+		Bytes:     lw.bytes,
+		Limited:   lw.limited,
+	}
+
+	switch d.Balancer.AccessLog {
+	case balancer.AccessLogDatabase:
+		accesslog.Log(entry)
+		break
+	case balancer.AccessLogNode:
+		logrus.WithFields(logrus.Fields{
+			"balancer": d.Balancer.Name,
+			"client":   entry.Client,
+			"method":   entry.Method,
+			"host":     entry.Host,
+			"path":     entry.Path,
+			"status":   entry.Status,
+			"latency":  entry.Latency,
+			"backend":  entry.Backend,
+			"bytes":    entry.Bytes,
+			"limited":  entry.Limited,
+		}).Info("proxy: Access log")
+		break
+	}
+}
@@ -1,9 +1,9 @@
 package proxy
 
 import (
+	"context"
 	"crypto/md5"
 	"crypto/tls"
-	"math/rand"
 	"net/http"
 	"strconv"
 	"sync"
@@ -13,6 +13,7 @@ import (
 	"github.com/dropbox/godropbox/container/set"
 	"github.com/pritunl/pritunl-cloud/authority"
 	"github.com/pritunl/pritunl-cloud/balancer"
+	"github.com/pritunl/pritunl-cloud/node"
 )
 
 type Domain struct {
@@ -31,6 +32,9 @@ type Domain struct {
 	Domain            *balancer.Domain
 	ClientAuthority   *authority.Authority
 	ClientCertificate *tls.Certificate
+	Routes            []*Route
+	DefaultAll        bool
+	Limiter           *Limiter
 
 	OnlineWebFirst      []*Handler
 	UnknownHighWebFirst []*Handler
@@ -67,6 +71,13 @@ func (d *Domain) CalculateHash() {
 	h.Write([]byte(strconv.FormatBool(d.Balancer.WebSockets)))
 	h.Write([]byte(d.Domain.Domain))
 	h.Write([]byte(d.Domain.Host))
+	h.Write([]byte(strconv.Itoa(d.Balancer.RateLimit)))
+	h.Write([]byte(strconv.Itoa(d.Balancer.RateLimitBurst)))
+	h.Write([]byte(d.Balancer.RateLimitKey))
+	h.Write([]byte(d.Balancer.RateLimitHeader))
+	h.Write([]byte(strconv.FormatBool(d.Balancer.StickySessions)))
+	h.Write([]byte(d.Balancer.StickyCookie))
+	h.Write([]byte(d.Balancer.AccessLog))
 
 	if !d.Balancer.ClientAuthority.IsZero() {
 		h.Write([]byte(d.Balancer.ClientAuthority.Hex()))
@@ -75,6 +86,32 @@ func (d *Domain) CalculateHash() {
 		h.Write([]byte(backend.Protocol))
 		h.Write([]byte(backend.Hostname))
 		h.Write([]byte(strconv.Itoa(backend.Port)))
+		h.Write([]byte(backend.Group))
+		h.Write([]byte(strconv.Itoa(backend.Weight)))
+	}
+	for _, rte := range d.Balancer.Routes {
+		h.Write([]byte(rte.Domain))
+		h.Write([]byte(rte.Path))
+		h.Write([]byte(rte.Regex))
+		h.Write([]byte(rte.Group))
+		h.Write([]byte(rte.Rewrite))
+		h.Write([]byte(strconv.FormatBool(rte.RedirectHttps)))
+		h.Write([]byte(rte.RedirectHost))
+		h.Write([]byte(strconv.Itoa(rte.RedirectCode)))
+		for _, header := range rte.RequestHeaders {
+			h.Write([]byte(header.Name))
+			h.Write([]byte(header.Value))
+		}
+		for _, name := range rte.RequestHeadersRemove {
+			h.Write([]byte(name))
+		}
+		for _, header := range rte.ResponseHeaders {
+			h.Write([]byte(header.Name))
+			h.Write([]byte(header.Value))
+		}
+		for _, name := range rte.ResponseHeadersRemove {
+			h.Write([]byte(name))
+		}
 	}
 
 	d.Hash = h.Sum(nil)
@@ -112,7 +149,14 @@ func (d *Domain) Init() {
 	unknownHighWebSecond := []*Handler{}
 	unknownHighWebThird := []*Handler{}
 
+	d.Routes = NewRoutes(d)
+	d.DefaultAll = true
+
 	for i, backend := range d.Balancer.Backends {
+		if backend.Group == "" {
+			d.DefaultAll = false
+		}
+
 		hand := NewHandler(i, UnknownHigh, d.ProxyProto, d.ProxyPort, d,
 			backend, d.ResponseHandler, d.ErrorHandlerFirst)
 		unknownHighWebFirst = append(unknownHighWebFirst, hand)
@@ -147,127 +191,141 @@ func (d *Domain) Init() {
 	d.WebSocketConns = set.NewSet()
 }
 
-func (d *Domain) ServeHTTPFirst(rw http.ResponseWriter, r *http.Request) {
-	atomic.AddInt32(d.Requests, 1)
+func (d *Domain) serveHandlers(rw http.ResponseWriter, r *http.Request,
+	tiers ...[]*Handler) {
 
-	onlineWebFirst := d.OnlineWebFirst
-	l := len(onlineWebFirst)
-	if l != 0 {
-		onlineWebFirst[rand.Intn(l)].Serve(rw, r)
-		return
-	}
+	group := getGroup(r)
+	all := group == "" && d.DefaultAll
 
-	unknownHighWebFirst := d.UnknownHighWebFirst
-	l = len(unknownHighWebFirst)
-	if l != 0 {
-		unknownHighWebFirst[rand.Intn(l)].Serve(rw, r)
-		return
-	}
-
-	unknownMidWebFirst := d.UnknownMidWebFirst
-	l = len(unknownMidWebFirst)
-	if l != 0 {
-		unknownMidWebFirst[rand.Intn(l)].Serve(rw, r)
-		return
-	}
-
-	unknownLowWebFirst := d.UnknownLowWebFirst
-	l = len(unknownLowWebFirst)
-	if l != 0 {
-		unknownLowWebFirst[rand.Intn(l)].Serve(rw, r)
-		return
-	}
-
-	offlineWebFirst := d.OfflineWebFirst
-	l = len(offlineWebFirst)
-	if l != 0 {
-		offlineWebFirst[rand.Intn(l)].Serve(rw, r)
-		return
+	for _, handlers := range tiers {
+		hand := selectHandler(handlers, group, all)
+		if hand != nil {
+			setBackend(rw, hand)
+			hand.Serve(rw, r)
+			return
+		}
 	}
 
 	rw.WriteHeader(http.StatusBadGateway)
 }
 
-func (d *Domain) ServeHTTPSecond(rw http.ResponseWriter, r *http.Request) {
-	atomic.AddInt32(d.Retries, 1)
+func (d *Domain) serveSticky(rw http.ResponseWriter,
+	r *http.Request) bool {
 
-	onlineWebSecond := d.OnlineWebSecond
-	l := len(onlineWebSecond)
-	if l != 0 {
-		onlineWebSecond[rand.Intn(l)].Serve(rw, r)
-		return
+	cookie, err := r.Cookie(d.Balancer.StickyCookie)
+	if err != nil || cookie.Value == "" {
+		return false
 	}
 
-	unknownHighWebSecond := d.UnknownHighWebSecond
-	l = len(unknownHighWebSecond)
-	if l != 0 {
-		unknownHighWebSecond[rand.Intn(l)].Serve(rw, r)
-		return
-	}
+	group := getGroup(r)
+	all := group == "" && d.DefaultAll
 
-	unknownMidWebSecond := d.UnknownMidWebSecond
-	l = len(unknownMidWebSecond)
-	if l != 0 {
-		unknownMidWebSecond[rand.Intn(l)].Serve(rw, r)
-		return
+	tiers := [][]*Handler{
+		d.OnlineWebFirst,
+		d.UnknownHighWebFirst,
+		d.UnknownMidWebFirst,
+		d.UnknownLowWebFirst,
 	}
 
-	unknownLowWebSecond := d.UnknownLowWebSecond
-	l = len(unknownLowWebSecond)
-	if l != 0 {
-		unknownLowWebSecond[rand.Intn(l)].Serve(rw, r)
-		return
+	for _, handlers := range tiers {
+		for _, hand := range handlers {
+			if hand.Affinity != cookie.Value ||
+				(!all && hand.Group != group) {
+
+				continue
+			}
+
+			setBackend(rw, hand)
+			hand.Serve(rw, r)
+			return true
+		}
 	}
 
-	offlineWebSecond := d.OfflineWebSecond
-	l = len(offlineWebSecond)
-	if l != 0 {
-		offlineWebSecond[rand.Intn(l)].Serve(rw, r)
-		return
+	return false
+}
+
+func (d *Domain) rateLimitKey(r *http.Request) string {
+	if d.Balancer.RateLimitKey == balancer.RateLimitHeader {
+		key := r.Header.Get(d.Balancer.RateLimitHeader)
+		if key != "" {
+			return key
+		}
 	}
 
-	rw.WriteHeader(http.StatusBadGateway)
+	return node.Self.GetRemoteAddr(r)
 }
 
-func (d *Domain) ServeHTTPThird(rw http.ResponseWriter, r *http.Request) {
-	atomic.AddInt32(d.Retries, 1)
+func (d *Domain) ServeHTTPFirst(rw http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(d.Requests, 1)
 
-	onlineWebThird := d.OnlineWebThird
-	l := len(onlineWebThird)
-	if l != 0 {
-		onlineWebThird[rand.Intn(l)].Serve(rw, r)
-		return
+	var lw *logWriter
+	if d.Balancer.AccessLog != "" {
+		lw = &logWriter{
+			ResponseWriter: rw,
+		}
+		rw = lw
+		defer d.accessLog(lw, r, time.Now())
 	}
 
-	unknownHighWebThird := d.UnknownHighWebThird
-	l = len(unknownHighWebThird)
-	if l != 0 {
-		unknownHighWebThird[rand.Intn(l)].Serve(rw, r)
+	if d.Limiter != nil && !d.Limiter.Allow(d.rateLimitKey(r)) {
+		if lw != nil {
+			lw.limited = true
+		}
+		rw.Header().Set("Retry-After", "1")
+		rw.WriteHeader(http.StatusTooManyRequests)
 		return
 	}
 
-	unknownMidWebThird := d.UnknownMidWebThird
-	l = len(unknownMidWebThird)
-	if l != 0 {
-		unknownMidWebThird[rand.Intn(l)].Serve(rw, r)
-		return
-	}
+	for _, rte := range d.Routes {
+		if !rte.Match(r) {
+			continue
+		}
 
-	unknownLowWebThird := d.UnknownLowWebThird
-	l = len(unknownLowWebThird)
-	if l != 0 {
-		unknownLowWebThird[rand.Intn(l)].Serve(rw, r)
-		return
+		if rte.Redirect(rw, r) {
+			return
+		}
+
+		r = r.Clone(context.WithValue(r.Context(), routeKey{}, rte))
+		rte.Rewrite(r)
+		rte.RequestHeaders(r)
+		break
 	}
 
-	offlineWebThird := d.OfflineWebThird
-	l = len(offlineWebThird)
-	if l != 0 {
-		offlineWebThird[rand.Intn(l)].Serve(rw, r)
+	if d.Balancer.StickySessions && d.serveSticky(rw, r) {
 		return
 	}
 
-	rw.WriteHeader(http.StatusBadGateway)
+	d.serveHandlers(rw, r,
+		d.OnlineWebFirst,
+		d.UnknownHighWebFirst,
+		d.UnknownMidWebFirst,
+		d.UnknownLowWebFirst,
+		d.OfflineWebFirst,
+	)
+}
+
+func (d *Domain) ServeHTTPSecond(rw http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(d.Retries, 1)
+
+	d.serveHandlers(rw, r,
+		d.OnlineWebSecond,
+		d.UnknownHighWebSecond,
+		d.UnknownMidWebSecond,
+		d.UnknownLowWebSecond,
+		d.OfflineWebSecond,
+	)
+}
+
+func (d *Domain) ServeHTTPThird(rw http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(d.Retries, 1)
+
+	d.serveHandlers(rw, r,
+		d.OnlineWebThird,
+		d.UnknownHighWebThird,
+		d.UnknownMidWebThird,
+		d.UnknownLowWebThird,
+		d.OfflineWebThird,
+	)
 }
 
 func (d *Domain) checkHandler(hand *Handler) {
@@ -886,9 +944,37 @@ func (d *Domain) ResponseHandler(hand *Handler, resp *http.Response) error {
 		d.upgradeHandler(hand)
 	}
 
+	if d.Balancer.StickySessions {
+		d.setSticky(hand, resp)
+	}
+
+	rte := getRoute(resp.Request)
+	if rte != nil {
+		rte.ResponseHeaders(resp)
+	}
+
 	return nil
 }
 
+func (d *Domain) setSticky(hand *Handler, resp *http.Response) {
+	if resp.Request != nil {
+		cookie, err := resp.Request.Cookie(d.Balancer.StickyCookie)
+		if err == nil && cookie.Value == hand.Affinity {
+			return
+		}
+	}
+
+	cookie := &http.Cookie{
+		Name:     d.Balancer.StickyCookie,
+		Value:    hand.Affinity,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   d.ProxyProto == "https",
+		SameSite: http.SameSiteLaxMode,
+	}
+	resp.Header.Add("Set-Cookie", cookie.String())
+}
+
 func (d *Domain) ErrorHandlerFirst(hand *Handler, rw http.ResponseWriter,
 	r *http.Request, err error) {
 
@@ -0,0 +1,66 @@
+package proxy
+
+import (
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type Limiter struct {
+	Rate    int
+	Burst   int
+	buckets map[string]*bucket
+	lock    sync.Mutex
+}
+
+func (l *Limiter) Allow(key string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := time.Now()
+
+	buck := l.buckets[key]
+	if buck == nil {
+		buck = &bucket{
+			tokens: float64(l.Burst),
+			last:   now,
+		}
+		l.buckets[key] = buck
+	} else {
+		buck.tokens += now.Sub(buck.last).Seconds() * float64(l.Rate)
+		if buck.tokens > float64(l.Burst) {
+			buck.tokens = float64(l.Burst)
+		}
+		buck.last = now
+	}
+
+	if buck.tokens < 1 {
+		return false
+	}
+	buck.tokens -= 1
+
+	return true
+}
+
+func (l *Limiter) Clean(expire time.Duration) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for key, buck := range l.buckets {
+		if time.Since(buck.last) > expire {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+func NewLimiter(rate, burst int) *Limiter {
+	return &Limiter{
+		Rate:    rate,
+		Burst:   burst,
+		buckets: map[string]*bucket{},
+	}
+}
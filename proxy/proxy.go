@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/balancer"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/node"
@@ -16,8 +17,9 @@ import (
 )
 
 type Proxy struct {
-	Domains map[string]*Domain
-	lock    sync.Mutex
+	Domains  map[string]*Domain
+	Limiters map[bson.ObjectID]*Limiter
+	lock     sync.Mutex
 }
 
 type balancerState struct {
@@ -41,6 +43,7 @@ func (p *Proxy) Update(db *database.Database, balncs []*balancer.Balancer) (
 	err error) {
 
 	domains := map[string]*Domain{}
+	limiters := map[bson.ObjectID]*Limiter{}
 	domainsName := set.NewSet()
 	remDomains := []*Domain{}
 	states := []*balancerState{}
@@ -69,6 +72,17 @@ func (p *Proxy) Update(db *database.Database, balncs []*balancer.Balancer) (
 			Offline:     []string{},
 		}
 
+		var limiter *Limiter
+		if balnc.RateLimit > 0 {
+			limiter = p.Limiters[balnc.Id]
+			if limiter == nil || limiter.Rate != balnc.RateLimit ||
+				limiter.Burst != balnc.RateLimitBurst {
+
+				limiter = NewLimiter(balnc.RateLimit, balnc.RateLimitBurst)
+			}
+			limiters[balnc.Id] = limiter
+		}
+
 		for _, domain := range balnc.Domains {
 			if domains[domain.Domain] != nil {
 				conflictDomain := domains[domain.Domain]
@@ -90,6 +104,7 @@ func (p *Proxy) Update(db *database.Database, balncs []*balancer.Balancer) (
 				ProxyPort:  proxyPort,
 				Balancer:   balnc,
 				Domain:     domain,
+				Limiter:    limiter,
 				Requests:   new(int32),
 				Retries:    new(int32),
 			}
@@ -196,6 +211,7 @@ func (p *Proxy) Update(db *database.Database, balncs []*balancer.Balancer) (
 	}
 
 	p.Domains = domains
+	p.Limiters = limiters
 	p.lock.Unlock()
 
 	for _, domain := range remDomains {
@@ -256,10 +272,22 @@ func (p *Proxy) syncCount() {
 	}
 }
 
+func (p *Proxy) cleanLimiters() {
+	p.lock.Lock()
+	limiters := p.Limiters
+	p.lock.Unlock()
+
+	expire := time.Duration(settings.Router.RateLimitExpire) * time.Second
+	for _, limiter := range limiters {
+		limiter.Clean(expire)
+	}
+}
+
 func (p *Proxy) runCounter() {
 	for {
 		time.Sleep(10 * time.Second)
 		p.syncCount()
+		p.cleanLimiters()
 	}
 }
 
@@ -282,6 +310,7 @@ func (p *Proxy) runHealthCheck() {
 
 func (p *Proxy) Init() {
 	p.Domains = map[string]*Domain{}
+	p.Limiters = map[bson.ObjectID]*Limiter{}
 	go p.runCounter()
 	go p.runHealthCheck()
 }
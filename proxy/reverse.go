@@ -1,7 +1,9 @@
 package proxy
 
 import (
+	"crypto/md5"
 	"crypto/tls"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"net"
@@ -36,6 +38,9 @@ type Handler struct {
 	BackendProto       string
 	BackendProtoWs     string
 	RequestHost        string
+	Group              string
+	Weight             int
+	Affinity           string
 	ForwardedProto     string
 	ForwardedPort      string
 	TlsConfig          *tls.Config
@@ -155,6 +160,15 @@ func NewHandler(index, state int, proxyProto string, proxyPort int,
 	backendProto := backend.Protocol
 	backendHost := utils.FormatHostPort(backend.Hostname, backend.Port)
 
+	weight := backend.Weight
+	if weight <= 0 {
+		weight = balancer.DefaultWeight
+	}
+
+	affinityHash := md5.Sum([]byte(fmt.Sprintf("%s:%s:%d",
+		domain.Balancer.Id.Hex(), backend.Hostname, backend.Port)))
+	affinity := hex.EncodeToString(affinityHash[:8])
+
 	backendProtoWs := ""
 	if backendProto == "https" {
 		backendProtoWs = "wss"
@@ -255,6 +269,9 @@ func NewHandler(index, state int, proxyProto string, proxyPort int,
 		BackendProto:   backendProto,
 		BackendProtoWs: backendProtoWs,
 		RequestHost:    reqHost,
+		Group:          backend.Group,
+		Weight:         weight,
+		Affinity:       affinity,
 		ForwardedProto: proxyProto,
 		ForwardedPort:  proxyPortStr,
 		WebSockets:     domain.Balancer.WebSockets,
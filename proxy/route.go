@@ -0,0 +1,185 @@
+package proxy
+
+import (
+	"math/rand"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/pritunl/pritunl-cloud/balancer"
+	"github.com/sirupsen/logrus"
+)
+
+type routeKey struct{}
+
+type Route struct {
+	Route *balancer.Route
+	Regex *regexp.Regexp
+}
+
+func (r *Route) Match(req *http.Request) bool {
+	if r.Route.Path != "" && !strings.HasPrefix(
+		req.URL.Path, r.Route.Path) {
+
+		return false
+	}
+
+	if r.Regex != nil && !r.Regex.MatchString(req.URL.Path) {
+		return false
+	}
+
+	return true
+}
+
+func (r *Route) Redirect(rw http.ResponseWriter, req *http.Request) bool {
+	if !r.Route.IsRedirect() {
+		return false
+	}
+
+	scheme := "http"
+	if req.TLS != nil {
+		scheme = "https"
+	}
+	host := req.Host
+
+	if r.Route.RedirectHttps {
+		scheme = "https"
+	}
+	if r.Route.RedirectHost != "" {
+		host = r.Route.RedirectHost
+	}
+
+	if host == req.Host && ((scheme == "https") == (req.TLS != nil)) {
+		return false
+	}
+
+	u := &url.URL{
+		Scheme:   scheme,
+		Host:     host,
+		Path:     req.URL.Path,
+		RawPath:  req.URL.RawPath,
+		RawQuery: req.URL.RawQuery,
+	}
+
+	http.Redirect(rw, req, u.String(), r.Route.RedirectCode)
+	return true
+}
+
+func (r *Route) Rewrite(req *http.Request) {
+	if r.Route.Rewrite == "" {
+		return
+	}
+
+	pth := req.URL.Path
+	if r.Regex != nil {
+		pth = r.Regex.ReplaceAllString(pth, r.Route.Rewrite)
+	} else {
+		pth = strings.TrimPrefix(pth, r.Route.Path)
+		if strings.HasSuffix(r.Route.Rewrite, "/") {
+			pth = strings.TrimPrefix(pth, "/")
+		} else if pth != "" && !strings.HasPrefix(pth, "/") {
+			pth = "/" + pth
+		}
+		pth = r.Route.Rewrite + pth
+	}
+
+	if !strings.HasPrefix(pth, "/") {
+		pth = "/" + pth
+	}
+
+	req.URL.Path = pth
+	req.URL.RawPath = ""
+}
+
+func (r *Route) RequestHeaders(req *http.Request) {
+	for _, name := range r.Route.RequestHeadersRemove {
+		req.Header.Del(name)
+	}
+	for _, header := range r.Route.RequestHeaders {
+		req.Header.Set(header.Name, header.Value)
+	}
+}
+
+func (r *Route) ResponseHeaders(resp *http.Response) {
+	for _, name := range r.Route.ResponseHeadersRemove {
+		resp.Header.Del(name)
+	}
+	for _, header := range r.Route.ResponseHeaders {
+		resp.Header.Set(header.Name, header.Value)
+	}
+}
+
+func NewRoutes(domain *Domain) (routes []*Route) {
+	routes = []*Route{}
+
+	for _, rte := range domain.Balancer.Routes {
+		if rte.Domain != "" && rte.Domain != domain.Domain.Domain {
+			continue
+		}
+
+		route := &Route{
+			Route: rte,
+		}
+
+		if rte.Regex != "" {
+			re, err := regexp.Compile(rte.Regex)
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"balancer": domain.Balancer.Name,
+					"domain":   domain.Domain.Domain,
+					"regex":    rte.Regex,
+					"error":    err,
+				}).Error("proxy: Failed to compile balancer route regex")
+				continue
+			}
+			route.Regex = re
+		}
+
+		routes = append(routes, route)
+	}
+
+	return
+}
+
+func getRoute(r *http.Request) *Route {
+	if r == nil {
+		return nil
+	}
+
+	rte, _ := r.Context().Value(routeKey{}).(*Route)
+	return rte
+}
+
+func getGroup(r *http.Request) string {
+	rte := getRoute(r)
+	if rte == nil {
+		return ""
+	}
+	return rte.Route.Group
+}
+
+func selectHandler(handlers []*Handler, group string, all bool) *Handler {
+	total := 0
+	for _, hand := range handlers {
+		if all || hand.Group == group {
+			total += hand.Weight
+		}
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	n := rand.Intn(total)
+	for _, hand := range handlers {
+		if all || hand.Group == group {
+			n -= hand.Weight
+			if n < 0 {
+				return hand
+			}
+		}
+	}
+
+	return nil
+}
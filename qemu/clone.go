@@ -0,0 +1,173 @@
+package qemu
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/data"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/disk"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/instance"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/paths"
+	"github.com/pritunl/pritunl-cloud/pool"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/pritunl/pritunl-cloud/vm"
+	"github.com/sirupsen/logrus"
+)
+
+func cloneDisks(db *database.Database, inst *instance.Instance,
+	virt *vm.VirtualMachine) (err error) {
+
+	srcInst, err := instance.Get(db, inst.CloneSource)
+	if err != nil {
+		return
+	}
+
+	if srcInst.Node != node.Self.Id {
+		err = &errortypes.NotFoundError{
+			errors.New("qemu: Clone source instance not on node"),
+		}
+		return
+	}
+
+	srcDsks, err := disk.GetInstance(db, srcInst.Id)
+	if err != nil {
+		return
+	}
+
+	var srcVirt *vm.VirtualMachine
+	exists, err := utils.Exists(paths.GetUnitPath(srcInst.Id))
+	if err != nil {
+		return
+	}
+	if exists {
+		srcVirt, err = GetVmInfo(db, srcInst.Id, false, true)
+		if err != nil {
+			return
+		}
+	}
+
+	srcs := []*disk.Disk{}
+	dsts := []*disk.Disk{}
+	for _, src := range srcDsks {
+		if src.Index != "0" && !inst.CloneDisks {
+			continue
+		}
+
+		if src.State != disk.Available || src.IsTransfer() {
+			err = &errortypes.ReadError{
+				errors.Newf("qemu: Clone source disk '%s' not available",
+					src.Id.Hex()),
+			}
+			return
+		}
+
+		name := inst.Name
+		if src.Index != "0" {
+			name = fmt.Sprintf("%s-%s", inst.Name, src.Index)
+		}
+
+		dst := &disk.Disk{
+			Id:             bson.NewObjectID(),
+			Name:           name,
+			State:          disk.Available,
+			Type:           src.Type,
+			SystemType:     src.SystemType,
+			SystemKind:     src.SystemKind,
+			Pool:           src.Pool,
+			Node:           node.Self.Id,
+			Organization:   inst.Organization,
+			Instance:       inst.Id,
+			Datacenter:     node.Self.Datacenter,
+			Zone:           node.Self.Zone,
+			SourceInstance: inst.Id,
+			FileSystem:     src.FileSystem,
+			Image:          src.Image,
+			Index:          src.Index,
+			Size:           src.Size,
+		}
+
+		errData, e := dst.Validate(db)
+		if e != nil {
+			err = e
+			return
+		}
+		if errData != nil {
+			err = errData.GetError()
+			return
+		}
+
+		srcs = append(srcs, src)
+		dsts = append(dsts, dst)
+	}
+
+	if len(dsts) == 0 || dsts[0].Index != "0" {
+		err = &errortypes.NotFoundError{
+			errors.New("qemu: Clone source instance missing root disk"),
+		}
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"instance_id":        inst.Id.Hex(),
+		"source_instance_id": srcInst.Id.Hex(),
+		"disks":              len(dsts),
+		"quiesce":            inst.CloneQuiesce,
+	}).Info("qemu: Cloning instance disks")
+
+	err = data.CloneDisks(db, srcVirt, srcs, dsts, inst.CloneQuiesce)
+	if err != nil {
+		return
+	}
+
+	for _, dsk := range dsts {
+		err = dsk.Insert(db)
+		if err != nil {
+			return
+		}
+
+		if dsk.Type == disk.Lvm {
+			pl, e := pool.Get(db, dsk.Pool)
+			if e != nil {
+				err = e
+				return
+			}
+
+			virt.DriveDevices = append(virt.DriveDevices, &vm.DriveDevice{
+				Id:     dsk.Id.Hex(),
+				Type:   vm.Lvm,
+				VgName: pl.VgName,
+				LvName: dsk.Id.Hex(),
+			})
+		} else {
+			index, e := strconv.Atoi(dsk.Index)
+			if e != nil {
+				err = &errortypes.ParseError{
+					errors.Wrap(e, "qemu: Failed to parse disk index"),
+				}
+				return
+			}
+
+			dskPth, e := dsk.GetPath(db)
+			if e != nil {
+				err = e
+				return
+			}
+
+			virt.Disks = append(virt.Disks, &vm.Disk{
+				Id:    dsk.Id,
+				Index: index,
+				Path:  dskPth,
+			})
+		}
+	}
+
+	_ = event.PublishDispatch(db, "disk.change")
+
+	return
+}
@@ -82,6 +82,7 @@ func cleanRun(virt *vm.VirtualMachine) (err error) {
 	sockPath := paths.GetSockPath(virt.Id)
 	qmpSockPath := paths.GetQmpSockPath(virt.Id)
 	guestPath := paths.GetGuestPath(virt.Id)
+	serialPath := paths.GetSerialSockPath(virt.Id)
 
 	err = utils.RemoveAll(runPath)
 	if err != nil {
@@ -108,6 +109,11 @@ func cleanRun(virt *vm.VirtualMachine) (err error) {
 		return
 	}
 
+	err = utils.RemoveAll(serialPath)
+	if err != nil {
+		return
+	}
+
 	return
 }
 
@@ -311,6 +317,8 @@ func Destroy(db *database.Database, virt *vm.VirtualMachine) (err error) {
 		}
 
 		if vrt != nil && vrt.State == vm.Running {
+			preStop(db, virt)
+
 			guestShutdown := true
 			err = guest.Shutdown(virt.Id)
 			if err != nil {
@@ -503,7 +503,12 @@ func Create(db *database.Database, inst *instance.Instance,
 		}
 	}
 
-	if dsk == nil {
+	if dsk == nil && !inst.CloneSource.IsZero() {
+		err = cloneDisks(db, inst, virt)
+		if err != nil {
+			return
+		}
+	} else if dsk == nil {
 		dsk = &disk.Disk{
 			Id:               bson.NewObjectID(),
 			Name:             inst.Name,
@@ -588,10 +593,16 @@ func Create(db *database.Database, inst *instance.Instance,
 				LvName: dsk.Id.Hex(),
 			})
 		} else {
+			dskPth, e := dsk.GetPath(db)
+			if e != nil {
+				err = e
+				return
+			}
+
 			virt.Disks = append(virt.Disks, &vm.Disk{
 				Id:    dsk.Id,
 				Index: 0,
-				Path:  paths.GetDiskPath(dsk.Id),
+				Path:  dskPth,
 			})
 		}
 	}
@@ -235,6 +235,21 @@ func PowerOn(db *database.Database, inst *instance.Instance,
 	return
 }
 
+func preStop(db *database.Database, virt *vm.VirtualMachine) {
+	if virt.ImdsHostSecret == "" || settings.Hypervisor.PreStopTimeout <= 0 {
+		return
+	}
+
+	err := imds.PreStop(db, virt.Id, virt.ImdsHostSecret,
+		time.Duration(settings.Hypervisor.PreStopTimeout)*time.Second)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"instance_id": virt.Id.Hex(),
+			"error":       err,
+		}).Warn("qemu: Failed to run instance pre-stop")
+	}
+}
+
 func PowerOff(db *database.Database, virt *vm.VirtualMachine) (err error) {
 	unitName := paths.GetUnitName(virt.Id)
 
@@ -246,6 +261,8 @@ func PowerOff(db *database.Database, virt *vm.VirtualMachine) (err error) {
 		"id": virt.Id.Hex(),
 	}).Info("qemu: Stopping virtual machine")
 
+	preStop(db, virt)
+
 	guestShutdown := true
 	err = guest.Shutdown(virt.Id)
 	if err != nil {
@@ -731,6 +731,16 @@ func (q *Qemu) Marshal() (output string, err error) {
 		}
 	}
 
+	cmd = append(cmd, "-chardev")
+	cmd = append(cmd, fmt.Sprintf(
+		"socket,id=serial0,path=%s,server=on,wait=off,"+
+			"logfile=%s,logappend=on",
+		paths.GetSerialSockPath(q.Id),
+		paths.GetSerialLogPath(q.Id),
+	))
+	cmd = append(cmd, "-serial")
+	cmd = append(cmd, "chardev:serial0")
+
 	cmd = append(cmd, "-monitor")
 	cmd = append(cmd, fmt.Sprintf(
 		"unix:%s,server=on,wait=off",
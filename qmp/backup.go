@@ -67,6 +67,9 @@ func driveGetDevice(vmId bson.ObjectID, dsk *disk.Disk) (
 	for _, blockDev := range returnData.Return {
 		idStr := strings.Split(path.Base(
 			blockDev.Inserted.Image.Filename), ".")[0]
+		if i := strings.LastIndex(idStr, "-"); i != -1 {
+			idStr = idStr[i+1:]
+		}
 
 		diskId, err := bson.ObjectIDFromHex(idStr)
 		if err != nil {
@@ -169,16 +172,38 @@ func driveBackupCheck(vmId bson.ObjectID, deviceName string) (
 func BackupDisk(vmId bson.ObjectID, dsk *disk.Disk,
 	destPth string) (err error) {
 
+	deviceName, err := StartBackupDisk(vmId, dsk, destPth)
+	if err != nil {
+		return
+	}
+
+	err = WaitBackupDisk(vmId, deviceName)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// StartBackupDisk starts a backup job for the disk, the copy is a point in
+// time image from when the job was started
+func StartBackupDisk(vmId bson.ObjectID, dsk *disk.Disk,
+	destPth string) (deviceName string, err error) {
+
 	logrus.WithFields(logrus.Fields{
 		"instance_id": vmId.Hex(),
 		"disk_id":     dsk.Id.Hex(),
 	}).Info("qmp: Backing up disk")
 
-	deviceName, err := driveBackup(vmId, dsk, destPth)
+	deviceName, err = driveBackup(vmId, dsk, destPth)
 	if err != nil {
 		return
 	}
 
+	return
+}
+
+func WaitBackupDisk(vmId bson.ObjectID, deviceName string) (err error) {
 	for {
 		complete, e := driveBackupCheck(vmId, deviceName)
 		if e != nil {
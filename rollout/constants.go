@@ -0,0 +1,9 @@
+package rollout
+
+import (
+	"time"
+)
+
+const (
+	ReadyDelay = 30 * time.Second
+)
@@ -0,0 +1,536 @@
+package rollout
+
+import (
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/deployment"
+	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/scheduler"
+	"github.com/pritunl/pritunl-cloud/spec"
+	"github.com/pritunl/pritunl-cloud/unit"
+	"github.com/sirupsen/logrus"
+)
+
+type Rollouts struct {
+	changed bool
+}
+
+func (r *Rollouts) syncTargets(unt *unit.Unit,
+	deplysMap map[bson.ObjectID]*deployment.Deployment) {
+
+	rllt := unt.Rollout
+	surge := rllt.Update.Strategy == spec.BlueGreen ||
+		rllt.Update.MaxSurge > 0
+
+	for _, deply := range deplysMap {
+		if surge && deply.Spec == rllt.Spec && !deply.Timestamp.Before(
+			rllt.Timestamp) && rllt.Target(deply.Id) == nil &&
+			!rllt.HasSurge(deply.Id) {
+
+			rllt.Surge = append(rllt.Surge, deply.Id)
+		}
+	}
+
+	for _, target := range rllt.Targets {
+		deply := deplysMap[target.Deployment]
+		if deply == nil || deply.Action == deployment.Destroy ||
+			deply.State == deployment.Archived {
+
+			if target.State != unit.TargetRemoved &&
+				target.State != unit.TargetReverted {
+
+				target.State = unit.TargetRemoved
+				r.changed = true
+			}
+			continue
+		}
+
+		switch target.State {
+		case unit.TargetPending:
+			if deply.Spec == rllt.Spec {
+				target.NewSpec = rllt.Spec
+				target.State = unit.TargetMigrated
+				target.Migrated = time.Now()
+				r.changed = true
+			}
+			break
+		case unit.TargetMigrating:
+			if deply.Action == deployment.Migrate {
+				break
+			}
+
+			if deply.Spec == target.NewSpec {
+				target.State = unit.TargetMigrated
+				if target.Migrated.IsZero() {
+					target.Migrated = time.Now()
+				}
+			} else {
+				target.State = unit.TargetFailed
+			}
+			r.changed = true
+			break
+		case unit.TargetMigrated:
+			if deply.IsHealthy() && time.Since(
+				target.Migrated) >= ReadyDelay {
+
+				target.State = unit.TargetReady
+				r.changed = true
+			} else if !deply.IsHealthy() && time.Since(
+				target.Migrated) > rllt.HealthTimeout() {
+
+				target.State = unit.TargetFailed
+				r.changed = true
+			}
+			break
+		case unit.TargetReverting:
+			if deply.Action == deployment.Migrate {
+				break
+			}
+
+			if deply.Spec == target.PrevSpec {
+				target.State = unit.TargetReverted
+			} else {
+				target.State = unit.TargetFailed
+			}
+			r.changed = true
+			break
+		}
+	}
+
+	rllt.Ready = rllt.CountState(unit.TargetReady)
+}
+
+func (r *Rollouts) surgeReady(unt *unit.Unit,
+	deplysMap map[bson.ObjectID]*deployment.Deployment) (ready int) {
+
+	for _, deplyId := range unt.Rollout.Surge {
+		deply := deplysMap[deplyId]
+		if deply != nil && deply.State == deployment.Deployed &&
+			deply.IsHealthy() {
+
+			ready += 1
+		}
+	}
+
+	return
+}
+
+func (r *Rollouts) schedule(db *database.Database, unt *unit.Unit,
+	count int) (scheduled bool, err error) {
+
+	rllt := unt.Rollout
+
+	if count <= 0 {
+		scheduled = true
+		return
+	}
+
+	errData, err := scheduler.ManualSchedule(db, unt, rllt.Spec, count)
+	if err != nil {
+		return
+	}
+
+	if errData != nil {
+		if errData.Error == "scheduler_active" {
+			return
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"pod":     unt.Pod.Hex(),
+			"unit":    unt.Id.Hex(),
+			"spec":    rllt.Spec.Hex(),
+			"message": errData.Message,
+		}).Warning("rollout: Failed to schedule unit rollout")
+
+		r.fail(unt, errData.Message)
+		r.changed = true
+		return
+	}
+
+	scheduled = true
+	return
+}
+
+func (r *Rollouts) fail(unt *unit.Unit, message string) {
+	rllt := unt.Rollout
+
+	if rllt.Update.Rollback {
+		logrus.WithFields(logrus.Fields{
+			"pod":     unt.Pod.Hex(),
+			"unit":    unt.Id.Hex(),
+			"spec":    rllt.Spec.Hex(),
+			"message": message,
+		}).Warning("rollout: Rolling back unit rollout")
+
+		rllt.State = unit.RolloutRollback
+		rllt.Message = message + ", rolling back"
+		r.changed = true
+	} else if rllt.Message != message+", rollout paused" {
+		rllt.Message = message + ", rollout paused"
+		r.changed = true
+	}
+}
+
+func (r *Rollouts) complete(db *database.Database, unt *unit.Unit,
+	removeIds []bson.ObjectID) (err error) {
+
+	rllt := unt.Rollout
+
+	if len(removeIds) > 0 {
+		err = deployment.RemoveMulti(db, unt.Id, removeIds)
+		if err != nil {
+			return
+		}
+	}
+
+	unt.DeploySpec = rllt.Spec
+	err = unt.CommitFields(db, set.NewSet("deploy_spec"))
+	if err != nil {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"pod":      unt.Pod.Hex(),
+		"unit":     unt.Id.Hex(),
+		"spec":     rllt.Spec.Hex(),
+		"strategy": rllt.Update.Strategy,
+	}).Info("rollout: Unit rollout complete")
+
+	rllt.State = unit.RolloutComplete
+	rllt.Message = "Rollout complete"
+	r.changed = true
+
+	return
+}
+
+func (r *Rollouts) runRolling(db *database.Database, unt *unit.Unit,
+	deplysMap map[bson.ObjectID]*deployment.Deployment) (err error) {
+
+	rllt := unt.Rollout
+
+	if !rllt.Scheduled {
+		rllt.Scheduled, err = r.schedule(db, unt, rllt.Update.MaxSurge)
+		if err != nil {
+			return
+		}
+		if !rllt.Scheduled {
+			return
+		}
+	}
+
+	if rllt.CountState(unit.TargetReady, unit.TargetRemoved) ==
+		len(rllt.Targets) {
+
+		err = r.complete(db, unt, rllt.Surge)
+		if err != nil {
+			return
+		}
+
+		return
+	}
+
+	unavailable := rllt.CountState(unit.TargetMigrating,
+		unit.TargetMigrated)
+	slots := rllt.Update.MaxUnavailable + r.surgeReady(
+		unt, deplysMap) - unavailable
+
+	canary := rllt.CanaryCount()
+	if canary > 0 && rllt.CountState(unit.TargetReady) < canary {
+		started := rllt.CountState(unit.TargetMigrating,
+			unit.TargetMigrated, unit.TargetReady)
+		slots = min(slots, canary-started)
+	}
+
+	deplyIds := []bson.ObjectID{}
+	for _, target := range rllt.Targets {
+		if slots <= 0 {
+			break
+		}
+
+		if target.State != unit.TargetPending {
+			continue
+		}
+
+		deply := deplysMap[target.Deployment]
+		if deply == nil || deply.State != deployment.Deployed ||
+			deply.Action != "" {
+
+			continue
+		}
+
+		target.State = unit.TargetMigrating
+		target.NewSpec = rllt.Spec
+		target.Migrated = time.Time{}
+		deplyIds = append(deplyIds, target.Deployment)
+		slots -= 1
+	}
+
+	if len(deplyIds) == 0 {
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"pod":         unt.Pod.Hex(),
+		"unit":        unt.Id.Hex(),
+		"spec":        rllt.Spec.Hex(),
+		"strategy":    rllt.Update.Strategy,
+		"deployments": len(deplyIds),
+	}).Info("rollout: Migrating unit deployments")
+
+	err = deployment.MigrateMulti(db, unt.Id, rllt.Spec, deplyIds)
+	if err != nil {
+		return
+	}
+
+	rllt.Message = "Migrating deployments"
+	r.changed = true
+
+	return
+}
+
+func (r *Rollouts) runBlueGreen(db *database.Database, unt *unit.Unit,
+	deplysMap map[bson.ObjectID]*deployment.Deployment) (err error) {
+
+	rllt := unt.Rollout
+
+	blueIds := []bson.ObjectID{}
+	for _, target := range rllt.Targets {
+		if target.State == unit.TargetRemoved {
+			continue
+		}
+		blueIds = append(blueIds, target.Deployment)
+	}
+
+	if !rllt.Scheduled {
+		rllt.Scheduled, err = r.schedule(db, unt, len(blueIds))
+		if err != nil {
+			return
+		}
+		if !rllt.Scheduled {
+			return
+		}
+
+		rllt.Message = "Deploying green deployments"
+		r.changed = true
+		return
+	}
+
+	greenReady := r.surgeReady(unt, deplysMap)
+	if greenReady >= len(blueIds) {
+		logrus.WithFields(logrus.Fields{
+			"pod":   unt.Pod.Hex(),
+			"unit":  unt.Id.Hex(),
+			"spec":  rllt.Spec.Hex(),
+			"green": greenReady,
+		}).Info("rollout: Switching unit to green deployments")
+
+		for _, target := range rllt.Targets {
+			target.State = unit.TargetRemoved
+		}
+
+		err = r.complete(db, unt, blueIds)
+		if err != nil {
+			return
+		}
+
+		return
+	}
+
+	if time.Since(rllt.Timestamp) > rllt.HealthTimeout() {
+		r.fail(unt, "Green deployments failed health check")
+	}
+
+	return
+}
+
+func (r *Rollouts) rollback(db *database.Database, unt *unit.Unit,
+	deplysMap map[bson.ObjectID]*deployment.Deployment) (err error) {
+
+	rllt := unt.Rollout
+
+	surgeIds := []bson.ObjectID{}
+	for _, deplyId := range rllt.Surge {
+		deply := deplysMap[deplyId]
+		if deply != nil && deply.Action != deployment.Destroy {
+			surgeIds = append(surgeIds, deplyId)
+		}
+	}
+
+	if len(surgeIds) > 0 {
+		err = deployment.RemoveMulti(db, unt.Id, surgeIds)
+		if err != nil {
+			return
+		}
+	}
+
+	revertIds := map[bson.ObjectID][]bson.ObjectID{}
+	remaining := 0
+	for _, target := range rllt.Targets {
+		deply := deplysMap[target.Deployment]
+
+		switch target.State {
+		case unit.TargetRemoved, unit.TargetReverted:
+			continue
+		case unit.TargetReverting:
+			remaining += 1
+			continue
+		}
+
+		if deply == nil {
+			continue
+		}
+
+		if deply.Action == deployment.Migrate {
+			remaining += 1
+			continue
+		}
+
+		if deply.Spec == target.PrevSpec {
+			target.State = unit.TargetReverted
+			r.changed = true
+			continue
+		}
+
+		target.State = unit.TargetReverting
+		target.NewSpec = target.PrevSpec
+		revertIds[target.PrevSpec] = append(
+			revertIds[target.PrevSpec], target.Deployment)
+		remaining += 1
+		r.changed = true
+	}
+
+	for specId, deplyIds := range revertIds {
+		logrus.WithFields(logrus.Fields{
+			"pod":         unt.Pod.Hex(),
+			"unit":        unt.Id.Hex(),
+			"spec":        specId.Hex(),
+			"deployments": len(deplyIds),
+		}).Info("rollout: Reverting unit deployments")
+
+		err = deployment.MigrateMulti(db, unt.Id, specId, deplyIds)
+		if err != nil {
+			return
+		}
+	}
+
+	if remaining == 0 {
+		logrus.WithFields(logrus.Fields{
+			"pod":  unt.Pod.Hex(),
+			"unit": unt.Id.Hex(),
+			"spec": rllt.Spec.Hex(),
+		}).Info("rollout: Unit rollout rolled back")
+
+		rllt.State = unit.RolloutRolledBack
+		rllt.Message = "Rollout rolled back"
+		r.changed = true
+	}
+
+	return
+}
+
+func (r *Rollouts) process(db *database.Database, unt *unit.Unit) (
+	err error) {
+
+	rllt := unt.Rollout
+	if rllt.Update == nil {
+		rllt.Update = &spec.Update{
+			Strategy:       spec.Rolling,
+			MaxUnavailable: 1,
+		}
+	}
+
+	deplys, err := deployment.GetAll(db, &bson.M{
+		"unit": unt.Id,
+	})
+	if err != nil {
+		return
+	}
+
+	deplysMap := map[bson.ObjectID]*deployment.Deployment{}
+	for _, deply := range deplys {
+		deplysMap[deply.Id] = deply
+	}
+
+	snapshot := rllt.Snapshot()
+
+	r.syncTargets(unt, deplysMap)
+
+	if rllt.State == unit.RolloutRunning {
+		if rllt.CountState(unit.TargetFailed) > 0 {
+			r.fail(unt, "Deployment failed health check")
+		}
+	}
+
+	switch rllt.State {
+	case unit.RolloutRunning:
+		if rllt.CountState(unit.TargetFailed) > 0 {
+			break
+		}
+
+		if rllt.Update.Strategy == spec.BlueGreen {
+			err = r.runBlueGreen(db, unt, deplysMap)
+		} else {
+			err = r.runRolling(db, unt, deplysMap)
+		}
+		if err != nil {
+			return
+		}
+		break
+	case unit.RolloutRollback:
+		err = r.rollback(db, unt, deplysMap)
+		if err != nil {
+			return
+		}
+		break
+	}
+
+	rllt.Modified = time.Now()
+	updated, err := unit.CommitRollout(db, unt.Id, rllt, snapshot)
+	if err != nil {
+		return
+	}
+
+	if !updated {
+		logrus.WithFields(logrus.Fields{
+			"pod":  unt.Pod.Hex(),
+			"unit": unt.Id.Hex(),
+		}).Info("rollout: Unit rollout targets changed, retrying")
+	}
+
+	return
+}
+
+func (r *Rollouts) Apply(db *database.Database) (err error) {
+	units, err := unit.GetAll(db, &bson.M{
+		"rollout.state": &bson.M{
+			"$in": []string{
+				unit.RolloutRunning,
+				unit.RolloutRollback,
+			},
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	for _, unt := range units {
+		e := r.process(db, unt)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"pod":   unt.Pod.Hex(),
+				"unit":  unt.Id.Hex(),
+				"error": e,
+			}).Error("rollout: Failed to process unit rollout")
+		}
+	}
+
+	if r.changed {
+		event.PublishDispatch(db, "pod.change")
+		event.PublishDispatch(db, "unit.change")
+	}
+
+	return
+}
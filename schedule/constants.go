@@ -0,0 +1,40 @@
+package schedule
+
+import (
+	"github.com/dropbox/godropbox/container/set"
+)
+
+const (
+	Start = "start"
+	Stop  = "stop"
+)
+
+var (
+	ValidActions = set.NewSet(
+		Start,
+		Stop,
+	)
+	monthNames = map[string]int{
+		"jan": 1,
+		"feb": 2,
+		"mar": 3,
+		"apr": 4,
+		"may": 5,
+		"jun": 6,
+		"jul": 7,
+		"aug": 8,
+		"sep": 9,
+		"oct": 10,
+		"nov": 11,
+		"dec": 12,
+	}
+	dayNames = map[string]int{
+		"sun": 0,
+		"mon": 1,
+		"tue": 2,
+		"wed": 3,
+		"thu": 4,
+		"fri": 5,
+		"sat": 6,
+	}
+)
@@ -0,0 +1,173 @@
+package schedule
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+)
+
+// Cron is a parsed five field cron expression in the form
+// "minute hour day-of-month month day-of-week"
+type Cron struct {
+	minutes     uint64
+	hours       uint64
+	days        uint64
+	months      uint64
+	weekdays    uint64
+	daysAny     bool
+	weekdaysAny bool
+}
+
+func parseValue(val string, names map[string]int) (n int, err error) {
+	if names != nil {
+		if nameVal, ok := names[strings.ToLower(val)]; ok {
+			n = nameVal
+			return
+		}
+	}
+
+	n, err = strconv.Atoi(val)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrapf(err, "schedule: Invalid cron value '%s'", val),
+		}
+		return
+	}
+
+	return
+}
+
+func parseField(field string, min, max int, names map[string]int) (
+	bits uint64, any bool, err error) {
+
+	any = strings.HasPrefix(field, "*")
+
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		hasStep := false
+
+		if i := strings.Index(part, "/"); i != -1 {
+			step, err = strconv.Atoi(part[i+1:])
+			if err != nil || step < 1 {
+				err = &errortypes.ParseError{
+					errors.Newf("schedule: Invalid cron step '%s'", part),
+				}
+				return
+			}
+			part = part[:i]
+			hasStep = true
+		}
+
+		lo := 0
+		hi := 0
+		if part == "*" {
+			lo = min
+			hi = max
+		} else if i := strings.Index(part, "-"); i != -1 {
+			lo, err = parseValue(part[:i], names)
+			if err != nil {
+				return
+			}
+
+			hi, err = parseValue(part[i+1:], names)
+			if err != nil {
+				return
+			}
+		} else {
+			lo, err = parseValue(part, names)
+			if err != nil {
+				return
+			}
+
+			hi = lo
+			if hasStep {
+				hi = max
+			}
+		}
+
+		if lo < min || hi > max || lo > hi {
+			err = &errortypes.ParseError{
+				errors.Newf("schedule: Cron value out of range '%s'", field),
+			}
+			return
+		}
+
+		for n := lo; n <= hi; n += step {
+			bits |= 1 << uint(n)
+		}
+	}
+
+	return
+}
+
+func ParseCron(expr string) (cron *Cron, err error) {
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		err = &errortypes.ParseError{
+			errors.Newf("schedule: Cron expression requires 5 fields '%s'",
+				expr),
+		}
+		return
+	}
+
+	cron = &Cron{}
+
+	cron.minutes, _, err = parseField(fields[0], 0, 59, nil)
+	if err != nil {
+		return
+	}
+
+	cron.hours, _, err = parseField(fields[1], 0, 23, nil)
+	if err != nil {
+		return
+	}
+
+	cron.days, cron.daysAny, err = parseField(fields[2], 1, 31, nil)
+	if err != nil {
+		return
+	}
+
+	cron.months, _, err = parseField(fields[3], 1, 12, monthNames)
+	if err != nil {
+		return
+	}
+
+	cron.weekdays, cron.weekdaysAny, err = parseField(
+		fields[4], 0, 7, dayNames)
+	if err != nil {
+		return
+	}
+
+	if cron.weekdays&(1<<7) != 0 {
+		cron.weekdays |= 1
+	}
+
+	return
+}
+
+// Match returns true if the minute of the time matches the expression,
+// the day of month and day of week match either when both are restricted
+func (c *Cron) Match(t time.Time) bool {
+	if c.minutes&(1<<uint(t.Minute())) == 0 ||
+		c.hours&(1<<uint(t.Hour())) == 0 ||
+		c.months&(1<<uint(t.Month())) == 0 {
+
+		return false
+	}
+
+	dayMatch := c.days&(1<<uint(t.Day())) != 0
+	weekdayMatch := c.weekdays&(1<<uint(t.Weekday())) != 0
+
+	if c.daysAny && c.weekdaysAny {
+		return true
+	} else if c.daysAny {
+		return weekdayMatch
+	} else if c.weekdaysAny {
+		return dayMatch
+	}
+
+	return dayMatch || weekdayMatch
+}
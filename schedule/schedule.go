@@ -0,0 +1,104 @@
+package schedule
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-cloud/errortypes"
+)
+
+type Schedule struct {
+	Action   string `bson:"action" json:"action"`
+	Cron     string `bson:"cron" json:"cron"`
+	Timezone string `bson:"timezone" json:"timezone"`
+}
+
+func (s *Schedule) Validate() (errData *errortypes.ErrorData, err error) {
+	if !ValidActions.Contains(s.Action) {
+		errData = &errortypes.ErrorData{
+			Error:   "invalid_schedule_action",
+			Message: "Power schedule action must be start or stop",
+		}
+		return
+	}
+
+	_, e := ParseCron(s.Cron)
+	if e != nil {
+		errData = &errortypes.ErrorData{
+			Error:   "invalid_schedule_cron",
+			Message: "Power schedule cron expression is invalid",
+		}
+		return
+	}
+
+	if s.Timezone == "" {
+		s.Timezone = "UTC"
+	}
+
+	_, e = time.LoadLocation(s.Timezone)
+	if e != nil {
+		errData = &errortypes.ErrorData{
+			Error:   "invalid_schedule_timezone",
+			Message: "Power schedule timezone is invalid",
+		}
+		return
+	}
+
+	return
+}
+
+// Match returns true if the schedule is due in the minute of the time
+// in the schedule timezone
+func (s *Schedule) Match(t time.Time) (match bool, err error) {
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return
+	}
+
+	loc, e := time.LoadLocation(s.Timezone)
+	if e != nil {
+		loc = time.UTC
+	}
+
+	match = cron.Match(t.In(loc))
+	return
+}
+
+// Last returns the most recent minute after the start time and up to the
+// end time that the schedule is due or a zero time if none are due
+func (s *Schedule) Last(start, end time.Time) (last time.Time, err error) {
+	cron, err := ParseCron(s.Cron)
+	if err != nil {
+		return
+	}
+
+	loc, e := time.LoadLocation(s.Timezone)
+	if e != nil {
+		loc = time.UTC
+	}
+
+	start = start.Truncate(time.Minute)
+	t := end.Truncate(time.Minute)
+	for t.After(start) {
+		if cron.Match(t.In(loc)) {
+			last = t
+			return
+		}
+		t = t.Add(-time.Minute)
+	}
+
+	return
+}
+
+func Equal(a, b []*Schedule) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if *a[i] != *b[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -45,7 +45,7 @@ func (u *InstanceUnit) Schedule(db *database.Database, count int) (err error) {
 
 	overrideCount := 0
 	if count == 0 {
-		u.count = u.unit.Count - len(u.unit.Deployments)
+		u.count = u.unit.GetCount() - len(u.unit.Deployments)
 	} else {
 		u.count = count
 		overrideCount = len(u.unit.Deployments) + count
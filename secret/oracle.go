@@ -89,7 +89,7 @@ func NewOracleProvider(secr *Secret) (prov *OracleProvider, err error) {
 	prov = &OracleProvider{
 		privateKey:  privateKey,
 		tenancy:     secr.Key,
-		user:        secr.Value,
+		user:        string(secr.Value),
 		fingerprint: fingerprint,
 		region:      secr.Region,
 		compartment: secr.Key,
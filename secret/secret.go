@@ -7,22 +7,23 @@ import (
 	"github.com/dropbox/godropbox/errors"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/envelope"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/utils"
 )
 
 type Secret struct {
-	Id           bson.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name         string        `bson:"name" json:"name"`
-	Comment      string        `bson:"comment" json:"comment"`
-	Organization bson.ObjectID `bson:"organization" json:"organization"`
-	Type         string        `bson:"type" json:"type"`
-	Key          string        `bson:"key" json:"key"`
-	Value        string        `bson:"value" json:"value"`
-	Region       string        `bson:"region" json:"region"`
-	PublicKey    string        `bson:"public_key" json:"public_key"`
-	Data         string        `bson:"data" json:"data"`
-	PrivateKey   string        `bson:"private_key" json:"-"`
+	Id           bson.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name         string          `bson:"name" json:"name"`
+	Comment      string          `bson:"comment" json:"comment"`
+	Organization bson.ObjectID   `bson:"organization" json:"organization"`
+	Type         string          `bson:"type" json:"type"`
+	Key          string          `bson:"key" json:"key"`
+	Value        envelope.String `bson:"value" json:"value"`
+	Region       string          `bson:"region" json:"region"`
+	PublicKey    string          `bson:"public_key" json:"public_key"`
+	Data         string          `bson:"data" json:"data"`
+	PrivateKey   envelope.String `bson:"private_key" json:"-"`
 }
 
 type Completion struct {
@@ -88,7 +89,7 @@ func (c *Secret) Validate(db *database.Database) (
 		}
 
 		c.PublicKey = strings.TrimSpace(string(pubKey))
-		c.PrivateKey = strings.TrimSpace(string(privKey))
+		c.PrivateKey = envelope.String(strings.TrimSpace(string(privKey)))
 	}
 
 	return
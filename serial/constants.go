@@ -0,0 +1,13 @@
+package serial
+
+import (
+	"time"
+)
+
+const (
+	authTimeout = 10 * time.Second
+	dialTimeout = 10 * time.Second
+	authOk      = "ok"
+
+	hostCheckInterval = 30 * time.Second
+)
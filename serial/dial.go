@@ -0,0 +1,96 @@
+package serial
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/settings"
+)
+
+func readLine(conn net.Conn) (line string, err error) {
+	buf := make([]byte, 1)
+	for len(line) < 128 {
+		_, err = conn.Read(buf)
+		if err != nil {
+			return
+		}
+		if buf[0] == '\n' {
+			return
+		}
+		line += string(buf)
+	}
+
+	err = errors.New("serial: Relay response too long")
+	return
+}
+
+// Dial connects to the serial relay on the instance node
+func Dial(host string, instId bson.ObjectID, passwd string) (
+	conn net.Conn, err error) {
+
+	addr := net.JoinHostPort(host,
+		fmt.Sprintf("%d", settings.Hypervisor.SerialPort))
+
+	conn, err = net.DialTimeout("tcp", addr, dialTimeout)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "serial: Failed to connect to relay"),
+		}
+		return
+	}
+
+	_ = conn.SetDeadline(time.Now().Add(authTimeout))
+
+	_, err = conn.Write([]byte(instId.Hex() + "\n"))
+	if err != nil {
+		conn.Close()
+		conn = nil
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "serial: Failed to write relay auth"),
+		}
+		return
+	}
+
+	nonce, err := readLine(conn)
+	if err != nil {
+		conn.Close()
+		conn = nil
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "serial: Failed to read relay challenge"),
+		}
+		return
+	}
+
+	_, err = conn.Write([]byte(authResponse(
+		instId, passwd, strings.TrimSpace(nonce)) + "\n"))
+	if err != nil {
+		conn.Close()
+		conn = nil
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "serial: Failed to write relay auth"),
+		}
+		return
+	}
+
+	line, err := readLine(conn)
+	if err != nil || strings.TrimSpace(line) != authOk {
+		conn.Close()
+		conn = nil
+		if err == nil {
+			err = errors.New("serial: Relay rejected connection")
+		}
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "serial: Relay authentication failed"),
+		}
+		return
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	return
+}
@@ -0,0 +1,48 @@
+package serial
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+)
+
+type Log struct {
+	Id        bson.ObjectID `bson:"_id" json:"id"`
+	Node      bson.ObjectID `bson:"node" json:"node"`
+	Data      string        `bson:"data" json:"data"`
+	Timestamp time.Time     `bson:"timestamp" json:"timestamp"`
+}
+
+func (l *Log) Commit(db *database.Database) (err error) {
+	coll := db.SerialLogs()
+
+	err = coll.Upsert(&bson.M{
+		"_id": l.Id,
+	}, l)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetLog(db *database.Database, instId bson.ObjectID) (
+	lg *Log, err error) {
+
+	coll := db.SerialLogs()
+	lg = &Log{}
+
+	err = coll.FindOneId(instId, lg)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			lg = &Log{
+				Id: instId,
+			}
+			err = nil
+		}
+		return
+	}
+
+	return
+}
@@ -0,0 +1,225 @@
+package serial
+
+import (
+	"bufio"
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/paths"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
+)
+
+type relayInstance struct {
+	Id             bson.ObjectID `bson:"_id"`
+	Node           bson.ObjectID `bson:"node"`
+	SerialPassword string        `bson:"serial_password"`
+}
+
+func authorize(instId bson.ObjectID, nonce, resp string) (
+	valid bool, err error) {
+
+	db := database.GetDatabase()
+	defer db.Close()
+
+	inst := &relayInstance{}
+	err = db.Instances().FindOneId(instId, inst)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+		}
+		return
+	}
+
+	if inst.Node != node.Self.Id || inst.SerialPassword == "" {
+		return
+	}
+
+	valid = hmac.Equal(
+		[]byte(authResponse(instId, inst.SerialPassword, nonce)),
+		[]byte(resp),
+	)
+	return
+}
+
+func readAuth(reader *bufio.Reader) (line string, err error) {
+	line, err = reader.ReadString('\n')
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "serial: Failed to read relay auth"),
+		}
+		return
+	}
+
+	line = strings.TrimSpace(line)
+	return
+}
+
+func handle(conn net.Conn) (err error) {
+	defer conn.Close()
+
+	_ = conn.SetDeadline(time.Now().Add(authTimeout))
+
+	reader := bufio.NewReader(conn)
+	line, err := readAuth(reader)
+	if err != nil {
+		return
+	}
+
+	instId, err := bson.ObjectIDFromHex(line)
+	if err != nil {
+		err = &errortypes.AuthenticationError{
+			errors.Wrap(err, "serial: Invalid relay instance"),
+		}
+		return
+	}
+
+	nonce, err := utils.RandStr(32)
+	if err != nil {
+		return
+	}
+
+	_, err = conn.Write([]byte(nonce + "\n"))
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "serial: Failed to write relay challenge"),
+		}
+		return
+	}
+
+	resp, err := readAuth(reader)
+	if err != nil {
+		return
+	}
+
+	valid, err := authorize(instId, nonce, resp)
+	if err != nil {
+		return
+	}
+
+	if !valid {
+		err = &errortypes.AuthenticationError{
+			errors.New("serial: Relay authentication failed"),
+		}
+		return
+	}
+
+	sockConn, err := net.DialTimeout("unix",
+		paths.GetSerialSockPath(instId), dialTimeout)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "serial: Failed to connect serial socket"),
+		}
+		return
+	}
+	defer sockConn.Close()
+
+	_, err = conn.Write([]byte(authOk + "\n"))
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "serial: Failed to write relay auth"),
+		}
+		return
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+
+	wait := make(chan bool, 2)
+	go func() {
+		_, _ = io.Copy(sockConn, reader)
+		wait <- true
+	}()
+	go func() {
+		_, _ = io.Copy(conn, sockConn)
+		wait <- true
+	}()
+	<-wait
+
+	return
+}
+
+func accept(listener net.Listener, done chan bool) {
+	for {
+		conn, e := listener.Accept()
+		if e != nil {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			if constants.Shutdown {
+				return
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"error": e,
+			}).Error("serial: Relay accept error")
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		go func() {
+			e := handle(conn)
+			if e != nil {
+				logrus.WithFields(logrus.Fields{
+					"remote": conn.RemoteAddr().String(),
+					"error":  e,
+				}).Warn("serial: Relay connection error")
+			}
+		}()
+	}
+}
+
+// Serve runs the relay between remote console sessions and the local
+// instance serial sockets, the relay listens on the loopback and node
+// private address and returns when the private address changes
+func Serve() (err error) {
+	hosts := []string{"127.0.0.1"}
+	privHost := getPrivateHost(node.Self)
+	if privHost != "" && privHost != "127.0.0.1" {
+		hosts = append(hosts, privHost)
+	}
+
+	done := make(chan bool)
+	listeners := []net.Listener{}
+	defer func() {
+		close(done)
+		for _, listener := range listeners {
+			listener.Close()
+		}
+	}()
+
+	for _, host := range hosts {
+		listener, e := net.Listen("tcp", net.JoinHostPort(host,
+			fmt.Sprintf("%d", settings.Hypervisor.SerialPort)))
+		if e != nil {
+			err = &errortypes.RequestError{
+				errors.Wrap(e, "serial: Failed to listen on relay port"),
+			}
+			return
+		}
+		listeners = append(listeners, listener)
+
+		go accept(listener, done)
+	}
+
+	for {
+		time.Sleep(hostCheckInterval)
+
+		if constants.Shutdown || getPrivateHost(node.Self) != privHost {
+			return
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package serial
+
+import (
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/paths"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	offsets = map[bson.ObjectID]int64{}
+)
+
+func getLogSize() int64 {
+	size := int64(settings.Hypervisor.SerialLogSize) * 1024
+	if size <= 0 {
+		size = 64 * 1024
+	}
+	return size
+}
+
+func readLog(pth string, offset, size int64) (data []byte, err error) {
+	file, err := os.Open(pth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "serial: Failed to open serial log"),
+		}
+		return
+	}
+	defer file.Close()
+
+	_, err = file.Seek(offset, io.SeekStart)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "serial: Failed to seek serial log"),
+		}
+		return
+	}
+
+	data, err = io.ReadAll(io.LimitReader(file, size))
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "serial: Failed to read serial log"),
+		}
+		return
+	}
+
+	return
+}
+
+func syncLog(db *database.Database, instId bson.ObjectID,
+	size int64) (err error) {
+
+	logSize := getLogSize()
+	offset, ok := offsets[instId]
+	if ok && offset == size {
+		return
+	}
+
+	replace := false
+	if !ok || size < offset {
+		replace = true
+		offset = size - logSize
+		if offset < 0 {
+			offset = 0
+		}
+	} else if size-offset > logSize {
+		replace = true
+		offset = size - logSize
+	}
+
+	data, err := readLog(paths.GetSerialLogPath(instId), offset, logSize)
+	if err != nil {
+		return
+	}
+
+	lg := &Log{
+		Id: instId,
+	}
+	if !replace {
+		lg, err = GetLog(db, instId)
+		if err != nil {
+			return
+		}
+	}
+
+	output := lg.Data + string(data)
+	if int64(len(output)) > logSize {
+		output = output[int64(len(output))-logSize:]
+	}
+
+	lg.Node = node.Self.Id
+	lg.Data = output
+	lg.Timestamp = time.Now()
+
+	err = lg.Commit(db)
+	if err != nil {
+		return
+	}
+
+	offset += int64(len(data))
+	if offset > logSize*8 {
+		err = os.Truncate(paths.GetSerialLogPath(instId), 0)
+		if err != nil {
+			err = &errortypes.WriteError{
+				errors.Wrap(err, "serial: Failed to truncate serial log"),
+			}
+			return
+		}
+		offset = 0
+	}
+	offsets[instId] = offset
+
+	return
+}
+
+// SyncLogs persists the tail of each local serial log, must only be called
+// from a single routine
+func SyncLogs(db *database.Database) (err error) {
+	instsPath := path.Join(node.Self.GetVirtPath(), "instances")
+
+	items, err := os.ReadDir(instsPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			err = nil
+			return
+		}
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "serial: Failed to read instances directory"),
+		}
+		return
+	}
+
+	found := map[bson.ObjectID]bool{}
+	for _, item := range items {
+		instId, e := bson.ObjectIDFromHex(item.Name())
+		if e != nil {
+			continue
+		}
+
+		info, e := os.Stat(paths.GetSerialLogPath(instId))
+		if e != nil {
+			continue
+		}
+		found[instId] = true
+
+		e = syncLog(db, instId, info.Size())
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"instance": instId.Hex(),
+				"error":    e,
+			}).Error("serial: Failed to sync serial log")
+		}
+	}
+
+	for instId := range offsets {
+		if !found[instId] {
+			delete(offsets, instId)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,45 @@
+package serial
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/node"
+)
+
+// GetHost returns the internal address of the relay on the node, the
+// relay is not available on public addresses
+func GetHost(nde *node.Node) (host string) {
+	if nde.Id == node.Self.Id {
+		host = "127.0.0.1"
+		return
+	}
+
+	return getPrivateHost(nde)
+}
+
+func getPrivateHost(nde *node.Node) (host string) {
+	host = nde.PrivateIps[nde.DefaultInterface]
+	if host != "" {
+		return
+	}
+
+	for _, privIp := range nde.PrivateIps {
+		if privIp != "" {
+			host = privIp
+			return
+		}
+	}
+
+	return
+}
+
+// authResponse returns the response to the relay challenge, the serial
+// password is never sent to the relay
+func authResponse(instId bson.ObjectID, passwd, nonce string) string {
+	hash := hmac.New(sha256.New, []byte(passwd))
+	hash.Write([]byte(instId.Hex() + "&" + nonce))
+	return hex.EncodeToString(hash.Sum(nil))
+}
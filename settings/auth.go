@@ -3,6 +3,7 @@ package settings
 import (
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/envelope"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/utils"
 )
@@ -26,24 +27,24 @@ const (
 )
 
 type Provider struct {
-	Id              bson.ObjectID `bson:"id" json:"id"`
-	Type            string        `bson:"type" json:"type"`
-	Label           string        `bson:"label" json:"label"`
-	DefaultRoles    []string      `bson:"default_roles" json:"default_roles"`
-	AutoCreate      bool          `bson:"auto_create" json:"auto_create"`
-	RoleManagement  string        `bson:"role_management" json:"role_management"`
-	Region          string        `bson:"region" json:"region"`                     // azure
-	Tenant          string        `bson:"tenant" json:"tenant"`                     // azure
-	ClientId        string        `bson:"client_id" json:"client_id"`               // azure + authzero
-	ClientSecret    string        `bson:"client_secret" json:"client_secret"`       // azure + authzero
-	Domain          string        `bson:"domain" json:"domain"`                     // google + authzero
-	GoogleKey       string        `bson:"google_key" json:"google_key"`             // google
-	GoogleEmail     string        `bson:"google_email" json:"google_email"`         // google
-	JumpCloudAppId  string        `bson:"jumpcloud_app_id" json:"jumpcloud_app_id"` // jumpcloud
-	JumpCloudSecret string        `bson:"jumpcloud_secret" json:"jumpcloud_secret"` // jumpcloud
-	IssuerUrl       string        `bson:"issuer_url" json:"issuer_url"`             // saml
-	SamlUrl         string        `bson:"saml_url" json:"saml_url"`                 // saml
-	SamlCert        string        `bson:"saml_cert" json:"saml_cert"`               // saml
+	Id              bson.ObjectID   `bson:"id" json:"id"`
+	Type            string          `bson:"type" json:"type"`
+	Label           string          `bson:"label" json:"label"`
+	DefaultRoles    []string        `bson:"default_roles" json:"default_roles"`
+	AutoCreate      bool            `bson:"auto_create" json:"auto_create"`
+	RoleManagement  string          `bson:"role_management" json:"role_management"`
+	Region          string          `bson:"region" json:"region"`                     // azure
+	Tenant          string          `bson:"tenant" json:"tenant"`                     // azure
+	ClientId        string          `bson:"client_id" json:"client_id"`               // azure + authzero
+	ClientSecret    envelope.String `bson:"client_secret" json:"client_secret"`       // azure + authzero
+	Domain          string          `bson:"domain" json:"domain"`                     // google + authzero
+	GoogleKey       string          `bson:"google_key" json:"google_key"`             // google
+	GoogleEmail     string          `bson:"google_email" json:"google_email"`         // google
+	JumpCloudAppId  string          `bson:"jumpcloud_app_id" json:"jumpcloud_app_id"` // jumpcloud
+	JumpCloudSecret string          `bson:"jumpcloud_secret" json:"jumpcloud_secret"` // jumpcloud
+	IssuerUrl       string          `bson:"issuer_url" json:"issuer_url"`             // saml
+	SamlUrl         string          `bson:"saml_url" json:"saml_url"`                 // saml
+	SamlCert        string          `bson:"saml_cert" json:"saml_cert"`               // saml
 }
 
 func (p *Provider) Validate(db *database.Database) (
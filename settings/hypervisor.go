@@ -48,6 +48,7 @@ type hypervisor struct {
 	AddressRefreshTtl      int    `bson:"address_refresh_ttl" default:"1800"`
 	StartTimeout           int    `bson:"start_timeout" default:"45"`
 	StopTimeout            int    `bson:"stop_timeout" default:"180"`
+	PreStopTimeout         int    `bson:"pre_stop_timeout" default:"60"`
 	RefreshRate            int    `bson:"refresh_rate" default:"90"`
 	SplashTime             int    `bson:"splash_time" default:"60"`
 	DhcpRenewTtl           int    `bson:"dhcp_renew_ttl" default:"60"`
@@ -71,6 +72,14 @@ type hypervisor struct {
 	DnsServerSecondary6    string `bson:"dns_server_secondary6" default:"2001:4860:4860::8844"`
 	NodePortMaxAttempts    int    `bson:"node_port_max_attempts" default:"10000"`
 	MaxDeploymentFailures  int    `bson:"max_deployment_failures" default:"3"`
+	SerialPort             int    `bson:"serial_port" default:"9790"`
+	SerialLogSize          int    `bson:"serial_log_size" default:"64"`
+	SerialLogSync          int    `bson:"serial_log_sync" default:"15"`
+	ImageCacheMax          int    `bson:"image_cache_max" default:"100"`
+	ImageCachePort         int    `bson:"image_cache_port" default:"9791"`
+	NoImageCachePeers      bool   `bson:"no_image_cache_peers"`
+	NoImageCachePrefetch   bool   `bson:"no_image_cache_prefetch"`
+	DiskTransferPort       int    `bson:"disk_transfer_port" default:"9792"`
 }
 
 func newHypervisor() interface{} {
@@ -20,6 +20,9 @@ type router struct {
 	SkipVerify           bool   `bson:"skip_verify"`
 	ProxyResolverRefresh int    `bson:"proxy_resolver_refresh" default:"30"`
 	ProxyResolverTtl     int    `bson:"proxy_resolver_ttl" default:"30"`
+	AccessLogBuffer      int    `bson:"access_log_buffer" default:"10000"`
+	AccessLogBatch       int    `bson:"access_log_batch" default:"500"`
+	RateLimitExpire      int    `bson:"rate_limit_expire" default:"300"`
 }
 
 func newRouter() interface{} {
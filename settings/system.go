@@ -29,6 +29,8 @@ type system struct {
 	TwilioAccount        string `bson:"twilio_account"`
 	TwilioSecret         string `bson:"twilio_secret"`
 	TwilioNumber         string `bson:"twilio_number"`
+	ImageCatalogPath     string `bson:"image_catalog_path"`
+	ImageCatalogKeyring  string `bson:"image_catalog_keyring"`
 }
 
 func newSystem() interface{} {
@@ -3,18 +3,27 @@ package settings
 var Telemetry *telemetry
 
 type telemetry struct {
-	Id               string `bson:"_id"`
-	CveSource        string `bson:"cve_source" default:"redhat"`
-	NvdTtl           int    `bson:"nvd_ttl" default:"21600"`
-	NvdFinalTtl      int    `bson:"nvd_final_ttl" default:"604800"`
-	NvdApiLimit      int    `bson:"nvd_api_limit" default:"8"`
-	NvdApiAuthLimit  int    `bson:"nvd_api_auth_limit" default:"1"`
-	NvdApiKey        string `bson:"nvd_api_key"`
-	RedhatTtl        int    `bson:"redhat_ttl" default:"21600"`
-	RedhatFinalTtl   int    `bson:"redhat_final_ttl" default:"604800"`
-	RedhatApiLimit   int    `bson:"redhat_api_limit" default:"1"`
-	DescriptionLimit int    `bson:"description_limit" default:"10000"`
-	VuxmlSizeLimit   int    `bson:"vuxml_size_limit" default:"67108864"`
+	Id                     string `bson:"_id"`
+	CveSource              string `bson:"cve_source" default:"redhat"`
+	Offline                bool   `bson:"offline"`
+	NvdTtl                 int    `bson:"nvd_ttl" default:"21600"`
+	NvdFinalTtl            int    `bson:"nvd_final_ttl" default:"604800"`
+	NvdApiLimit            int    `bson:"nvd_api_limit" default:"8"`
+	NvdApiAuthLimit        int    `bson:"nvd_api_auth_limit" default:"1"`
+	NvdApiKey              string `bson:"nvd_api_key"`
+	RedhatTtl              int    `bson:"redhat_ttl" default:"21600"`
+	RedhatFinalTtl         int    `bson:"redhat_final_ttl" default:"604800"`
+	RedhatApiLimit         int    `bson:"redhat_api_limit" default:"1"`
+	UbuntuTtl              int    `bson:"ubuntu_ttl" default:"21600"`
+	UbuntuFinalTtl         int    `bson:"ubuntu_final_ttl" default:"604800"`
+	UbuntuApiLimit         int    `bson:"ubuntu_api_limit" default:"1"`
+	DescriptionLimit       int    `bson:"description_limit" default:"10000"`
+	VuxmlSizeLimit         int    `bson:"vuxml_size_limit" default:"67108864"`
+	DebianTrackerSizeLimit int    `bson:"debian_tracker_size_limit" default:"268435456"`
+	DebianTrackerTtl       int    `bson:"debian_tracker_ttl" default:"21600"`
+	UbuntuTrackerSizeLimit int    `bson:"ubuntu_tracker_size_limit" default:"536870912"`
+	UbuntuTrackerTtl       int    `bson:"ubuntu_tracker_ttl" default:"21600"`
+	ImportSizeLimit        int    `bson:"import_size_limit" default:"536870912"`
 }
 
 func newTelemetry() interface{} {
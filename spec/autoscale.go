@@ -0,0 +1,120 @@
+package spec
+
+import (
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/metric"
+)
+
+type Autoscale struct {
+	MinCount          int     `bson:"min_count" json:"min_count"`
+	MaxCount          int     `bson:"max_count" json:"max_count"`
+	CpuTarget         int     `bson:"cpu_target" json:"cpu_target"`
+	MemoryTarget      int     `bson:"memory_target" json:"memory_target"`
+	Metric            string  `bson:"metric" json:"metric"`
+	MetricTarget      float64 `bson:"metric_target" json:"metric_target"`
+	Window            int     `bson:"window" json:"window"`
+	ScaleUpCooldown   int     `bson:"scale_up_cooldown" json:"scale_up_cooldown"`
+	ScaleDownCooldown int     `bson:"scale_down_cooldown" json:"scale_down_cooldown"`
+}
+
+func (a *Autoscale) Validate() (errData *errortypes.ErrorData, err error) {
+	if a.MinCount < 1 {
+		errData = &errortypes.ErrorData{
+			Error:   "autoscale_min_count_invalid",
+			Message: "Autoscale min count must be at least 1",
+		}
+		return
+	}
+
+	if a.MaxCount < a.MinCount || a.MaxCount > AutoscaleMaxCount {
+		errData = &errortypes.ErrorData{
+			Error:   "autoscale_max_count_invalid",
+			Message: "Autoscale max count is invalid",
+		}
+		return
+	}
+
+	if a.CpuTarget < 0 || a.CpuTarget > 100 {
+		errData = &errortypes.ErrorData{
+			Error:   "autoscale_cpu_target_invalid",
+			Message: "Autoscale CPU target must be between 1 and 100",
+		}
+		return
+	}
+
+	if a.MemoryTarget < 0 || a.MemoryTarget > 100 {
+		errData = &errortypes.ErrorData{
+			Error:   "autoscale_memory_target_invalid",
+			Message: "Autoscale memory target must be between 1 and 100",
+		}
+		return
+	}
+
+	if a.Metric != "" {
+		if metric.FilterCustomName(a.Metric) != a.Metric {
+			errData = &errortypes.ErrorData{
+				Error:   "autoscale_metric_invalid",
+				Message: "Autoscale metric name is invalid",
+			}
+			return
+		}
+
+		if a.MetricTarget <= 0 {
+			errData = &errortypes.ErrorData{
+				Error:   "autoscale_metric_target_invalid",
+				Message: "Autoscale metric target must be greater than 0",
+			}
+			return
+		}
+	} else {
+		a.MetricTarget = 0
+	}
+
+	if a.CpuTarget == 0 && a.MemoryTarget == 0 && a.Metric == "" {
+		errData = &errortypes.ErrorData{
+			Error:   "autoscale_target_missing",
+			Message: "Autoscale requires a CPU, memory or metric target",
+		}
+		return
+	}
+
+	if a.Window == 0 {
+		a.Window = DefaultAutoscaleWindow
+	}
+	if a.ScaleUpCooldown == 0 {
+		a.ScaleUpCooldown = DefaultScaleUpCooldown
+	}
+	if a.ScaleDownCooldown == 0 {
+		a.ScaleDownCooldown = DefaultScaleDownCooldown
+	}
+
+	if a.Window < 60 {
+		errData = &errortypes.ErrorData{
+			Error:   "autoscale_window_invalid",
+			Message: "Autoscale window must be at least 60 seconds",
+		}
+		return
+	}
+
+	if a.ScaleUpCooldown < 0 || a.ScaleDownCooldown < 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "autoscale_cooldown_invalid",
+			Message: "Autoscale cooldown is invalid",
+		}
+		return
+	}
+
+	return
+}
+
+type AutoscaleYaml struct {
+	MinCount          int     `yaml:"minCount"`
+	MaxCount          int     `yaml:"maxCount"`
+	CpuTarget         int     `yaml:"cpuTarget"`
+	MemoryTarget      int     `yaml:"memoryTarget"`
+	Metric            string  `yaml:"metric"`
+	MetricTarget      float64 `yaml:"metricTarget"`
+	Window            int     `yaml:"window"`
+	ScaleUpCooldown   int     `yaml:"scaleUpCooldown"`
+	ScaleDownCooldown int     `yaml:"scaleDownCooldown"`
+}
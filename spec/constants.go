@@ -35,6 +35,22 @@ const (
 
 	Disk     = "disk"
 	HostPath = "host_path"
+
+	Rolling   = "rolling"
+	BlueGreen = "blue_green"
+	Canary    = "canary"
+
+	DefaultCanaryPercent = 10
+	DefaultHealthTimeout = 600
+
+	AutoscaleMaxCount        = 256
+	DefaultAutoscaleWindow   = 300
+	DefaultScaleUpCooldown   = 180
+	DefaultScaleDownCooldown = 600
+
+	Hard  = "hard"
+	Soft  = "soft"
+	Clear = "clear"
 )
 
 type Base struct {
@@ -0,0 +1,168 @@
+package spec
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type Change struct {
+	Field   string      `json:"field"`
+	Class   string      `json:"class"`
+	Restart bool        `json:"restart"`
+	Old     interface{} `json:"old"`
+	New     interface{} `json:"new"`
+}
+
+func diffValue(val reflect.Value) interface{} {
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil
+		}
+		return val.Elem().Interface()
+	}
+	return val.Interface()
+}
+
+// instanceFieldClass returns the change class of an instance field from
+// the diff struct tag, fields without a class are hard changes
+func instanceFieldClass(field reflect.StructField) (
+	class string, restart bool) {
+
+	opts := strings.Split(field.Tag.Get("diff"), ",")
+	class = opts[0]
+	if class == "" {
+		class = Hard
+	}
+
+	for _, opt := range opts[1:] {
+		if opt == "restart" {
+			restart = true
+		}
+	}
+
+	return
+}
+
+func (s *Spec) diffInstance(spc *Spec) (changes []*Change) {
+	curInst := s.Instance
+	if curInst == nil {
+		curInst = &Instance{}
+	}
+	newInst := spc.Instance
+	if newInst == nil {
+		newInst = &Instance{}
+	}
+
+	curVal := reflect.ValueOf(curInst).Elem()
+	newVal := reflect.ValueOf(newInst).Elem()
+	typ := curVal.Type()
+
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("bson"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		curField := curVal.Field(i)
+		newField := newVal.Field(i)
+
+		if name == "roles" {
+			if utils.CompareStringSlicesUnsorted(
+				curInst.Roles, newInst.Roles) {
+
+				continue
+			}
+		} else if reflect.DeepEqual(
+			curField.Interface(), newField.Interface()) {
+
+			continue
+		}
+
+		class, restart := instanceFieldClass(typ.Field(i))
+
+		changes = append(changes, &Change{
+			Field:   "instance." + name,
+			Class:   class,
+			Restart: restart,
+			Old:     diffValue(curField),
+			New:     diffValue(newField),
+		})
+	}
+
+	return
+}
+
+func (s *Spec) Diff(spc *Spec) (changes []*Change) {
+	changes = []*Change{}
+
+	if s.Name != spc.Name {
+		changes = append(changes, &Change{
+			Field: "name",
+			Class: Soft,
+			Old:   s.Name,
+			New:   spc.Name,
+		})
+	}
+
+	if s.Count != spc.Count {
+		changes = append(changes, &Change{
+			Field: "count",
+			Class: Soft,
+			Old:   s.Count,
+			New:   spc.Count,
+		})
+	}
+
+	if s.Hash != spc.Hash {
+		changes = append(changes, &Change{
+			Field: "data",
+			Class: Soft,
+			Old:   s.Hash,
+			New:   spc.Hash,
+		})
+	}
+
+	if s.Kind != spc.Kind {
+		changes = append(changes, &Change{
+			Field: "kind",
+			Class: Hard,
+			Old:   s.Kind,
+			New:   spc.Kind,
+		})
+	}
+
+	if s.Instance != nil || spc.Instance != nil {
+		changes = append(changes, s.diffInstance(spc)...)
+	}
+
+	if !reflect.DeepEqual(s.Firewall, spc.Firewall) {
+		changes = append(changes, &Change{
+			Field: "firewall",
+			Class: Soft,
+			Old:   s.Firewall,
+			New:   spc.Firewall,
+		})
+	}
+
+	if !reflect.DeepEqual(s.Domain, spc.Domain) {
+		changes = append(changes, &Change{
+			Field: "domain",
+			Class: Soft,
+			Old:   s.Domain,
+			New:   spc.Domain,
+		})
+	}
+
+	if !reflect.DeepEqual(s.Journal, spc.Journal) {
+		changes = append(changes, &Change{
+			Field: "journal",
+			Class: Soft,
+			Old:   s.Journal,
+			New:   spc.Journal,
+		})
+	}
+
+	return
+}
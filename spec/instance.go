@@ -1,41 +1,49 @@
 package spec
 
 import (
+	"time"
+
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/nodeport"
+	"github.com/pritunl/pritunl-cloud/schedule"
 )
 
 type Instance struct {
-	Plan                bson.ObjectID   `bson:"plan,omitempty" json:"plan"`                           // clear
-	Datacenter          bson.ObjectID   `bson:"datacenter" json:"datacenter"`                         // hard
-	Zone                bson.ObjectID   `bson:"zone" json:"zone"`                                     // hard
-	Node                bson.ObjectID   `bson:"node,omitempty" json:"node"`                           // hard
-	Shape               bson.ObjectID   `bson:"shape,omitempty" json:"shape"`                         // hard
-	Vpc                 bson.ObjectID   `bson:"vpc" json:"vpc"`                                       // hard
-	Subnet              bson.ObjectID   `bson:"subnet" json:"subnet"`                                 // hard
-	Roles               []string        `bson:"roles" json:"roles"`                                   // soft
-	Processors          int             `bson:"processors" json:"processors"`                         // soft
-	Memory              int             `bson:"memory" json:"memory"`                                 // soft
-	Uefi                *bool           `bson:"uefi,omitempty" json:"uefi"`                           // soft
-	SecureBoot          *bool           `bson:"secure_boot,omitempty" json:"secure_boot"`             // soft
-	CloudType           string          `bson:"cloud_type" json:"cloud_type"`                         // soft
-	Tpm                 bool            `bson:"tpm" json:"tpm"`                                       // soft
-	Vnc                 bool            `bson:"vnc" json:"vnc"`                                       // soft
-	DeleteProtection    bool            `bson:"delete_protection" json:"delete_protection"`           // soft
-	SkipSourceDestCheck bool            `bson:"skip_source_dest_check" json:"skip_source_dest_check"` // soft
-	Gui                 bool            `bson:"gui" json:"gui"`                                       // soft
-	HostAddress         *bool           `bson:"host_address,omitempty" json:"host_address"`           // soft
-	PublicAddress       *bool           `bson:"public_address,omitempty" json:"public_address"`       // soft
-	PublicAddress6      *bool           `bson:"public_address6,omitempty" json:"public_address6"`     // soft
-	DhcpServer          bool            `bson:"dhcp_server" json:"dhcp_server"`                       // soft
-	Image               bson.ObjectID   `bson:"image" json:"image"`                                   // hard
-	DiskSize            int             `bson:"disk_size" json:"disk_size"`                           // hard
-	Mounts              []Mount         `bson:"mounts" json:"mounts"`                                 // hard
-	NodePorts           []NodePort      `bson:"node_ports" json:"node_ports"`                         // soft
-	Certificates        []bson.ObjectID `bson:"certificates" json:"certificates"`                     // soft
-	Secrets             []bson.ObjectID `bson:"secrets" json:"secrets"`                               // soft
-	Pods                []bson.ObjectID `bson:"pods" json:"pods"`                                     // soft
+	Plan                bson.ObjectID        `bson:"plan,omitempty" json:"plan" diff:"clear"`
+	Datacenter          bson.ObjectID        `bson:"datacenter" json:"datacenter" diff:"hard"`
+	Zone                bson.ObjectID        `bson:"zone" json:"zone" diff:"hard"`
+	Node                bson.ObjectID        `bson:"node,omitempty" json:"node" diff:"hard"`
+	Shape               bson.ObjectID        `bson:"shape,omitempty" json:"shape" diff:"hard"`
+	Vpc                 bson.ObjectID        `bson:"vpc" json:"vpc" diff:"hard"`
+	Subnet              bson.ObjectID        `bson:"subnet" json:"subnet" diff:"hard"`
+	Roles               []string             `bson:"roles" json:"roles" diff:"soft"`
+	Processors          int                  `bson:"processors" json:"processors" diff:"soft,restart"`
+	Memory              int                  `bson:"memory" json:"memory" diff:"soft,restart"`
+	Uefi                *bool                `bson:"uefi,omitempty" json:"uefi" diff:"soft,restart"`
+	SecureBoot          *bool                `bson:"secure_boot,omitempty" json:"secure_boot" diff:"soft,restart"`
+	CloudType           string               `bson:"cloud_type" json:"cloud_type" diff:"soft,restart"`
+	Tpm                 bool                 `bson:"tpm" json:"tpm" diff:"soft,restart"`
+	Vnc                 bool                 `bson:"vnc" json:"vnc" diff:"soft,restart"`
+	DeleteProtection    bool                 `bson:"delete_protection" json:"delete_protection" diff:"soft"`
+	SkipSourceDestCheck bool                 `bson:"skip_source_dest_check" json:"skip_source_dest_check" diff:"soft"`
+	Gui                 bool                 `bson:"gui" json:"gui" diff:"soft,restart"`
+	HostAddress         *bool                `bson:"host_address,omitempty" json:"host_address" diff:"soft,restart"`
+	PublicAddress       *bool                `bson:"public_address,omitempty" json:"public_address" diff:"soft,restart"`
+	PublicAddress6      *bool                `bson:"public_address6,omitempty" json:"public_address6" diff:"soft,restart"`
+	DhcpServer          bool                 `bson:"dhcp_server" json:"dhcp_server" diff:"soft,restart"`
+	PowerSchedules      []*schedule.Schedule `bson:"power_schedules" json:"power_schedules" diff:"soft"`
+	Expire              time.Time            `bson:"expire" json:"expire" diff:"soft"`
+	Lifetime            int                  `bson:"lifetime" json:"lifetime" diff:"soft"`
+	Image               bson.ObjectID        `bson:"image" json:"image" diff:"hard"`
+	DiskSize            int                  `bson:"disk_size" json:"disk_size" diff:"hard"`
+	Mounts              []Mount              `bson:"mounts" json:"mounts" diff:"hard"`
+	NodePorts           []NodePort           `bson:"node_ports" json:"node_ports" diff:"soft"`
+	Certificates        []bson.ObjectID      `bson:"certificates" json:"certificates" diff:"soft"`
+	Secrets             []bson.ObjectID      `bson:"secrets" json:"secrets" diff:"soft"`
+	Pods                []bson.ObjectID      `bson:"pods" json:"pods" diff:"soft"`
+	Update              *Update              `bson:"update,omitempty" json:"update" diff:"soft"`
+	Autoscale           *Autoscale           `bson:"autoscale,omitempty" json:"autoscale" diff:"soft"`
 }
 
 type NodePort struct {
@@ -108,6 +116,21 @@ func (i *Instance) DiffNodePorts(newNodePorts []NodePort) bool {
 	return false
 }
 
+// GetExpire returns the expire time for an instance created at the time,
+// the earlier of the spec expire time and lifetime is used
+func (i *Instance) GetExpire(created time.Time) (expire time.Time) {
+	expire = i.Expire
+
+	if i.Lifetime != 0 {
+		lifetimeExpire := created.Add(time.Duration(i.Lifetime) * time.Hour)
+		if expire.IsZero() || lifetimeExpire.Before(expire) {
+			expire = lifetimeExpire
+		}
+	}
+
+	return
+}
+
 func (i *Instance) MemoryUnits() float64 {
 	return float64(i.Memory) / float64(1024)
 }
@@ -146,6 +169,9 @@ type InstanceYaml struct {
 	PublicAddress       *bool                  `yaml:"publicAddress"`
 	PublicAddress6      *bool                  `yaml:"publicAddress6"`
 	DhcpServer          bool                   `yaml:"dhcpServer"`
+	PowerSchedules      []InstanceScheduleYaml `yaml:"powerSchedules"`
+	Expire              string                 `yaml:"expire"`
+	Lifetime            int                    `yaml:"lifetime"`
 	Image               string                 `yaml:"image"`
 	Mounts              []InstanceMountYaml    `yaml:"mounts"`
 	NodePorts           []InstanceNodePortYaml `yaml:"nodePorts"`
@@ -153,6 +179,8 @@ type InstanceYaml struct {
 	Secrets             []string               `yaml:"secrets"`
 	Pods                []string               `yaml:"pods"`
 	DiskSize            int                    `yaml:"diskSize"`
+	Update              *UpdateYaml            `yaml:"update"`
+	Autoscale           *AutoscaleYaml         `yaml:"autoscale"`
 }
 
 type InstanceMountYaml struct {
@@ -168,3 +196,9 @@ type InstanceNodePortYaml struct {
 	ExternalPort int    `yaml:"externalPort"`
 	InternalPort int    `yaml:"internalPort"`
 }
+
+type InstanceScheduleYaml struct {
+	Action   string `yaml:"action"`
+	Cron     string `yaml:"cron"`
+	Timezone string `yaml:"timezone"`
+}
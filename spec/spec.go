@@ -4,6 +4,7 @@ import (
 	"crypto/sha1"
 	"fmt"
 	"io"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,6 +20,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/journal"
 	"github.com/pritunl/pritunl-cloud/node"
 	"github.com/pritunl/pritunl-cloud/organization"
+	"github.com/pritunl/pritunl-cloud/schedule"
 	"github.com/pritunl/pritunl-cloud/settings"
 	"github.com/pritunl/pritunl-cloud/shape"
 	"github.com/pritunl/pritunl-cloud/utils"
@@ -105,7 +107,15 @@ func (s *Spec) GetAllNodes(db *database.Database) (ndes Nodes,
 					if dsk == nil || !dsk.IsAvailable() {
 						continue
 					}
-					mountSet.Add(dsk.Node)
+					if dsk.IsShared() {
+						for _, nde := range allNdes {
+							if slices.Contains(nde.Pools, dsk.Pool) {
+								mountSet.Add(nde.Id)
+							}
+						}
+					} else {
+						mountSet.Add(dsk.Node)
+					}
 				}
 			} else if mount.Type == HostPath {
 				for _, nde := range allNdes {
@@ -151,6 +161,16 @@ func (s *Spec) GetAllNodes(db *database.Database) (ndes Nodes,
 	return
 }
 
+func ResourceTokens(data string) (tokens []string) {
+	for _, matches := range resourcesRe.FindAllStringSubmatch(data, -1) {
+		if len(matches) > 1 {
+			tokens = append(tokens, finder.FindTokens(matches[1])...)
+		}
+	}
+
+	return
+}
+
 func (s *Spec) ExtractResources() (resources string, err error) {
 	matches := resourcesRe.FindStringSubmatch(s.Data)
 	if len(matches) > 1 {
@@ -604,9 +624,93 @@ func (s *Spec) parseInstance(db *database.Database,
 	data.PublicAddress6 = dataYaml.PublicAddress6
 	data.DhcpServer = dataYaml.DhcpServer
 
+	data.PowerSchedules = []*schedule.Schedule{}
+	for _, schdYaml := range dataYaml.PowerSchedules {
+		schd := &schedule.Schedule{
+			Action:   schdYaml.Action,
+			Cron:     schdYaml.Cron,
+			Timezone: schdYaml.Timezone,
+		}
+
+		errData, err = schd.Validate()
+		if err != nil || errData != nil {
+			return
+		}
+
+		data.PowerSchedules = append(data.PowerSchedules, schd)
+	}
+
+	if dataYaml.Expire != "" {
+		data.Expire, err = time.Parse(time.RFC3339, dataYaml.Expire)
+		if err != nil {
+			err = nil
+			errData = &errortypes.ErrorData{
+				Error:   "invalid_unit_expire",
+				Message: "Unit instance expire must be an RFC 3339 timestamp",
+			}
+			return
+		}
+	}
+
+	if dataYaml.Lifetime < 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "invalid_unit_lifetime",
+			Message: "Unit instance lifetime is invalid",
+		}
+		return
+	}
+	data.Lifetime = dataYaml.Lifetime
+
 	data.Roles = dataYaml.Roles
 	data.DiskSize = dataYaml.DiskSize
 
+	if dataYaml.Update != nil {
+		update := &Update{
+			Strategy:       dataYaml.Update.Strategy,
+			MaxUnavailable: dataYaml.Update.MaxUnavailable,
+			MaxSurge:       dataYaml.Update.MaxSurge,
+			CanaryPercent:  dataYaml.Update.CanaryPercent,
+			HealthTimeout:  dataYaml.Update.HealthTimeout,
+			Rollback:       dataYaml.Update.Rollback,
+		}
+
+		errData, err = update.Validate()
+		if err != nil || errData != nil {
+			return
+		}
+
+		data.Update = update
+	}
+
+	if dataYaml.Autoscale != nil {
+		if dataYaml.Kind == finder.ImageKind {
+			errData = &errortypes.ErrorData{
+				Error:   "autoscale_invalid",
+				Message: "Autoscale not valid for image kind",
+			}
+			return
+		}
+
+		autoscale := &Autoscale{
+			MinCount:          dataYaml.Autoscale.MinCount,
+			MaxCount:          dataYaml.Autoscale.MaxCount,
+			CpuTarget:         dataYaml.Autoscale.CpuTarget,
+			MemoryTarget:      dataYaml.Autoscale.MemoryTarget,
+			Metric:            dataYaml.Autoscale.Metric,
+			MetricTarget:      dataYaml.Autoscale.MetricTarget,
+			Window:            dataYaml.Autoscale.Window,
+			ScaleUpCooldown:   dataYaml.Autoscale.ScaleUpCooldown,
+			ScaleDownCooldown: dataYaml.Autoscale.ScaleDownCooldown,
+		}
+
+		errData, err = autoscale.Validate()
+		if err != nil || errData != nil {
+			return
+		}
+
+		data.Autoscale = autoscale
+	}
+
 	s.Name = dataYaml.Name
 	s.Kind = dataYaml.Kind
 	s.Failover = dataYaml.Failover
@@ -0,0 +1,87 @@
+package spec
+
+import (
+	"github.com/pritunl/pritunl-cloud/errortypes"
+)
+
+type Update struct {
+	Strategy       string `bson:"strategy" json:"strategy"`
+	MaxUnavailable int    `bson:"max_unavailable" json:"max_unavailable"`
+	MaxSurge       int    `bson:"max_surge" json:"max_surge"`
+	CanaryPercent  int    `bson:"canary_percent" json:"canary_percent"`
+	HealthTimeout  int    `bson:"health_timeout" json:"health_timeout"`
+	Rollback       bool   `bson:"rollback" json:"rollback"`
+}
+
+func (u *Update) Validate() (errData *errortypes.ErrorData, err error) {
+	switch u.Strategy {
+	case Rolling:
+		u.CanaryPercent = 0
+		break
+	case BlueGreen:
+		u.MaxUnavailable = 0
+		u.MaxSurge = 0
+		u.CanaryPercent = 0
+		break
+	case Canary:
+		if u.CanaryPercent == 0 {
+			u.CanaryPercent = DefaultCanaryPercent
+		}
+		if u.CanaryPercent < 1 || u.CanaryPercent > 100 {
+			errData = &errortypes.ErrorData{
+				Error:   "update_canary_percent_invalid",
+				Message: "Update canary percent must be between 1 and 100",
+			}
+			return
+		}
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "update_strategy_invalid",
+			Message: "Update strategy is invalid",
+		}
+		return
+	}
+
+	if u.Strategy != BlueGreen {
+		if u.MaxUnavailable == 0 {
+			u.MaxUnavailable = 1
+		}
+		if u.MaxUnavailable < 0 {
+			errData = &errortypes.ErrorData{
+				Error:   "update_max_unavailable_invalid",
+				Message: "Update max unavailable is invalid",
+			}
+			return
+		}
+		if u.MaxSurge < 0 {
+			errData = &errortypes.ErrorData{
+				Error:   "update_max_surge_invalid",
+				Message: "Update max surge is invalid",
+			}
+			return
+		}
+	}
+
+	if u.HealthTimeout == 0 {
+		u.HealthTimeout = DefaultHealthTimeout
+	}
+	if u.HealthTimeout < 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "update_health_timeout_invalid",
+			Message: "Update health timeout is invalid",
+		}
+		return
+	}
+
+	return
+}
+
+type UpdateYaml struct {
+	Strategy       string `yaml:"strategy"`
+	MaxUnavailable int    `yaml:"maxUnavailable"`
+	MaxSurge       int    `yaml:"maxSurge"`
+	CanaryPercent  int    `yaml:"canaryPercent"`
+	HealthTimeout  int    `yaml:"healthTimeout"`
+	Rollback       bool   `yaml:"rollback"`
+}
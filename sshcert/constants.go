@@ -0,0 +1,6 @@
+package sshcert
+
+const (
+	User = "user"
+	Host = "host"
+)
@@ -0,0 +1,143 @@
+package sshcert
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/envelope"
+)
+
+type HostKey struct {
+	Id          bson.ObjectID   `bson:"_id" json:"id"`
+	Authority   bson.ObjectID   `bson:"authority" json:"authority"`
+	PrivateKey  envelope.String `bson:"private_key" json:"-"`
+	PublicKey   string          `bson:"public_key" json:"public_key"`
+	Certificate string          `bson:"certificate" json:"certificate"`
+	ValidBefore time.Time       `bson:"valid_before" json:"valid_before"`
+}
+
+func (h *HostKey) Expiring(ttl time.Duration) bool {
+	return h.Certificate == "" || time.Until(h.ValidBefore) < ttl/2
+}
+
+func (h *HostKey) Commit(db *database.Database) (err error) {
+	coll := db.SshHostKeys()
+
+	err = coll.Upsert(&bson.M{
+		"_id": h.Id,
+	}, h)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetHostKey(db *database.Database, instId bson.ObjectID) (
+	hostKey *HostKey, err error) {
+
+	coll := db.SshHostKeys()
+	hostKey = &HostKey{}
+
+	err = coll.FindOneId(instId, hostKey)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			hostKey = nil
+			err = nil
+		}
+		return
+	}
+
+	return
+}
+
+func GetHostKeys(db *database.Database, instIds []bson.ObjectID) (
+	hostKeys map[bson.ObjectID]*HostKey, err error) {
+
+	coll := db.SshHostKeys()
+	hostKeys = map[bson.ObjectID]*HostKey{}
+
+	if len(instIds) == 0 {
+		return
+	}
+
+	cursor, err := coll.Find(db, &bson.M{
+		"_id": &bson.M{
+			"$in": instIds,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		hostKey := &HostKey{}
+		err = cursor.Decode(hostKey)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		hostKeys[hostKey.Id] = hostKey
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func RemoveHostKey(db *database.Database, instId bson.ObjectID) (
+	err error) {
+
+	coll := db.SshHostKeys()
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"_id": instId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetAllHostKeys(db *database.Database) (
+	hostKeys []*HostKey, err error) {
+
+	coll := db.SshHostKeys()
+	hostKeys = []*HostKey{}
+
+	cursor, err := coll.Find(db, &bson.M{})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		hostKey := &HostKey{}
+		err = cursor.Decode(hostKey)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		hostKeys = append(hostKeys, hostKey)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,45 @@
+package sshcert
+
+import (
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+)
+
+type Certificate struct {
+	Id           bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Authority    bson.ObjectID `bson:"authority" json:"authority"`
+	Organization bson.ObjectID `bson:"organization" json:"organization"`
+	Type         string        `bson:"type" json:"type"`
+	User         bson.ObjectID `bson:"user,omitempty" json:"user"`
+	Instance     bson.ObjectID `bson:"instance,omitempty" json:"instance"`
+	KeyId        string        `bson:"key_id" json:"key_id"`
+	Serial       int64         `bson:"serial" json:"serial"`
+	Principals   []string      `bson:"principals" json:"principals"`
+	Fingerprint  string        `bson:"fingerprint" json:"fingerprint"`
+	ValidAfter   time.Time     `bson:"valid_after" json:"valid_after"`
+	ValidBefore  time.Time     `bson:"valid_before" json:"valid_before"`
+	Timestamp    time.Time     `bson:"timestamp" json:"timestamp"`
+}
+
+func (c *Certificate) Insert(db *database.Database) (err error) {
+	coll := db.SshCertificates()
+
+	if !c.Id.IsZero() {
+		err = &errortypes.DatabaseError{
+			errors.New("sshcert: Certificate already exists"),
+		}
+		return
+	}
+
+	_, err = coll.InsertOne(db, c)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
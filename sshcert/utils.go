@@ -0,0 +1,48 @@
+package sshcert
+
+import (
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
+	"github.com/pritunl/pritunl-cloud/database"
+)
+
+func GetAll(db *database.Database, query *bson.M, limit int64) (
+	certs []*Certificate, err error) {
+
+	coll := db.SshCertificates()
+	certs = []*Certificate{}
+
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	cursor, err := coll.Find(db, query, options.Find().
+		SetSort(&bson.D{
+			{"timestamp", -1},
+		}).
+		SetLimit(limit))
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		cert := &Certificate{}
+		err = cursor.Decode(cert)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		certs = append(certs, cert)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -1,10 +1,21 @@
 package storage
 
+import (
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
 const (
 	Public  = "public"
 	Private = "private"
 	Web     = "web"
 
+	Filesystem = "filesystem"
+
+	MountHealthy   = "healthy"
+	MountMissing   = utils.DirMissing
+	MountUnmounted = utils.DirUnmounted
+	MountReadOnly  = utils.DirReadOnly
+
 	AwsStandard         = "aws_standard"
 	AwsInfrequentAccess = "aws_infrequent_access"
 	AwsGlacier          = "aws_glacier"
@@ -0,0 +1,149 @@
+package storage
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+type NodePath struct {
+	Node bson.ObjectID `bson:"node" json:"node"`
+	Path string        `bson:"path" json:"path"`
+}
+
+type Health struct {
+	Status    string    `bson:"status" json:"status"`
+	Message   string    `bson:"message" json:"message"`
+	Timestamp time.Time `bson:"timestamp" json:"timestamp"`
+}
+
+func (s *Storage) IsFilesystem() bool {
+	return s.Type == Filesystem
+}
+
+func (s *Storage) validatePaths() (errData *errortypes.ErrorData) {
+	s.Path = path.Clean(strings.TrimSpace(s.Path))
+	if !path.IsAbs(s.Path) || s.Path == "/" {
+		errData = &errortypes.ErrorData{
+			Error:   "invalid_path",
+			Message: "Storage path must be an absolute directory",
+		}
+		return
+	}
+
+	if s.NodePaths == nil {
+		s.NodePaths = []*NodePath{}
+	}
+
+	nodePaths := []*NodePath{}
+	for _, nodePath := range s.NodePaths {
+		if nodePath == nil || nodePath.Node.IsZero() {
+			continue
+		}
+
+		nodePath.Path = path.Clean(strings.TrimSpace(nodePath.Path))
+		if !path.IsAbs(nodePath.Path) || nodePath.Path == "/" {
+			errData = &errortypes.ErrorData{
+				Error:   "invalid_node_path",
+				Message: "Storage node path must be an absolute directory",
+			}
+			return
+		}
+
+		nodePaths = append(nodePaths, nodePath)
+	}
+	s.NodePaths = nodePaths
+
+	return
+}
+
+// GetPath returns the storage directory on the node
+func (s *Storage) GetPath(nodeId bson.ObjectID) string {
+	for _, nodePath := range s.NodePaths {
+		if nodePath.Node == nodeId {
+			return nodePath.Path
+		}
+	}
+	return s.Path
+}
+
+// GetKeyPath returns the path of an object key within the storage
+// directory on the node
+func (s *Storage) GetKeyPath(nodeId bson.ObjectID, key string) (
+	pth string, err error) {
+
+	root := s.GetPath(nodeId)
+	pth = filepath.Join(root, filepath.FromSlash(key))
+
+	if !strings.HasPrefix(pth, root+string(filepath.Separator)) {
+		err = &errortypes.ParseError{
+			errors.Newf("storage: Invalid object key '%s'", key),
+		}
+		return
+	}
+
+	return
+}
+
+// CheckMount verifies the storage directory on the node exists, is
+// writable and when required is a mount point
+func (s *Storage) CheckMount(nodeId bson.ObjectID) (health *Health) {
+	pth := s.GetPath(nodeId)
+	health = &Health{
+		Status:    MountHealthy,
+		Timestamp: time.Now(),
+	}
+
+	status, message := utils.CheckDir(pth, s.Mount,
+		fmt.Sprintf(".pritunl-cloud-%s", nodeId.Hex()))
+	if status != "" {
+		health.Status = status
+		health.Message = message
+		return
+	}
+
+	return
+}
+
+// Available returns an error when the storage directory is not usable
+// on the node
+func (s *Storage) Available(nodeId bson.ObjectID) (err error) {
+	health := s.CheckMount(nodeId)
+	if health.Status != MountHealthy {
+		err = &errortypes.ConnectionError{
+			errors.Newf("storage: Filesystem storage unavailable, %s",
+				health.Message),
+		}
+		return
+	}
+
+	return
+}
+
+func (s *Storage) SetHealth(db *database.Database, nodeId bson.ObjectID,
+	health *Health) (err error) {
+
+	coll := db.Storages()
+
+	_, err = coll.UpdateOne(db, &bson.M{
+		"_id": s.Id,
+	}, &bson.M{
+		"$set": &bson.M{
+			"health." + nodeId.Hex(): health,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -8,20 +8,25 @@ import (
 	"github.com/dropbox/godropbox/errors"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/envelope"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/utils"
 )
 
 type Storage struct {
-	Id        bson.ObjectID `bson:"_id,omitempty" json:"id"`
-	Name      string        `bson:"name" json:"name"`
-	Comment   string        `bson:"comment" json:"comment"`
-	Type      string        `bson:"type" json:"type"`
-	Endpoint  string        `bson:"endpoint" json:"endpoint"`
-	Bucket    string        `bson:"bucket" json:"bucket"`
-	AccessKey string        `bson:"access_key" json:"access_key"`
-	SecretKey string        `bson:"secret_key" json:"secret_key"`
-	Insecure  bool          `bson:"insecure" json:"insecure"`
+	Id        bson.ObjectID      `bson:"_id,omitempty" json:"id"`
+	Name      string             `bson:"name" json:"name"`
+	Comment   string             `bson:"comment" json:"comment"`
+	Type      string             `bson:"type" json:"type"`
+	Endpoint  string             `bson:"endpoint" json:"endpoint"`
+	Bucket    string             `bson:"bucket" json:"bucket"`
+	AccessKey string             `bson:"access_key" json:"access_key"`
+	SecretKey envelope.String    `bson:"secret_key" json:"secret_key"`
+	Insecure  bool               `bson:"insecure" json:"insecure"`
+	Path      string             `bson:"path" json:"path"`
+	NodePaths []*NodePath        `bson:"node_paths" json:"node_paths"`
+	Mount     bool               `bson:"mount" json:"mount"`
+	Health    map[string]*Health `bson:"health" json:"health"`
 }
 
 type Completion struct {
@@ -60,6 +65,18 @@ func (s *Storage) Validate(db *database.Database) (
 		break
 	case Web:
 		break
+	case Filesystem:
+		s.Endpoint = ""
+		s.Bucket = ""
+		s.AccessKey = ""
+		s.SecretKey = ""
+		s.Insecure = false
+
+		errData = s.validatePaths()
+		if errData != nil {
+			return
+		}
+		break
 	case "":
 		s.Type = Public
 		break
@@ -71,6 +88,12 @@ func (s *Storage) Validate(db *database.Database) (
 		return
 	}
 
+	if s.Type != Filesystem {
+		s.Path = ""
+		s.NodePaths = []*NodePath{}
+		s.Mount = false
+	}
+
 	return
 }
 
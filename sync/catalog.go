@@ -0,0 +1,45 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-cloud/catalog"
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/sirupsen/logrus"
+)
+
+func catalogSync() (err error) {
+	db := database.GetDatabase()
+	defer db.Close()
+
+	err = catalog.Refresh(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func catalogRunner() {
+	time.Sleep(1 * time.Second)
+
+	for {
+		if constants.Shutdown {
+			return
+		}
+
+		err := catalogSync()
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("sync: Failed to refresh image catalog")
+		}
+
+		time.Sleep(60 * time.Second)
+	}
+}
+
+func initCatalog() {
+	go catalogRunner()
+}
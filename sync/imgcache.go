@@ -0,0 +1,226 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/data"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/finder"
+	"github.com/pritunl/pritunl-cloud/image"
+	"github.com/pritunl/pritunl-cloud/imgcache"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/scheduler"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/pritunl/pritunl-cloud/spec"
+	"github.com/pritunl/pritunl-cloud/unit"
+	"github.com/sirupsen/logrus"
+)
+
+var imgCacheFailed = map[string]time.Time{}
+
+func imgCacheServe() {
+	for {
+		err := imgcache.Serve()
+		if constants.Shutdown {
+			return
+		}
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("sync: Image cache peer server error")
+		}
+
+		time.Sleep(3 * time.Second)
+	}
+}
+
+func imgCachePrefetchImages(db *database.Database) (
+	imgIds []bson.ObjectID, err error) {
+
+	imgIds = []bson.ObjectID{}
+	imgIdsSet := set.NewSet()
+	specIds := []bson.ObjectID{}
+
+	schds, err := scheduler.GetAllActive(db)
+	if err != nil {
+		return
+	}
+
+	for _, schd := range schds {
+		if len(schd.Tickets[node.Self.Id]) > 0 {
+			specIds = append(specIds, schd.Spec)
+		}
+	}
+
+	if len(specIds) > 0 {
+		spcs, e := spec.GetAll(db, &bson.M{
+			"_id": &bson.M{
+				"$in": specIds,
+			},
+		})
+		if e != nil {
+			err = e
+			return
+		}
+
+		for _, spc := range spcs {
+			if spc.Instance != nil && !spc.Instance.Image.IsZero() &&
+				!imgIdsSet.Contains(spc.Instance.Image) {
+
+				imgIdsSet.Add(spc.Instance.Image)
+				imgIds = append(imgIds, spc.Instance.Image)
+			}
+		}
+	}
+
+	if node.Self.Zone.IsZero() {
+		return
+	}
+
+	units, err := unit.GetAll(db, &bson.M{
+		"kind": finder.InstanceKind,
+	})
+	if err != nil {
+		return
+	}
+
+	specIds = []bson.ObjectID{}
+	for _, unt := range units {
+		if !unt.DeploySpec.IsZero() {
+			specIds = append(specIds, unt.DeploySpec)
+		}
+	}
+
+	if len(specIds) == 0 {
+		return
+	}
+
+	spcs, err := spec.GetAll(db, &bson.M{
+		"_id": &bson.M{
+			"$in": specIds,
+		},
+		"instance.zone": node.Self.Zone,
+	})
+	if err != nil {
+		return
+	}
+
+	for _, spc := range spcs {
+		if spc.Instance == nil || spc.Instance.Image.IsZero() ||
+			imgIdsSet.Contains(spc.Instance.Image) {
+
+			continue
+		}
+
+		if !spc.Instance.Node.IsZero() &&
+			spc.Instance.Node != node.Self.Id {
+
+			continue
+		}
+
+		imgIdsSet.Add(spc.Instance.Image)
+		imgIds = append(imgIds, spc.Instance.Image)
+	}
+
+	return
+}
+
+func imgCacheSync() (err error) {
+	db := database.GetDatabase()
+	defer db.Close()
+
+	imgIds, err := imgCachePrefetchImages(db)
+	if err != nil {
+		return
+	}
+
+	for _, imgId := range imgIds {
+		if constants.Shutdown {
+			return
+		}
+
+		img, e := image.Get(db, imgId)
+		if e != nil {
+			if _, ok := e.(*database.NotFoundError); ok {
+				continue
+			}
+			err = e
+			return
+		}
+
+		key := img.Id.Hex() + "-" + img.Etag
+		if failed, ok := imgCacheFailed[key]; ok &&
+			time.Since(failed) < 10*time.Minute {
+
+			continue
+		}
+
+		fetched, e := data.PrefetchImage(db, img)
+		if e != nil {
+			imgCacheFailed[key] = time.Now()
+
+			logrus.WithFields(logrus.Fields{
+				"image_id": img.Id.Hex(),
+				"key":      img.Key,
+				"error":    e,
+			}).Error("sync: Failed to prefetch image")
+			continue
+		}
+		delete(imgCacheFailed, key)
+
+		if fetched {
+			logrus.WithFields(logrus.Fields{
+				"image_id": img.Id.Hex(),
+				"key":      img.Key,
+			}).Info("sync: Prefetched image into cache")
+		}
+	}
+
+	return
+}
+
+func imgCacheRunner() {
+	time.Sleep(1 * time.Second)
+
+	for {
+		time.Sleep(1 * time.Second)
+		if constants.Shutdown {
+			return
+		}
+
+		if node.Self.IsHypervisor() {
+			break
+		}
+	}
+
+	if !settings.Hypervisor.NoImageCachePeers {
+		go imgCacheServe()
+	}
+
+	for {
+		time.Sleep(30 * time.Second)
+		if constants.Shutdown {
+			return
+		}
+
+		if !node.Self.IsHypervisor() ||
+			settings.Hypervisor.NoImageCachePrefetch {
+
+			continue
+		}
+
+		err := imgCacheSync()
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("sync: Failed to prefetch images")
+		}
+	}
+}
+
+func initImgCache() {
+	go imgCacheRunner()
+}
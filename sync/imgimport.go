@@ -0,0 +1,65 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/imgimport"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/sirupsen/logrus"
+)
+
+func imgImportSync() (processed bool, err error) {
+	db := database.GetDatabase()
+	defer db.Close()
+
+	imprt, err := imgimport.Claim(db, node.Self.Id)
+	if err != nil || imprt == nil {
+		return
+	}
+	processed = true
+
+	event.PublishDispatch(db, "image_import.change")
+
+	err = imprt.Process(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func imgImportRunner() {
+	time.Sleep(1 * time.Second)
+
+	for {
+		time.Sleep(10 * time.Second)
+		if constants.Shutdown {
+			return
+		}
+
+		if !node.Self.IsHypervisor() {
+			continue
+		}
+
+		for !constants.Shutdown {
+			processed, err := imgImportSync()
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"error": err,
+				}).Error("sync: Failed to process image import")
+				break
+			}
+
+			if !processed {
+				break
+			}
+		}
+	}
+}
+
+func initImgImport() {
+	go imgImportRunner()
+}
@@ -0,0 +1,45 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/logsink"
+	"github.com/sirupsen/logrus"
+)
+
+func logSinkSync() (err error) {
+	db := database.GetDatabase()
+	defer db.Close()
+
+	err = logsink.Sync(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func logSinkRunner() {
+	time.Sleep(1 * time.Second)
+
+	for {
+		if constants.Shutdown {
+			return
+		}
+
+		err := logSinkSync()
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("sync: Failed to sync log sinks")
+		}
+
+		time.Sleep(10 * time.Second)
+	}
+}
+
+func initLogSink() {
+	go logSinkRunner()
+}
@@ -0,0 +1,123 @@
+package sync
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/alert"
+	"github.com/pritunl/pritunl-cloud/alertevent"
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/pool"
+	"github.com/sirupsen/logrus"
+)
+
+var thinPoolWarned = map[bson.ObjectID]bool{}
+
+func thinPoolAlert(pl *pool.Pool, alrt *alert.Alert) {
+	usage := 0.0
+	label := ""
+
+	switch alrt.Resource {
+	case alert.ThinPoolData:
+		usage = pl.Stats.DataPercent
+		label = "data"
+		break
+	case alert.ThinPoolMetadata:
+		usage = pl.Stats.MetaPercent
+		label = "metadata"
+		break
+	default:
+		return
+	}
+
+	if usage < float64(alrt.ValueInt) {
+		return
+	}
+
+	alertevent.New(
+		alrt.Organization,
+		alrt.Roles,
+		pl.Id,
+		alrt.Name,
+		pl.Name,
+		alrt.Resource,
+		fmt.Sprintf("Thin pool %s usage at %.1f%%", label, usage),
+		alrt.Level,
+		time.Duration(alrt.Frequency)*time.Second,
+	)
+}
+
+func poolSync() (err error) {
+	db := database.GetDatabase()
+	defer db.Close()
+
+	if len(node.Self.Pools) == 0 {
+		return
+	}
+
+	pools, err := pool.GetAll(db, &bson.M{
+		"_id": &bson.M{
+			"$in": node.Self.Pools,
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	alrts, err := alert.GetAll(db)
+	if err != nil {
+		return
+	}
+
+	for _, pl := range pools {
+		if !pl.IsThin() || pl.Stats == nil {
+			continue
+		}
+
+		if pl.Stats.DataPercent >= 90 || pl.Stats.MetaPercent >= 90 {
+			if !thinPoolWarned[pl.Id] {
+				logrus.WithFields(logrus.Fields{
+					"pool_id":      pl.Id.Hex(),
+					"vg_name":      pl.VgName,
+					"thin_pool":    pl.ThinPool,
+					"data_percent": pl.Stats.DataPercent,
+					"meta_percent": pl.Stats.MetaPercent,
+				}).Warning("sync: Thin pool usage high")
+				thinPoolWarned[pl.Id] = true
+			}
+		} else {
+			delete(thinPoolWarned, pl.Id)
+		}
+
+		for _, alrt := range alrts {
+			thinPoolAlert(pl, alrt)
+		}
+	}
+
+	return
+}
+
+func poolRunner() {
+	time.Sleep(1 * time.Second)
+
+	for {
+		time.Sleep(60 * time.Second)
+		if constants.Shutdown {
+			return
+		}
+
+		err := poolSync()
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("sync: Failed to check pool usage")
+		}
+	}
+}
+
+func initPool() {
+	go poolRunner()
+}
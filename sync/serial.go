@@ -0,0 +1,84 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/serial"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/sirupsen/logrus"
+)
+
+func serialRelay() {
+	for {
+		err := serial.Serve()
+		if constants.Shutdown {
+			return
+		}
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("sync: Serial relay error")
+		}
+
+		time.Sleep(3 * time.Second)
+	}
+}
+
+func serialSync() (err error) {
+	db := database.GetDatabase()
+	defer db.Close()
+
+	err = serial.SyncLogs(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func serialRunner() {
+	time.Sleep(1 * time.Second)
+
+	for {
+		time.Sleep(1 * time.Second)
+		if constants.Shutdown {
+			return
+		}
+
+		if node.Self.IsHypervisor() {
+			break
+		}
+	}
+
+	go serialRelay()
+
+	for {
+		delay := settings.Hypervisor.SerialLogSync
+		if delay <= 0 {
+			delay = 15
+		}
+		time.Sleep(time.Duration(delay) * time.Second)
+
+		if constants.Shutdown {
+			return
+		}
+
+		if !node.Self.IsHypervisor() {
+			continue
+		}
+
+		err := serialSync()
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("sync: Failed to sync serial logs")
+		}
+	}
+}
+
+func initSerial() {
+	go serialRunner()
+}
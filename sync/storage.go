@@ -0,0 +1,73 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/storage"
+	"github.com/sirupsen/logrus"
+)
+
+var storageHealth = map[bson.ObjectID]string{}
+
+func storageSync() (err error) {
+	db := database.GetDatabase()
+	defer db.Close()
+
+	stores, err := storage.GetAll(db)
+	if err != nil {
+		return
+	}
+
+	for _, store := range stores {
+		if store.Type != storage.Filesystem {
+			continue
+		}
+
+		health := store.CheckMount(node.Self.Id)
+
+		if storageHealth[store.Id] != health.Status {
+			if health.Status != storage.MountHealthy {
+				logrus.WithFields(logrus.Fields{
+					"storage_id": store.Id.Hex(),
+					"path":       store.GetPath(node.Self.Id),
+					"status":     health.Status,
+					"message":    health.Message,
+				}).Warning("sync: Filesystem storage unavailable")
+			}
+			storageHealth[store.Id] = health.Status
+		}
+
+		err = store.SetHealth(db, node.Self.Id, health)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func storageRunner() {
+	time.Sleep(1 * time.Second)
+
+	for {
+		time.Sleep(30 * time.Second)
+		if constants.Shutdown {
+			return
+		}
+
+		err := storageSync()
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("sync: Failed to check storage health")
+		}
+	}
+}
+
+func initStorage() {
+	go storageRunner()
+}
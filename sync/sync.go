@@ -4,4 +4,12 @@ func Init() {
 	initAuth()
 	initNode()
 	initVm()
+	initSerial()
+	initLogSink()
+	initImgImport()
+	initCatalog()
+	initStorage()
+	initImgCache()
+	initPool()
+	initTransfer()
 }
@@ -0,0 +1,43 @@
+package sync
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-cloud/constants"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/transfer"
+	"github.com/sirupsen/logrus"
+)
+
+func transferRunner() {
+	time.Sleep(1 * time.Second)
+
+	for {
+		time.Sleep(1 * time.Second)
+		if constants.Shutdown {
+			return
+		}
+
+		if node.Self.IsHypervisor() {
+			break
+		}
+	}
+
+	for {
+		err := transfer.Serve()
+		if constants.Shutdown {
+			return
+		}
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("sync: Disk transfer server error")
+		}
+
+		time.Sleep(3 * time.Second)
+	}
+}
+
+func initTransfer() {
+	go transferRunner()
+}
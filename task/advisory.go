@@ -9,7 +9,10 @@ import (
 	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
 	"github.com/pritunl/pritunl-cloud/advisory"
 	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/debtracker"
 	"github.com/pritunl/pritunl-cloud/manifest"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/pritunl/pritunl-cloud/ubuntutracker"
 	"github.com/pritunl/pritunl-cloud/vulnerability"
 	"github.com/pritunl/pritunl-cloud/vuxml"
 	"github.com/sirupsen/logrus"
@@ -29,9 +32,64 @@ type advisoryProcessor struct {
 	vulnerabilities map[string]*vulnerability.Vulnerability
 	advisories      map[bson.ObjectID]map[string]*advisory.Advisory
 	vuxmlDb         map[string]*vuxml.VuxmlEntry
+	debTracker      debtracker.Tracker
+	debTrackerReady bool
+	ubuTrackers     map[string]*ubuntutracker.Tracker
 	dismissals      map[bson.ObjectID]map[string]*advisory.Dismissal
 }
 
+func (a *advisoryProcessor) getVulnerabilities(db *database.Database,
+	vulnIds []string) (vulns []*vulnerability.Vulnerability) {
+
+	vulns = []*vulnerability.Vulnerability{}
+
+	for _, vulnId := range vulnIds {
+		vuln, ok := a.vulnerabilities[vulnId]
+		if !ok {
+			var err error
+			vuln, err = vulnerability.GetOneLimit(db, vulnId)
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"vulnerability": vulnId,
+					"error":         err,
+				}).Error("task: Failed to query vulnerability")
+				vuln = nil
+			}
+			a.vulnerabilities[vulnId] = vuln
+		}
+
+		if vuln != nil {
+			vulns = append(vulns, vuln)
+		}
+	}
+
+	return
+}
+
+func (a *advisoryProcessor) getUbuntuTracker(release string) (
+	trkr *ubuntutracker.Tracker) {
+
+	if release == "" || settings.Telemetry.Offline {
+		return
+	}
+
+	trkr, ok := a.ubuTrackers[release]
+	if ok {
+		return
+	}
+
+	trkr, err := ubuntutracker.Get(release)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"release": release,
+			"error":   err,
+		}).Error("task: Failed to load Ubuntu security tracker")
+	}
+	a.ubuTrackers[release] = trkr
+
+	return
+}
+
 func (a *advisoryProcessor) Run(db *database.Database) (err error) {
 	cursor, err := manifest.FindUpdates(db)
 	if err != nil {
@@ -123,32 +181,82 @@ func (a *advisoryProcessor) parseUpdates(db *database.Database,
 		}
 
 		if updt.Type == advisory.RedHat {
-			updtVulns := []*vulnerability.Vulnerability{}
+			updtVulns := a.getVulnerabilities(db, updt.Vulnerabilities)
 
-			for _, vulnId := range updt.Vulnerabilities {
-				vuln, ok := a.vulnerabilities[vulnId]
-				if !ok {
-					vuln, err = vulnerability.GetOneLimit(db, vulnId)
+			adv := orgAdvs[updt.Id]
+			if adv == nil {
+				adv = advisory.FromUpdate(
+					updt, updts.Organization, a.now, updtVulns)
+
+				if orgDismissals != nil {
+					dism := orgDismissals[adv.Reference]
+					if dism != nil {
+						adv.Dismissed = dism.Dismissed
+						adv.DismissedResources = dism.DismissedResources
+					}
+				}
+
+				orgAdvs[updt.Id] = adv
+			}
+
+			adv.MergePackages(updt.Packages)
+
+			adv.UpdateScore()
+
+			switch updts.Variant {
+			case manifest.InstanceVariant:
+				adv.Instances = append(adv.Instances, updts.Resource)
+			case manifest.NodeVariant:
+				adv.Nodes = append(adv.Nodes, updts.Resource)
+			}
+
+			resourceAdvs = append(resourceAdvs, adv)
+		} else if updt.Type == advisory.Debian ||
+			updt.Type == advisory.Ubuntu {
+
+			// The Debian tracker is keyed by Debian release codenames,
+			// Ubuntu updates are matched with the release OVAL data
+			var cves []string
+			var urgency string
+			entries := []*debtracker.Entry{}
+
+			if updt.Type == advisory.Debian {
+				if !a.debTrackerReady && !settings.Telemetry.Offline {
+					a.debTrackerReady = true
+					a.debTracker, err = debtracker.Get()
 					if err != nil {
 						logrus.WithFields(logrus.Fields{
-							"vulnerability": vulnId,
-							"error":         err,
-						}).Error("task: Failed to query vulnerability")
+							"error": err,
+						}).Error("task: Failed to load Debian " +
+							"security tracker")
 						err = nil
-						vuln = nil
 					}
-					a.vulnerabilities[vulnId] = vuln
 				}
+				trkr := a.debTracker
 
-				if vuln != nil {
-					updtVulns = append(updtVulns, vuln)
+				cves, urgency = trkr.Match(updt.Source, updt.Release,
+					updt.Version, updt.Vulnerabilities)
+
+				for _, cve := range cves {
+					entries = append(entries, trkr.Entry(updt.Source, cve))
+				}
+			} else {
+				trkr := a.getUbuntuTracker(updt.Release)
+
+				cves, urgency = trkr.Match(updt.Packages, updt.Version,
+					updt.Vulnerabilities)
+
+				for _, cve := range cves {
+					entries = append(entries, trkr.Entry(cve))
 				}
 			}
 
+			updtVulns := a.getVulnerabilities(db, cves)
+
 			adv := orgAdvs[updt.Id]
 			if adv == nil {
-				adv = advisory.FromUpdate(
-					updt, updts.Organization, a.now, updtVulns)
+				adv = advisory.FromUpdate(updt, updts.Organization,
+					a.now, []*vulnerability.Vulnerability{})
 
 				if orgDismissals != nil {
 					dism := orgDismissals[adv.Reference]
@@ -161,7 +269,7 @@ func (a *advisoryProcessor) parseUpdates(db *database.Database,
 				orgAdvs[updt.Id] = adv
 			}
 
-			adv.MergePackages(updt.Packages)
+			adv.MergeTracker(updt, urgency, entries, updtVulns)
 
 			adv.UpdateScore()
 
@@ -175,7 +283,11 @@ func (a *advisoryProcessor) parseUpdates(db *database.Database,
 			resourceAdvs = append(resourceAdvs, adv)
 		} else if updt.Type == advisory.FreeBsd {
 			if a.vuxmlDb == nil {
-				a.vuxmlDb, err = vuxml.Load()
+				if settings.Telemetry.Offline {
+					a.vuxmlDb, err = vuxml.LoadDb(db)
+				} else {
+					a.vuxmlDb, err = vuxml.Load()
+				}
 				if err != nil {
 					logrus.WithFields(logrus.Fields{
 						"error": err,
@@ -189,27 +301,7 @@ func (a *advisoryProcessor) parseUpdates(db *database.Database,
 				continue
 			}
 
-			updtVulns := []*vulnerability.Vulnerability{}
-
-			for _, vulnId := range updt.Vulnerabilities {
-				vuln, ok := a.vulnerabilities[vulnId]
-				if !ok {
-					vuln, err = vulnerability.GetOneLimit(db, vulnId)
-					if err != nil {
-						logrus.WithFields(logrus.Fields{
-							"vulnerability": vulnId,
-							"error":         err,
-						}).Error("task: Failed to query vulnerability")
-						err = nil
-						vuln = nil
-					}
-					a.vulnerabilities[vulnId] = vuln
-				}
-
-				if vuln != nil {
-					updtVulns = append(updtVulns, vuln)
-				}
-			}
+			updtVulns := a.getVulnerabilities(db, updt.Vulnerabilities)
 
 			for _, pkg := range updt.Packages {
 				pkgName, _, _ := strings.Cut(pkg, "@")
@@ -318,6 +410,7 @@ func advisoryDataHandler(db *database.Database) (err error) {
 	advProc := &advisoryProcessor{
 		vulnerabilities: map[string]*vulnerability.Vulnerability{},
 		advisories:      map[bson.ObjectID]map[string]*advisory.Advisory{},
+		ubuTrackers:     map[string]*ubuntutracker.Tracker{},
 		now:             time.Now(),
 	}
 
@@ -0,0 +1,41 @@
+package task
+
+import (
+	"github.com/pritunl/pritunl-cloud/catalog"
+	"github.com/pritunl/pritunl-cloud/data"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/settings"
+)
+
+var catalogLocal = &Task{
+	Name:    "image_catalog_local",
+	Version: 1,
+	Hours: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
+		12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23},
+	Minutes:    []int{0, 14, 29, 44, 59},
+	Handler:    catalogLocalHandler,
+	RunOnStart: true,
+	Local:      true,
+}
+
+func catalogLocalHandler(db *database.Database) (err error) {
+	if settings.System.ImageCatalogPath == "" {
+		return
+	}
+
+	err = data.SyncCatalogLocal(db)
+	if err != nil {
+		return
+	}
+
+	err = catalog.Refresh(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func init() {
+	register(catalogLocal)
+}
@@ -1,9 +1,19 @@
 package task
 
+import (
+	"time"
+)
+
 const (
 	Running  = "running"
 	Failed   = "failed"
 	Finished = "finished"
+	Panicked = "panicked"
+
+	runsLimit      = 100
+	recordInterval = 1 * time.Minute
+
+	powerScheduleLookback = 24 * time.Hour
 )
 
 var (
@@ -0,0 +1,31 @@
+package task
+
+import (
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/imgimport"
+)
+
+var imgImportClean = &Task{
+	Name:    "image_import_clean",
+	Version: 1,
+	Hours: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11,
+		12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23},
+	Minutes: []int{35},
+	Handler: imgImportCleanHandler,
+}
+
+func imgImportCleanHandler(db *database.Database) (err error) {
+	err = imgimport.Clean(db)
+	if err != nil {
+		return
+	}
+
+	event.PublishDispatch(db, "image_import.change")
+
+	return
+}
+
+func init() {
+	register(imgImportClean)
+}
@@ -0,0 +1,196 @@
+package task
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/alert"
+	"github.com/pritunl/pritunl-cloud/alertevent"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/instance"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	expireProtected = map[bson.ObjectID]bool{}
+)
+
+var instancePower = &Task{
+	Name:    "instance_power",
+	Version: 1,
+	Hours:   AllHours,
+	Minutes: AllMins,
+	Handler: instancePowerHandler,
+}
+
+var instanceExpire = &Task{
+	Name:    "instance_expire",
+	Version: 1,
+	Hours:   AllHours,
+	Minutes: AllMins,
+	Handler: instanceExpireHandler,
+}
+
+func instancePowerHandler(db *database.Database) (err error) {
+	now := time.Now()
+
+	state, err := getState(db, "instance_power")
+	if err != nil {
+		return
+	}
+
+	// Apply the most recent schedule in the minutes missed since the
+	// last successful run
+	lastRun := state.LastSuccess
+	if lastRun.IsZero() {
+		lastRun = now.Add(-time.Minute)
+	} else if now.Sub(lastRun) > powerScheduleLookback {
+		lastRun = now.Add(-powerScheduleLookback)
+	}
+
+	insts, err := instance.GetAll(db, &bson.M{
+		"power_schedules.0": &bson.M{
+			"$exists": true,
+		},
+		"action": &bson.M{
+			"$in": []string{instance.Start, instance.Stop},
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	changed := false
+	for _, inst := range insts {
+		action := inst.ScheduledAction(lastRun, now)
+		if action == "" || action == inst.Action {
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"instance_id": inst.Id.Hex(),
+			"action":      action,
+		}).Info("task: Applying instance power schedule")
+
+		err = instance.SetAction(db, inst.Id, action)
+		if err != nil {
+			return
+		}
+		changed = true
+	}
+
+	if changed {
+		event.PublishDispatch(db, "instance.change")
+	}
+
+	return
+}
+
+func instanceExpireAlert(inst *instance.Instance, alrt *alert.Alert,
+	now time.Time) {
+
+	if alrt.Resource != alert.InstanceExpire ||
+		alrt.Organization != inst.Organization {
+
+		return
+	}
+
+	remaining := inst.Expire.Sub(now)
+	if remaining > time.Duration(alrt.ValueInt)*time.Hour {
+		return
+	}
+
+	matched := false
+	for _, role := range alrt.Roles {
+		if slices.Contains(inst.Roles, role) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return
+	}
+
+	alertevent.New(
+		inst.Organization,
+		alrt.Roles,
+		inst.Id,
+		alrt.Name,
+		inst.Name,
+		alrt.Resource,
+		fmt.Sprintf("Instance will be destroyed in %s",
+			remaining.Round(time.Minute)),
+		alrt.Level,
+		time.Duration(alrt.Frequency)*time.Second,
+	)
+}
+
+func instanceExpireHandler(db *database.Database) (err error) {
+	now := time.Now()
+
+	insts, err := instance.GetAll(db, &bson.M{
+		"expire": &bson.M{
+			"$gt": time.Time{},
+		},
+		"action": &bson.M{
+			"$ne": instance.Destroy,
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	alrts, err := alert.GetAll(db)
+	if err != nil {
+		return
+	}
+
+	protected := map[bson.ObjectID]bool{}
+	changed := false
+	for _, inst := range insts {
+		if !inst.Expired(now) {
+			for _, alrt := range alrts {
+				instanceExpireAlert(inst, alrt, now)
+			}
+			continue
+		}
+
+		if inst.DeleteProtection {
+			if !expireProtected[inst.Id] {
+				logrus.WithFields(logrus.Fields{
+					"instance_id": inst.Id.Hex(),
+					"expire":      inst.Expire,
+				}).Warning("task: Skipping destroy of expired instance " +
+					"with delete protection")
+			}
+			protected[inst.Id] = true
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"instance_id": inst.Id.Hex(),
+			"expire":      inst.Expire,
+		}).Info("task: Destroying expired instance")
+
+		err = instance.Delete(db, inst.Id)
+		if err != nil {
+			return
+		}
+		changed = true
+	}
+	expireProtected = protected
+
+	if changed {
+		event.PublishDispatch(db, "instance.change")
+	}
+
+	return
+}
+
+func init() {
+	register(instancePower)
+	register(instanceExpire)
+}
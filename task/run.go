@@ -0,0 +1,75 @@
+package task
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
+	"github.com/pritunl/pritunl-cloud/database"
+)
+
+type Run struct {
+	Id       bson.ObjectID `bson:"_id,omitempty" json:"id"`
+	Task     string        `bson:"task" json:"task"`
+	Node     bson.ObjectID `bson:"node" json:"node"`
+	Local    bool          `bson:"local" json:"local"`
+	Manual   bool          `bson:"manual" json:"manual"`
+	State    string        `bson:"state" json:"state"`
+	Start    time.Time     `bson:"start" json:"start"`
+	Duration int64         `bson:"duration" json:"duration"`
+	Error    string        `bson:"error" json:"error"`
+	Trace    string        `bson:"trace" json:"trace"`
+}
+
+func (r *Run) Insert(db *database.Database) (err error) {
+	coll := db.TaskRuns()
+
+	_, err = coll.InsertOne(db, r)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetRuns(db *database.Database, name string) (
+	runs []*Run, err error) {
+
+	coll := db.TaskRuns()
+	runs = []*Run{}
+
+	cursor, err := coll.Find(
+		db,
+		&bson.M{
+			"task": name,
+		},
+		options.Find().
+			SetSort(bson.D{{"start", -1}}).
+			SetLimit(runsLimit),
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		run := &Run{}
+		err = cursor.Decode(run)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		runs = append(runs, run)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -58,7 +58,7 @@ func scheduleUnits(db *database.Database) (err error) {
 	}
 
 	for _, unt := range units {
-		if len(unt.Deployments) >= unt.Count {
+		if len(unt.Deployments) >= unt.GetCount() {
 			continue
 		}
 
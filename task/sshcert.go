@@ -0,0 +1,96 @@
+package task
+
+import (
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/authority"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/instance"
+	"github.com/pritunl/pritunl-cloud/sshcert"
+	"github.com/sirupsen/logrus"
+)
+
+var sshCertRenew = &Task{
+	Name:    "ssh_certificate_renew",
+	Version: 1,
+	Hours: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12,
+		13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23},
+	Minutes: []int{15},
+	Handler: sshCertRenewHandler,
+}
+
+func sshCertRenewHandler(db *database.Database) (err error) {
+	authrs, err := authority.GetAll(db, &bson.M{
+		"type":              authority.SshAuthority,
+		"host_certificates": true,
+	})
+	if err != nil {
+		return
+	}
+
+	orgCas := map[bson.ObjectID]*authority.Authority{}
+	orgIds := []bson.ObjectID{}
+	for _, authr := range authrs {
+		orgCas[authr.Organization] = authr
+		orgIds = append(orgIds, authr.Organization)
+	}
+
+	activeKeys := set.NewSet()
+
+	if len(orgIds) > 0 {
+		insts, e := instance.GetAll(db, &bson.M{
+			"organization": &bson.M{
+				"$in": orgIds,
+			},
+		})
+		if e != nil {
+			err = e
+			return
+		}
+
+		for _, inst := range insts {
+			authr := orgCas[inst.Organization]
+			if authr == nil || len(authr.GetPrincipals(inst.Roles)) == 0 {
+				continue
+			}
+			activeKeys.Add(inst.Id)
+
+			if !inst.IsActive() {
+				continue
+			}
+
+			_, e = authr.GetHostKey(db, inst.Id,
+				authority.HostPrincipals(inst.Name, inst.PublicIps,
+					inst.PublicIps6, inst.PrivateIps, inst.PrivateIps6))
+			if e != nil {
+				logrus.WithFields(logrus.Fields{
+					"instance":  inst.Id.Hex(),
+					"authority": authr.Id.Hex(),
+					"error":     e,
+				}).Error("task: Failed to renew ssh host certificate")
+			}
+		}
+	}
+
+	hostKeys, err := sshcert.GetAllHostKeys(db)
+	if err != nil {
+		return
+	}
+
+	for _, hostKey := range hostKeys {
+		if activeKeys.Contains(hostKey.Id) {
+			continue
+		}
+
+		err = sshcert.RemoveHostKey(db, hostKey.Id)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func init() {
+	register(sshCertRenew)
+}
@@ -0,0 +1,141 @@
+package task
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/mongo-go-driver/v2/mongo/options"
+	"github.com/pritunl/pritunl-cloud/database"
+)
+
+type State struct {
+	Id            string        `bson:"_id" json:"id"`
+	Disabled      bool          `bson:"disabled" json:"disabled"`
+	DisabledUntil time.Time     `bson:"disabled_until" json:"disabled_until"`
+	LastNode      bson.ObjectID `bson:"last_node" json:"last_node"`
+	LastState     string        `bson:"last_state" json:"last_state"`
+	LastDuration  int64         `bson:"last_duration" json:"last_duration"`
+	LastSuccess   time.Time     `bson:"last_success" json:"last_success"`
+	LastFailure   time.Time     `bson:"last_failure" json:"last_failure"`
+	LastError     string        `bson:"last_error" json:"last_error"`
+}
+
+func (s *State) IsDisabled() bool {
+	if !s.Disabled {
+		return false
+	}
+	return s.DisabledUntil.IsZero() || time.Now().Before(s.DisabledUntil)
+}
+
+func getState(db *database.Database, name string) (
+	state *State, err error) {
+
+	coll := db.TaskStates()
+	state = &State{}
+
+	err = coll.FindOneId(name, state)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			state = &State{
+				Id: name,
+			}
+			err = nil
+		}
+		return
+	}
+
+	return
+}
+
+func getStates(db *database.Database) (
+	states map[string]*State, err error) {
+
+	coll := db.TaskStates()
+	states = map[string]*State{}
+
+	cursor, err := coll.Find(db, &bson.M{})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		state := &State{}
+		err = cursor.Decode(state)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		states[state.Id] = state
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func setResult(db *database.Database, run *Run) (err error) {
+	coll := db.TaskStates()
+
+	fields := bson.M{
+		"last_node":     run.Node,
+		"last_state":    run.State,
+		"last_duration": run.Duration,
+	}
+
+	if run.State == Finished {
+		fields["last_success"] = run.Start
+	} else {
+		fields["last_failure"] = run.Start
+		fields["last_error"] = run.Error
+	}
+
+	_, err = coll.UpdateOne(
+		db,
+		&bson.M{
+			"_id": run.Task,
+		},
+		&bson.M{
+			"$set": fields,
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func setDisabled(db *database.Database, name string, disabled bool,
+	until time.Time) (err error) {
+
+	coll := db.TaskStates()
+
+	_, err = coll.UpdateOne(
+		db,
+		&bson.M{
+			"_id": name,
+		},
+		&bson.M{
+			"$set": &bson.M{
+				"disabled":       disabled,
+				"disabled_until": until,
+			},
+		},
+		options.UpdateOne().SetUpsert(true),
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
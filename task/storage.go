@@ -3,6 +3,7 @@ package task
 import (
 	"github.com/dropbox/godropbox/container/set"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/catalog"
 	"github.com/pritunl/pritunl-cloud/data"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/event"
@@ -41,6 +42,7 @@ func storageSyncHandler(db *database.Database) (err error) {
 	}
 
 	storeIds := set.NewSet()
+	storeIdsList := []bson.ObjectID{}
 	stores, err := storage.GetAll(db)
 	if err != nil {
 		return
@@ -48,6 +50,7 @@ func storageSyncHandler(db *database.Database) (err error) {
 
 	for _, store := range stores {
 		storeIds.Add(store.Id)
+		storeIdsList = append(storeIdsList, store.Id)
 
 		err = data.Sync(db, store)
 		if err != nil {
@@ -59,6 +62,11 @@ func storageSyncHandler(db *database.Database) (err error) {
 		}
 	}
 
+	err = catalog.RemoveStorages(db, storeIdsList)
+	if err != nil {
+		return
+	}
+
 	imgStoreIds.Subtract(storeIds)
 
 	remStoreIds := []bson.ObjectID{}
@@ -5,7 +5,9 @@ import (
 	"runtime/debug"
 	"time"
 
+	"github.com/dropbox/godropbox/errors"
 	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/node"
 	"github.com/pritunl/pritunl-cloud/utils"
 	"github.com/pritunl/pritunl-cloud/version"
@@ -28,6 +30,7 @@ type Task struct {
 	Local      bool
 	DebugNodes []string
 	timestamp  time.Time
+	recorded   time.Time
 }
 
 func (t *Task) scheduled(hour, min int) bool {
@@ -43,7 +46,74 @@ func (t *Task) scheduled(hour, min int) bool {
 	return false
 }
 
-func (t *Task) runShared(db *database.Database, now time.Time) {
+func (t *Task) handle(db *database.Database, manual bool) (err error) {
+	run := &Run{
+		Task:   t.Name,
+		Node:   node.Self.Id,
+		Local:  t.Local,
+		Manual: manual,
+		State:  Running,
+		Start:  time.Now(),
+	}
+
+	defer func() {
+		panc := recover()
+		if panc != nil {
+			run.State = Panicked
+			run.Error = fmt.Sprintf("%v", panc)
+			run.Trace = string(debug.Stack())
+
+			logrus.WithFields(logrus.Fields{
+				"task":  t.Name,
+				"trace": run.Trace,
+				"panic": panc,
+			}).Error("task: Panic in task handler")
+
+			err = &errortypes.UnknownError{
+				errors.Newf("task: Panic in task handler '%v'", panc),
+			}
+		}
+
+		run.Duration = time.Since(run.Start).Milliseconds()
+
+		if run.State == Finished && t.Seconds != 0 &&
+			time.Since(t.recorded) < recordInterval {
+
+			return
+		}
+		t.recorded = time.Now()
+
+		e := run.Insert(db)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"task":  t.Name,
+				"error": e,
+			}).Error("task: Failed to record task run")
+		}
+
+		e = setResult(db, run)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"task":  t.Name,
+				"error": e,
+			}).Error("task: Failed to update task state")
+		}
+	}()
+
+	err = t.Handler(db)
+	if err != nil {
+		run.State = Failed
+		run.Error = err.Error()
+	} else {
+		run.State = Finished
+	}
+
+	return
+}
+
+func (t *Task) runShared(db *database.Database, now time.Time,
+	manual bool) {
+
 	defer func() {
 		panc := recover()
 		if panc != nil {
@@ -54,27 +124,34 @@ func (t *Task) runShared(db *database.Database, now time.Time) {
 		}
 	}()
 
-	if t.Seconds == 0 {
-		time.Sleep(time.Duration(utils.RandInt(0, 1000)) * time.Millisecond)
+	id := ""
+	if manual {
+		id = fmt.Sprintf("%s-manual-%d", t.Name, now.Unix())
 	} else {
-		time.Sleep(time.Duration(utils.RandInt(0, 300)) * time.Millisecond)
-	}
+		if t.Seconds == 0 {
+			time.Sleep(
+				time.Duration(utils.RandInt(0, 1000)) * time.Millisecond)
+		} else {
+			time.Sleep(
+				time.Duration(utils.RandInt(0, 300)) * time.Millisecond)
+		}
 
-	if t.DebugNodes != nil {
-		matched := false
-		for _, ndeName := range t.DebugNodes {
-			if node.Self.Name == ndeName {
-				matched = true
+		if t.DebugNodes != nil {
+			matched := false
+			for _, ndeName := range t.DebugNodes {
+				if node.Self.Name == ndeName {
+					matched = true
+				}
+			}
+			if !matched {
+				return
 			}
 		}
-		if !matched {
-			return
-		}
-	}
 
-	id := fmt.Sprintf("%s-%d", t.Name, now.Unix()-int64(now.Second()))
-	if t.Seconds != 0 {
-		id += fmt.Sprintf("-%d", GetBlock(now, t.Seconds))
+		id = fmt.Sprintf("%s-%d", t.Name, now.Unix()-int64(now.Second()))
+		if t.Seconds != 0 {
+			id += fmt.Sprintf("-%d", GetBlock(now, t.Seconds))
+		}
 	}
 
 	job := &Job{
@@ -99,7 +176,7 @@ func (t *Task) runShared(db *database.Database, now time.Time) {
 		return
 	}
 
-	err = t.Handler(db)
+	err = t.handle(db, manual)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"task":  t.Name,
@@ -140,7 +217,7 @@ func (t *Task) runLocal(db *database.Database, now time.Time) {
 		id += fmt.Sprintf("-%d", GetBlock(now, t.Seconds))
 	}
 
-	err := t.Handler(db)
+	err := t.handle(db, false)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"task":  t.Name,
@@ -174,6 +251,19 @@ func (t *Task) run(now time.Time) {
 			}
 		}
 
+		state, err := getState(db, t.Name)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"task":  t.Name,
+				"error": err,
+			}).Error("task: Failed to get task state")
+			return
+		}
+
+		if state.IsDisabled() {
+			return
+		}
+
 		curTimestamp := t.timestamp
 		if !curTimestamp.IsZero() {
 			if time.Since(curTimestamp) > 10*time.Minute {
@@ -192,11 +282,32 @@ func (t *Task) run(now time.Time) {
 		if t.Local {
 			t.runLocal(db, now)
 		} else {
-			t.runShared(db, now)
+			t.runShared(db, now, false)
 		}
 	}()
 }
 
+func (t *Task) trigger() {
+	go func() {
+		db := database.GetDatabase()
+		defer db.Close()
+
+		if !t.timestamp.IsZero() {
+			return
+		}
+		t.timestamp = time.Now()
+		defer func() {
+			t.timestamp = time.Time{}
+		}()
+
+		logrus.WithFields(logrus.Fields{
+			"task": t.Name,
+		}).Info("task: Manually triggered task")
+
+		t.runShared(db, time.Now(), true)
+	}()
+}
+
 func runScheduler() {
 	now := time.Now()
 	curHour := now.Hour()
@@ -0,0 +1,147 @@
+package task
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+)
+
+type Info struct {
+	Name          string        `json:"name"`
+	Version       int           `json:"version"`
+	Hours         []int         `json:"hours"`
+	Minutes       []int         `json:"minutes"`
+	Seconds       int           `json:"seconds"`
+	RunOnStart    bool          `json:"run_on_start"`
+	Local         bool          `json:"local"`
+	Disabled      bool          `json:"disabled"`
+	DisabledUntil time.Time     `json:"disabled_until"`
+	LastNode      bson.ObjectID `json:"last_node"`
+	LastState     string        `json:"last_state"`
+	LastDuration  int64         `json:"last_duration"`
+	LastSuccess   time.Time     `json:"last_success"`
+	LastFailure   time.Time     `json:"last_failure"`
+	LastError     string        `json:"last_error"`
+}
+
+func get(name string) *Task {
+	for _, task := range registry {
+		if task.Name == name {
+			return task
+		}
+	}
+	return nil
+}
+
+func Exists(name string) bool {
+	return get(name) != nil
+}
+
+func GetAll(db *database.Database) (infos []*Info, err error) {
+	infos = []*Info{}
+
+	states, err := getStates(db)
+	if err != nil {
+		return
+	}
+
+	for _, task := range registry {
+		info := &Info{
+			Name:       task.Name,
+			Version:    task.Version,
+			Hours:      task.Hours,
+			Minutes:    task.Minutes,
+			Seconds:    int(task.Seconds.Seconds()),
+			RunOnStart: task.RunOnStart,
+			Local:      task.Local,
+		}
+
+		state := states[task.Name]
+		if state != nil {
+			info.Disabled = state.IsDisabled()
+			if info.Disabled {
+				info.DisabledUntil = state.DisabledUntil
+			}
+			info.LastNode = state.LastNode
+			info.LastState = state.LastState
+			info.LastDuration = state.LastDuration
+			info.LastSuccess = state.LastSuccess
+			info.LastFailure = state.LastFailure
+			info.LastError = state.LastError
+		}
+
+		infos = append(infos, info)
+	}
+
+	return
+}
+
+// Trigger runs a shared task immediately on the local node
+func Trigger(db *database.Database, name string) (
+	errData *errortypes.ErrorData, err error) {
+
+	task := get(name)
+	if task == nil {
+		errData = &errortypes.ErrorData{
+			Error:   "task_not_found",
+			Message: "Task not found",
+		}
+		return
+	}
+
+	if task.Local {
+		errData = &errortypes.ErrorData{
+			Error:   "task_local",
+			Message: "Cannot trigger a local task",
+		}
+		return
+	}
+
+	if !task.timestamp.IsZero() {
+		errData = &errortypes.ErrorData{
+			Error:   "task_running",
+			Message: "Task is already running",
+		}
+		return
+	}
+
+	task.trigger()
+
+	return
+}
+
+// SetDisabled disables the task on all nodes until the given duration
+// elapses, a zero duration disables the task until enabled
+func SetDisabled(db *database.Database, name string, disabled bool,
+	duration time.Duration) (errData *errortypes.ErrorData, err error) {
+
+	if get(name) == nil {
+		errData = &errortypes.ErrorData{
+			Error:   "task_not_found",
+			Message: "Task not found",
+		}
+		return
+	}
+
+	if duration < 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "task_disable_duration_invalid",
+			Message: "Task disable duration invalid",
+		}
+		return
+	}
+
+	until := time.Time{}
+	if disabled && duration > 0 {
+		until = time.Now().Add(duration)
+	}
+
+	err = setDisabled(db, name, disabled, until)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,402 @@
+package telemetry
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/tools/commander"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	aptInstReg = regexp.MustCompile(
+		`^Inst (\S+) \[([^\]]+)\] \((\S+) ([^\[]*)`)
+	aptChangelogReg = regexp.MustCompile(
+		`^(\S+) \(([^)]+)\) [^;]*;.*urgency=(\S+)`)
+	aptIdReg = regexp.MustCompile(`[^a-zA-Z0-9-_]`)
+)
+
+type aptUpgrade struct {
+	Package   string
+	Source    string
+	Current   string
+	Candidate string
+}
+
+func aptRelease() (distro, codename string) {
+	file, err := os.Open("/etc/os-release")
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, val, ok := strings.Cut(scanner.Text(), "=")
+		if !ok {
+			continue
+		}
+		val = strings.Trim(val, `"'`)
+
+		switch key {
+		case "ID":
+			distro = strings.ToLower(val)
+		case "VERSION_CODENAME":
+			codename = strings.ToLower(val)
+		}
+	}
+
+	return
+}
+
+func aptSecurityUpgrades() (upgrades []*aptUpgrade, err error) {
+	resp, err := commander.Exec(&commander.Opt{
+		Name: "apt-get",
+		Args: []string{
+			"-s",
+			"-o", "Debug::NoLocking=1",
+			"dist-upgrade",
+		},
+		Timeout: 90 * time.Second,
+		PipeOut: true,
+		PipeErr: true,
+	})
+	if err != nil {
+		if resp != nil {
+			logrus.WithFields(
+				resp.Map(),
+			).Error("telemetry: Failed to get apt upgrade list")
+		}
+		return
+	}
+
+	upgrades = []*aptUpgrade{}
+	for _, line := range strings.Split(string(resp.Output), "\n") {
+		matches := aptInstReg.FindStringSubmatch(strings.TrimSpace(line))
+		if len(matches) < 5 {
+			continue
+		}
+
+		origins := matches[4]
+		if !strings.Contains(origins, "-security") &&
+			!strings.Contains(origins, "Debian-Security") {
+
+			continue
+		}
+
+		upgrades = append(upgrades, &aptUpgrade{
+			Package:   matches[1],
+			Current:   matches[2],
+			Candidate: matches[3],
+		})
+	}
+
+	return
+}
+
+func aptSources(upgrades []*aptUpgrade) {
+	if len(upgrades) == 0 {
+		return
+	}
+
+	args := []string{
+		"-W",
+		"-f=${Package}\t${Source}\n",
+	}
+	for _, upgrade := range upgrades {
+		args = append(args, upgrade.Package)
+	}
+
+	resp, err := commander.Exec(&commander.Opt{
+		Name:    "dpkg-query",
+		Args:    args,
+		Timeout: 30 * time.Second,
+		PipeOut: true,
+		PipeErr: true,
+	})
+	if err != nil && resp == nil {
+		return
+	}
+
+	sources := map[string]string{}
+	for _, line := range strings.Split(string(resp.Output), "\n") {
+		pkg, src, ok := strings.Cut(line, "\t")
+		if !ok {
+			continue
+		}
+
+		src, _, _ = strings.Cut(strings.TrimSpace(src), " ")
+		if src != "" {
+			sources[pkg] = src
+		}
+	}
+
+	for _, upgrade := range upgrades {
+		upgrade.Source = sources[upgrade.Package]
+		if upgrade.Source == "" {
+			upgrade.Source = upgrade.Package
+		}
+	}
+}
+
+func parseAptChangelog(output, current string) (cves []string,
+	severity, description string) {
+
+	cveSet := map[string]bool{}
+	entry := 0
+	descLines := []string{}
+	severity = moderate
+	topSeverity := ""
+
+	for _, line := range strings.Split(output, "\n") {
+		matches := aptChangelogReg.FindStringSubmatch(line)
+		if len(matches) > 3 {
+			if matches[2] == current {
+				break
+			}
+
+			entry += 1
+			sev := parseUrgency(matches[3])
+			if topSeverity == "" || sev == critical ||
+				(sev == important && topSeverity == moderate) {
+
+				topSeverity = sev
+			}
+			continue
+		}
+
+		if entry == 0 {
+			continue
+		}
+
+		for _, cve := range cveReg.FindAllString(line, -1) {
+			if !cveSet[cve] {
+				cveSet[cve] = true
+				cves = append(cves, cve)
+			}
+		}
+
+		if entry == 1 {
+			line = strings.TrimSpace(line)
+			if line != "" && !strings.HasPrefix(line, "-- ") {
+				descLines = append(descLines, line)
+			}
+		}
+	}
+
+	if topSeverity != "" {
+		severity = topSeverity
+	}
+	sort.Strings(cves)
+	description = strings.Join(descLines, "\n")
+
+	return
+}
+
+// aptCachedChangelog reads the changelog from the candidate package in
+// the local apt archive cache
+func aptCachedChangelog(upgrade *aptUpgrade) (output string) {
+	matches, _ := filepath.Glob(filepath.Join(aptArchivesPath, fmt.Sprintf(
+		"%s_%s_*.deb", upgrade.Package,
+		strings.ReplaceAll(upgrade.Candidate, ":", "%3a"))))
+	if len(matches) == 0 {
+		return
+	}
+
+	cmd := exec.Command("dpkg-deb", "--fsys-tarfile", matches[0])
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return
+	}
+
+	err = cmd.Start()
+	if err != nil {
+		return
+	}
+	defer func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	}()
+
+	names := set.NewSet(
+		"./usr/share/doc/"+upgrade.Package+"/changelog.Debian.gz",
+		"./usr/share/doc/"+upgrade.Package+"/changelog.gz",
+	)
+
+	reader := tar.NewReader(stdout)
+	for {
+		header, e := reader.Next()
+		if e != nil {
+			return
+		}
+
+		if !names.Contains(header.Name) {
+			continue
+		}
+
+		gzReader, e := gzip.NewReader(reader)
+		if e != nil {
+			return
+		}
+
+		data, e := io.ReadAll(io.LimitReader(gzReader, aptChangelogLimit))
+		if e != nil {
+			return
+		}
+
+		output = string(data)
+		return
+	}
+}
+
+// aptChangelogs returns the changelog for each source package, changelogs
+// not in the local cache are downloaded with a single apt request
+func aptChangelogs(upgrades []*aptUpgrade) (outputs map[string]string) {
+	outputs = map[string]string{}
+	sources := set.NewSet()
+	pkgs := []string{}
+
+	for _, upgrade := range upgrades {
+		if sources.Contains(upgrade.Source) {
+			continue
+		}
+		sources.Add(upgrade.Source)
+
+		output := aptCachedChangelog(upgrade)
+		if output != "" {
+			outputs[upgrade.Source] = output
+			continue
+		}
+
+		pkgs = append(pkgs, upgrade.Package)
+	}
+
+	if len(pkgs) == 0 {
+		return
+	}
+
+	resp, err := commander.Exec(&commander.Opt{
+		Name:    "apt-get",
+		Args:    append([]string{"changelog"}, pkgs...),
+		Timeout: 300 * time.Second,
+		PipeOut: true,
+		PipeErr: true,
+	})
+	if err != nil {
+		if resp == nil {
+			return
+		}
+		logrus.WithFields(
+			resp.Map(),
+		).Warn("telemetry: Failed to get apt changelogs")
+	}
+
+	cur := ""
+	lines := map[string][]string{}
+	for _, line := range strings.Split(string(resp.Output), "\n") {
+		matches := aptChangelogReg.FindStringSubmatch(line)
+		if len(matches) > 3 && matches[1] != cur &&
+			lines[matches[1]] == nil && sources.Contains(matches[1]) {
+
+			cur = matches[1]
+		}
+
+		if cur != "" {
+			lines[cur] = append(lines[cur], line)
+		}
+	}
+
+	for src, srcLines := range lines {
+		if outputs[src] == "" {
+			outputs[src] = strings.Join(srcLines, "\n")
+		}
+	}
+
+	return
+}
+
+func aptUpdates() (updates []*Update, err error) {
+	distro, codename := aptRelease()
+
+	typ := ""
+	switch distro {
+	case Debian:
+		typ = Debian
+	case Ubuntu:
+		typ = Ubuntu
+	default:
+		return
+	}
+
+	upgrades, err := aptSecurityUpgrades()
+	if err != nil {
+		return
+	}
+
+	aptSources(upgrades)
+
+	sort.Slice(upgrades, func(i, j int) bool {
+		if upgrades[i].Source != upgrades[j].Source {
+			return upgrades[i].Source < upgrades[j].Source
+		}
+		return upgrades[i].Package < upgrades[j].Package
+	})
+
+	updates = []*Update{}
+	updatesMap := map[string]*Update{}
+	updateUpgrades := []*aptUpgrade{}
+
+	for _, upgrade := range upgrades {
+		key := upgrade.Source + "|" + upgrade.Candidate
+		pkgRef := upgrade.Package + "@" + upgrade.Candidate
+
+		updt := updatesMap[key]
+		if updt != nil {
+			updt.Packages = append(updt.Packages, pkgRef)
+			continue
+		}
+
+		if len(updates) >= aptUpdateLimit {
+			continue
+		}
+
+		updt = &Update{
+			Id: strings.ToUpper(typ) + "-" + upgrade.Source + ":" +
+				aptIdReg.ReplaceAllString(upgrade.Candidate, "_"),
+			Type:     typ,
+			Packages: []string{pkgRef},
+			Release:  codename,
+			Source:   upgrade.Source,
+			Version:  upgrade.Candidate,
+		}
+
+		updatesMap[key] = updt
+		updates = append(updates, updt)
+		updateUpgrades = append(updateUpgrades, upgrade)
+	}
+
+	changelogs := aptChangelogs(updateUpgrades)
+
+	for i, upgrade := range updateUpgrades {
+		updt := updates[i]
+
+		updt.Vulnerabilities, updt.Severity, updt.Description =
+			parseAptChangelog(changelogs[upgrade.Source], upgrade.Current)
+		if updt.Vulnerabilities == nil {
+			updt.Vulnerabilities = []string{}
+		}
+	}
+
+	return
+}
@@ -1,5 +1,9 @@
 package telemetry
 
+import (
+	"time"
+)
+
 const (
 	Host      = "host"
 	Instance  = "instance"
@@ -7,11 +11,20 @@ const (
 
 	RedHat  = "rhel"
 	FreeBsd = "freebsd"
+	Debian  = "debian"
+	Ubuntu  = "ubuntu"
 
 	moderate  = "moderate"
 	important = "important"
 	critical  = "critical"
 
+	aptUpdateLimit    = 50
+	aptChangelogLimit = 4194304
+	aptArchivesPath   = "/var/cache/apt/archives"
+
+	CustomMetricsPath = "/var/lib/pritunl-cloud-agent/metrics"
+	CustomMetricsTtl  = 5 * time.Minute
+
 	Low      = 1
 	Medium   = 2
 	High     = 3
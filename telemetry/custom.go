@@ -0,0 +1,89 @@
+package telemetry
+
+import (
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/metric"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+func SetCustomMetric(name, value string) (err error) {
+	name = metric.FilterCustomName(name)
+	if name == "" {
+		err = &errortypes.ParseError{
+			errors.New("telemetry: Invalid custom metric name"),
+		}
+		return
+	}
+
+	val, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "telemetry: Invalid custom metric value"),
+		}
+		return
+	}
+
+	err = utils.ExistsMkdir(CustomMetricsPath, 0755)
+	if err != nil {
+		return
+	}
+
+	err = utils.Write(path.Join(CustomMetricsPath, name),
+		strconv.FormatFloat(val, 'f', -1, 64), 0644)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func getCustomMetrics() (values map[string]float64) {
+	entries, err := os.ReadDir(CustomMetricsPath)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		name := metric.FilterCustomName(entry.Name())
+		if name == "" || name != entry.Name() {
+			continue
+		}
+
+		info, e := entry.Info()
+		if e != nil || time.Since(info.ModTime()) > CustomMetricsTtl {
+			continue
+		}
+
+		data, e := utils.Read(path.Join(CustomMetricsPath, name))
+		if e != nil {
+			continue
+		}
+
+		val, e := strconv.ParseFloat(strings.TrimSpace(data), 64)
+		if e != nil {
+			continue
+		}
+
+		if values == nil {
+			values = map[string]float64{}
+		}
+		values[name] = val
+
+		if len(values) >= metric.CustomLimit {
+			break
+		}
+	}
+
+	return
+}
@@ -91,6 +91,16 @@ func MetricsRefresh() (sample *metric.Sample, err error) {
 		}
 	}
 
+	if Mode == Instance {
+		values := getCustomMetrics()
+		if len(values) > 0 {
+			sample.Custom = &metric.Custom{
+				Timestamp: timestamp,
+				Values:    values,
+			}
+		}
+	}
+
 	if Mode != Instance {
 		var filter set.Set
 		if Mode == Namespace {
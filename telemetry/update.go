@@ -56,6 +56,9 @@ type Update struct {
 	Severity        string   `bson:"severity" json:"severity"`
 	Description     string   `bson:"description" json:"description"`
 	Packages        []string `bson:"packages" json:"packages"`
+	Release         string   `bson:"release,omitempty" json:"release,omitempty"`
+	Source          string   `bson:"source,omitempty" json:"source,omitempty"`
+	Version         string   `bson:"version,omitempty" json:"version,omitempty"`
 	Score           int      `bson:"-" json:"score"`
 }
 
@@ -64,7 +67,9 @@ func (u *Update) Validate(db *database.Database) (
 
 	u.Id = utils.FilterId(u.Id)
 
-	if u.Type != RedHat && u.Type != FreeBsd {
+	if u.Type != RedHat && u.Type != FreeBsd &&
+		u.Type != Debian && u.Type != Ubuntu {
+
 		errData = &errortypes.ErrorData{
 			Error:   "invalid_type",
 			Message: "Invalid update type",
@@ -87,6 +92,10 @@ func (u *Update) Validate(db *database.Database) (
 		u.Packages[i] = utils.FilterStr(pkg, 128)
 	}
 
+	u.Release = utils.FilterStr(u.Release, 64)
+	u.Source = utils.FilterStr(u.Source, 128)
+	u.Version = utils.FilterStr(u.Version, 128)
+
 	return
 }
 
@@ -361,6 +370,10 @@ func UpdatesRefresh() (updates []*Update, err error) {
 		updates, err = pkgUpdates()
 		return
 	}
+	if IsApt() {
+		updates, err = aptUpdates()
+		return
+	}
 
 	return
 }
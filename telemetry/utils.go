@@ -19,6 +19,15 @@ func IsDnf() bool {
 	return err == nil
 }
 
+func IsApt() bool {
+	if runtime.GOOS != "linux" {
+		return false
+	}
+
+	_, err := os.Stat("/usr/bin/apt-get")
+	return err == nil
+}
+
 func IsPkg() bool {
 	if runtime.GOOS != "freebsd" {
 		return false
@@ -85,6 +94,16 @@ func parseSeverity(value string) string {
 	return ""
 }
 
+func parseUrgency(value string) string {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "critical", "emergency":
+		return critical
+	case "high":
+		return important
+	}
+	return moderate
+}
+
 func isSeparatorLine(line string) bool {
 	trimmed := strings.TrimSpace(line)
 	if len(trimmed) == 0 {
@@ -0,0 +1,177 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/disk"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/settings"
+)
+
+var client = &http.Client{
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: dialTimeout,
+		}).DialContext,
+		ResponseHeaderTimeout: headerTimeout,
+	},
+}
+
+type progressReader struct {
+	reader   io.Reader
+	size     int64
+	read     int64
+	progress func(percent int)
+}
+
+func (p *progressReader) Read(buf []byte) (n int, err error) {
+	n, err = p.reader.Read(buf)
+	p.read += int64(n)
+	if p.progress != nil && p.size > 0 {
+		p.progress(int(p.read * 100 / p.size))
+	}
+	return
+}
+
+func getHost(nde *node.Node) (host string, err error) {
+	if !nde.IsOnline() {
+		err = &errortypes.NotFoundError{
+			errors.New("transfer: Target node is offline"),
+		}
+		return
+	}
+
+	host = nde.PrivateIps[nde.DefaultInterface]
+	if host == "" {
+		for _, privIp := range nde.PrivateIps {
+			if privIp != "" {
+				host = privIp
+				break
+			}
+		}
+	}
+
+	if host == "" {
+		err = &errortypes.NotFoundError{
+			errors.New("transfer: Target node missing private IP"),
+		}
+		return
+	}
+
+	return
+}
+
+func getChecksum(pth string) (checksum string, err error) {
+	file, err := os.Open(pth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "transfer: Failed to open disk"),
+		}
+		return
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "transfer: Failed to read disk"),
+		}
+		return
+	}
+
+	checksum = hex.EncodeToString(hash.Sum(nil))
+	return
+}
+
+// Send streams the qcow2 disk file to the transfer target node over the
+// internal network, the target verifies the checksum before storing
+func Send(db *database.Database, dsk *disk.Disk, pth string,
+	progress func(percent int)) (err error) {
+
+	nde, err := node.Get(db, dsk.TargetNode)
+	if err != nil {
+		return
+	}
+
+	host, err := getHost(nde)
+	if err != nil {
+		return
+	}
+
+	checksum, err := getChecksum(pth)
+	if err != nil {
+		return
+	}
+
+	file, err := os.Open(pth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "transfer: Failed to open disk"),
+		}
+		return
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "transfer: Failed to stat disk"),
+		}
+		return
+	}
+
+	reader := &progressReader{
+		reader:   file,
+		size:     stat.Size(),
+		progress: progress,
+	}
+
+	req, err := http.NewRequest(
+		http.MethodPut,
+		fmt.Sprintf("http://%s/disk/%s", net.JoinHostPort(host,
+			fmt.Sprintf("%d", settings.Hypervisor.DiskTransferPort)),
+			dsk.Id.Hex()),
+		reader,
+	)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "transfer: Failed to create request"),
+		}
+		return
+	}
+
+	req.ContentLength = stat.Size()
+	req.Header.Set(tokenHeader, dsk.TransferToken)
+	req.Header.Set(checksumHeader, checksum)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "transfer: Transfer request failed"),
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		err = &errortypes.RequestError{
+			errors.Newf("transfer: Target node error %d '%s'",
+				resp.StatusCode, strings.TrimSpace(string(body))),
+		}
+		return
+	}
+
+	return
+}
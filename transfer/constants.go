@@ -0,0 +1,12 @@
+package transfer
+
+import (
+	"time"
+)
+
+const (
+	tokenHeader    = "Pritunl-Transfer-Token"
+	checksumHeader = "Pritunl-Transfer-Checksum"
+	dialTimeout    = 10 * time.Second
+	headerTimeout  = 30 * time.Second
+)
@@ -0,0 +1,198 @@
+package transfer
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/disk"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/node"
+	"github.com/pritunl/pritunl-cloud/paths"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/pritunl/pritunl-cloud/utils"
+	"github.com/sirupsen/logrus"
+)
+
+func authorize(diskId bson.ObjectID, token string) (
+	dsk *disk.Disk, err error) {
+
+	db := database.GetDatabase()
+	defer db.Close()
+
+	dsk, err = disk.Get(db, diskId)
+	if err != nil {
+		dsk = nil
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+		}
+		return
+	}
+
+	if !dsk.IsTransfer() || dsk.TargetNode != node.Self.Id ||
+		dsk.TargetDisk.IsZero() || dsk.TransferToken == "" ||
+		subtle.ConstantTimeCompare(
+			[]byte(dsk.TransferToken), []byte(token)) != 1 {
+
+		dsk = nil
+		return
+	}
+
+	return
+}
+
+func receive(dsk *disk.Disk, r *http.Request) (
+	status int, err error) {
+
+	status = http.StatusInternalServerError
+
+	err = utils.ExistsMkdir(paths.GetDisksPath(), 0755)
+	if err != nil {
+		return
+	}
+
+	dskPth := paths.GetDiskPath(dsk.TargetDisk)
+	tempPth := dskPth + ".partial"
+
+	exists, err := utils.Exists(dskPth)
+	if err != nil {
+		return
+	}
+
+	if exists {
+		status = http.StatusConflict
+		err = &errortypes.WriteError{
+			errors.New("transfer: Target disk already exists"),
+		}
+		return
+	}
+
+	file, err := os.OpenFile(tempPth,
+		os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "transfer: Failed to create disk"),
+		}
+		return
+	}
+	defer utils.Remove(tempPth)
+
+	hash := sha256.New()
+	written, err := io.Copy(io.MultiWriter(file, hash), r.Body)
+	file.Close()
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "transfer: Failed to write disk"),
+		}
+		return
+	}
+
+	if written != r.ContentLength {
+		status = http.StatusBadRequest
+		err = &errortypes.WriteError{
+			errors.New("transfer: Incomplete disk transfer"),
+		}
+		return
+	}
+
+	if hex.EncodeToString(hash.Sum(nil)) != r.Header.Get(checksumHeader) {
+		status = http.StatusBadRequest
+		err = &errortypes.VerificationError{
+			errors.New("transfer: Disk checksum mismatch"),
+		}
+		return
+	}
+
+	err = os.Rename(tempPth, dskPth)
+	if err != nil {
+		err = &errortypes.WriteError{
+			errors.Wrap(err, "transfer: Failed to move disk"),
+		}
+		return
+	}
+
+	status = http.StatusOK
+	return
+}
+
+func handle(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	diskId, err := bson.ObjectIDFromHex(
+		strings.TrimPrefix(r.URL.Path, "/disk/"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	dsk, err := authorize(diskId, r.Header.Get(tokenHeader))
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"remote": r.RemoteAddr,
+			"error":  err,
+		}).Error("transfer: Failed to authorize transfer request")
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if dsk == nil {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"remote":      r.RemoteAddr,
+		"disk_id":     dsk.Id.Hex(),
+		"target_disk": dsk.TargetDisk.Hex(),
+	}).Info("transfer: Receiving disk transfer")
+
+	status, err := receive(dsk, r)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"remote":  r.RemoteAddr,
+			"disk_id": dsk.Id.Hex(),
+			"error":   err,
+		}).Error("transfer: Failed to receive disk")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(err.Error()))
+		return
+	}
+
+	w.WriteHeader(status)
+}
+
+// Serve runs the server for receiving disk transfers from other nodes
+func Serve() (err error) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/disk/", handle)
+
+	server := &http.Server{
+		Addr: fmt.Sprintf(
+			":%d", settings.Hypervisor.DiskTransferPort),
+		Handler:           mux,
+		ReadHeaderTimeout: headerTimeout,
+		IdleTimeout:       1 * time.Minute,
+	}
+
+	err = server.ListenAndServe()
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "transfer: Transfer server error"),
+		}
+		return
+	}
+
+	return
+}
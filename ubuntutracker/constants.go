@@ -0,0 +1,6 @@
+package ubuntutracker
+
+const (
+	UbuntuOval = "https://security-metadata.canonical.com/oval/" +
+		"com.ubuntu.%s.cve.oval.xml.bz2"
+)
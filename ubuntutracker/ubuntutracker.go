@@ -0,0 +1,451 @@
+package ubuntutracker
+
+import (
+	"compress/bzip2"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/debtracker"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/settings"
+)
+
+var (
+	trackerClient = &http.Client{
+		Timeout: 300 * time.Second,
+	}
+	releaseReg = regexp.MustCompile(`^[a-z]+$`)
+	cache      = map[string]*cacheEntry{}
+	cacheLock  sync.Mutex
+)
+
+type cacheEntry struct {
+	tracker   *Tracker
+	timestamp time.Time
+}
+
+type ovalReference struct {
+	Source string `xml:"source,attr"`
+	RefId  string `xml:"ref_id,attr"`
+}
+
+type ovalCriterion struct {
+	TestRef string `xml:"test_ref,attr"`
+}
+
+type ovalCriteria struct {
+	Criteria   []*ovalCriteria  `xml:"criteria"`
+	Criterions []*ovalCriterion `xml:"criterion"`
+}
+
+type ovalDefinition struct {
+	Description string           `xml:"metadata>description"`
+	References  []*ovalReference `xml:"metadata>reference"`
+	Severity    string           `xml:"metadata>advisory>severity"`
+	Criteria    *ovalCriteria    `xml:"criteria"`
+}
+
+type ovalRef struct {
+	Ref string `xml:"object_ref,attr"`
+}
+
+type ovalStateRef struct {
+	Ref string `xml:"state_ref,attr"`
+}
+
+type ovalTest struct {
+	Id     string        `xml:"id,attr"`
+	Object *ovalRef      `xml:"object"`
+	State  *ovalStateRef `xml:"state"`
+}
+
+type ovalName struct {
+	VarRef string `xml:"var_ref,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type ovalObject struct {
+	Id   string    `xml:"id,attr"`
+	Name *ovalName `xml:"name"`
+}
+
+type ovalEvr struct {
+	Operation string `xml:"operation,attr"`
+	Value     string `xml:",chardata"`
+}
+
+type ovalState struct {
+	Id  string   `xml:"id,attr"`
+	Evr *ovalEvr `xml:"evr"`
+}
+
+type ovalVariable struct {
+	Id     string   `xml:"id,attr"`
+	Values []string `xml:"value"`
+}
+
+type ovalData struct {
+	definitions []*ovalDefinition
+	tests       map[string]*ovalTest
+	objects     map[string]*ovalObject
+	states      map[string]*ovalState
+	variables   map[string]*ovalVariable
+}
+
+// Tracker holds the fixed package versions for each CVE in an Ubuntu
+// release, packages are binary package names from the release OVAL data
+type Tracker struct {
+	Release string
+	Entries map[string]*debtracker.Entry
+	Fixed   map[string]map[string]string
+}
+
+func normalizeVersion(version string) string {
+	return strings.TrimPrefix(strings.TrimSpace(version), "0:")
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case "critical":
+		return 4
+	case "high":
+		return 3
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	}
+	return 0
+}
+
+func (t *Tracker) Entry(cve string) *debtracker.Entry {
+	if t == nil {
+		return nil
+	}
+
+	return t.Entries[cve]
+}
+
+// Match returns the CVEs fixed by the update version of any of the
+// packages, packages are formatted as name@version
+func (t *Tracker) Match(packages []string, version string, cves []string) (
+	matched []string, urgency string) {
+
+	matchedSet := map[string]bool{}
+	matched = []string{}
+
+	for _, cve := range cves {
+		if !matchedSet[cve] {
+			matchedSet[cve] = true
+			matched = append(matched, cve)
+		}
+	}
+
+	if t != nil && version != "" {
+		version = normalizeVersion(version)
+
+		for _, pkg := range packages {
+			pkgName, _, _ := strings.Cut(pkg, "@")
+
+			for cve, fixed := range t.Fixed[pkgName] {
+				if fixed != version || matchedSet[cve] {
+					continue
+				}
+
+				matchedSet[cve] = true
+				matched = append(matched, cve)
+			}
+		}
+	}
+
+	for _, cve := range matched {
+		entry := t.Entry(cve)
+		if entry == nil {
+			continue
+		}
+
+		rel := entry.Releases[t.Release]
+		if rel == nil {
+			continue
+		}
+
+		if severityRank(rel.Urgency) > severityRank(urgency) {
+			urgency = rel.Urgency
+		}
+	}
+
+	sort.Strings(matched)
+
+	return
+}
+
+func (o *ovalData) testRefs(criteria *ovalCriteria, refs []string) []string {
+	if criteria == nil {
+		return refs
+	}
+
+	for _, criterion := range criteria.Criterions {
+		refs = append(refs, criterion.TestRef)
+	}
+	for _, child := range criteria.Criteria {
+		refs = o.testRefs(child, refs)
+	}
+
+	return refs
+}
+
+func (o *ovalData) packages(test *ovalTest) (names []string) {
+	if test.Object == nil {
+		return
+	}
+
+	obj := o.objects[test.Object.Ref]
+	if obj == nil || obj.Name == nil {
+		return
+	}
+
+	if obj.Name.VarRef == "" {
+		name := strings.TrimSpace(obj.Name.Value)
+		if name != "" {
+			names = append(names, name)
+		}
+		return
+	}
+
+	vr := o.variables[obj.Name.VarRef]
+	if vr == nil {
+		return
+	}
+
+	for _, name := range vr.Values {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return
+}
+
+func (o *ovalData) tracker(release string) (trkr *Tracker) {
+	trkr = &Tracker{
+		Release: release,
+		Entries: map[string]*debtracker.Entry{},
+		Fixed:   map[string]map[string]string{},
+	}
+
+	for _, def := range o.definitions {
+		cves := []string{}
+		for _, ref := range def.References {
+			if ref.Source == "CVE" && ref.RefId != "" {
+				cves = append(cves, ref.RefId)
+			}
+		}
+		if len(cves) == 0 {
+			continue
+		}
+
+		severity := strings.ToLower(strings.TrimSpace(def.Severity))
+		for _, cve := range cves {
+			trkr.Entries[cve] = &debtracker.Entry{
+				Description: strings.TrimSpace(def.Description),
+				Releases: map[string]*debtracker.Release{
+					release: {
+						Status:  debtracker.Resolved,
+						Urgency: severity,
+					},
+				},
+			}
+		}
+
+		for _, testRef := range o.testRefs(def.Criteria, nil) {
+			test := o.tests[testRef]
+			if test == nil || test.State == nil {
+				continue
+			}
+
+			ste := o.states[test.State.Ref]
+			if ste == nil || ste.Evr == nil ||
+				ste.Evr.Operation != "less than" {
+
+				continue
+			}
+
+			fixed := normalizeVersion(ste.Evr.Value)
+			if fixed == "" {
+				continue
+			}
+
+			for _, name := range o.packages(test) {
+				pkgFixed := trkr.Fixed[name]
+				if pkgFixed == nil {
+					pkgFixed = map[string]string{}
+					trkr.Fixed[name] = pkgFixed
+				}
+
+				for _, cve := range cves {
+					pkgFixed[cve] = fixed
+				}
+			}
+		}
+	}
+
+	return
+}
+
+func parse(reader io.Reader) (data *ovalData, err error) {
+	data = &ovalData{
+		tests:     map[string]*ovalTest{},
+		objects:   map[string]*ovalObject{},
+		states:    map[string]*ovalState{},
+		variables: map[string]*ovalVariable{},
+	}
+
+	decoder := xml.NewDecoder(reader)
+	for {
+		token, e := decoder.Token()
+		if e == io.EOF {
+			break
+		}
+		if e != nil {
+			err = &errortypes.ParseError{
+				errors.Wrap(e, "ubuntutracker: Failed to parse oval"),
+			}
+			return
+		}
+
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "definition":
+			def := &ovalDefinition{}
+			err = decoder.DecodeElement(def, &start)
+			if err == nil {
+				data.definitions = append(data.definitions, def)
+			}
+			break
+		case "dpkginfo_test":
+			test := &ovalTest{}
+			err = decoder.DecodeElement(test, &start)
+			if err == nil {
+				data.tests[test.Id] = test
+			}
+			break
+		case "dpkginfo_object":
+			obj := &ovalObject{}
+			err = decoder.DecodeElement(obj, &start)
+			if err == nil {
+				data.objects[obj.Id] = obj
+			}
+			break
+		case "dpkginfo_state":
+			ste := &ovalState{}
+			err = decoder.DecodeElement(ste, &start)
+			if err == nil {
+				data.states[ste.Id] = ste
+			}
+			break
+		case "constant_variable":
+			vr := &ovalVariable{}
+			err = decoder.DecodeElement(vr, &start)
+			if err == nil {
+				data.variables[vr.Id] = vr
+			}
+			break
+		}
+		if err != nil {
+			err = &errortypes.ParseError{
+				errors.Wrap(err, "ubuntutracker: Failed to parse oval"),
+			}
+			return
+		}
+	}
+
+	return
+}
+
+func Load(release string) (trkr *Tracker, err error) {
+	if !releaseReg.MatchString(release) {
+		err = &errortypes.ParseError{
+			errors.Newf("ubuntutracker: Invalid release '%s'", release),
+		}
+		return
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf(UbuntuOval, release), nil)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "ubuntutracker: Failed to create oval request"),
+		}
+		return
+	}
+
+	resp, err := trackerClient.Do(req)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "ubuntutracker: Failed to request oval"),
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err = &errortypes.RequestError{
+			errors.Newf(
+				"ubuntutracker: Bad oval status %d", resp.StatusCode),
+		}
+		return
+	}
+
+	data, err := parse(io.LimitReader(
+		bzip2.NewReader(resp.Body),
+		int64(settings.Telemetry.UbuntuTrackerSizeLimit),
+	))
+	if err != nil {
+		return
+	}
+
+	trkr = data.tracker(release)
+
+	return
+}
+
+// Get returns the tracker data for the release, the data is cached for
+// the tracker ttl and the last cached data is returned if the reload fails
+func Get(release string) (trkr *Tracker, err error) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+
+	ttl := time.Duration(settings.Telemetry.UbuntuTrackerTtl) * time.Second
+	cached := cache[release]
+	if cached != nil && time.Since(cached.timestamp) < ttl {
+		trkr = cached.tracker
+		return
+	}
+
+	trkr, err = Load(release)
+	if err != nil {
+		if cached != nil {
+			trkr = cached.tracker
+		}
+		return
+	}
+
+	cache[release] = &cacheEntry{
+		tracker:   trkr,
+		timestamp: time.Now(),
+	}
+
+	return
+}
@@ -5,13 +5,16 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/dropbox/godropbox/container/set"
 	"github.com/gin-gonic/gin"
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/authority"
+	"github.com/pritunl/pritunl-cloud/authorizer"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/demo"
+	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/event"
 	"github.com/pritunl/pritunl-cloud/utils"
 )
@@ -25,6 +28,10 @@ type authorityData struct {
 	Key         string        `json:"key"`
 	Principals  []string      `json:"principals"`
 	Certificate string        `json:"certificate"`
+
+	HostCertificates bool `json:"host_certificates"`
+	UserTtl          int  `json:"user_ttl"`
+	HostTtl          int  `json:"host_ttl"`
 }
 
 type authoritiesData struct {
@@ -66,6 +73,9 @@ func authorityPut(c *gin.Context) {
 	authr.Key = data.Key
 	authr.Principals = data.Principals
 	authr.Certificate = data.Certificate
+	authr.HostCertificates = data.HostCertificates
+	authr.UserTtl = data.UserTtl
+	authr.HostTtl = data.HostTtl
 
 	fields := set.NewSet(
 		"name",
@@ -76,6 +86,10 @@ func authorityPut(c *gin.Context) {
 		"key",
 		"principals",
 		"certificate",
+		"private_key",
+		"host_certificates",
+		"user_ttl",
+		"host_ttl",
 	)
 
 	errData, err := authr.Validate(db)
@@ -126,6 +140,10 @@ func authorityPost(c *gin.Context) {
 		Key:          data.Key,
 		Principals:   data.Principals,
 		Certificate:  data.Certificate,
+
+		HostCertificates: data.HostCertificates,
+		UserTtl:          data.UserTtl,
+		HostTtl:          data.HostTtl,
 	}
 
 	errData, err := fire.Validate(db)
@@ -322,3 +340,71 @@ func authoritiesGet(c *gin.Context) {
 
 	c.JSON(200, data)
 }
+
+type sshCertificateData struct {
+	PublicKey string `json:"public_key"`
+}
+
+type sshCertificateRespData struct {
+	Certificate string    `json:"certificate"`
+	Authority   string    `json:"authority"`
+	Serial      int64     `json:"serial"`
+	Principals  []string  `json:"principals"`
+	ValidBefore time.Time `json:"valid_before"`
+}
+
+func authoritySshCertificatePost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+	userOrg := c.MustGet("organization").(bson.ObjectID)
+	data := &sshCertificateData{}
+
+	err := c.Bind(data)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	ca, err := authority.GetOrgCa(db, userOrg)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			c.JSON(400, &errortypes.ErrorData{
+				Error:   "ssh_authority_not_found",
+				Message: "Organization does not have a certificate authority",
+			})
+			return
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	cert, record, errData, err := ca.IssueUserCertificate(db, c.Request,
+		usr.Id, usr.Username, strings.TrimSpace(data.PublicKey), usr.Roles)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	c.JSON(200, &sshCertificateRespData{
+		Certificate: cert,
+		Authority:   ca.Certificate,
+		Serial:      record.Serial,
+		Principals:  record.Principals,
+		ValidBefore: record.ValidBefore,
+	})
+}
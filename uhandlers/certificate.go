@@ -14,6 +14,7 @@ import (
 	"github.com/pritunl/pritunl-cloud/certificate"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/demo"
+	"github.com/pritunl/pritunl-cloud/envelope"
 	"github.com/pritunl/pritunl-cloud/errortypes"
 	"github.com/pritunl/pritunl-cloud/event"
 	"github.com/pritunl/pritunl-cloud/secret"
@@ -93,7 +94,7 @@ func certificatePut(c *gin.Context) {
 
 	cert.Name = data.Name
 	cert.Comment = data.Comment
-	cert.Key = data.Key
+	cert.Key = envelope.String(data.Key)
 	cert.Certificate = data.Certificate
 	cert.Type = data.Type
 	cert.AcmeDomains = data.AcmeDomains
@@ -113,7 +114,7 @@ func certificatePut(c *gin.Context) {
 	)
 
 	if cert.Type != certificate.LetsEncrypt {
-		cert.Key = data.Key
+		cert.Key = envelope.String(data.Key)
 		fields.Add("key")
 		cert.Certificate = data.Certificate
 		fields.Add("certificate")
@@ -174,7 +175,7 @@ func certificatePost(c *gin.Context) {
 	}
 
 	if cert.Type != certificate.LetsEncrypt {
-		cert.Key = data.Key
+		cert.Key = envelope.String(data.Key)
 		cert.Certificate = data.Certificate
 	}
 
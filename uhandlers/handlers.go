@@ -63,11 +63,13 @@ func Register(engine *gin.Engine) {
 	orgGroup.GET("/authority/:authority_id", authorityGet)
 	orgGroup.PUT("/authority/:authority_id", authorityPut)
 	orgGroup.POST("/authority", authorityPost)
+	orgGroup.POST("/authority/ssh_certificate", authoritySshCertificatePost)
 	orgGroup.DELETE("/authority", authoritiesDelete)
 	orgGroup.DELETE("/authority/:authority_id", authorityDelete)
 
 	orgGroup.GET("/balancer", balancersGet)
 	orgGroup.GET("/balancer/:balancer_id", balancerGet)
+	orgGroup.GET("/balancer/:balancer_id/access_log", balancerAccessLogGet)
 	orgGroup.PUT("/balancer/:balancer_id", balancerPut)
 	orgGroup.POST("/balancer", balancerPost)
 	orgGroup.DELETE("/balancer", balancersDelete)
@@ -120,6 +122,7 @@ func Register(engine *gin.Engine) {
 	orgGroup.DELETE("/disk/:disk_id", diskDelete)
 
 	csrfGroup.GET("/event", eventGet)
+	orgGroup.GET("/event/stream", streamGet)
 
 	orgGroup.GET("/firewall", firewallsGet)
 	orgGroup.GET("/firewall/:firewall_id", firewallGet)
@@ -134,14 +137,26 @@ func Register(engine *gin.Engine) {
 	orgGroup.DELETE("/image", imagesDelete)
 	orgGroup.DELETE("/image/:image_id", imageDelete)
 
+	orgGroup.GET("/image_import", imageImportsGet)
+	orgGroup.GET("/image_import/:import_id", imageImportGet)
+	orgGroup.POST("/image_import", imageImportPost)
+	orgGroup.PUT("/image_import/:import_id/chunk/:chunk",
+		imageImportChunkPut)
+	orgGroup.POST("/image_import/:import_id/complete",
+		imageImportCompletePost)
+	orgGroup.DELETE("/image_import/:import_id", imageImportDelete)
+
 	orgGroup.GET("/instance", instancesGet)
 	orgGroup.PUT("/instance", instancesPut)
 	orgGroup.GET("/instance/:instance_id", instanceGet)
 	orgGroup.GET("/instance/:instance_id/advisory", instanceAdvisoryGet)
 	orgGroup.GET("/instance/:instance_id/chart", instanceChartGet)
 	orgGroup.GET("/instance/:instance_id/vnc", instanceVncGet)
+	orgGroup.GET("/instance/:instance_id/serial", instanceSerialGet)
+	orgGroup.GET("/instance/:instance_id/serial_log", instanceSerialLogGet)
 	orgGroup.PUT("/instance/:instance_id", instancePut)
 	orgGroup.POST("/instance", instancePost)
+	orgGroup.POST("/instance/:instance_id/clone", instanceClonePost)
 	orgGroup.DELETE("/instance", instancesDelete)
 	orgGroup.DELETE("/instance/:instance_id", instanceDelete)
 
@@ -167,14 +182,23 @@ func Register(engine *gin.Engine) {
 	orgGroup.DELETE("/secret", secretsDelete)
 	orgGroup.DELETE("/secret/:secr_id", secretDelete)
 
+	orgGroup.GET("/log_sink", logSinksGet)
+	orgGroup.GET("/log_sink/:sink_id", logSinkGet)
+	orgGroup.PUT("/log_sink/:sink_id", logSinkPut)
+	orgGroup.POST("/log_sink", logSinkPost)
+	orgGroup.DELETE("/log_sink", logSinksDelete)
+	orgGroup.DELETE("/log_sink/:sink_id", logSinkDelete)
+
 	orgGroup.GET("/pod", podsGet)
 	orgGroup.GET("/pod/:pod_id", podGet)
 	orgGroup.PUT("/pod/:pod_id", podPut)
 	orgGroup.PUT("/pod/:pod_id/drafts", podDraftsPut)
 	orgGroup.PUT("/pod/:pod_id/deploy", podDeployPut)
 	orgGroup.POST("/pod", podPost)
+	orgGroup.POST("/pod/import", podImportPost)
 	orgGroup.DELETE("/pod", podsDelete)
 	orgGroup.DELETE("/pod/:pod_id", podDelete)
+	orgGroup.GET("/pod/:pod_id/export", podExportGet)
 	orgGroup.GET("/pod/:pod_id/unit/:unit_id", podUnitGet)
 	orgGroup.PUT("/pod/:pod_id/unit/:unit_id/deployment",
 		podUnitDeploymentsPut)
@@ -186,7 +210,9 @@ func Register(engine *gin.Engine) {
 		"/pod/:pod_id/unit/:unit_id/deployment/:deployment_id/log",
 		podUnitDeploymentLogGet,
 	)
+	orgGroup.GET("/pod/:pod_id/unit/:unit_id/log", podUnitLogGet)
 	orgGroup.GET("/pod/:pod_id/unit/:unit_id/spec", podUnitSpecsGet)
+	orgGroup.POST("/pod/:pod_id/unit/:unit_id/preview", podUnitPreviewPost)
 	orgGroup.GET("/pod/:pod_id/unit/:unit_id/spec/:spec_id", podUnitSpecGet)
 
 	csrfGroup.GET("/shape", shapesGet)
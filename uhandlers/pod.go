@@ -2,6 +2,7 @@ package uhandlers
 
 import (
 	"fmt"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"github.com/pritunl/mongo-go-driver/v2/bson"
 	"github.com/pritunl/pritunl-cloud/aggregate"
 	"github.com/pritunl/pritunl-cloud/authorizer"
+	"github.com/pritunl/pritunl-cloud/bundle"
 	"github.com/pritunl/pritunl-cloud/database"
 	"github.com/pritunl/pritunl-cloud/demo"
 	"github.com/pritunl/pritunl-cloud/deployment"
@@ -52,6 +54,10 @@ type deploymentData struct {
 	Tags []string      `json:"tags"`
 }
 
+type unitPreviewData struct {
+	Spec string `json:"spec"`
+}
+
 type specsData struct {
 	Specs []*spec.Named `json:"specs"`
 	Count int64         `json:"count"`
@@ -523,6 +529,8 @@ type PodUnit struct {
 	Id          bson.ObjectID           `json:"id"`
 	Pod         bson.ObjectID           `json:"pod"`
 	Kind        string                  `json:"kind"`
+	Rollout     *unit.Rollout           `json:"rollout"`
+	Autoscale   *unit.Autoscale         `json:"autoscale"`
 	Deployments []*aggregate.Deployment `json:"deployments"`
 }
 
@@ -593,6 +601,8 @@ func podUnitGet(c *gin.Context) {
 		Id:          unt.Id,
 		Pod:         unt.Pod,
 		Kind:        unt.Kind,
+		Rollout:     unt.Rollout,
+		Autoscale:   unt.Autoscale,
 		Deployments: deploys,
 	}
 
@@ -670,6 +680,19 @@ func podUnitDeploymentsPut(c *gin.Context) {
 			return
 		}
 
+		break
+	case deployment.Rollback:
+		errData, err := unt.RollbackRollout(db)
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		if errData != nil {
+			c.JSON(400, errData)
+			return
+		}
+
 		break
 	}
 
@@ -849,6 +872,56 @@ func podUnitDeploymentLogGet(c *gin.Context) {
 	c.JSON(200, data)
 }
 
+func podUnitLogGet(c *gin.Context) {
+	if demo.IsDemo() {
+		c.JSON(200, demo.DeploymentLogs)
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	userOrg := c.MustGet("organization").(bson.ObjectID)
+
+	unitId, ok := utils.ParseObjectId(c.Param("unit_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	unt, err := unit.GetOrg(db, userOrg, unitId)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			c.AbortWithStatus(404)
+		} else {
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	kind := int32(0)
+	switch c.Query("resource") {
+	case "autoscale":
+		kind = journal.UnitAutoscale
+		break
+	}
+
+	if kind == 0 {
+		utils.AbortWithStatus(c, 404)
+		return
+	}
+
+	data, err := journal.GetOutput(c, db, unt.Id, kind)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			c.AbortWithStatus(404)
+		} else {
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	c.JSON(200, data)
+}
+
 func podUnitSpecsGet(c *gin.Context) {
 	if demo.IsDemo() {
 		unitId, ok := utils.ParseObjectId(c.Param("unit_id"))
@@ -956,3 +1029,172 @@ func podUnitSpecGet(c *gin.Context) {
 
 	c.JSON(200, spec)
 }
+
+func podExportGet(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	userOrg := c.MustGet("organization").(bson.ObjectID)
+
+	podId, ok := utils.ParseObjectId(c.Param("pod_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	pd, err := pod.GetOrg(db, userOrg, podId)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			c.AbortWithStatus(404)
+		} else {
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	bndl, err := bundle.Export(db, pd)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	var data []byte
+	contentType := ""
+	fileName := ""
+
+	switch c.Query("format") {
+	case bundle.Tar:
+		data, err = bndl.Tar()
+		contentType = "application/x-tar"
+		fileName = pd.Name + ".tar"
+		break
+	default:
+		data, err = bndl.Yaml()
+		contentType = "application/yaml"
+		fileName = pd.Name + ".yaml"
+		break
+	}
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.Header("Content-Disposition",
+		fmt.Sprintf("attachment; filename=\"%s\"", fileName))
+	c.Data(200, contentType, data)
+}
+
+func podImportPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	userOrg := c.MustGet("organization").(bson.ObjectID)
+
+	dryRun := c.Query("dry_run") == "true"
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, bundle.MaxSize))
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "handler: Failed to read bundle"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	bndl, err := bundle.Parse(body)
+	if err != nil {
+		c.JSON(400, &errortypes.ErrorData{
+			Error:   "bundle_invalid",
+			Message: "Failed to parse bundle",
+		})
+		return
+	}
+
+	report, errData, err := bundle.Import(db, userOrg, bndl,
+		c.Query("name"), dryRun)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	if !report.Valid() && !dryRun {
+		c.JSON(400, report)
+		return
+	}
+
+	if !dryRun {
+		event.PublishDispatch(db, "pod.change")
+		event.PublishDispatch(db, "unit.change")
+		event.PublishDispatch(db, "plan.change")
+	}
+
+	c.JSON(200, report)
+}
+
+func podUnitPreviewPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	userOrg := c.MustGet("organization").(bson.ObjectID)
+	data := &unitPreviewData{}
+
+	podId, ok := utils.ParseObjectId(c.Param("pod_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	unitId, ok := utils.ParseObjectId(c.Param("unit_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	unt, err := unit.GetOrg(db, userOrg, unitId)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			c.AbortWithStatus(404)
+		} else {
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	if unt.Pod != podId {
+		utils.AbortWithStatus(c, 404)
+		return
+	}
+
+	preview, errData, err := unt.Preview(db, data.Spec)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	c.JSON(200, preview)
+}
@@ -0,0 +1,63 @@
+package uhandlers
+
+import (
+	"strings"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/event"
+	"github.com/pritunl/pritunl-cloud/utils"
+)
+
+func streamGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	userOrg := c.MustGet("organization").(bson.ObjectID)
+
+	strm := &event.Stream{
+		Organization: userOrg,
+		Types:        set.NewSet(),
+		Resources:    set.NewSet(),
+	}
+
+	cursor := c.Query("cursor")
+	if cursor == "" {
+		cursor = c.GetHeader("Last-Event-ID")
+	}
+	if cursor != "" {
+		cursorId, ok := utils.ParseObjectId(cursor)
+		if !ok {
+			utils.AbortWithStatus(c, 400)
+			return
+		}
+		strm.Cursor = cursorId
+	}
+
+	for _, typ := range strings.Split(c.Query("type"), ",") {
+		typ = strings.TrimSpace(typ)
+		if typ != "" {
+			strm.Types.Add(typ)
+		}
+	}
+
+	for _, resource := range strings.Split(c.Query("resource"), ",") {
+		resource = strings.TrimSpace(resource)
+		if resource == "" {
+			continue
+		}
+
+		resourceId, ok := utils.ParseObjectId(resource)
+		if !ok {
+			utils.AbortWithStatus(c, 400)
+			return
+		}
+		strm.Resources.Add(resourceId)
+	}
+
+	err := strm.Serve(db, c.Writer, c.Request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+}
@@ -0,0 +1,27 @@
+package unit
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+)
+
+type Autoscale struct {
+	Spec          bson.ObjectID `bson:"spec" json:"spec"`
+	Count         int           `bson:"count" json:"count"`
+	Desired       int           `bson:"desired" json:"desired"`
+	CpuUsage      float64       `bson:"cpu_usage" json:"cpu_usage"`
+	MemUsage      float64       `bson:"mem_usage" json:"mem_usage"`
+	MetricValue   float64       `bson:"metric_value" json:"metric_value"`
+	Timestamp     time.Time     `bson:"timestamp" json:"timestamp"`
+	LastScaleUp   time.Time     `bson:"last_scale_up" json:"last_scale_up"`
+	LastScaleDown time.Time     `bson:"last_scale_down" json:"last_scale_down"`
+	Message       string        `bson:"message" json:"message"`
+}
+
+func (u *Unit) GetCount() int {
+	if u.Autoscale != nil && u.Autoscale.Count > 0 {
+		return u.Autoscale.Count
+	}
+	return u.Count
+}
@@ -0,0 +1,22 @@
+package unit
+
+const (
+	RolloutRunning    = "running"
+	RolloutComplete   = "complete"
+	RolloutRollback   = "rollback"
+	RolloutRolledBack = "rolled_back"
+
+	TargetPending   = "pending"
+	TargetMigrating = "migrating"
+	TargetMigrated  = "migrated"
+	TargetReady     = "ready"
+	TargetFailed    = "failed"
+	TargetReverting = "reverting"
+	TargetReverted  = "reverted"
+	TargetRemoved   = "removed"
+
+	PreviewReplace = "replace"
+	PreviewRestart = "restart"
+	PreviewUpdate  = "update"
+	PreviewNone    = "none"
+)
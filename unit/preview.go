@@ -0,0 +1,155 @@
+package unit
+
+import (
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/deployment"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/spec"
+)
+
+type Preview struct {
+	Hash        string               `json:"hash"`
+	Changed     bool                 `json:"changed"`
+	Changes     []*spec.Change       `json:"changes"`
+	Deployments []*DeploymentPreview `json:"deployments"`
+	Replaced    int                  `json:"replaced"`
+	Restarted   int                  `json:"restarted"`
+	Updated     int                  `json:"updated"`
+	Untouched   int                  `json:"untouched"`
+}
+
+type DeploymentPreview struct {
+	Id      bson.ObjectID  `json:"id"`
+	Spec    bson.ObjectID  `json:"spec"`
+	Action  string         `json:"action"`
+	Changes []*spec.Change `json:"changes"`
+}
+
+func previewAction(changes []*spec.Change) string {
+	action := PreviewNone
+
+	for _, change := range changes {
+		switch change.Class {
+		case spec.Hard:
+			return PreviewReplace
+		case spec.Soft, spec.Clear:
+			if change.Restart {
+				action = PreviewRestart
+			} else if action == PreviewNone {
+				action = PreviewUpdate
+			}
+		}
+	}
+
+	return action
+}
+
+func (u *Unit) Preview(db *database.Database, data string) (
+	preview *Preview, errData *errortypes.ErrorData, err error) {
+
+	jrnls := map[string]int32{}
+	for key, index := range u.Journals {
+		jrnls[key] = index
+	}
+
+	kindGen := &Unit{
+		Id:            u.Id,
+		Journals:      jrnls,
+		JournalsIndex: u.JournalsIndex,
+		newUnit:       true,
+	}
+
+	newSpc := spec.New(u.Pod, u.Id, u.Organization, data)
+
+	errData, err = newSpc.Parse(db, kindGen)
+	if err != nil || errData != nil {
+		return
+	}
+
+	if u.Kind != "" && u.Kind != newSpc.Kind {
+		errData = &errortypes.ErrorData{
+			Error:   "spec_kind_invalid",
+			Message: "Cannot change spec kind",
+		}
+		return
+	}
+
+	preview = &Preview{
+		Hash:        newSpc.Hash,
+		Changes:     []*spec.Change{},
+		Deployments: []*DeploymentPreview{},
+	}
+
+	specs := map[bson.ObjectID]*spec.Spec{}
+
+	liveSpecId := u.DeploySpec
+	if liveSpecId.IsZero() {
+		liveSpecId = u.LastSpec
+	}
+
+	if !liveSpecId.IsZero() {
+		liveSpc, e := spec.Get(db, liveSpecId)
+		if e != nil {
+			if _, ok := e.(*database.NotFoundError); !ok {
+				err = e
+				return
+			}
+		} else {
+			specs[liveSpc.Id] = liveSpc
+			preview.Changes = liveSpc.Diff(newSpc)
+		}
+	}
+	preview.Changed = len(preview.Changes) > 0
+
+	deplys, err := deployment.GetAll(db, &bson.M{
+		"unit": u.Id,
+	})
+	if err != nil {
+		return
+	}
+
+	for _, deply := range deplys {
+		deplySpc := specs[deply.Spec]
+		if deplySpc == nil {
+			spc, e := spec.Get(db, deply.Spec)
+			if e != nil {
+				if _, ok := e.(*database.NotFoundError); !ok {
+					err = e
+					return
+				}
+			} else {
+				deplySpc = spc
+				specs[spc.Id] = spc
+			}
+		}
+
+		deplyPreview := &DeploymentPreview{
+			Id:   deply.Id,
+			Spec: deply.Spec,
+		}
+
+		if deplySpc == nil {
+			deplyPreview.Action = PreviewReplace
+			deplyPreview.Changes = []*spec.Change{}
+		} else {
+			deplyPreview.Changes = deplySpc.Diff(newSpc)
+			deplyPreview.Action = previewAction(deplyPreview.Changes)
+		}
+
+		switch deplyPreview.Action {
+		case PreviewReplace:
+			preview.Replaced += 1
+		case PreviewRestart:
+			preview.Restarted += 1
+		case PreviewUpdate:
+			preview.Updated += 1
+		default:
+			preview.Untouched += 1
+		}
+
+		preview.Deployments = append(preview.Deployments, deplyPreview)
+	}
+
+	return
+}
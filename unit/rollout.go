@@ -0,0 +1,238 @@
+package unit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/deployment"
+	"github.com/pritunl/pritunl-cloud/spec"
+)
+
+type Rollout struct {
+	State     string           `bson:"state" json:"state"`
+	Spec      bson.ObjectID    `bson:"spec" json:"spec"`
+	Update    *spec.Update     `bson:"update" json:"update"`
+	Timestamp time.Time        `bson:"timestamp" json:"timestamp"`
+	Modified  time.Time        `bson:"modified" json:"modified"`
+	Message   string           `bson:"message" json:"message"`
+	Total     int              `bson:"total" json:"total"`
+	Ready     int              `bson:"ready" json:"ready"`
+	Scheduled bool             `bson:"scheduled" json:"scheduled"`
+	Surge     []bson.ObjectID  `bson:"surge" json:"surge"`
+	Targets   []*RolloutTarget `bson:"targets" json:"targets"`
+}
+
+type RolloutTarget struct {
+	Deployment bson.ObjectID `bson:"deployment" json:"deployment"`
+	PrevSpec   bson.ObjectID `bson:"prev_spec" json:"prev_spec"`
+	NewSpec    bson.ObjectID `bson:"new_spec" json:"new_spec"`
+	State      string        `bson:"state" json:"state"`
+	Migrated   time.Time     `bson:"migrated" json:"migrated"`
+}
+
+func (r *Rollout) IsActive() bool {
+	return r != nil && (r.State == RolloutRunning ||
+		r.State == RolloutRollback)
+}
+
+func (r *Rollout) HasSurge(deplyId bson.ObjectID) bool {
+	for _, surgeId := range r.Surge {
+		if surgeId == deplyId {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rollout) Target(deplyId bson.ObjectID) *RolloutTarget {
+	for _, target := range r.Targets {
+		if target.Deployment == deplyId {
+			return target
+		}
+	}
+	return nil
+}
+
+func (r *Rollout) CountState(states ...string) (count int) {
+	for _, target := range r.Targets {
+		for _, state := range states {
+			if target.State == state {
+				count += 1
+				break
+			}
+		}
+	}
+	return
+}
+
+func (r *Rollout) CanaryCount() (count int) {
+	if r.Update == nil || r.Update.Strategy != spec.Canary {
+		return
+	}
+
+	count = (len(r.Targets)*r.Update.CanaryPercent + 99) / 100
+	if count < 1 {
+		count = 1
+	}
+
+	return
+}
+
+func (r *Rollout) HealthTimeout() time.Duration {
+	timeout := spec.DefaultHealthTimeout
+	if r.Update != nil && r.Update.HealthTimeout > 0 {
+		timeout = r.Update.HealthTimeout
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+func newRollout(spc *spec.Spec, deplys []*deployment.Deployment) (
+	rllt *Rollout) {
+
+	rllt = &Rollout{
+		State:     RolloutRunning,
+		Spec:      spc.Id,
+		Update:    spc.Instance.Update,
+		Timestamp: time.Now(),
+		Modified:  time.Now(),
+		Total:     len(deplys),
+		Surge:     []bson.ObjectID{},
+		Targets:   []*RolloutTarget{},
+	}
+
+	for _, deply := range deplys {
+		rllt.Targets = append(rllt.Targets, &RolloutTarget{
+			Deployment: deply.Id,
+			PrevSpec:   deply.Spec,
+			State:      TargetPending,
+		})
+	}
+
+	return
+}
+
+func RolloutMigrated(db *database.Database,
+	unitId, deplyId, specId bson.ObjectID) (err error) {
+
+	coll := db.Units()
+
+	_, err = coll.UpdateOne(db, bson.M{
+		"_id": unitId,
+		"rollout.targets": bson.M{
+			"$elemMatch": bson.M{
+				"deployment": deplyId,
+				"new_spec":   specId,
+			},
+		},
+	}, bson.M{
+		"$set": bson.M{
+			"rollout.targets.$.migrated": time.Now(),
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func RolloutFailed(db *database.Database,
+	unitId, deplyId, specId bson.ObjectID) (err error) {
+
+	coll := db.Units()
+
+	_, err = coll.UpdateOne(db, bson.M{
+		"_id":           unitId,
+		"rollout.state": RolloutRunning,
+		"rollout.targets": bson.M{
+			"$elemMatch": bson.M{
+				"deployment": deplyId,
+				"new_spec":   specId,
+			},
+		},
+	}, bson.M{
+		"$set": bson.M{
+			"rollout.targets.$.state": TargetFailed,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Snapshot returns a copy of the targets to detect the targets changed
+// when processing the rollout
+func (r *Rollout) Snapshot() (targets []RolloutTarget) {
+	targets = make([]RolloutTarget, len(r.Targets))
+	for i, target := range r.Targets {
+		targets[i] = *target
+	}
+	return
+}
+
+// CommitRollout updates the rollout fields and the targets changed since
+// the snapshot. Changed targets must still be in the snapshot state to
+// avoid overwriting target updates reported by deployments, updated is
+// false when a target was modified and the rollout should be reprocessed
+func CommitRollout(db *database.Database, unitId bson.ObjectID,
+	rllt *Rollout, snapshot []RolloutTarget) (updated bool, err error) {
+
+	coll := db.Units()
+
+	query := bson.M{
+		"_id":               unitId,
+		"rollout.timestamp": rllt.Timestamp,
+	}
+	fields := bson.M{
+		"rollout.state":     rllt.State,
+		"rollout.update":    rllt.Update,
+		"rollout.modified":  rllt.Modified,
+		"rollout.message":   rllt.Message,
+		"rollout.ready":     rllt.Ready,
+		"rollout.scheduled": rllt.Scheduled,
+		"rollout.surge":     rllt.Surge,
+	}
+
+	for i, target := range rllt.Targets {
+		if i >= len(snapshot) {
+			break
+		}
+		prev := snapshot[i]
+		key := fmt.Sprintf("rollout.targets.%d", i)
+
+		if target.State != prev.State {
+			query[key+".state"] = prev.State
+			fields[key+".state"] = target.State
+		}
+		if target.NewSpec != prev.NewSpec {
+			fields[key+".new_spec"] = target.NewSpec
+		}
+		if !target.Migrated.Equal(prev.Migrated) {
+			fields[key+".migrated"] = target.Migrated
+		}
+
+		if target.State != prev.State || target.NewSpec != prev.NewSpec ||
+			!target.Migrated.Equal(prev.Migrated) {
+
+			query[key+".deployment"] = prev.Deployment
+		}
+	}
+
+	resp, err := coll.UpdateOne(db, query, bson.M{
+		"$set": fields,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	updated = resp.MatchedCount > 0
+
+	return
+}
@@ -31,6 +31,8 @@ type Unit struct {
 	Hash             string           `bson:"hash" json:"hash"`
 	Journals         map[string]int32 `bson:"journals" json:"-"`
 	JournalsIndex    int32            `bson:"journals_index" json:"-"`
+	Rollout          *Rollout         `bson:"rollout,omitempty" json:"rollout"`
+	Autoscale        *Autoscale       `bson:"autoscale,omitempty" json:"autoscale"`
 	journalsLock     sync.Mutex       `bson:"-" json:"-"`
 	newUnit          bool             `bson:"-" json:"-"`
 }
@@ -80,6 +82,8 @@ func (u *Unit) Refresh(db *database.Database) (err error) {
 	u.Hash = unt.Hash
 	u.Journals = unt.Journals
 	u.JournalsIndex = unt.JournalsIndex
+	u.Rollout = unt.Rollout
+	u.Autoscale = unt.Autoscale
 	return
 }
 
@@ -121,7 +125,7 @@ func (u *Unit) Reserve(db *database.Database, deployId bson.ObjectID,
 	coll := db.Units()
 
 	if overrideCount == 0 {
-		if len(u.Deployments) >= u.Count {
+		if len(u.Deployments) >= u.GetCount() {
 			return
 		}
 	} else {
@@ -200,6 +204,14 @@ func (u *Unit) MigrateDeployements(db *database.Database,
 
 	coll := db.Deployments()
 
+	if u.Rollout.IsActive() {
+		errData = &errortypes.ErrorData{
+			Error:   "unit_rollout_active",
+			Message: "Cannot migrate deployments while rollout is active",
+		}
+		return
+	}
+
 	newSpc, err := spec.Get(db, newSpecId)
 	if err != nil {
 		return
@@ -242,6 +254,18 @@ func (u *Unit) MigrateDeployements(db *database.Database,
 		}
 	}
 
+	if newSpc.Instance != nil && newSpc.Instance.Update != nil &&
+		len(deplys) > 0 {
+
+		u.Rollout = newRollout(newSpc, deplys)
+		err = u.CommitFields(db, set.NewSet("rollout"))
+		if err != nil {
+			return
+		}
+
+		return
+	}
+
 	_, err = coll.UpdateMany(db, &bson.M{
 		"_id": &bson.M{
 			"$in": deplyIds,
@@ -262,6 +286,42 @@ func (u *Unit) MigrateDeployements(db *database.Database,
 	return
 }
 
+func (u *Unit) RollbackRollout(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	if u.Rollout == nil || u.Rollout.State != RolloutRunning {
+		errData = &errortypes.ErrorData{
+			Error:   "unit_rollout_inactive",
+			Message: "Unit does not have a running rollout",
+		}
+		return
+	}
+
+	coll := db.Units()
+
+	resp, err := coll.UpdateOne(db, bson.M{
+		"_id":           u.Id,
+		"rollout.state": RolloutRunning,
+	}, bson.M{
+		"$set": bson.M{
+			"rollout.state":    RolloutRollback,
+			"rollout.message":  "Rollback requested",
+			"rollout.modified": time.Now(),
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	if resp.MatchedCount == 1 {
+		u.Rollout.State = RolloutRollback
+		u.Rollout.Message = "Rollback requested"
+	}
+
+	return
+}
+
 func (u *Unit) newSpec(db *database.Database, spc *spec.Spec, newUnit bool) (
 	newSpec *spec.Spec, errData *errortypes.ErrorData, err error) {
 
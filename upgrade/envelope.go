@@ -0,0 +1,26 @@
+package upgrade
+
+import (
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/envelope"
+	"github.com/sirupsen/logrus"
+)
+
+func envelopeUpgrade(db *database.Database) (err error) {
+	if !envelope.Enabled() {
+		return
+	}
+
+	count, err := envelope.Rotate(db)
+	if err != nil {
+		return
+	}
+
+	if count > 0 {
+		logrus.WithFields(logrus.Fields{
+			"count": count,
+		}).Info("upgrade: Encrypted database fields")
+	}
+
+	return
+}
@@ -48,5 +48,10 @@ func Upgrade() (err error) {
 		return
 	}
 
+	err = envelopeUpgrade(db)
+	if err != nil {
+		return
+	}
+
 	return
 }
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+const (
+	DirMissing   = "missing"
+	DirUnmounted = "unmounted"
+	DirReadOnly  = "read_only"
+)
+
+// CheckDir verifies the directory exists, is writable and when required
+// is a mount point, the status is empty when the directory is usable
+func CheckDir(pth string, mount bool, testName string) (
+	status, message string) {
+
+	info, err := os.Stat(pth)
+	if err != nil || !info.IsDir() {
+		status = DirMissing
+		message = fmt.Sprintf("Directory %s not found", pth)
+		return
+	}
+
+	if mount {
+		parentInfo, e := os.Stat(filepath.Dir(pth))
+		if e != nil {
+			status = DirMissing
+			message = fmt.Sprintf("Parent directory of %s not found", pth)
+			return
+		}
+
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		parentStat, parentOk := parentInfo.Sys().(*syscall.Stat_t)
+		if ok && parentOk && stat.Dev == parentStat.Dev &&
+			stat.Ino != parentStat.Ino {
+
+			status = DirUnmounted
+			message = fmt.Sprintf("Directory %s not mounted", pth)
+			return
+		}
+	}
+
+	testPth := filepath.Join(pth, testName)
+	err = os.WriteFile(testPth, []byte(time.Now().Format(time.RFC3339)),
+		0600)
+	if err != nil {
+		status = DirReadOnly
+		message = fmt.Sprintf("Directory %s not writable", pth)
+		return
+	}
+	_ = os.Remove(testPth)
+
+	return
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path"
 	"strings"
+	"syscall"
 
 	"github.com/dropbox/godropbox/container/set"
 	"github.com/dropbox/godropbox/errors"
@@ -151,6 +152,33 @@ func IsPublicIp(ip net.IP) bool {
 	return true
 }
 
+// PublicDialControl is a dialer control that rejects connections to
+// addresses that are not public to prevent requests to internal services
+func PublicDialControl(network, address string, conn syscall.RawConn) (
+	err error) {
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "utils: Failed to parse dial address"),
+		}
+		return
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !IsPublicIp(ip) || ip.IsLoopback() || ip.IsPrivate() ||
+		ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsMulticast() {
+
+		err = &errortypes.RequestError{
+			errors.Newf("utils: Address %s not allowed", host),
+		}
+		return
+	}
+
+	return
+}
+
 type Address struct {
 	Address net.IP
 	Network *net.IPNet
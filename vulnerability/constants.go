@@ -28,10 +28,17 @@ const (
 
 	Nist   = "nist"
 	RedHat = "redhat"
+	Ubuntu = "ubuntu"
+
+	Nvd   = "nvd"
+	Osv   = "osv"
+	Vuxml = "vuxml"
 
 	nvdApi        = "https://services.nvd.nist.gov/rest/json/cves/2.0"
 	redhatApi     = "https://access.redhat.com"
 	redhatApiPath = "/hydra/rest/securitydata/cve/%s.json"
+	ubuntuApi     = "https://ubuntu.com"
+	ubuntuApiPath = "/security/cves/%s.json"
 )
 
 var (
@@ -0,0 +1,117 @@
+package vulnerability
+
+import (
+	"math"
+	"strings"
+)
+
+func cvss3Roundup(val float64) float64 {
+	intVal := int(math.Round(val * 100000))
+	if intVal%10000 == 0 {
+		return float64(intVal) / 100000.0
+	}
+	return (math.Floor(float64(intVal)/10000) + 1) / 10.0
+}
+
+func cvss3Impact(val string) float64 {
+	switch val {
+	case High:
+		return 0.56
+	case Low:
+		return 0.22
+	}
+	return 0
+}
+
+func cvss3Score(vector string) float64 {
+	if !strings.HasPrefix(vector, "CVSS:3") {
+		return 0
+	}
+
+	av, ac, pr, ui, scope, c, i, a := parseCvss3Vector(vector)
+
+	var avVal, acVal, prVal, uiVal float64
+
+	switch av {
+	case Network:
+		avVal = 0.85
+	case Adjacent:
+		avVal = 0.62
+	case Local:
+		avVal = 0.55
+	case Physical:
+		avVal = 0.2
+	default:
+		return 0
+	}
+
+	switch ac {
+	case Low:
+		acVal = 0.77
+	case High:
+		acVal = 0.44
+	default:
+		return 0
+	}
+
+	switch pr {
+	case None:
+		prVal = 0.85
+	case Low:
+		prVal = 0.62
+		if scope == Changed {
+			prVal = 0.68
+		}
+	case High:
+		prVal = 0.27
+		if scope == Changed {
+			prVal = 0.5
+		}
+	default:
+		return 0
+	}
+
+	switch ui {
+	case None:
+		uiVal = 0.85
+	case Required:
+		uiVal = 0.62
+	default:
+		return 0
+	}
+
+	iss := 1 - ((1 - cvss3Impact(c)) * (1 - cvss3Impact(i)) *
+		(1 - cvss3Impact(a)))
+
+	var impact float64
+	if scope == Changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	} else {
+		impact = 6.42 * iss
+	}
+
+	if impact <= 0 {
+		return 0
+	}
+
+	exploitability := 8.22 * avVal * acVal * prVal * uiVal
+
+	if scope == Changed {
+		return cvss3Roundup(math.Min(1.08*(impact+exploitability), 10))
+	}
+	return cvss3Roundup(math.Min(impact+exploitability, 10))
+}
+
+func cvss3Severity(score float64) string {
+	switch {
+	case score >= 9.0:
+		return Critical
+	case score >= 7.0:
+		return High
+	case score >= 4.0:
+		return Medium
+	case score > 0:
+		return Low
+	}
+	return None
+}
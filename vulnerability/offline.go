@@ -0,0 +1,429 @@
+package vulnerability
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-cloud/database"
+	"github.com/pritunl/pritunl-cloud/errortypes"
+	"github.com/pritunl/pritunl-cloud/settings"
+	"github.com/pritunl/pritunl-cloud/vuxml"
+	"github.com/ulikunitz/xz"
+)
+
+type osvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type osvEntry struct {
+	Id        string        `json:"id"`
+	Aliases   []string      `json:"aliases"`
+	Summary   string        `json:"summary"`
+	Details   string        `json:"details"`
+	Withdrawn string        `json:"withdrawn"`
+	Severity  []osvSeverity `json:"severity"`
+}
+
+type ImportResult struct {
+	Format          string `json:"format"`
+	Vulnerabilities int    `json:"vulnerabilities"`
+	Vuxmls          int    `json:"vuxmls"`
+}
+
+func (r *ImportResult) add(res *ImportResult) {
+	r.Vulnerabilities += res.Vulnerabilities
+	r.Vuxmls += res.Vuxmls
+	if r.Format == "" {
+		r.Format = res.Format
+	}
+}
+
+func commitLocal(db *database.Database, vuln *Vulnerability) (err error) {
+	errData, err := vuln.Validate(db)
+	if err != nil {
+		return
+	}
+	if errData != nil {
+		err = errData.GetError()
+		return
+	}
+
+	err = vuln.Commit(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func ImportNvd(db *database.Database, data []byte) (count int, err error) {
+	nvdResp := &nvdResponse{}
+	err = json.Unmarshal(data, nvdResp)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "vulnerability: Failed to parse nvd data"),
+		}
+		return
+	}
+
+	now := time.Now()
+
+	for _, item := range nvdResp.Vulnerabilities {
+		cve := item.Cve
+		if !strings.HasPrefix(strings.ToUpper(cve.ID), "CVE-") {
+			continue
+		}
+
+		vuln := &Vulnerability{
+			Id:        "local:" + strings.ToUpper(cve.ID),
+			Timestamp: now,
+			Status:    normalizeStatus(cve.VulnStatus),
+		}
+
+		for _, desc := range cve.Descriptions {
+			if desc.Lang == "en" {
+				vuln.Description = desc.Value
+				break
+			}
+		}
+
+		metrics := cve.Metrics.CvssMetricV31
+		if len(metrics) > 0 {
+			cvss := &metrics[0]
+			for i := range metrics {
+				if metrics[i].Type == "Primary" {
+					cvss = &metrics[i]
+					break
+				}
+			}
+
+			vuln.Score = cvss.CvssData.BaseScore
+			vuln.Severity = normalizeValue(cvss.CvssData.BaseSeverity)
+			vuln.Vector = normalizeValue(cvss.CvssData.AttackVector)
+			vuln.Complexity = normalizeValue(
+				cvss.CvssData.AttackComplexity)
+			vuln.Privileges = normalizeValue(
+				cvss.CvssData.PrivilegesRequired)
+			vuln.Interaction = normalizeValue(
+				cvss.CvssData.UserInteraction)
+			vuln.Scope = normalizeValue(cvss.CvssData.Scope)
+			vuln.Confidentiality = normalizeValue(
+				cvss.CvssData.ConfidentialityImpact)
+			vuln.Integrity = normalizeValue(cvss.CvssData.IntegrityImpact)
+			vuln.Availability = normalizeValue(
+				cvss.CvssData.AvailabilityImpact)
+		}
+
+		err = commitLocal(db, vuln)
+		if err != nil {
+			return
+		}
+
+		count += 1
+	}
+
+	return
+}
+
+func importOsvEntry(db *database.Database, entry *osvEntry,
+	now time.Time) (count int, err error) {
+
+	cveIds := []string{}
+	if strings.HasPrefix(strings.ToUpper(entry.Id), "CVE-") {
+		cveIds = append(cveIds, strings.ToUpper(entry.Id))
+	}
+	for _, alias := range entry.Aliases {
+		if strings.HasPrefix(strings.ToUpper(alias), "CVE-") {
+			cveIds = append(cveIds, strings.ToUpper(alias))
+		}
+	}
+
+	if len(cveIds) == 0 {
+		return
+	}
+
+	description := entry.Details
+	if description == "" {
+		description = entry.Summary
+	}
+
+	vector := ""
+	for _, sev := range entry.Severity {
+		if sev.Type == "CVSS_V3" {
+			vector = sev.Score
+			break
+		}
+	}
+
+	for _, cveId := range cveIds {
+		vuln := &Vulnerability{
+			Id:          "local:" + cveId,
+			Timestamp:   now,
+			Status:      Analyzed,
+			Description: description,
+		}
+
+		if entry.Withdrawn != "" {
+			vuln.Status = Rejected
+		}
+
+		if vector != "" {
+			av, ac, pr, ui, scope, c, i, a := parseCvss3Vector(vector)
+			vuln.Vector = av
+			vuln.Complexity = ac
+			vuln.Privileges = pr
+			vuln.Interaction = ui
+			vuln.Scope = scope
+			vuln.Confidentiality = c
+			vuln.Integrity = i
+			vuln.Availability = a
+			vuln.Score = cvss3Score(vector)
+			vuln.Severity = cvss3Severity(vuln.Score)
+		}
+
+		err = commitLocal(db, vuln)
+		if err != nil {
+			return
+		}
+
+		count += 1
+	}
+
+	return
+}
+
+func ImportOsv(db *database.Database, data []byte) (count int, err error) {
+	entries := []*osvEntry{}
+
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		err = json.Unmarshal(data, &entries)
+	} else {
+		entry := &osvEntry{}
+		err = json.Unmarshal(data, entry)
+		entries = append(entries, entry)
+	}
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "vulnerability: Failed to parse osv data"),
+		}
+		return
+	}
+
+	now := time.Now()
+
+	for _, entry := range entries {
+		n, e := importOsvEntry(db, entry, now)
+		if e != nil {
+			err = e
+			return
+		}
+		count += n
+	}
+
+	return
+}
+
+func detectJson(data []byte) string {
+	probe := map[string]json.RawMessage{}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		return Osv
+	}
+
+	err := json.Unmarshal(trimmed, &probe)
+	if err != nil {
+		return ""
+	}
+
+	if _, ok := probe["vulnerabilities"]; ok {
+		return Nvd
+	}
+	if _, ok := probe["id"]; ok {
+		return Osv
+	}
+
+	return ""
+}
+
+func readLimit(reader io.Reader) (data []byte, err error) {
+	limit := int64(settings.Telemetry.ImportSizeLimit)
+
+	data, err = io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "vulnerability: Failed to read import data"),
+		}
+		return
+	}
+
+	if int64(len(data)) > limit {
+		err = &errortypes.ParseError{
+			errors.New("vulnerability: Import data exceeds size limit"),
+		}
+		return
+	}
+
+	return
+}
+
+func importZip(db *database.Database, data []byte) (
+	result *ImportResult, err error) {
+
+	reader, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "vulnerability: Failed to open zip"),
+		}
+		return
+	}
+
+	result = &ImportResult{}
+
+	for _, file := range reader.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+
+		fileReader, e := file.Open()
+		if e != nil {
+			err = &errortypes.ReadError{
+				errors.Wrap(e, "vulnerability: Failed to open zip file"),
+			}
+			return
+		}
+
+		fileData, e := readLimit(fileReader)
+		fileReader.Close()
+		if e != nil {
+			err = e
+			return
+		}
+
+		res, e := Import(db, file.Name, fileData)
+		if e != nil {
+			err = e
+			return
+		}
+
+		result.add(res)
+	}
+
+	return
+}
+
+func Import(db *database.Database, name string, data []byte) (
+	result *ImportResult, err error) {
+
+	name = strings.ToLower(name)
+
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		result, err = importZip(db, data)
+		return
+	case strings.HasSuffix(name, ".gz"):
+		reader, e := gzip.NewReader(bytes.NewReader(data))
+		if e != nil {
+			err = &errortypes.ReadError{
+				errors.Wrap(e, "vulnerability: Failed to open gzip"),
+			}
+			return
+		}
+		defer reader.Close()
+
+		data, err = readLimit(reader)
+		if err != nil {
+			return
+		}
+
+		result, err = Import(db, strings.TrimSuffix(name, ".gz"), data)
+		return
+	case strings.HasSuffix(name, ".xz"):
+		reader, e := xz.NewReader(bytes.NewReader(data))
+		if e != nil {
+			err = &errortypes.ReadError{
+				errors.Wrap(e, "vulnerability: Failed to open xz"),
+			}
+			return
+		}
+
+		data, err = readLimit(reader)
+		if err != nil {
+			return
+		}
+
+		result, err = Import(db, strings.TrimSuffix(name, ".xz"), data)
+		return
+	}
+
+	result = &ImportResult{}
+
+	if path.Ext(name) == ".xml" ||
+		bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+
+		result.Format = Vuxml
+		result.Vuxmls, err = vuxml.Import(db, data)
+		return
+	}
+
+	switch detectJson(data) {
+	case Nvd:
+		result.Format = Nvd
+		result.Vulnerabilities, err = ImportNvd(db, data)
+	case Osv:
+		result.Format = Osv
+		result.Vulnerabilities, err = ImportOsv(db, data)
+	default:
+		err = &errortypes.ParseError{
+			errors.Newf("vulnerability: Unknown import format '%s'", name),
+		}
+	}
+
+	return
+}
+
+func ImportReader(db *database.Database, name string,
+	reader io.Reader) (result *ImportResult, err error) {
+
+	data, err := readLimit(reader)
+	if err != nil {
+		return
+	}
+
+	result, err = Import(db, name, data)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func ImportFile(db *database.Database, pth string) (
+	result *ImportResult, err error) {
+
+	file, err := os.Open(pth)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "vulnerability: Failed to open import file"),
+		}
+		return
+	}
+	defer file.Close()
+
+	result, err = ImportReader(db, path.Base(pth), file)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -70,6 +70,34 @@ type redhatCvss3 struct {
 	Status        string `json:"status"`
 }
 
+type ubuntuCvssV3 struct {
+	BaseScore             float64 `json:"baseScore"`
+	BaseSeverity          string  `json:"baseSeverity"`
+	AttackVector          string  `json:"attackVector"`
+	AttackComplexity      string  `json:"attackComplexity"`
+	PrivilegesRequired    string  `json:"privilegesRequired"`
+	UserInteraction       string  `json:"userInteraction"`
+	Scope                 string  `json:"scope"`
+	ConfidentialityImpact string  `json:"confidentialityImpact"`
+	IntegrityImpact       string  `json:"integrityImpact"`
+	AvailabilityImpact    string  `json:"availabilityImpact"`
+}
+
+type ubuntuImpact struct {
+	BaseMetricV3 struct {
+		CvssV3 ubuntuCvssV3 `json:"cvssV3"`
+	} `json:"baseMetricV3"`
+}
+
+type ubuntuResponse struct {
+	Id                string       `json:"id"`
+	Description       string       `json:"description"`
+	UbuntuDescription string       `json:"ubuntu_description"`
+	Priority          string       `json:"priority"`
+	Status            string       `json:"status"`
+	Impact            ubuntuImpact `json:"impact"`
+}
+
 type redhatResponse struct {
 	Name           string      `json:"name"`
 	ThreatSeverity string      `json:"threat_severity"`
@@ -96,6 +124,21 @@ func normalizeRedhatSeverity(severity string) string {
 	}
 }
 
+func normalizeUbuntuPriority(priority string) string {
+	switch strings.ToLower(priority) {
+	case "critical":
+		return Critical
+	case "high":
+		return High
+	case "medium":
+		return Medium
+	case "low", "negligible":
+		return Low
+	default:
+		return ""
+	}
+}
+
 func parseCvss3Vector(vector string) (av, ac, pr, ui, scope, c, i, a string) {
 	parts := strings.Split(vector, "/")
 	for _, part := range parts {
@@ -219,11 +262,17 @@ func normalizeValue(val string) string {
 }
 
 func getIdPrefix() string {
+	if settings.Telemetry.Offline {
+		return "local:"
+	}
+
 	switch settings.Telemetry.CveSource {
 	case RedHat:
 		return "rh:"
 	case Nist:
 		return "nvd:"
+	case Ubuntu:
+		return "ubuntu:"
 	}
 
 	return ""
@@ -471,6 +520,149 @@ func getOneRedhat(db *database.Database, cveId string) (
 	return
 }
 
+func getOneUbuntu(db *database.Database, cveId string) (
+	vuln *Vulnerability, err error) {
+
+	u, err := url.Parse(ubuntuApi)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "vulnerability: Failed to parse ubuntu url"),
+		}
+		return
+	}
+
+	u.Path = fmt.Sprintf(ubuntuApiPath, strings.ToUpper(cveId))
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "vulnerability: Failed to create request"),
+		}
+		return
+	}
+
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "vulnerability: Request failed"),
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		err = &errortypes.NotFoundError{
+			errors.New("vulnerability: Not found"),
+		}
+		return
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		err = &errortypes.RequestError{
+			errors.Newf(
+				"vulnerability: Bad status status %d", resp.StatusCode,
+			),
+		}
+		return
+	}
+
+	ubResp := &ubuntuResponse{}
+	err = json.NewDecoder(resp.Body).Decode(ubResp)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "vulnerability: Failed to decode response"),
+		}
+		return
+	}
+
+	if ubResp.Id == "" {
+		err = &errortypes.NotFoundError{
+			errors.New("vulnerability: Not found"),
+		}
+		return
+	}
+
+	vuln = &Vulnerability{
+		Id:          "ubuntu:" + strings.ToUpper(ubResp.Id),
+		Timestamp:   time.Now(),
+		Status:      Analyzed,
+		Description: ubResp.Description,
+		Statement:   ubResp.UbuntuDescription,
+		Severity:    normalizeUbuntuPriority(ubResp.Priority),
+	}
+
+	if ubResp.Status == "rejected" {
+		vuln.Status = Rejected
+	}
+
+	cvss := ubResp.Impact.BaseMetricV3.CvssV3
+	if cvss.BaseScore != 0 {
+		vuln.Score = cvss.BaseScore
+		if vuln.Severity == "" {
+			vuln.Severity = normalizeValue(cvss.BaseSeverity)
+		}
+		vuln.Vector = normalizeValue(cvss.AttackVector)
+		vuln.Complexity = normalizeValue(cvss.AttackComplexity)
+		vuln.Privileges = normalizeValue(cvss.PrivilegesRequired)
+		vuln.Interaction = normalizeValue(cvss.UserInteraction)
+		vuln.Scope = normalizeValue(cvss.Scope)
+		vuln.Confidentiality = normalizeValue(cvss.ConfidentialityImpact)
+		vuln.Integrity = normalizeValue(cvss.IntegrityImpact)
+		vuln.Availability = normalizeValue(cvss.AvailabilityImpact)
+	}
+
+	errData, err := vuln.Validate(db)
+	if err != nil {
+		return
+	}
+	if errData != nil {
+		err = errData.GetError()
+		return
+	}
+
+	err = vuln.Commit(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func getOneLocal(db *database.Database, cveId string) (
+	vuln *Vulnerability, err error) {
+
+	vuln, err = getOne(db, &bson.M{
+		"_id": "local:" + strings.ToUpper(cveId),
+	})
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func getOneSource(db *database.Database, cveId string) (
+	vuln *Vulnerability, err error) {
+
+	if settings.Telemetry.Offline {
+		vuln, err = getOneLocal(db, cveId)
+		return
+	}
+
+	switch settings.Telemetry.CveSource {
+	case RedHat:
+		vuln, err = getOneRedhat(db, cveId)
+	case Ubuntu:
+		vuln, err = getOneUbuntu(db, cveId)
+	default:
+		vuln, err = getOneNvd(db, cveId)
+	}
+
+	return
+}
+
 func GetOneLimit(db *database.Database, cveId string) (
 	vuln *Vulnerability, err error) {
 
@@ -485,7 +677,7 @@ func GetOneLimit(db *database.Database, cveId string) (
 		}
 	}
 
-	if vuln.IsFresh() {
+	if vuln.IsFresh() || settings.Telemetry.Offline {
 		return
 	}
 
@@ -494,6 +686,9 @@ func GetOneLimit(db *database.Database, cveId string) (
 	if settings.Telemetry.CveSource == RedHat {
 		limit = time.Duration(
 			settings.Telemetry.RedhatApiLimit) * time.Second
+	} else if settings.Telemetry.CveSource == Ubuntu {
+		limit = time.Duration(
+			settings.Telemetry.UbuntuApiLimit) * time.Second
 	} else if settings.Telemetry.NvdApiKey != "" {
 		limit = time.Duration(
 			settings.Telemetry.NvdApiAuthLimit) * time.Second
@@ -505,11 +700,7 @@ func GetOneLimit(db *database.Database, cveId string) (
 	}
 	lastCall = time.Now()
 
-	if settings.Telemetry.CveSource == RedHat {
-		vuln, err = getOneRedhat(db, cveId)
-	} else {
-		vuln, err = getOneNvd(db, cveId)
-	}
+	vuln, err = getOneSource(db, cveId)
 	if err != nil {
 		return
 	}
@@ -535,11 +726,7 @@ func GetOne(db *database.Database, cveId string) (
 		return
 	}
 
-	if settings.Telemetry.CveSource == RedHat {
-		vuln, err = getOneRedhat(db, cveId)
-	} else {
-		vuln, err = getOneNvd(db, cveId)
-	}
+	vuln, err = getOneSource(db, cveId)
 	if err != nil {
 		return
 	}
@@ -550,11 +737,7 @@ func GetOne(db *database.Database, cveId string) (
 func GetOneForce(db *database.Database, cveId string) (
 	vuln *Vulnerability, err error) {
 
-	if settings.Telemetry.CveSource == RedHat {
-		vuln, err = getOneRedhat(db, cveId)
-	} else {
-		vuln, err = getOneNvd(db, cveId)
-	}
+	vuln, err = getOneSource(db, cveId)
 	if err != nil {
 		return
 	}
@@ -158,10 +158,17 @@ func (v *Vulnerability) IsFresh() bool {
 		return false
 	}
 
+	if settings.Telemetry.Offline {
+		return true
+	}
+
 	var ttl, finalTtl int
 	if settings.Telemetry.CveSource == RedHat {
 		ttl = settings.Telemetry.RedhatTtl
 		finalTtl = settings.Telemetry.RedhatFinalTtl
+	} else if settings.Telemetry.CveSource == Ubuntu {
+		ttl = settings.Telemetry.UbuntuTtl
+		finalTtl = settings.Telemetry.UbuntuFinalTtl
 	} else {
 		ttl = settings.Telemetry.NvdTtl
 		finalTtl = settings.Telemetry.NvdFinalTtl
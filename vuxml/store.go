@@ -0,0 +1,89 @@
+package vuxml
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/v2/bson"
+	"github.com/pritunl/pritunl-cloud/database"
+)
+
+type storedEntry struct {
+	Id         string    `bson:"_id"`
+	Topic      string    `bson:"topic"`
+	Entry      string    `bson:"entry"`
+	Packages   []string  `bson:"packages"`
+	Cves       []string  `bson:"cves"`
+	Paragraphs []string  `bson:"paragraphs"`
+	Imported   time.Time `bson:"imported"`
+}
+
+func Import(db *database.Database, data []byte) (count int, err error) {
+	entries, err := ParseVuxml(data)
+	if err != nil {
+		return
+	}
+
+	coll := db.Vuxmls()
+	now := time.Now()
+
+	for _, entry := range entries {
+		err = coll.Upsert(&bson.M{
+			"_id": entry.Vid,
+		}, &storedEntry{
+			Id:         entry.Vid,
+			Topic:      entry.Topic,
+			Entry:      entry.Entry,
+			Packages:   entry.Packages,
+			Cves:       entry.Cves,
+			Paragraphs: entry.Paragraphs,
+			Imported:   now,
+		})
+		if err != nil {
+			return
+		}
+
+		count += 1
+	}
+
+	return
+}
+
+func LoadDb(db *database.Database) (
+	entries map[string]*VuxmlEntry, err error) {
+
+	coll := db.Vuxmls()
+	entries = map[string]*VuxmlEntry{}
+
+	cursor, err := coll.Find(db, &bson.M{})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		doc := &storedEntry{}
+		err = cursor.Decode(doc)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		entries[doc.Id] = &VuxmlEntry{
+			Vid:        doc.Id,
+			Topic:      doc.Topic,
+			Entry:      doc.Entry,
+			Packages:   doc.Packages,
+			Cves:       doc.Cves,
+			Paragraphs: doc.Paragraphs,
+		}
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
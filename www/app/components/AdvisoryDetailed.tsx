@@ -923,6 +923,10 @@ export default class AdvisoryDetailed extends React.Component<Props, State> {
 			typeLabel = 'Red Hat Security Advisory';
 		} else if (advisory.type === 'freebsd') {
 			typeLabel = 'FreeBSD VuXML Security Advisory';
+		} else if (advisory.type === 'debian') {
+			typeLabel = 'Debian Security Update';
+		} else if (advisory.type === 'ubuntu') {
+			typeLabel = 'Ubuntu Security Update';
 		}
 
 		let statusClass = 'bp5-tag tab-close ' +
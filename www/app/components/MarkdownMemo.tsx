@@ -58,6 +58,8 @@ const MarkdownWrap = React.memo<Props>((props) => {
 					className += " intent-secondary"
 				} else if (phase === "reload") {
 					className += " intent-primary"
+				} else if (phase === "pre-stop" || phase === "shutdown") {
+					className += " intent-danger"
 				}
 
 				const codeRef = React.useRef<HTMLElement>(null);
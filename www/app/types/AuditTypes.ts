@@ -7,11 +7,14 @@ export const CHANGE = 'audit.change';
 
 export interface Audit {
 	id?: string;
+	sequence?: number;
 	user?: string;
 	timestamp?: string;
 	type?: string;
 	fields?: {[key: string]: string};
 	agent?: AgentTypes.Agent;
+	prev?: string;
+	hash?: string;
 }
 
 export type Audits = Audit[];
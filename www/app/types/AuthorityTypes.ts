@@ -15,8 +15,29 @@ export interface Authority {
 	key?: string;
 	principals?: string[];
 	certificate?: string;
+	host_certificates?: boolean;
+	user_ttl?: number;
+	host_ttl?: number;
 }
 
+export interface SshCertificate {
+	id?: string;
+	authority?: string;
+	organization?: string;
+	type?: string;
+	user?: string;
+	instance?: string;
+	key_id?: string;
+	serial?: number;
+	principals?: string[];
+	fingerprint?: string;
+	valid_after?: string;
+	valid_before?: string;
+	timestamp?: string;
+}
+
+export type SshCertificates = SshCertificate[];
+
 export interface Filter {
 	id?: string;
 	name?: string;
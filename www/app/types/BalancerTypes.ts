@@ -13,6 +13,28 @@ export interface Backend {
 	protocol?: string;
 	hostname?: string;
 	port?: number;
+	group?: string;
+	weight?: number;
+}
+
+export interface Header {
+	name?: string;
+	value?: string;
+}
+
+export interface Route {
+	domain?: string;
+	path?: string;
+	regex?: string;
+	group?: string;
+	rewrite?: string;
+	redirect_https?: boolean;
+	redirect_host?: string;
+	redirect_code?: number;
+	request_headers?: Header[];
+	request_headers_remove?: string[];
+	response_headers?: Header[];
+	response_headers_remove?: string[];
 }
 
 export interface State {
@@ -39,10 +61,34 @@ export interface Balancer {
 	websockets?: boolean;
 	domains?: Domain[];
 	backends?: Backend[];
+	routes?: Route[];
 	check_path?: string;
+	rate_limit?: number;
+	rate_limit_burst?: number;
+	rate_limit_key?: string;
+	rate_limit_header?: string;
+	sticky_sessions?: boolean;
+	sticky_cookie?: string;
+	access_log?: string;
 	states?: {[key: string]: State};
 }
 
+export interface AccessLog {
+	id?: string;
+	balancer?: string;
+	node?: string;
+	timestamp?: string;
+	client?: string;
+	method?: string;
+	host?: string;
+	path?: string;
+	status?: number;
+	latency?: number;
+	backend?: string;
+	bytes?: number;
+	limited?: boolean;
+}
+
 export interface Filter {
 	id?: string;
 	name?: string;
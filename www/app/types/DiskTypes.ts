@@ -29,6 +29,10 @@ export interface Disk {
 	new_size?: number;
 	backup?: boolean;
 	backups?: Backup[];
+	target_node?: string;
+	target_pool?: string;
+	target_disk?: string;
+	progress?: number;
 	instance_info?: InstanceInfo;
 }
 
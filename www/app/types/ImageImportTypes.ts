@@ -0,0 +1,65 @@
+/// <reference path="../References.d.ts"/>
+export const SYNC = 'image_import.sync';
+export const TRAVERSE = 'image_import.traverse';
+export const FILTER = 'image_import.filter';
+export const CHANGE = 'image_import.change';
+
+export const CHUNK_SIZE = 64 * 1024 * 1024;
+
+export interface Part {
+	number?: number;
+	etag?: string;
+	size?: number;
+}
+
+export interface ImageImport {
+	id?: string;
+	name?: string;
+	comment?: string;
+	organization?: string;
+	storage?: string;
+	datacenter?: string;
+	source?: string;
+	url?: string;
+	format?: string;
+	detected?: string;
+	firmware?: string;
+	system_type?: string;
+	system_kind?: string;
+	state?: string;
+	stage?: string;
+	error?: string;
+	size?: number;
+	chunk_size?: number;
+	parts?: {[key: string]: Part};
+	progress?: number;
+	speed?: number;
+	hash?: string;
+	image?: string;
+	node?: string;
+	created?: string;
+	timestamp?: string;
+}
+
+export interface Filter {
+	state?: string;
+	organization?: string;
+}
+
+export type ImageImports = ImageImport[];
+
+export type ImageImportRo = Readonly<ImageImport>;
+export type ImageImportsRo = ReadonlyArray<ImageImportRo>;
+
+export interface ImageImportDispatch {
+	type: string;
+	data?: {
+		id?: string;
+		imageImport?: ImageImport;
+		imageImports?: ImageImports;
+		page?: number;
+		pageCount?: number;
+		filter?: Filter;
+		count?: number;
+	};
+}
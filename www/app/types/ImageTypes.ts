@@ -20,12 +20,36 @@ export interface Image {
 	system_type?: string;
 	system_kind?: string;
 	firmware?: string;
+	cloud_init?: string;
+	default_user?: string;
 	etag?: string;
 	last_modified?: string;
 	storage_class?: string;
 	tags?: string[];
 }
 
+export interface Release {
+	name?: string;
+	system_kind?: string;
+	system_type?: string;
+	firmware?: string;
+	cloud_init?: string;
+	default_user?: string;
+}
+
+export interface Catalog {
+	id?: string;
+	version?: number;
+	storage?: string;
+	hash?: string;
+	timestamp?: string;
+}
+
+export interface CatalogData {
+	catalogs?: Catalog[];
+	releases?: Release[];
+}
+
 export interface Filter {
 	id?: string;
 	name?: string;
@@ -20,6 +20,9 @@ export interface Instance {
 	image_backing?: boolean;
 	disk_type?: string;
 	disk_pool?: string;
+	clone_source?: string;
+	clone_disks?: boolean;
+	clone_quiesce?: boolean;
 	status?: string;
 	status_info?: StatusInfo;
 	uptime?: string;
@@ -33,7 +36,10 @@ export interface Instance {
 	reset_firmware?: boolean;
 	cloud_type?: string;
 	cloud_script?: string;
+	cloud_user?: string;
 	delete_protection?: boolean;
+	power_schedules?: PowerSchedule[];
+	expire?: string;
 	skip_source_dest_check?: boolean;
 	qemu_version?: string;
 	public_ips?: string[];
@@ -100,6 +106,12 @@ export interface Filter {
 	subnet?: string;
 }
 
+export interface PowerSchedule {
+	action?: string;
+	cron?: string;
+	timezone?: string;
+}
+
 export interface StatusInfo {
 	download_progress: number;
 	download_speed: number;
@@ -269,6 +281,13 @@ export function FilterNodePorts(nodePorts: NodePort[]): NodePort[] {
 	return newNodePorts
 }
 
+export interface SerialLog {
+	id?: string;
+	node?: string;
+	data?: string;
+	timestamp?: string;
+}
+
 export type Instances = Instance[];
 export type InstancesNode = Map<string, Instances>;
 
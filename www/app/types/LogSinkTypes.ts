@@ -0,0 +1,51 @@
+/// <reference path="../References.d.ts"/>
+export const SYNC = 'log_sink.sync';
+export const TRAVERSE = 'log_sink.traverse';
+export const FILTER = 'log_sink.filter';
+export const CHANGE = 'log_sink.change';
+
+export interface LogSink {
+	id?: string;
+	name?: string;
+	comment?: string;
+	organization?: string;
+	type?: string;
+	sources?: string[];
+	disabled?: boolean;
+	address?: string;
+	tls?: boolean;
+	url?: string;
+	username?: string;
+	password?: string;
+	token?: string;
+	tenant?: string;
+	batch_size?: number;
+	batch_interval?: number;
+	buffer_size?: number;
+}
+
+export interface Filter {
+	id?: string;
+	name?: string;
+	type?: string;
+	organization?: string;
+	comment?: string;
+}
+
+export type LogSinks = LogSink[];
+
+export type LogSinkRo = Readonly<LogSink>;
+export type LogSinksRo = ReadonlyArray<LogSinkRo>;
+
+export interface LogSinkDispatch {
+	type: string;
+	data?: {
+		id?: string;
+		logSink?: LogSink;
+		logSinks?: LogSinks;
+		page?: number;
+		pageCount?: number;
+		filter?: Filter;
+		count?: number;
+	};
+}
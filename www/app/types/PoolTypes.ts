@@ -12,6 +12,20 @@ export interface Pool {
 	zone?: string;
 	type?: string;
 	vg_name?: string;
+	thin_pool?: string;
+	overcommit?: number;
+	node?: string;
+	stats?: Stats;
+	path?: string;
+	mount?: boolean;
+}
+
+export interface Stats {
+	size?: number;
+	allocated?: number;
+	data_percent?: number;
+	meta_percent?: number;
+	timestamp?: string;
 }
 
 export interface Filter {
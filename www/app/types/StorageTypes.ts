@@ -14,6 +14,21 @@ export interface Storage {
 	access_key?: string;
 	secret_key?: string;
 	insecure?: boolean;
+	path?: string;
+	node_paths?: NodePath[];
+	mount?: boolean;
+	health?: {[key: string]: Health};
+}
+
+export interface NodePath {
+	node?: string;
+	path?: string;
+}
+
+export interface Health {
+	status?: string;
+	message?: string;
+	timestamp?: string;
 }
 
 export interface Filter {
@@ -0,0 +1,48 @@
+/// <reference path="../References.d.ts"/>
+export const SYNC = 'task.sync';
+export const CHANGE = 'task.change';
+
+export interface Task {
+	name?: string;
+	version?: number;
+	hours?: number[];
+	minutes?: number[];
+	seconds?: number;
+	run_on_start?: boolean;
+	local?: boolean;
+	disabled?: boolean;
+	disabled_until?: string;
+	last_node?: string;
+	last_state?: string;
+	last_duration?: number;
+	last_success?: string;
+	last_failure?: string;
+	last_error?: string;
+}
+
+export interface Run {
+	id?: string;
+	task?: string;
+	node?: string;
+	local?: boolean;
+	manual?: boolean;
+	state?: string;
+	start?: string;
+	duration?: number;
+	error?: string;
+	trace?: string;
+}
+
+export type Tasks = Task[];
+export type Runs = Run[];
+
+export type TaskRo = Readonly<Task>;
+export type TasksRo = ReadonlyArray<TaskRo>;
+
+export interface TaskDispatch {
+	type: string;
+	data?: {
+		tasks?: Tasks;
+		runs?: Runs;
+	};
+}